@@ -9,9 +9,7 @@ import (
 	"time"
 
 	"github.com/stanlocht/snap/pkg/repository"
-	"github.com/stanlocht/snap/pkg/snapmoji"
 	"github.com/stanlocht/snap/pkg/storage"
-	"github.com/stanlocht/snap/pkg/user"
 	"github.com/spf13/cobra"
 )
 
@@ -34,22 +32,39 @@ The message must start with a Snapmoji (e.g., :sparkles:, ✨).`,
 			message = args[0]
 		}
 
+		// Get current directory
+		currentDir, err := os.Getwd()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error getting current directory: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Find repository
+		repo, err := repository.Find(currentDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Load the repository's snapmojis
+		snapmojis := loadSnapmojis(repo.Path)
+
 		// Handle emoji selection
 		if selectEmoji {
 			// Display numbered list of emojis
-			fmt.Println(snapmoji.GetNumberedSnapmojiList())
+			fmt.Println(snapmojis.NumberedList())
 			fmt.Print("Enter emoji number: ")
 
 			// Read user input
 			var emojiNumber int
 			_, err := fmt.Scanf("%d", &emojiNumber)
-			if err != nil || emojiNumber < 1 || emojiNumber > len(snapmoji.Snapmojis) {
+			if err != nil || emojiNumber < 1 || emojiNumber > len(snapmojis.Snapmojis) {
 				fmt.Fprintf(os.Stderr, "Error: invalid emoji number\n")
 				os.Exit(1)
 			}
 
 			// Get selected emoji
-			selectedSnapmoji, _ := snapmoji.GetSnapmojiByNumber(emojiNumber)
+			selectedSnapmoji, _ := snapmojis.ByNumber(emojiNumber)
 
 			// Get commit message text
 			if message == "" {
@@ -73,40 +88,29 @@ The message must start with a Snapmoji (e.g., :sparkles:, ✨).`,
 			fmt.Fprintln(os.Stderr, "Error: commit message is required")
 			fmt.Fprintln(os.Stderr, "Provide a message as an argument or use --select-emoji (-s) to select an emoji from a list")
 			fmt.Fprintln(os.Stderr, "\nAvailable Snapmojis:")
-			fmt.Fprintln(os.Stderr, snapmoji.GetSnapmojiList())
+			fmt.Fprintln(os.Stderr, snapmojis.List())
 			os.Exit(1)
 		} else if autoConvert {
 			// Auto-convert keywords to emojis
 			oldMessage := message
-			message = snapmoji.AutoConvertKeywordsToEmoji(message)
+			message = snapmojis.AutoConvert(message)
 			if oldMessage != message {
 				fmt.Printf("Auto-converted: '%s' to '%s'\n", oldMessage, message)
 			}
 		}
 
 		// Validate commit message (must start with a snapmoji)
-		if err := snapmoji.ValidateCommitMessage(message); err != nil {
+		if err := snapmojis.Validate(message); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n\n", err)
 			fmt.Fprintln(os.Stderr, "Try using --select-emoji (-s) to select an emoji from a list")
 			fmt.Fprintln(os.Stderr, "Or use --auto-convert (-c) to auto-convert keywords to emojis")
 			fmt.Fprintln(os.Stderr, "\nAvailable Snapmojis:")
-			fmt.Fprintln(os.Stderr, snapmoji.GetSnapmojiList())
+			fmt.Fprintln(os.Stderr, snapmojis.List())
 			os.Exit(1)
 		}
 
-		// Get current directory
-		currentDir, err := os.Getwd()
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error getting current directory: %v\n", err)
-			os.Exit(1)
-		}
-
-		// Find repository
-		repo, err := repository.Find(currentDir)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
-		}
+		// Lint commit message
+		lintCommitMessage(repo.Path, message)
 
 		// Get author name
 		authorName, _ := rootCmd.PersistentFlags().GetString("author")
@@ -151,15 +155,6 @@ The message must start with a Snapmoji (e.g., :sparkles:, ✨).`,
 			os.Exit(1)
 		}
 
-		// Record user action
-		userManager := user.NewUserManager(repo.Path)
-		timestamp := time.Now().Format(time.RFC3339)
-		err = userManager.RecordAction(authorName, user.ActionCommit, message, timestamp)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error recording user action: %v\n", err)
-			os.Exit(1)
-		}
-
 		// Create tree from index
 		tree := &repository.Tree{
 			Entries: index.Entries,
@@ -173,10 +168,19 @@ The message must start with a Snapmoji (e.g., :sparkles:, ✨).`,
 			os.Exit(1)
 		}
 
+		timestamp := commit.Timestamp.Format(time.RFC3339)
 		fmt.Printf("Created commit %s\n", commit.ID[:7])
 		fmt.Printf("Message: %s\n", message)
 		fmt.Printf("Timestamp: %s\n", timestamp)
-		fmt.Printf("Earned %d points for committing!\n", user.PointValues[user.ActionCommit])
+
+		// Record user action, now that the commit exists
+		recordCommit(repo, authorName, commit, tree)
+
+		// Link the commit to the issues it mentions
+		linkCommitToIssues(repo, commit, authorName)
+
+		// Announce the achievements the commit earned
+		announceAchievements(repo, commit, authorName)
 	},
 }
 
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/stanlocht/snap/pkg/changelog"
+	"github.com/stanlocht/snap/pkg/repository"
+)
+
+// changelogCmd represents the changelog command
+var changelogCmd = &cobra.Command{
+	Use:   "changelog [<from>..<to> | <to>]",
+	Short: "Generate release notes from commits",
+	Long: `Generate release notes for the commits between two revisions, grouped by the
+category of their snapmoji: Features, Fixes, Performance, Security and so on.
+The notes list the issues the commits reference, the contributors and the
+version bump the changes call for.
+
+Revisions are tags, branches or commit IDs:
+
+  snap changelog                    Since the last tag
+  snap changelog v1.0.0..v1.1.0     Between two tags
+  snap changelog v1.0.0..           Since a tag
+  snap changelog v1.1.0             Up to a tag, since the tag before it
+
+Formats are markdown, json and keepachangelog (keepachangelog.com). Issue
+references link to --issue-url, e.g. https://example.com/issues/%d.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		format, _ := cmd.Flags().GetString("format")
+		issueURL, _ := cmd.Flags().GetString("issue-url")
+		if issueURL != "" && strings.Count(issueURL, "%d") != 1 {
+			fmt.Fprintf(os.Stderr, "Error: --issue-url must contain %%d once, for the issue ID\n")
+			os.Exit(1)
+		}
+
+		// Get current directory
+		currentDir, err := os.Getwd()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error getting current directory: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Find repository
+		repo, err := repository.Find(currentDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Parse range, starting at the tag before to if from is left out
+		from, to := "", "HEAD"
+		if len(args) == 1 {
+			var isRange bool
+			if from, to, isRange = strings.Cut(args[0], ".."); !isRange {
+				from, to = "", args[0]
+			}
+			if to == "" {
+				to = "HEAD"
+			}
+		}
+		if len(args) == 0 || !strings.Contains(args[0], "..") {
+			toID, err := repo.ResolveRevision(to)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			if from, err = changelog.PreviousTag(repo, toID); err != nil {
+				fmt.Fprintf(os.Stderr, "Error finding previous tag: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		// Generate changelog
+		notes, err := changelog.Generate(repo, from, to)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error generating changelog: %v\n", err)
+			os.Exit(1)
+		}
+
+		switch format {
+		case "markdown":
+			fmt.Print(notes.Markdown(issueURL))
+		case "keepachangelog":
+			fmt.Print(notes.KeepAChangelog(issueURL))
+		case "json":
+			data, err := json.MarshalIndent(notes, "", "  ")
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error encoding changelog: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Println(string(data))
+		default:
+			fmt.Fprintf(os.Stderr, "Error: unknown format %q, use one of: %s\n", format, strings.Join(changelog.Formats, ", "))
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(changelogCmd)
+	changelogCmd.Flags().StringP("format", "f", "markdown", "Output format: markdown, json or keepachangelog")
+	changelogCmd.Flags().String("issue-url", "", "URL of an issue, with %d for its ID, to link issue references to")
+}
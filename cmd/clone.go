@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/stanlocht/snap/pkg/remote"
+)
+
+// cloneCmd represents the clone command
+var cloneCmd = &cobra.Command{
+	Use:   "clone [url] [directory]",
+	Short: "Clone a repository into a new directory",
+	Long: `Clone a repository into a new directory.
+The new repository gets a remote called "origin" pointing at the source,
+remote-tracking references for each of its branches, and a working tree
+for the branch the source has checked out.`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		url := args[0]
+
+		// Work out the destination directory
+		var dir string
+		if len(args) > 1 {
+			dir = args[1]
+		} else {
+			dir = remote.DirFromURL(url)
+		}
+
+		fmt.Printf("Cloning into '%s'...\n", dir)
+
+		// Clone repository
+		repo, result, err := remote.Clone(url, dir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error cloning repository: %v\n", err)
+			os.Exit(1)
+		}
+
+		if len(result.Advertisement.Refs) == 0 {
+			fmt.Println("Warning: you appear to have cloned an empty repository")
+		} else {
+			fmt.Printf("Received %d objects\n", result.Objects)
+		}
+		fmt.Printf("Cloned into %s\n", repo.Path)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(cloneCmd)
+}
@@ -11,7 +11,6 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/stanlocht/snap/pkg/config"
 	"github.com/stanlocht/snap/pkg/repository"
-	"github.com/stanlocht/snap/pkg/snapmoji"
 	"github.com/stanlocht/snap/pkg/storage"
 	"github.com/stanlocht/snap/pkg/user"
 )
@@ -28,22 +27,39 @@ All commit messages must start with a Snapmoji (e.g., :sparkles:, ✨).`,
 		selectEmoji, _ := cmd.Flags().GetBool("select-emoji")
 		autoConvert, _ := cmd.Flags().GetBool("auto-convert")
 
+		// Get current directory
+		currentDir, err := os.Getwd()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error getting current directory: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Find repository
+		repo, err := repository.Find(currentDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Load the repository's snapmojis
+		snapmojis := loadSnapmojis(repo.Path)
+
 		// Handle emoji selection
 		if selectEmoji {
 			// Display numbered list of emojis
-			fmt.Println(snapmoji.GetNumberedSnapmojiList())
+			fmt.Println(snapmojis.NumberedList())
 			fmt.Print("Enter emoji number: ")
 
 			// Read user input
 			var emojiNumber int
 			_, err := fmt.Scanf("%d", &emojiNumber)
-			if err != nil || emojiNumber < 1 || emojiNumber > len(snapmoji.Snapmojis) {
+			if err != nil || emojiNumber < 1 || emojiNumber > len(snapmojis.Snapmojis) {
 				fmt.Fprintf(os.Stderr, "Error: invalid emoji number\n")
 				os.Exit(1)
 			}
 
 			// Get selected emoji
-			selectedSnapmoji, _ := snapmoji.GetSnapmojiByNumber(emojiNumber)
+			selectedSnapmoji, _ := snapmojis.ByNumber(emojiNumber)
 
 			// Get commit message text
 			if message == "" {
@@ -68,40 +84,29 @@ All commit messages must start with a Snapmoji (e.g., :sparkles:, ✨).`,
 			fmt.Fprintln(os.Stderr, "Use --message (-m) to specify a commit message")
 			fmt.Fprintln(os.Stderr, "Or use --select-emoji (-s) to select an emoji from a list")
 			fmt.Fprintln(os.Stderr, "\nAvailable Snapmojis:")
-			fmt.Fprintln(os.Stderr, snapmoji.GetSnapmojiList())
+			fmt.Fprintln(os.Stderr, snapmojis.List())
 			os.Exit(1)
 		} else if autoConvert {
 			// Auto-convert keywords to emojis
 			oldMessage := message
-			message = snapmoji.AutoConvertKeywordsToEmoji(message)
+			message = snapmojis.AutoConvert(message)
 			if oldMessage != message {
 				fmt.Printf("Auto-converted: '%s' to '%s'\n", oldMessage, message)
 			}
 		}
 
 		// Validate commit message (must start with a snapmoji)
-		if err := snapmoji.ValidateCommitMessage(message); err != nil {
+		if err := snapmojis.Validate(message); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n\n", err)
 			fmt.Fprintln(os.Stderr, "Try using --select-emoji (-s) to select an emoji from a list")
 			fmt.Fprintln(os.Stderr, "Or use --auto-convert (-c) to auto-convert keywords to emojis")
 			fmt.Fprintln(os.Stderr, "\nAvailable Snapmojis:")
-			fmt.Fprintln(os.Stderr, snapmoji.GetSnapmojiList())
-			os.Exit(1)
-		}
-
-		// Get current directory
-		currentDir, err := os.Getwd()
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error getting current directory: %v\n", err)
+			fmt.Fprintln(os.Stderr, snapmojis.List())
 			os.Exit(1)
 		}
 
-		// Find repository
-		repo, err := repository.Find(currentDir)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
-		}
+		// Lint commit message
+		lintCommitMessage(repo.Path, message)
 
 		// Get author name
 		authorName, _ := rootCmd.PersistentFlags().GetString("author")
@@ -119,15 +124,6 @@ All commit messages must start with a Snapmoji (e.g., :sparkles:, ✨).`,
 			}
 		}
 
-		// Record user action
-		userManager := user.NewUserManager(repo.Path)
-		timestamp := time.Now().Format(time.RFC3339)
-		err = userManager.RecordAction(authorName, user.ActionCommit, message, timestamp)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error recording user action: %v\n", err)
-			os.Exit(1)
-		}
-
 		// Load index
 		index, err := storage.LoadIndex(repo.Path)
 		if err != nil {
@@ -158,13 +154,136 @@ All commit messages must start with a Snapmoji (e.g., :sparkles:, ✨).`,
 			os.Exit(1)
 		}
 
+		timestamp := commit.Timestamp.Format(time.RFC3339)
 		fmt.Printf("Created commit %s\n", commit.ID[:7])
 		fmt.Printf("Message: %s\n", message)
 		fmt.Printf("Timestamp: %s\n", timestamp)
-		fmt.Printf("Earned %d points for committing!\n", user.PointValues[user.ActionCommit])
+
+		// Record user action, now that the commit exists
+		recordCommit(repo, authorName, commit, tree)
+
+		// Link the commit to the issues it mentions
+		linkCommitToIssues(repo, commit, authorName)
+
+		// Announce the achievements the commit earned
+		announceAchievements(repo, commit, authorName)
 	},
 }
 
+// recordCommit awards the author points for a commit, as the repository's
+// rules score its message and the files it changed, and takes back the points
+// of the commit it reverts. Failures are reported as warnings.
+func recordCommit(repo *repository.Repository, authorName string, commit *repository.Commit, tree *repository.Tree) {
+	changed, err := repo.ChangedFiles(commit.ParentID, tree)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record user action: %v\n", err)
+		return
+	}
+
+	userManager := user.NewUserManager(repo.Path)
+	activity := user.Activity{
+		Action:      user.ActionCommit,
+		Description: commit.Message,
+		Files:       len(changed),
+		Timestamp:   commit.Timestamp.Format(time.RFC3339),
+		Email:       commit.Email,
+		Commit:      commit.ID,
+		Empty:       len(changed) == 0,
+		Reverts:     user.RevertedCommit(commit.Message),
+	}
+	points, err := userManager.Record(authorName, activity)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record user action: %v\n", err)
+		return
+	}
+
+	switch {
+	case activity.Empty:
+		fmt.Println("No points for a commit that changes no files")
+	case activity.Reverts != "":
+		fmt.Println("No points for a revert")
+		clawback(userManager, activity.Reverts, user.FlagReverted)
+	default:
+		fmt.Printf("Earned %d points for committing!\n", points)
+	}
+}
+
+// clawback takes back the points of a reverted or popped commit and says whose
+// they were. Failures are reported as warnings.
+func clawback(userManager *user.UserManager, commitID string, flag user.Flag) {
+	name, points, err := userManager.Clawback(commitID, flag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to take back points: %v\n", err)
+		return
+	}
+	if name != "" {
+		fmt.Printf("Took back %d points from %s for the %s commit %s\n", points, name, flag, commitID[:7])
+	}
+}
+
+// linkCommitToIssues links a new commit to the issues its message refers to,
+// closes the ones it fixes and awards the committer points for closing them.
+// The commit already exists, so failures are reported as warnings.
+func linkCommitToIssues(repo *repository.Repository, commit *repository.Commit, authorName string) {
+	issueManager := newIssueManager(repo)
+	issueManager.Author = authorName
+
+	result, err := issueManager.LinkCommit(commit.ID, commit.Message)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to link commit to issues: %v\n", err)
+		return
+	}
+
+	for _, id := range result.Missing {
+		fmt.Fprintf(os.Stderr, "Warning: commit mentions issue #%d, which does not exist\n", id)
+	}
+	for _, id := range result.Linked {
+		fmt.Printf("Linked commit to issue #%d\n", id)
+	}
+
+	userManager := user.NewUserManager(repo.Path)
+	for _, id := range result.Closed {
+		// Points may depend on the issue's estimate
+		var estimate time.Duration
+		if closed, err := issueManager.GetIssue(id); err == nil {
+			estimate = closed.Estimate
+		}
+
+		description := fmt.Sprintf("Closed issue #%d in commit %s", id, commit.ID[:7])
+		points, err := userManager.RecordIssueClose(authorName, commit.Email, estimate, description, time.Now().Format(time.RFC3339))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to record user action: %v\n", err)
+			continue
+		}
+		fmt.Printf("Closed issue #%d! Earned %d points\n", id, points)
+	}
+
+	// The commit closed these issues already; point out the ones closed out of order
+	for _, id := range result.Closed {
+		blockers, err := issueManager.OpenBlockers(id)
+		if err != nil {
+			continue
+		}
+		for _, blocker := range blockers {
+			fmt.Fprintf(os.Stderr, "Warning: issue #%d was closed but is still blocked by open issue #%d\n", id, blocker.ID)
+		}
+	}
+}
+
+// announceAchievements evaluates the committer's achievements and announces
+// the ones the new commit earned. Failures are reported as warnings.
+func announceAchievements(repo *repository.Repository, commit *repository.Commit, authorName string) {
+	userManager := user.NewUserManager(repo.Path)
+	achievements, err := userManager.UpdateAchievements(authorName, commit.Email, commit.Timestamp)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to update achievements: %v\n", err)
+		return
+	}
+	for _, achievement := range achievements {
+		fmt.Printf("🏆 Achievement unlocked: %s %s – %s\n", achievement.Emoji, achievement.Name, achievement.Description)
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(commitCmd)
 	commitCmd.Flags().StringP("message", "m", "", "Commit message (must start with a snapmoji)")
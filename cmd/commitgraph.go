@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/stanlocht/snap/pkg/repository"
+)
+
+// commitGraphCmd represents the commit-graph command
+var commitGraphCmd = &cobra.Command{
+	Use:   "commit-graph",
+	Short: "Manage the commit-graph file",
+	Long: `Manage the commit-graph file.
+The commit graph caches the ancestry of every commit so that history walks,
+commit counts and merge-base queries don't need to read each commit object.
+It is updated automatically on every commit.`,
+}
+
+// commitGraphWriteCmd represents the commit-graph write command
+var commitGraphWriteCmd = &cobra.Command{
+	Use:   "write",
+	Short: "Rebuild the commit-graph file",
+	Long:  `Rebuild the commit-graph file from all commit objects in the repository.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Get current directory
+		currentDir, err := os.Getwd()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error getting current directory: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Find repository
+		repo, err := repository.Find(currentDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Rebuild commit graph
+		graph, err := repo.RebuildCommitGraph()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing commit graph: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Wrote commit graph with %d commits\n", len(graph.Entries))
+	},
+}
+
+// commitGraphVerifyCmd represents the commit-graph verify command
+var commitGraphVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Check the commit-graph file",
+	Long:  `Check the commit-graph file against the commit objects in the repository.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Get current directory
+		currentDir, err := os.Getwd()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error getting current directory: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Find repository
+		repo, err := repository.Find(currentDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Verify commit graph
+		problems, err := repo.VerifyCommitGraph()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error verifying commit graph: %v\n", err)
+			os.Exit(1)
+		}
+
+		if len(problems) == 0 {
+			fmt.Println("Commit graph is valid")
+			return
+		}
+
+		for _, problem := range problems {
+			fmt.Println(problem)
+		}
+		fmt.Fprintln(os.Stderr, "Run 'snap commit-graph write' to rebuild the commit graph")
+		os.Exit(1)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(commitGraphCmd)
+	commitGraphCmd.AddCommand(commitGraphWriteCmd)
+	commitGraphCmd.AddCommand(commitGraphVerifyCmd)
+}
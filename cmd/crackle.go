@@ -47,55 +47,22 @@ This command shows a colorful and stylized view of the commit history.`,
 		fmt.Println("✨ Stylized Commit Log with Snapmojis ✨")
 		fmt.Println(strings.Repeat("=", 60))
 
-		// Define some snapmoji prefixes for different types of commits
-		emojiMap := map[string]string{
-			"sparkles":            "✨",
-			"bug":                 "🐛",
-			"books":               "📚",
-			"recycle":             "♻️",
-			"wrench":              "🔧",
-			"white_check_mark":    "✅",
-			"rocket":              "🚀",
-			"lipstick":            "💄",
-			"fire":                "🔥",
-			"ambulance":           "🚑",
-			"art":                 "🎨",
-			"zap":                 "⚡️",
-			"lock":                "🔒",
-			"construction":        "🚧",
-			"memo":                "📝",
-			"truck":               "🚚",
-			"construction_worker": "👷",
-			"heavy_plus_sign":     "➕",
-			"heavy_minus_sign":    "➖",
-			"bookmark":            "🔖",
-		}
+		// Load the repository's snapmojis
+		snapmojis := loadSnapmojis(repo.Path)
 
 		// Display each commit with emoji and formatting
 		for i, commit := range history {
 			// Extract emoji from commit message if present
 			emoji := "📦" // Default emoji
-			for code, e := range emojiMap {
-				if strings.Contains(commit.Message, e) || strings.Contains(commit.Message, ":"+code+":") {
-					emoji = e
-					break
-				}
+			if s, ok := snapmojis.Match(commit.Message); ok {
+				emoji = s.Emoji
 			}
 
 			// Format date
 			date := commit.Timestamp.Format("2006-01-02 15:04:05")
 
 			// Format commit message (remove emoji if present)
-			message := commit.Message
-			for _, e := range emojiMap {
-				message = strings.TrimPrefix(message, e+" ")
-				message = strings.TrimPrefix(message, e)
-			}
-			// Also remove emoji codes
-			for code := range emojiMap {
-				message = strings.TrimPrefix(message, ":"+code+": ")
-				message = strings.TrimPrefix(message, ":"+code+":")
-			}
+			message := snapmojis.Strip(commit.Message)
 
 			// Print commit with formatting
 			fmt.Printf("%s %s | %s | %s\n", emoji, date, commit.Author, message)
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/stanlocht/snap/pkg/remote"
+	"github.com/stanlocht/snap/pkg/repository"
+)
+
+// fetchCmd represents the fetch command
+var fetchCmd = &cobra.Command{
+	Use:   "fetch [remote]",
+	Short: "Download objects and refs from a remote",
+	Long: `Download commits from a remote repository and update the remote-tracking
+references under refs/remotes/<remote>/. Your own branches are not changed.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Get remote name
+		remoteName := remote.DefaultRemoteName
+		if len(args) > 0 {
+			remoteName = args[0]
+		}
+
+		// Get current directory
+		currentDir, err := os.Getwd()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error getting current directory: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Find repository
+		repo, err := repository.Find(currentDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Fetch from remote
+		result, err := remote.Fetch(repo, remoteName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error fetching from %s: %v\n", remoteName, err)
+			os.Exit(1)
+		}
+
+		printFetchResult(result)
+	},
+}
+
+// printFetchResult prints the references updated by a fetch
+func printFetchResult(result *remote.FetchResult) {
+	if len(result.Updated) == 0 {
+		fmt.Printf("Already up to date with %s\n", result.Remote.Name)
+		return
+	}
+
+	fmt.Printf("From %s (%d objects)\n", result.Remote.URL, result.Objects)
+	for _, change := range result.Updated {
+		switch {
+		case change.New == "":
+			fmt.Printf("  - [deleted]         %s\n", change.Name)
+		case change.Old == "":
+			fmt.Printf("  * [new]             %s\n", change.Name)
+		default:
+			fmt.Printf("    %s..%s  %s\n", change.Old[:7], change.New[:7], change.Name)
+		}
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(fetchCmd)
+}
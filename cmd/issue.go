@@ -1,12 +1,17 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
-	"strconv"
+	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/stanlocht/snap/pkg/config"
 	"github.com/stanlocht/snap/pkg/issue"
 	"github.com/stanlocht/snap/pkg/repository"
+	"github.com/stanlocht/snap/pkg/user"
 	"github.com/spf13/cobra"
 )
 
@@ -17,17 +22,63 @@ var issueCmd = &cobra.Command{
 	Long:  `Manage issues in the repository.`,
 }
 
+// newIssueManager creates an issue manager that records the current user on issue changes
+func newIssueManager(repo *repository.Repository) *issue.IssueManager {
+	issueManager := issue.NewIssueManager(repo.Path)
+
+	authorName, _ := rootCmd.PersistentFlags().GetString("author")
+	if authorName == "" {
+		authorName, _ = config.GetValue(filepath.Join(repo.Path, ".snap", "config"), "user.name")
+	}
+	issueManager.Author = authorName
+
+	return issueManager
+}
+
+// openIssueManager finds the repository in the current directory and creates an
+// issue manager for it, exiting on failure
+func openIssueManager() *issue.IssueManager {
+	// Get current directory
+	currentDir, err := os.Getwd()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error getting current directory: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Find repository
+	repo, err := repository.Find(currentDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	return newIssueManager(repo)
+}
+
+// resolveIssueArg finds the issue an argument refers to, exiting on failure
+func resolveIssueArg(issueManager *issue.IssueManager, ref string) *issue.Issue {
+	target, err := issueManager.ResolveIssue(ref)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	return target
+}
+
 // issueNewCmd represents the issue new command
 var issueNewCmd = &cobra.Command{
 	Use:   "new",
 	Short: "Create a new issue",
-	Long:  `Create a new issue in the repository.`,
+	Long: `Create a new issue in the repository.
+With --template, the issue starts from a template in .snap/issue_templates:
+without --description, the template opens in $EDITOR.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		// Get issue title and description
 		title, _ := cmd.Flags().GetString("title")
 		description, _ := cmd.Flags().GetString("description")
+		templateName, _ := cmd.Flags().GetString("template")
 
-		if title == "" {
+		if title == "" && templateName == "" {
 			fmt.Fprintln(os.Stderr, "Error: issue title is required")
 			fmt.Fprintln(os.Stderr, "Use --title to specify an issue title")
 			os.Exit(1)
@@ -53,8 +104,14 @@ var issueNewCmd = &cobra.Command{
 			authorName = "unknown"
 		}
 
+		// Create issue from a template
+		if templateName != "" {
+			newIssueFromTemplate(repo, templateName, title, description, authorName)
+			return
+		}
+
 		// Create issue manager
-		issueManager := issue.NewIssueManager(repo.Path)
+		issueManager := newIssueManager(repo)
 
 		// Create issue
 		newIssue, err := issueManager.CreateIssue(title, description, authorName)
@@ -71,34 +128,59 @@ var issueNewCmd = &cobra.Command{
 var issueListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List issues",
-	Long:  `List issues in the repository.`,
+	Long: `List issues in the repository.
+Use --label, --milestone, --priority and --assignee to narrow the list down,
+or --query for a search such as:
+
+  snap issue list --query 'is:open assignee:alice label:bug created:>2026-01-01 "crash on start"'
+
+Queries support is:, author:, assignee:, label:, milestone:, priority:, no:,
+created:, updated:, closed: and sort: (id, created, updated, priority or title,
+with an optional -asc or -desc suffix). A leading "-" negates a qualifier.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		// Get show-closed flag
+		// Get filter flags
 		showClosed, _ := cmd.Flags().GetBool("show-closed")
-
-		// Get current directory
-		currentDir, err := os.Getwd()
+		label, _ := cmd.Flags().GetString("label")
+		milestone, _ := cmd.Flags().GetString("milestone")
+		assignee, _ := cmd.Flags().GetString("assignee")
+		priorityStr, _ := cmd.Flags().GetString("priority")
+		queryStr, _ := cmd.Flags().GetString("query")
+		limit, _ := cmd.Flags().GetInt("limit")
+		page, _ := cmd.Flags().GetInt("page")
+
+		priority, err := issue.ParsePriority(priorityStr)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error getting current directory: %v\n", err)
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
 
-		// Find repository
-		repo, err := repository.Find(currentDir)
+		// Parse query; without one, closed issues are only listed on request
+		if queryStr == "" && !showClosed {
+			queryStr = "is:open"
+		}
+		query, err := issue.ParseQuery(queryStr)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
+		query.AddFilter(issue.Filter{
+			ShowClosed: true,
+			Label:      label,
+			Milestone:  milestone,
+			Priority:   priority,
+			Assignee:   assignee,
+		})
 
 		// Create issue manager
-		issueManager := issue.NewIssueManager(repo.Path)
+		issueManager := openIssueManager()
 
-		// List issues
-		issues, err := issueManager.ListIssues(showClosed)
+		// Search issues
+		result, err := issueManager.Search(query, page, limit)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error listing issues: %v\n", err)
 			os.Exit(1)
 		}
+		issues := result.Issues
 
 		if len(issues) == 0 {
 			fmt.Println("No issues found")
@@ -113,11 +195,20 @@ var issueListCmd = &cobra.Command{
 			}
 
 			fmt.Printf("#%d [%s] %s", issue.ID, statusStr, issue.Title)
+			if issue.Priority != "" {
+				fmt.Printf(" !%s", issue.Priority)
+			}
+			for _, label := range issue.Labels {
+				fmt.Printf(" {%s}", label)
+			}
 			if issue.AssignedTo != "" {
 				fmt.Printf(" (assigned to %s)", issue.AssignedTo)
 			}
 			fmt.Println()
 		}
+		if result.Pages() > 1 {
+			fmt.Printf("\nPage %d of %d (%d issues)\n", result.Page, result.Pages(), result.Total)
+		}
 	},
 }
 
@@ -125,16 +216,10 @@ var issueListCmd = &cobra.Command{
 var issueShowCmd = &cobra.Command{
 	Use:   "show [issue-id]",
 	Short: "Show issue details",
-	Long:  `Show details of a specific issue.`,
-	Args:  cobra.ExactArgs(1),
+	Long: `Show details of a specific issue.
+Issues can be referred to by number (e.g. 12 or #12) or by a prefix of their ID.`,
+	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		// Parse issue ID
-		id, err := strconv.Atoi(args[0])
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: invalid issue ID: %s\n", args[0])
-			os.Exit(1)
-		}
-
 		// Get current directory
 		currentDir, err := os.Getwd()
 		if err != nil {
@@ -150,45 +235,214 @@ var issueShowCmd = &cobra.Command{
 		}
 
 		// Create issue manager
-		issueManager := issue.NewIssueManager(repo.Path)
+		issueManager := newIssueManager(repo)
 
 		// Get issue
-		issue, err := issueManager.GetIssue(id)
+		issue, err := issueManager.ResolveIssue(args[0])
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: issue #%d not found\n", id)
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
 
 		// Print issue details
 		fmt.Printf("Issue #%d: %s\n", issue.ID, issue.Title)
+		fmt.Printf("ID: %s\n", issue.UID)
 		fmt.Printf("Status: %s\n", issue.Status)
+		if workflow, err := issueManager.Workflow(); err == nil {
+			fmt.Printf("State: %s\n", workflow.StateOf(issue))
+		}
 		fmt.Printf("Created by: %s at %s\n", issue.CreatedBy, issue.CreatedAt.Format("2006-01-02 15:04:05"))
 		if issue.Status == "closed" {
 			fmt.Printf("Closed at: %s\n", issue.ClosedAt.Format("2006-01-02 15:04:05"))
+			if issue.ClosedBy != "" {
+				fmt.Printf("Closed by commit: %s\n", issue.ClosedBy[:7])
+			}
 		}
 		if issue.AssignedTo != "" {
 			fmt.Printf("Assigned to: %s\n", issue.AssignedTo)
 		}
+		if issue.Priority != "" {
+			fmt.Printf("Priority: %s\n", issue.Priority)
+		}
+		if len(issue.Labels) > 0 {
+			fmt.Printf("Labels: %s\n", strings.Join(issue.Labels, ", "))
+		}
+		if issue.Milestone != "" {
+			fmt.Printf("Milestone: %s\n", issue.Milestone)
+		}
+		printEstimate(issue)
 		fmt.Println()
 		fmt.Println("Description:")
 		fmt.Println(issue.Description)
+
+		// Print relations
+		relations, err := issueManager.Relations(issue.ID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		printRelations("Blocks", relations.Blocks)
+		printRelations("Blocked by", relations.BlockedBy)
+		printRelations("Duplicate of", relations.DuplicateOf)
+		printRelations("Duplicates", relations.Duplicates)
+		printRelations("Related to", relations.Related)
+
+		// Print time log
+		printTimeLog(issue)
+
+		// Print timeline
+		history := issue.History()
+		if len(history) > 0 {
+			fmt.Println()
+			fmt.Println("Timeline:")
+			fmt.Printf("  %s %s opened the issue\n", issue.CreatedAt.Format("2006-01-02 15:04:05"), issue.CreatedBy)
+		}
+		for _, event := range history {
+			fmt.Printf("  %s %s %s\n", event.At.Format("2006-01-02 15:04:05"), event.Actor, event.Describe())
+		}
+
+		// Print linked commits
+		if len(issue.Commits) > 0 {
+			fmt.Println()
+			fmt.Printf("Commits (%d):\n", len(issue.Commits))
+		}
+		for _, commitID := range issue.Commits {
+			commit, err := repo.GetCommit(commitID)
+			if err != nil {
+				// The commit may not have been fetched yet
+				fmt.Printf("  %s\n", commitID[:7])
+				continue
+			}
+			fmt.Printf("  %s %s (%s)\n", commitID[:7], commit.Message, commit.Author)
+		}
+
+		// Print comments
+		thread := issue.CommentThread()
+		if len(thread) > 0 {
+			fmt.Println()
+			fmt.Printf("Comments (%d):\n", len(thread))
+		}
+		for _, comment := range thread {
+			indent := strings.Repeat("    ", comment.Depth)
+			edited := ""
+			if comment.Edited() {
+				edited = fmt.Sprintf(" (edited %s)", comment.LastModified().Format("2006-01-02 15:04:05"))
+			}
+			fmt.Println()
+			fmt.Printf("%s[%s] %s at %s%s\n", indent, comment.ID, comment.Author, comment.CreatedAt.Format("2006-01-02 15:04:05"), edited)
+			for _, line := range strings.Split(strings.TrimRight(comment.Body, "\n"), "\n") {
+				fmt.Printf("%s  %s\n", indent, line)
+			}
+		}
 	},
 }
 
-// issueCloseCmd represents the issue close command
-var issueCloseCmd = &cobra.Command{
-	Use:   "close [issue-id]",
-	Short: "Close an issue",
-	Long:  `Close an issue in the repository.`,
-	Args:  cobra.ExactArgs(1),
+// printRelations prints a list of related issues under a heading
+func printRelations(heading string, issues []*issue.Issue) {
+	if len(issues) == 0 {
+		return
+	}
+	fmt.Println()
+	fmt.Printf("%s:\n", heading)
+	for _, related := range issues {
+		fmt.Printf("  #%d [%s] %s\n", related.ID, related.Status, related.Title)
+	}
+}
+
+// issueCommentCmd represents the issue comment command
+var issueCommentCmd = &cobra.Command{
+	Use:   "comment [issue-id]",
+	Short: "Comment on an issue",
+	Long: `Add a comment to an issue. Comments are markdown.
+Use --reply-to to answer another comment, or --edit to change one of your own comments.`,
+	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		// Parse issue ID
-		id, err := strconv.Atoi(args[0])
+		// Get comment body
+		message, _ := cmd.Flags().GetString("message")
+		replyTo, _ := cmd.Flags().GetString("reply-to")
+		editID, _ := cmd.Flags().GetString("edit")
+
+		if strings.TrimSpace(message) == "" {
+			fmt.Fprintln(os.Stderr, "Error: comment message is required")
+			fmt.Fprintln(os.Stderr, "Use -m to specify a comment message")
+			os.Exit(1)
+		}
+
+		// Get current directory
+		currentDir, err := os.Getwd()
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: invalid issue ID: %s\n", args[0])
+			fmt.Fprintf(os.Stderr, "Error getting current directory: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Find repository
+		repo, err := repository.Find(currentDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Create issue manager
+		issueManager := newIssueManager(repo)
+		authorName := issueManager.Author
+		if authorName == "" {
+			authorName = "unknown"
+		}
+
+		// Find issue
+		target, err := issueManager.ResolveIssue(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
 
+		// Edit an existing comment
+		if editID != "" {
+			comment, err := issueManager.EditComment(target.ID, editID, authorName, message)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error editing comment: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("Edited comment %s on issue #%d\n", comment.ID, target.ID)
+			return
+		}
+
+		// Add comment
+		comment, err := issueManager.AddComment(target.ID, authorName, message, replyTo)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error adding comment: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Record user action; the mailmap may know the author by their email
+		email, _ := rootCmd.PersistentFlags().GetString("email")
+		if email == "" {
+			email, _ = config.GetValue(filepath.Join(repo.Path, ".snap", "config"), "user.email")
+		}
+		userManager := user.NewUserManager(repo.Path)
+		activity := user.Activity{
+			Action:      user.ActionIssueComment,
+			Description: fmt.Sprintf("Commented on issue #%d", target.ID),
+			Timestamp:   time.Now().Format(time.RFC3339),
+			Email:       email,
+		}
+		if _, err := userManager.Record(authorName, activity); err != nil {
+			fmt.Fprintf(os.Stderr, "Error recording user action: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Added comment %s to issue #%d\n", comment.ID, target.ID)
+	},
+}
+
+// issueCloseCmd represents the issue close command
+var issueCloseCmd = &cobra.Command{
+	Use:   "close [issue-id]",
+	Short: "Close an issue",
+	Long: `Close an issue in the repository.
+Issues that are blocked by open issues are only closed with --force.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
 		// Get current directory
 		currentDir, err := os.Getwd()
 		if err != nil {
@@ -204,32 +458,51 @@ var issueCloseCmd = &cobra.Command{
 		}
 
 		// Create issue manager
-		issueManager := issue.NewIssueManager(repo.Path)
+		issueManager := newIssueManager(repo)
+
+		// Find issue
+		target, err := issueManager.ResolveIssue(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Refuse to close issues that are still waiting on others
+		blockers, err := issueManager.OpenBlockers(target.ID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		force, _ := cmd.Flags().GetBool("force")
+		if len(blockers) > 0 {
+			fmt.Fprintf(os.Stderr, "Warning: issue #%d is blocked by open issues:\n", target.ID)
+			for _, blocker := range blockers {
+				fmt.Fprintf(os.Stderr, "  #%d %s\n", blocker.ID, blocker.Title)
+			}
+			if !force {
+				fmt.Fprintln(os.Stderr, "Use --force to close it anyway")
+				os.Exit(1)
+			}
+		}
 
 		// Close issue
-		if err := issueManager.CloseIssue(id); err != nil {
+		if err := issueManager.CloseIssue(target.ID, ""); err != nil {
 			fmt.Fprintf(os.Stderr, "Error closing issue: %v\n", err)
 			os.Exit(1)
 		}
 
-		fmt.Printf("Closed issue #%d\n", id)
+		fmt.Printf("Closed issue #%d\n", target.ID)
 	},
 }
 
 // issueAssignCmd represents the issue assign command
 var issueAssignCmd = &cobra.Command{
 	Use:   "assign [issue-id] [assignee]",
-	Short: "Assign an issue to a user",
-	Long:  `Assign an issue to a user in the repository.`,
-	Args:  cobra.ExactArgs(2),
+	Short: "Assign an issue to a user or team",
+	Long: `Assign an issue to a user in the repository, or to a team configured with
+snap config set team.<name>.members, e.g. snap issue assign 3 team:backend.`,
+	Args: cobra.ExactArgs(2),
 	Run: func(cmd *cobra.Command, args []string) {
-		// Parse issue ID
-		id, err := strconv.Atoi(args[0])
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: invalid issue ID: %s\n", args[0])
-			os.Exit(1)
-		}
-
 		// Get assignee
 		assignee := args[1]
 
@@ -248,15 +521,116 @@ var issueAssignCmd = &cobra.Command{
 		}
 
 		// Create issue manager
-		issueManager := issue.NewIssueManager(repo.Path)
+		issueManager := newIssueManager(repo)
+
+		// Find issue
+		target, err := issueManager.ResolveIssue(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
 
 		// Assign issue
-		if err := issueManager.AssignIssue(id, assignee); err != nil {
+		if err := issueManager.AssignIssue(target.ID, assignee); err != nil {
 			fmt.Fprintf(os.Stderr, "Error assigning issue: %v\n", err)
 			os.Exit(1)
 		}
 
-		fmt.Printf("Assigned issue #%d to %s\n", id, assignee)
+		fmt.Printf("Assigned issue #%d to %s\n", target.ID, assignee)
+	},
+}
+
+// issueEditCmd represents the issue edit command
+var issueEditCmd = &cobra.Command{
+	Use:   "edit [issue-id]",
+	Short: "Edit the title or description of an issue",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Get new title and description
+		title, _ := cmd.Flags().GetString("title")
+		description, _ := cmd.Flags().GetString("description")
+		if title == "" && description == "" {
+			fmt.Fprintln(os.Stderr, "Error: nothing to change")
+			fmt.Fprintln(os.Stderr, "Use --title or --description to specify the new values")
+			os.Exit(1)
+		}
+
+		issueManager := openIssueManager()
+		target := resolveIssueArg(issueManager, args[0])
+
+		// Edit issue
+		if err := issueManager.EditIssue(target.ID, title, description); err != nil {
+			fmt.Fprintf(os.Stderr, "Error editing issue: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Updated issue #%d\n", target.ID)
+	},
+}
+
+// issueReopenCmd represents the issue reopen command
+var issueReopenCmd = &cobra.Command{
+	Use:   "reopen [issue-id]",
+	Short: "Reopen a closed issue",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		issueManager := openIssueManager()
+		target := resolveIssueArg(issueManager, args[0])
+
+		// Reopen issue
+		if err := issueManager.ReopenIssue(target.ID); err != nil {
+			fmt.Fprintf(os.Stderr, "Error reopening issue: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Reopened issue #%d\n", target.ID)
+	},
+}
+
+// issueUnassignCmd represents the issue unassign command
+var issueUnassignCmd = &cobra.Command{
+	Use:   "unassign [issue-id]",
+	Short: "Remove the assignee of an issue",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		issueManager := openIssueManager()
+		target := resolveIssueArg(issueManager, args[0])
+
+		// Unassign issue
+		if err := issueManager.UnassignIssue(target.ID); err != nil {
+			fmt.Fprintf(os.Stderr, "Error unassigning issue: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Unassigned %s from issue #%d\n", target.AssignedTo, target.ID)
+	},
+}
+
+// issueMoveCmd represents the issue move command
+var issueMoveCmd = &cobra.Command{
+	Use:   "move [issue-id] [state]",
+	Short: "Move an issue to another workflow state",
+	Long: `Move an issue to another workflow state. The states are configured with
+workflow.states (default: todo, in-progress, review, done); moving an issue
+to the last state closes it, unless open issues block it.`,
+	Example: `  snap issue move 12 review
+  snap config set workflow.states "backlog, doing, done"`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		issueManager := openIssueManager()
+		target := resolveIssueArg(issueManager, args[0])
+
+		// Move issue, refusing to finish issues that are still waiting on others
+		force, _ := cmd.Flags().GetBool("force")
+		if err := issueManager.MoveIssue(target.ID, args[1], force); err != nil {
+			fmt.Fprintf(os.Stderr, "Error moving issue: %v\n", err)
+			if errors.Is(err, issue.ErrBlocked) {
+				fmt.Fprintln(os.Stderr, "Use --force to move it anyway")
+			}
+			os.Exit(1)
+		}
+
+		fmt.Printf("Moved issue #%d to %s\n", target.ID, args[1])
 	},
 }
 
@@ -267,11 +641,37 @@ func init() {
 	issueCmd.AddCommand(issueShowCmd)
 	issueCmd.AddCommand(issueCloseCmd)
 	issueCmd.AddCommand(issueAssignCmd)
+	issueCmd.AddCommand(issueCommentCmd)
+	issueCmd.AddCommand(issueEditCmd)
+	issueCmd.AddCommand(issueReopenCmd)
+	issueCmd.AddCommand(issueUnassignCmd)
+	issueCmd.AddCommand(issueMoveCmd)
 
 	// Add flags for issue new command
 	issueNewCmd.Flags().StringP("title", "t", "", "Issue title")
 	issueNewCmd.Flags().StringP("description", "d", "", "Issue description")
+	issueNewCmd.Flags().String("template", "", "Start from an issue template (see snap issue templates)")
+
+	// Add flags for issue edit command
+	issueEditCmd.Flags().StringP("title", "t", "", "New issue title")
+	issueEditCmd.Flags().StringP("description", "d", "", "New issue description")
 
 	// Add flags for issue list command
 	issueListCmd.Flags().BoolP("show-closed", "c", false, "Show closed issues")
+	issueListCmd.Flags().StringP("label", "l", "", "Only show issues with this label")
+	issueListCmd.Flags().String("milestone", "", "Only show issues in this milestone")
+	issueListCmd.Flags().StringP("priority", "p", "", "Only show issues with this priority")
+	issueListCmd.Flags().String("assignee", "", "Only show issues assigned to this user")
+	issueListCmd.Flags().StringP("query", "q", "", "Search query, e.g. 'is:open label:bug crash'")
+	issueListCmd.Flags().IntP("limit", "n", 0, "Number of issues per page (0 for all)")
+	issueListCmd.Flags().Int("page", 1, "Page to show when --limit is set")
+
+	// Add flags for issue close command
+	issueCloseCmd.Flags().BoolP("force", "f", false, "Close the issue even if open issues block it")
+	issueMoveCmd.Flags().BoolP("force", "f", false, "Finish the issue even if open issues block it")
+
+	// Add flags for issue comment command
+	issueCommentCmd.Flags().StringP("message", "m", "", "Comment text (markdown)")
+	issueCommentCmd.Flags().String("reply-to", "", "ID of the comment to reply to")
+	issueCommentCmd.Flags().String("edit", "", "ID of your comment to replace with the new text")
 }
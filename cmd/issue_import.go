@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"github.com/stanlocht/snap/pkg/issue"
+)
+
+// formatFlag parses the --format flag of a command, exiting on failure
+func formatFlag(cmd *cobra.Command) issue.Format {
+	name, _ := cmd.Flags().GetString("format")
+	format, err := issue.ParseFormat(name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	return format
+}
+
+// printFieldCounts prints fields with the number of issues they apply to, by name
+func printFieldCounts(heading string, counts map[string]int) {
+	if len(counts) == 0 {
+		return
+	}
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(os.Stderr, heading)
+	for _, name := range names {
+		plural := "s"
+		if counts[name] == 1 {
+			plural = ""
+		}
+		fmt.Fprintf(os.Stderr, "  %s (%d issue%s)\n", name, counts[name], plural)
+	}
+}
+
+// issueImportCmd represents the issue import command
+var issueImportCmd = &cobra.Command{
+	Use:   "import [file]",
+	Short: "Import issues from another tracker",
+	Long: `Import issues exported from another tracker. Formats are:
+  github  JSON from the GitHub API or 'gh issue list --json ...' (include
+          comments to bring them along)
+  gitlab  JSON from the GitLab API, optionally with a "notes" array per issue
+  csv     a table with a header row; columns such as id, title, description,
+          state, author, assignee, labels, milestone, priority, created_at,
+          updated_at and closed_at are recognized
+
+Issues keep their original numbers unless the number is already taken.
+Labels and milestones are created as needed. Issues that were imported
+before are skipped, and data that has no place in snap is reported.
+Use - to read from standard input.`,
+	Example: `  gh issue list --state all --json number,title,body,state,author,assignees,labels,milestone,comments,createdAt,updatedAt,closedAt,url > issues.json
+  snap issue import --format github issues.json`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		format := formatFlag(cmd)
+
+		// Open file
+		var input io.Reader = os.Stdin
+		if args[0] != "-" {
+			file, err := os.Open(args[0])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error opening file: %v\n", err)
+				os.Exit(1)
+			}
+			defer file.Close()
+			input = file
+		}
+
+		// Import issues
+		result, err := openIssueManager().Import(format, input)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error importing issues: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Imported %d issues\n", len(result.Imported))
+		if len(result.Skipped) > 0 {
+			fmt.Printf("Skipped %d issues that were imported before\n", len(result.Skipped))
+		}
+		renumbered := make([]int, 0, len(result.Renumbered))
+		for id := range result.Renumbered {
+			renumbered = append(renumbered, id)
+		}
+		sort.Ints(renumbered)
+		for _, id := range renumbered {
+			fmt.Printf("Issue #%d was imported as #%d because #%d is taken\n", id, result.Renumbered[id], id)
+		}
+		printFieldCounts("Warning: some data could not be imported:", result.Unmapped)
+	},
+}
+
+// issueExportCmd represents the issue export command
+var issueExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export issues for another tracker",
+	Long: `Export every issue as github JSON (the shape 'gh issue list --json' writes),
+gitlab JSON or csv. Exported files can be imported again with snap issue import.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		format := formatFlag(cmd)
+		outputPath, _ := cmd.Flags().GetString("output")
+
+		// Create output file
+		var output io.Writer = os.Stdout
+		if outputPath != "" && outputPath != "-" {
+			file, err := os.Create(outputPath)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error creating file: %v\n", err)
+				os.Exit(1)
+			}
+			defer file.Close()
+			output = file
+		}
+
+		// Export issues
+		result, err := openIssueManager().Export(format, output)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error exporting issues: %v\n", err)
+			os.Exit(1)
+		}
+
+		if output != os.Stdout {
+			fmt.Printf("Exported %d issues to %s\n", result.Exported, outputPath)
+		}
+		printFieldCounts(fmt.Sprintf("Warning: %s files have no place for:", format), result.Dropped)
+	},
+}
+
+func init() {
+	issueCmd.AddCommand(issueImportCmd)
+	issueCmd.AddCommand(issueExportCmd)
+
+	issueImportCmd.Flags().StringP("format", "f", "", "Format of the file: github, gitlab or csv")
+	issueImportCmd.MarkFlagRequired("format")
+	issueExportCmd.Flags().StringP("format", "f", "github", "Format to write: github, gitlab or csv")
+	issueExportCmd.Flags().StringP("output", "o", "", "File to write (default: standard output)")
+}
@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/stanlocht/snap/pkg/issue"
+)
+
+// issueLabelCmd represents the issue label command
+var issueLabelCmd = &cobra.Command{
+	Use:   "label",
+	Short: "Manage issue labels",
+	Long: `Manage the labels defined in the repository and the labels on issues.
+Labels must be created before they can be added to issues.`,
+}
+
+// issueLabelCreateCmd represents the issue label create command
+var issueLabelCreateCmd = &cobra.Command{
+	Use:   "create [name]",
+	Short: "Create a label",
+	Long: `Create a label that issues can carry.
+Colors are given as #rrggbb; a color is picked automatically when none is given.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		color, _ := cmd.Flags().GetString("color")
+		description, _ := cmd.Flags().GetString("description")
+
+		// Create label
+		label, err := openIssueManager().CreateLabel(args[0], color, description)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating label: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Created label %s (%s)\n", label.Name, label.Color)
+	},
+}
+
+// issueLabelEditCmd represents the issue label edit command
+var issueLabelEditCmd = &cobra.Command{
+	Use:   "edit [name]",
+	Short: "Change a label's color or description",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		color, _ := cmd.Flags().GetString("color")
+		description, _ := cmd.Flags().GetString("description")
+
+		// Update label
+		label, err := openIssueManager().UpdateLabel(args[0], color, description)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error updating label: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Updated label %s (%s)\n", label.Name, label.Color)
+	},
+}
+
+// issueLabelListCmd represents the issue label list command
+var issueLabelListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List labels",
+	Run: func(cmd *cobra.Command, args []string) {
+		// List labels
+		labels, err := openIssueManager().ListLabels()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error listing labels: %v\n", err)
+			os.Exit(1)
+		}
+
+		if len(labels) == 0 {
+			fmt.Println("No labels defined")
+			return
+		}
+
+		for _, label := range labels {
+			fmt.Printf("%s %s", label.Color, label.Name)
+			if label.Description != "" {
+				fmt.Printf(" - %s", label.Description)
+			}
+			fmt.Println()
+		}
+	},
+}
+
+// issueLabelDeleteCmd represents the issue label delete command
+var issueLabelDeleteCmd = &cobra.Command{
+	Use:   "delete [name]",
+	Short: "Delete a label",
+	Long:  `Delete a label and remove it from every issue that carries it.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Delete label
+		if err := openIssueManager().DeleteLabel(args[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error deleting label: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Deleted label %s\n", args[0])
+	},
+}
+
+// issueLabelAddCmd represents the issue label add command
+var issueLabelAddCmd = &cobra.Command{
+	Use:   "add [issue-id] [label...]",
+	Short: "Add labels to an issue",
+	Args:  cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		issueManager := openIssueManager()
+		target := resolveIssueArg(issueManager, args[0])
+
+		// Add labels
+		if err := issueManager.AddLabels(target.ID, args[1:]...); err != nil {
+			fmt.Fprintf(os.Stderr, "Error adding labels: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Labeled issue #%d with %s\n", target.ID, strings.Join(args[1:], ", "))
+	},
+}
+
+// issueLabelRemoveCmd represents the issue label remove command
+var issueLabelRemoveCmd = &cobra.Command{
+	Use:   "remove [issue-id] [label...]",
+	Short: "Remove labels from an issue",
+	Args:  cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		issueManager := openIssueManager()
+		target := resolveIssueArg(issueManager, args[0])
+
+		// Remove labels
+		if err := issueManager.RemoveLabels(target.ID, args[1:]...); err != nil {
+			fmt.Fprintf(os.Stderr, "Error removing labels: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Removed %s from issue #%d\n", strings.Join(args[1:], ", "), target.ID)
+	},
+}
+
+// issuePriorityCmd represents the issue priority command
+var issuePriorityCmd = &cobra.Command{
+	Use:   "priority [issue-id] [level]",
+	Short: "Set the priority of an issue",
+	Long: `Set the priority of an issue to low, medium, high or critical.
+Use "none" to clear it.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		priority, err := issue.ParsePriority(args[1])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		issueManager := openIssueManager()
+		target := resolveIssueArg(issueManager, args[0])
+
+		// Set priority
+		if err := issueManager.SetPriority(target.ID, priority); err != nil {
+			fmt.Fprintf(os.Stderr, "Error setting priority: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Set priority of issue #%d to %s\n", target.ID, priority)
+	},
+}
+
+func init() {
+	issueCmd.AddCommand(issueLabelCmd)
+	issueCmd.AddCommand(issuePriorityCmd)
+	issueLabelCmd.AddCommand(issueLabelCreateCmd)
+	issueLabelCmd.AddCommand(issueLabelEditCmd)
+	issueLabelCmd.AddCommand(issueLabelListCmd)
+	issueLabelCmd.AddCommand(issueLabelDeleteCmd)
+	issueLabelCmd.AddCommand(issueLabelAddCmd)
+	issueLabelCmd.AddCommand(issueLabelRemoveCmd)
+
+	// Add flags for label create and edit commands
+	for _, cmd := range []*cobra.Command{issueLabelCreateCmd, issueLabelEditCmd} {
+		cmd.Flags().String("color", "", "Label color as #rrggbb")
+		cmd.Flags().StringP("description", "d", "", "Label description")
+	}
+}
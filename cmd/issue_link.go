@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/stanlocht/snap/pkg/issue"
+)
+
+// parseLinkArgs resolves the issues and relationship of a link command, exiting on failure.
+// blocked-by links are turned around so that from is always the blocking issue.
+func parseLinkArgs(issueManager *issue.IssueManager, args []string) (from, to *issue.Issue, linkType issue.LinkType) {
+	linkType, inverse, err := issue.ParseLinkType(args[1])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	from = resolveIssueArg(issueManager, args[0])
+	to = resolveIssueArg(issueManager, args[2])
+	if inverse {
+		from, to = to, from
+	}
+	return from, to, linkType
+}
+
+// issueLinkCmd represents the issue link command
+var issueLinkCmd = &cobra.Command{
+	Use:   "link [issue-id] [relationship] [other-issue-id]",
+	Short: "Link an issue to another issue",
+	Long: `Record a relationship between two issues. Relationships are:
+  blocks        the other issue can't be finished before this one
+  blocked-by    this issue can't be finished before the other one
+  duplicate-of  this issue reports the same thing as the other one
+  related-to    the issues are related
+
+Links that would make an issue block itself or duplicate itself, directly
+or through other issues, are refused.`,
+	Example: `  snap issue link 3 blocks 5
+  snap issue link 7 duplicate-of 2`,
+	Args: cobra.ExactArgs(3),
+	Run: func(cmd *cobra.Command, args []string) {
+		issueManager := openIssueManager()
+		from, to, linkType := parseLinkArgs(issueManager, args)
+
+		// Add link
+		if err := issueManager.AddLink(from.ID, to.ID, linkType); err != nil {
+			fmt.Fprintf(os.Stderr, "Error linking issues: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Linked issue #%d %s #%d\n", from.ID, linkType, to.ID)
+	},
+}
+
+// issueUnlinkCmd represents the issue unlink command
+var issueUnlinkCmd = &cobra.Command{
+	Use:   "unlink [issue-id] [relationship] [other-issue-id]",
+	Short: "Remove a link between two issues",
+	Args:  cobra.ExactArgs(3),
+	Run: func(cmd *cobra.Command, args []string) {
+		issueManager := openIssueManager()
+		from, to, linkType := parseLinkArgs(issueManager, args)
+
+		// Remove link
+		if err := issueManager.RemoveLink(from.ID, to.ID, linkType); err != nil {
+			fmt.Fprintf(os.Stderr, "Error unlinking issues: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Unlinked issue #%d %s #%d\n", from.ID, linkType, to.ID)
+	},
+}
+
+// printDependencyTree prints an issue and, below it, the issues that block it
+func printDependencyTree(graph *issue.Graph, target *issue.Issue, prefix string, path map[string]bool) {
+	blockers := graph.Blockers(target)
+	for i, blocker := range blockers {
+		branch, indent := "├── ", "│   "
+		if i == len(blockers)-1 {
+			branch, indent = "└── ", "    "
+		}
+
+		// Merges can join two histories into a cycle; show it instead of looping
+		if path[blocker.UID] {
+			fmt.Printf("%s%s#%d %s (cycle)\n", prefix, branch, blocker.ID, blocker.Title)
+			continue
+		}
+
+		fmt.Printf("%s%s%s\n", prefix, branch, formatDependency(blocker))
+		path[blocker.UID] = true
+		printDependencyTree(graph, blocker, prefix+indent, path)
+		delete(path, blocker.UID)
+	}
+}
+
+// formatDependency formats an issue for the dependency tree
+func formatDependency(target *issue.Issue) string {
+	status := " "
+	if target.Status == issue.StatusClosed {
+		status = "✓"
+	}
+	return fmt.Sprintf("[%s] #%d %s", status, target.ID, target.Title)
+}
+
+// issueDepsCmd represents the issue deps command
+var issueDepsCmd = &cobra.Command{
+	Use:   "deps [issue-id]",
+	Short: "Show the dependency tree of issues",
+	Long: `Show which issues block which as a tree: each issue is followed by the
+issues that must be finished first. Without an issue, the tree of every
+issue that doesn't block another one is shown.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		issueManager := openIssueManager()
+
+		// Build graph
+		graph, err := issueManager.DependencyGraph()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error building dependency graph: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Pick the roots of the tree
+		var roots []*issue.Issue
+		if len(args) == 1 {
+			roots = append(roots, resolveIssueArg(issueManager, args[0]))
+		} else {
+			for _, target := range graph.Issues {
+				if !graph.Blocks(target) && len(graph.Blockers(target)) > 0 {
+					roots = append(roots, target)
+				}
+			}
+		}
+
+		if len(roots) == 0 {
+			fmt.Println("No issues are blocked")
+			return
+		}
+
+		for _, root := range roots {
+			fmt.Println(formatDependency(root))
+			printDependencyTree(graph, root, "", map[string]bool{root.UID: true})
+		}
+	},
+}
+
+func init() {
+	issueCmd.AddCommand(issueLinkCmd)
+	issueCmd.AddCommand(issueUnlinkCmd)
+	issueCmd.AddCommand(issueDepsCmd)
+}
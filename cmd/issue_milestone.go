@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stanlocht/snap/pkg/issue"
+)
+
+// issueMilestoneCmd represents the issue milestone command
+var issueMilestoneCmd = &cobra.Command{
+	Use:   "milestone",
+	Short: "Manage milestones",
+	Long:  `Manage milestones: groups of issues with a due date.`,
+}
+
+// printMilestone prints a one-line summary of a milestone and its progress
+func printMilestone(issueManager *issue.IssueManager, milestone *issue.Milestone) {
+	progress, err := issueManager.MilestoneProgress(milestone.Name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error getting milestone progress: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("%s [%s] %d%% (%d/%d closed)", milestone.Name, strings.ToUpper(string(milestone.Status)), progress.Percent(), progress.Closed, progress.Total)
+	if !milestone.DueDate.IsZero() {
+		fmt.Printf(" due %s", milestone.DueDate.Format("2006-01-02"))
+		if milestone.Overdue(time.Now()) {
+			fmt.Print(" (overdue)")
+		}
+	}
+	fmt.Println()
+}
+
+// issueMilestoneCreateCmd represents the issue milestone create command
+var issueMilestoneCreateCmd = &cobra.Command{
+	Use:   "create [name]",
+	Short: "Create a milestone",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		description, _ := cmd.Flags().GetString("description")
+		dueStr, _ := cmd.Flags().GetString("due")
+
+		// Parse due date; a milestone is due at the end of its day
+		var due time.Time
+		if dueStr != "" {
+			day, err := time.ParseInLocation("2006-01-02", dueStr, time.Local)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: invalid due date %q (use YYYY-MM-DD)\n", dueStr)
+				os.Exit(1)
+			}
+			due = day.AddDate(0, 0, 1).Add(-time.Second)
+		}
+
+		// Create milestone
+		milestone, err := openIssueManager().CreateMilestone(args[0], description, due)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating milestone: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Created milestone %s\n", milestone.Name)
+	},
+}
+
+// issueMilestoneListCmd represents the issue milestone list command
+var issueMilestoneListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List milestones and their progress",
+	Run: func(cmd *cobra.Command, args []string) {
+		showClosed, _ := cmd.Flags().GetBool("show-closed")
+
+		// List milestones
+		issueManager := openIssueManager()
+		milestones, err := issueManager.ListMilestones(showClosed)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error listing milestones: %v\n", err)
+			os.Exit(1)
+		}
+
+		if len(milestones) == 0 {
+			fmt.Println("No milestones found")
+			return
+		}
+
+		for _, milestone := range milestones {
+			printMilestone(issueManager, milestone)
+		}
+	},
+}
+
+// issueMilestoneShowCmd represents the issue milestone show command
+var issueMilestoneShowCmd = &cobra.Command{
+	Use:   "show [name]",
+	Short: "Show a milestone and its issues",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		issueManager := openIssueManager()
+
+		// Get milestone
+		milestone, err := issueManager.GetMilestone(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		printMilestone(issueManager, milestone)
+		if milestone.Description != "" {
+			fmt.Println(milestone.Description)
+		}
+
+		// Print issues
+		issues, err := issueManager.MilestoneIssues(milestone.Name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error listing issues: %v\n", err)
+			os.Exit(1)
+		}
+		if len(issues) > 0 {
+			fmt.Println()
+		}
+		for _, issue := range issues {
+			fmt.Printf("#%d [%s] %s\n", issue.ID, strings.ToUpper(string(issue.Status)), issue.Title)
+		}
+	},
+}
+
+// issueMilestoneCloseCmd represents the issue milestone close command
+var issueMilestoneCloseCmd = &cobra.Command{
+	Use:   "close [name]",
+	Short: "Close a milestone",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Close milestone
+		if err := openIssueManager().CloseMilestone(args[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error closing milestone: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Closed milestone %s\n", args[0])
+	},
+}
+
+// issueMilestoneSetCmd represents the issue milestone set command
+var issueMilestoneSetCmd = &cobra.Command{
+	Use:   "set [issue-id] [milestone]",
+	Short: "Add an issue to a milestone",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		issueManager := openIssueManager()
+		target := resolveIssueArg(issueManager, args[0])
+
+		// Set milestone
+		if err := issueManager.SetMilestone(target.ID, args[1]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error setting milestone: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Added issue #%d to milestone %s\n", target.ID, args[1])
+	},
+}
+
+// issueMilestoneUnsetCmd represents the issue milestone unset command
+var issueMilestoneUnsetCmd = &cobra.Command{
+	Use:   "unset [issue-id]",
+	Short: "Remove an issue from its milestone",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		issueManager := openIssueManager()
+		target := resolveIssueArg(issueManager, args[0])
+
+		// Unset milestone
+		if err := issueManager.SetMilestone(target.ID, ""); err != nil {
+			fmt.Fprintf(os.Stderr, "Error removing milestone: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Removed issue #%d from its milestone\n", target.ID)
+	},
+}
+
+func init() {
+	issueCmd.AddCommand(issueMilestoneCmd)
+	issueMilestoneCmd.AddCommand(issueMilestoneCreateCmd)
+	issueMilestoneCmd.AddCommand(issueMilestoneListCmd)
+	issueMilestoneCmd.AddCommand(issueMilestoneShowCmd)
+	issueMilestoneCmd.AddCommand(issueMilestoneCloseCmd)
+	issueMilestoneCmd.AddCommand(issueMilestoneSetCmd)
+	issueMilestoneCmd.AddCommand(issueMilestoneUnsetCmd)
+
+	// Add flags for milestone commands
+	issueMilestoneCreateCmd.Flags().StringP("description", "d", "", "Milestone description")
+	issueMilestoneCreateCmd.Flags().String("due", "", "Due date (YYYY-MM-DD)")
+	issueMilestoneListCmd.Flags().BoolP("show-closed", "c", false, "Show closed milestones")
+}
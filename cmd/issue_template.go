@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/stanlocht/snap/pkg/issue"
+	"github.com/stanlocht/snap/pkg/repository"
+)
+
+// issueDraftFile is where issue drafts are edited, relative to the .snap directory
+const issueDraftFile = "ISSUE_EDITMSG"
+
+// editorCommand returns the user's editor and its arguments
+func editorCommand() []string {
+	for _, variable := range []string{"VISUAL", "EDITOR"} {
+		if fields := strings.Fields(os.Getenv(variable)); len(fields) > 0 {
+			return fields
+		}
+	}
+	return []string{"vi"}
+}
+
+// editIssueDraft opens a draft in the user's editor and returns the title on its
+// first line and the description below it. An empty title cancels the issue.
+func editIssueDraft(path, draft string, template *issue.Template) (string, string, error) {
+	instructions := "<!--\nWrite the title on the first line and the description below it.\n"
+	if len(template.Required) > 0 {
+		instructions += "Required sections: " + strings.Join(template.Required, ", ") + "\n"
+	}
+	instructions += "Save an empty file to cancel.\n-->\n"
+
+	if err := os.WriteFile(path, []byte(strings.TrimRight(draft, "\n")+"\n\n"+instructions), 0644); err != nil {
+		return "", "", fmt.Errorf("failed to write draft: %w", err)
+	}
+
+	editor := editorCommand()
+	editCmd := exec.Command(editor[0], append(editor[1:], path)...)
+	editCmd.Stdin, editCmd.Stdout, editCmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := editCmd.Run(); err != nil {
+		return "", "", fmt.Errorf("failed to run editor %s: %w", editor[0], err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read draft: %w", err)
+	}
+	text := strings.TrimSpace(strings.Replace(string(data), instructions, "", 1))
+	title, description, _ := strings.Cut(text, "\n")
+	return strings.TrimSpace(title), strings.TrimSpace(description), nil
+}
+
+// newIssueFromTemplate creates an issue from a template. Without a description,
+// the template is opened in the user's editor until its required sections are filled in.
+func newIssueFromTemplate(repo *repository.Repository, name, title, description, authorName string) {
+	issueManager := newIssueManager(repo)
+
+	// Get template
+	template, err := issueManager.GetTemplate(name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if title != "" && !strings.HasPrefix(title, template.Prefix) {
+		title = template.Prefix + title
+	}
+
+	// Create the issue from the flags
+	if description != "" {
+		newIssue, err := issueManager.CreateIssueFromTemplate(template, title, description, authorName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating issue: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Created issue #%d: %s\n", newIssue.ID, newIssue.Title)
+		return
+	}
+
+	// Or let the user write it in their editor
+	draftPath := filepath.Join(repo.Path, repository.SnapDirName, issueDraftFile)
+	draft := template.Draft()
+	if title != "" {
+		draft = title + "\n\n" + template.Body
+	}
+	input := bufio.NewReader(os.Stdin)
+	for {
+		title, description, err := editIssueDraft(draftPath, draft, template)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if title == "" {
+			os.Remove(draftPath)
+			fmt.Fprintln(os.Stderr, "Aborting issue due to empty title")
+			os.Exit(1)
+		}
+
+		newIssue, err := issueManager.CreateIssueFromTemplate(template, title, description, authorName)
+		if err == nil {
+			os.Remove(draftPath)
+			fmt.Printf("Created issue #%d: %s\n", newIssue.ID, newIssue.Title)
+			return
+		}
+
+		// Keep what the user wrote and let them fix it
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		fmt.Fprint(os.Stderr, "Edit the issue again? [Y/n] ")
+		answer, _ := input.ReadString('\n')
+		if strings.HasPrefix(strings.ToLower(strings.TrimSpace(answer)), "n") {
+			fmt.Fprintf(os.Stderr, "Your draft was saved in %s\n", draftPath)
+			os.Exit(1)
+		}
+		draft = title + "\n\n" + description
+	}
+}
+
+// issueTemplatesCmd represents the issue templates command
+var issueTemplatesCmd = &cobra.Command{
+	Use:   "templates",
+	Short: "List issue templates",
+	Long: `List the issue templates in .snap/issue_templates. Templates are markdown
+files with front matter setting the title prefix, labels, assignee, priority
+and the sections that must be filled in:
+
+  ---
+  name: Bug report
+  about: Something isn't working
+  title: "[Bug] "
+  labels: bug
+  required: Steps to reproduce, Expected behavior
+  ---
+  ## Steps to reproduce
+
+  ## Expected behavior
+
+Create an issue from one with snap issue new --template <name>.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		templates, err := openIssueManager().ListTemplates()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error listing templates: %v\n", err)
+			os.Exit(1)
+		}
+
+		if len(templates) == 0 {
+			fmt.Printf("No issue templates found. Add them to %s\n", filepath.Join(repository.SnapDirName, issue.TemplateDirName))
+			return
+		}
+
+		for _, template := range templates {
+			fmt.Printf("%s: %s", template.Name, template.Title)
+			if template.About != "" {
+				fmt.Printf(" – %s", template.About)
+			}
+			fmt.Println()
+			if len(template.Required) > 0 {
+				fmt.Printf("  Required: %s\n", strings.Join(template.Required, ", "))
+			}
+		}
+	},
+}
+
+func init() {
+	issueCmd.AddCommand(issueTemplatesCmd)
+}
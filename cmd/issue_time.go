@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stanlocht/snap/pkg/issue"
+)
+
+// printEstimate prints an issue's estimate and the time spent on it, if any
+func printEstimate(target *issue.Issue) {
+	if target.Estimate > 0 {
+		fmt.Printf("Estimate: %s\n", issue.FormatDuration(target.Estimate))
+	}
+	if spent := target.Spent(); spent > 0 {
+		fmt.Printf("Time spent: %s\n", issue.FormatDuration(spent))
+	}
+}
+
+// printTimeLog prints the time logged on an issue
+func printTimeLog(target *issue.Issue) {
+	entries := target.TimeLog()
+	if len(entries) == 0 {
+		return
+	}
+
+	fmt.Println()
+	fmt.Println("Time log:")
+	for _, entry := range entries {
+		fmt.Printf("  %s %s logged %s", entry.Date.Format("2006-01-02"), entry.Author, issue.FormatDuration(entry.Duration))
+		if entry.Note != "" {
+			fmt.Printf(": %s", entry.Note)
+		}
+		fmt.Println()
+	}
+}
+
+// issueEstimateCmd represents the issue estimate command
+var issueEstimateCmd = &cobra.Command{
+	Use:   "estimate [issue-id] [duration]",
+	Short: "Estimate how long an issue will take",
+	Long: `Set how much working time an issue is expected to take, e.g. 45m, 3h,
+1h30m or 2d. A day is 8 hours and a week 5 days. Use "none" to remove the estimate.`,
+	Example: `  snap issue estimate 12 3h
+  snap issue estimate 12 none`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		issueManager := openIssueManager()
+		target := resolveIssueArg(issueManager, args[0])
+
+		// Parse estimate
+		var estimate time.Duration
+		if !strings.EqualFold(args[1], "none") {
+			var err error
+			estimate, err = issue.ParseDuration(args[1])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		// Set estimate
+		if err := issueManager.SetEstimate(target.ID, estimate); err != nil {
+			fmt.Fprintf(os.Stderr, "Error setting estimate: %v\n", err)
+			os.Exit(1)
+		}
+
+		if estimate == 0 {
+			fmt.Printf("Removed the estimate of issue #%d\n", target.ID)
+			return
+		}
+		fmt.Printf("Estimated issue #%d at %s\n", target.ID, issue.FormatDuration(estimate))
+	},
+}
+
+// issueLogTimeCmd represents the issue log-time command
+var issueLogTimeCmd = &cobra.Command{
+	Use:   "log-time [issue-id] [duration]",
+	Short: "Log time spent on an issue",
+	Long: `Record working time spent on an issue, e.g. 45m, 1h30m or 1d.
+The time is logged for today unless --date is given.`,
+	Example: `  snap issue log-time 12 45m -m "Reproduced the crash"
+  snap issue log-time 12 2h --date 2026-03-02`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		note, _ := cmd.Flags().GetString("message")
+		dateValue, _ := cmd.Flags().GetString("date")
+
+		issueManager := openIssueManager()
+		authorName := issueManager.Author
+		if authorName == "" {
+			authorName = "unknown"
+		}
+		target := resolveIssueArg(issueManager, args[0])
+
+		// Parse duration and date
+		duration, err := issue.ParseDuration(args[1])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		var date time.Time
+		if dateValue != "" {
+			date, err = time.ParseInLocation("2006-01-02", dateValue, time.Local)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: invalid date %q, expected YYYY-MM-DD\n", dateValue)
+				os.Exit(1)
+			}
+		}
+
+		// Log time
+		if _, err := issueManager.LogTime(target.ID, authorName, duration, note, date); err != nil {
+			fmt.Fprintf(os.Stderr, "Error logging time: %v\n", err)
+			os.Exit(1)
+		}
+
+		updated := resolveIssueArg(issueManager, args[0])
+		fmt.Printf("Logged %s on issue #%d (%s spent", issue.FormatDuration(duration), target.ID, issue.FormatDuration(updated.Spent()))
+		if updated.Estimate > 0 {
+			fmt.Printf(" of %s estimated", issue.FormatDuration(updated.Estimate))
+		}
+		fmt.Println(")")
+	},
+}
+
+// formatReportDuration formats a duration for a time report, showing zero as "-"
+func formatReportDuration(d time.Duration) string {
+	if d == 0 {
+		return "-"
+	}
+	return issue.FormatDuration(d)
+}
+
+// issueTimeReportCmd represents the issue time-report command
+var issueTimeReportCmd = &cobra.Command{
+	Use:   "time-report",
+	Short: "Report estimated and logged time",
+	Long: `Report estimated and logged time per user or per milestone.
+
+Per user, the estimates are those of the issues assigned to the user and the
+logged time is the time the user logged. Per milestone, both are those of the
+milestone's issues. Remaining is the estimate of the issues that are still open.`,
+	Example: `  snap issue time-report
+  snap issue time-report --by milestone --since 2026-03-01`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		by, _ := cmd.Flags().GetString("by")
+		sinceValue, _ := cmd.Flags().GetString("since")
+
+		// Parse start date
+		var since time.Time
+		if sinceValue != "" {
+			var err error
+			since, err = time.ParseInLocation("2006-01-02", sinceValue, time.Local)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: invalid date %q, expected YYYY-MM-DD\n", sinceValue)
+				os.Exit(1)
+			}
+		}
+
+		// Get report
+		issueManager := openIssueManager()
+		var reports []*issue.TimeReport
+		var err error
+		switch by {
+		case "user":
+			reports, err = issueManager.UserTimeReport(since)
+		case "milestone":
+			reports, err = issueManager.MilestoneTimeReport(since)
+		default:
+			fmt.Fprintf(os.Stderr, "Error: invalid --by %q, expected user or milestone\n", by)
+			os.Exit(1)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error getting time report: %v\n", err)
+			os.Exit(1)
+		}
+
+		if len(reports) == 0 {
+			fmt.Println("No time logged or estimated yet")
+			return
+		}
+
+		// Print report
+		heading := strings.ToUpper(by[:1]) + by[1:]
+		fmt.Printf("%-20s %6s %10s %10s %10s\n", heading, "Issues", "Estimate", "Remaining", "Spent")
+		var total issue.TimeReport
+		for _, report := range reports {
+			name := report.Name
+			if name == "" {
+				name = "(none)"
+			}
+			fmt.Printf("%-20s %6d %10s %10s %10s\n", name, report.Issues, formatReportDuration(report.Estimate), formatReportDuration(report.Remaining), formatReportDuration(report.Spent))
+			total.Issues += report.Issues
+			total.Estimate += report.Estimate
+			total.Remaining += report.Remaining
+			total.Spent += report.Spent
+		}
+		if by == "milestone" {
+			fmt.Printf("%-20s %6d %10s %10s %10s\n", "Total", total.Issues, formatReportDuration(total.Estimate), formatReportDuration(total.Remaining), formatReportDuration(total.Spent))
+		}
+	},
+}
+
+func init() {
+	issueCmd.AddCommand(issueEstimateCmd)
+	issueCmd.AddCommand(issueLogTimeCmd)
+	issueCmd.AddCommand(issueTimeReportCmd)
+
+	issueLogTimeCmd.Flags().StringP("message", "m", "", "What the time was spent on")
+	issueLogTimeCmd.Flags().String("date", "", "Day the work was done (YYYY-MM-DD, default today)")
+
+	issueTimeReportCmd.Flags().String("by", "user", "Group by user or milestone")
+	issueTimeReportCmd.Flags().String("since", "", "Only count time logged on or after this date (YYYY-MM-DD)")
+}
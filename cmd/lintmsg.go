@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/stanlocht/snap/pkg/lint"
+	"github.com/stanlocht/snap/pkg/repository"
+)
+
+// printFindings prints lint findings, errors first, and reports whether any is an error
+func printFindings(w io.Writer, findings []lint.Finding) bool {
+	labels := map[lint.Severity]string{lint.SeverityError: "Error", lint.SeverityWarning: "Warning"}
+	for _, severity := range []lint.Severity{lint.SeverityError, lint.SeverityWarning} {
+		for _, finding := range findings {
+			if finding.Severity == severity {
+				fmt.Fprintf(w, "%s: %s (%s)\n", labels[severity], finding.Message, finding.Rule)
+			}
+		}
+	}
+	return lint.HasErrors(findings)
+}
+
+// lintCommitMessage checks a commit message against the repository's lint
+// policy, printing the findings and exiting if any is an error
+func lintCommitMessage(repoPath, message string) {
+	policy, err := lint.Load(repoPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading lint policy: %v\n", err)
+		os.Exit(1)
+	}
+	if printFindings(os.Stderr, policy.Lint(message)) {
+		fmt.Fprintln(os.Stderr, "\nCheck a message before committing with snap lint-msg -m \"<message>\"")
+		os.Exit(1)
+	}
+}
+
+// lintMsgCmd represents the lint-msg command
+var lintMsgCmd = &cobra.Command{
+	Use:   "lint-msg [file]",
+	Short: "Check a commit message against the lint policy",
+	Long: `Check a commit message against the repository's lint policy, as snap commit
+and snap boom do. The message is read from a file, as commit-msg hooks pass it,
+from --message, or from standard input. Lines starting with # in a file are
+comments and left out. Exits with status 1 if any finding is an error.
+
+Rules: snapmoji, scope, subject-length, imperative, blank-line, issue-reference
+and banned-words. A missing snapmoji and banned words are errors, the other
+rules warn by default. Each can be set to error, warning or off, and some take options:
+
+  snap config set lint.blank-line.severity error
+  snap config set lint.subject-length.max 50
+  snap config set lint.imperative.severity off
+  snap config set lint.issue-reference.snapmojis "🐛, 🚑"
+  snap config set lint.banned-words.words "wip, fixup"
+  snap config set lint.scope.allowed "web, cli"
+  snap config set lint.scope.required true
+
+Outside a repository the default policy applies.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		message, _ := cmd.Flags().GetString("message")
+
+		// Read message
+		switch {
+		case len(args) == 1:
+			data, err := os.ReadFile(args[0])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error reading commit message: %v\n", err)
+				os.Exit(1)
+			}
+			var lines []string
+			for _, line := range strings.Split(string(data), "\n") {
+				if !strings.HasPrefix(line, "#") {
+					lines = append(lines, line)
+				}
+			}
+			message = strings.TrimSpace(strings.Join(lines, "\n"))
+		case message == "":
+			data, err := io.ReadAll(bufio.NewReader(os.Stdin))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error reading commit message: %v\n", err)
+				os.Exit(1)
+			}
+			message = strings.TrimSpace(string(data))
+		}
+
+		// Load the repository's policy, or the default one outside a repository
+		policy := lint.DefaultPolicy()
+		if currentDir, err := os.Getwd(); err == nil {
+			if repo, err := repository.Find(currentDir); err == nil {
+				if policy, err = lint.Load(repo.Path); err != nil {
+					fmt.Fprintf(os.Stderr, "Error loading lint policy: %v\n", err)
+					os.Exit(1)
+				}
+			}
+		}
+
+		findings := policy.Lint(message)
+		if len(findings) == 0 {
+			fmt.Println("Commit message looks good")
+			return
+		}
+		if printFindings(os.Stdout, findings) {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(lintMsgCmd)
+	lintMsgCmd.Flags().StringP("message", "m", "", "Commit message to check")
+}
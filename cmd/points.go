@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stanlocht/snap/pkg/user"
+)
+
+// pointsCmd represents the points command
+var pointsCmd = &cobra.Command{
+	Use:   "points",
+	Short: "Manage user points",
+	Long: `Manage the points and stats kept in .snap/users.
+
+Points can always be rebuilt from history: the commits on local branches,
+comments on issues and issues closed by commits, scored by the current rules.
+Set points.cache to true (snap config set points.cache true) to treat the
+user files purely as a cache that is rebuilt whenever history or the rules change.`,
+}
+
+// pointsRecomputeCmd represents the points recompute command
+var pointsRecomputeCmd = &cobra.Command{
+	Use:   "recompute",
+	Short: "Rebuild user stats from history",
+	Long: `Rebuild every user's points and stats by replaying the repository's history
+under the current rules. With --check, nothing is written; users whose stored
+stats differ from history are listed and the command fails if there are any.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		check, _ := cmd.Flags().GetBool("check")
+		userManager := openUserManager()
+
+		// Report drift
+		if check {
+			drifts, err := userManager.CheckDrift()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error checking points: %v\n", err)
+				os.Exit(1)
+			}
+			if len(drifts) == 0 {
+				fmt.Println("Points match history")
+				return
+			}
+			for _, drift := range drifts {
+				fmt.Printf("%s: %s stored, %s in history\n", drift.Name, describeStats(drift.Stored), describeStats(drift.Computed))
+			}
+			fmt.Fprintf(os.Stderr, "%d users have drifted; run snap points recompute to fix them\n", len(drifts))
+			os.Exit(1)
+		}
+
+		// Rebuild users
+		users, err := userManager.Recompute()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error recomputing points: %v\n", err)
+			os.Exit(1)
+		}
+		if err := userManager.SaveUsers(users); err != nil {
+			fmt.Fprintf(os.Stderr, "Error saving users: %v\n", err)
+			os.Exit(1)
+		}
+
+		for _, u := range users {
+			fmt.Printf("%s: %s\n", u.Name, describeStats(u))
+		}
+		fmt.Printf("Recomputed %d users\n", len(users))
+	},
+}
+
+// pointsAuditCmd represents the points audit command
+var pointsAuditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Report suspicious activity",
+	Long: `Report the users whose actions the safeguards against gaming points flagged:
+empty commits, reverts and reverted or popped commits, which earn nothing, and
+actions that earned less for coming in quick succession or going over a daily cap.
+Use --since to look only at recent activity, e.g. --since 7d.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		sinceValue, _ := cmd.Flags().GetString("since")
+		userManager := openUserManager()
+
+		// Get window
+		var window user.Window
+		if sinceValue != "" {
+			loc, err := userManager.Location()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			window.Since, err = user.ParseSince(sinceValue, time.Now().In(loc), loc)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		// Audit users
+		suspicions, err := userManager.Audit(window)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error auditing points: %v\n", err)
+			os.Exit(1)
+		}
+		if len(suspicions) == 0 {
+			fmt.Println("No suspicious activity")
+			return
+		}
+
+		fmt.Println("Suspicious activity:")
+		for _, suspicion := range suspicions {
+			fmt.Printf("\n%s: %d flagged, %d commits counted, last at %s\n", suspicion.Name, suspicion.Total,
+				suspicion.Commits, suspicion.Last.Format("2006-01-02 15:04:05"))
+			for _, flag := range user.Flags {
+				if count := suspicion.Flags[flag]; count > 0 {
+					fmt.Printf("  %-25s %d\n", flag.Describe(), count)
+				}
+			}
+		}
+	},
+}
+
+// describeStats summarizes a user's points and counters
+func describeStats(u *user.User) string {
+	return fmt.Sprintf("%d points (%d commits, %d issues closed, %d comments)", u.Points, u.Commits, u.IssuesClosed, u.Comments)
+}
+
+func init() {
+	rootCmd.AddCommand(pointsCmd)
+	pointsCmd.AddCommand(pointsRecomputeCmd)
+	pointsRecomputeCmd.Flags().Bool("check", false, "Report users whose stats differ from history without changing them")
+	pointsCmd.AddCommand(pointsAuditCmd)
+	pointsAuditCmd.Flags().String("since", "", "Audit only activity since a time, e.g. 7d or 2026-01-31")
+}
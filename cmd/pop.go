@@ -5,6 +5,7 @@ import (
 	"os"
 
 	"github.com/stanlocht/snap/pkg/repository"
+	"github.com/stanlocht/snap/pkg/user"
 	"github.com/spf13/cobra"
 )
 
@@ -58,6 +59,9 @@ This command undoes the last commit, keeping the changes in the working director
 		fmt.Printf("Undid last commit %s\n", currentCommitID[:7])
 		fmt.Printf("Message: %s\n", currentCommit.Message)
 		fmt.Println("Changes from the commit are still in your working directory")
+
+		// The commit no longer earns points
+		clawback(user.NewUserManager(repo.Path), currentCommitID, user.FlagPopped)
 	},
 }
 
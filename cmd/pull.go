@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/stanlocht/snap/pkg/config"
+	"github.com/stanlocht/snap/pkg/issue"
+	"github.com/stanlocht/snap/pkg/remote"
+	"github.com/stanlocht/snap/pkg/repository"
+)
+
+// pullCmd represents the pull command
+var pullCmd = &cobra.Command{
+	Use:   "pull [remote] [branch]",
+	Short: "Fetch from a remote and integrate its changes",
+	Long: `Fetch from a remote repository and integrate the remote branch into the current branch.
+If the current branch has no commits of its own the branch is fast-forwarded;
+otherwise a merge commit is created. Pulling stops if a file was changed on both sides.`,
+	Args: cobra.MaximumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Get remote name
+		remoteName := remote.DefaultRemoteName
+		if len(args) > 0 {
+			remoteName = args[0]
+		}
+
+		// Get current directory
+		currentDir, err := os.Getwd()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error getting current directory: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Find repository
+		repo, err := repository.Find(currentDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Get branch, defaulting to the current one
+		var branch string
+		if len(args) > 1 {
+			branch = args[1]
+		} else {
+			branch, err = repo.CurrentBranch()
+			if err != nil || branch == "" {
+				fmt.Fprintln(os.Stderr, "Error: not on a branch, specify the branch to pull")
+				os.Exit(1)
+			}
+		}
+
+		// Get author name and email for a possible merge commit
+		configPath := filepath.Join(repo.Path, ".snap", "config")
+		authorName, _ := rootCmd.PersistentFlags().GetString("author")
+		if authorName == "" {
+			authorName, _ = config.GetValue(configPath, "user.name")
+		}
+		if authorName == "" {
+			authorName = "snap"
+		}
+		email, _ := rootCmd.PersistentFlags().GetString("email")
+		if email == "" {
+			email, _ = config.GetValue(configPath, "user.email")
+		}
+
+		// Pull from remote
+		result, err := remote.Pull(repo, remoteName, branch, authorName, email)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error pulling from %s: %v\n", remoteName, err)
+			os.Exit(1)
+		}
+
+		printFetchResult(result.Fetch)
+		printIssueMerge(result.Issues)
+		switch result.Outcome {
+		case remote.PullUpToDate:
+			fmt.Println("Already up to date")
+		case remote.PullFastForward:
+			fmt.Printf("Fast-forwarded %s to %s\n", branch, result.CommitID[:7])
+		case remote.PullMerge:
+			fmt.Printf("Merged %s/%s into %s (merge commit %s)\n", remoteName, branch, branch, result.CommitID[:7])
+		}
+	},
+}
+
+// printIssueMerge reports how issues from the remote were merged
+func printIssueMerge(result *issue.MergeResult) {
+	if result == nil {
+		return
+	}
+
+	switch result.Outcome {
+	case issue.MergeFastForward:
+		fmt.Println("Issues updated")
+	case issue.MergeCommit:
+		fmt.Printf("Merged issues (%d new, %d changed on both sides)\n", result.NewIssues, result.ChangedBoth)
+	}
+	for _, conflict := range result.Conflicts {
+		fmt.Printf("  Issue #%d: %s was changed on both sides, kept the most recent edit\n", conflict.Issue, conflict.Field)
+	}
+	for _, renumbering := range result.Renumbered {
+		fmt.Printf("  Issue #%d is now #%d (%s) because its number was taken\n", renumbering.Old, renumbering.New, renumbering.UID[:8])
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(pullCmd)
+}
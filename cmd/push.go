@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/stanlocht/snap/pkg/remote"
+	"github.com/stanlocht/snap/pkg/repository"
+)
+
+// pushCmd represents the push command
+var pushCmd = &cobra.Command{
+	Use:   "push [remote] [branch]",
+	Short: "Upload local commits to a remote",
+	Long: `Upload the commits of a branch to a remote repository.
+Pushes that would discard commits on the remote are refused unless --force is given.`,
+	Args: cobra.MaximumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Get force flag
+		force, _ := cmd.Flags().GetBool("force")
+
+		// Get remote name
+		remoteName := remote.DefaultRemoteName
+		if len(args) > 0 {
+			remoteName = args[0]
+		}
+
+		// Get current directory
+		currentDir, err := os.Getwd()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error getting current directory: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Find repository
+		repo, err := repository.Find(currentDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Get branch, defaulting to the current one
+		var branch string
+		if len(args) > 1 {
+			branch = args[1]
+		} else {
+			branch, err = repo.CurrentBranch()
+			if err != nil || branch == "" {
+				fmt.Fprintln(os.Stderr, "Error: not on a branch, specify the branch to push")
+				os.Exit(1)
+			}
+		}
+
+		// Push to remote
+		result, err := remote.Push(repo, remoteName, branch, force)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error pushing to %s: %v\n", remoteName, err)
+			os.Exit(1)
+		}
+
+		if result.UpToDate {
+			fmt.Println("Everything up to date")
+			return
+		}
+
+		fmt.Printf("To %s (%d objects)\n", result.Remote.URL, result.Objects)
+		switch {
+		case result.Old == result.New:
+			// Only metadata such as issues was pushed
+		case result.Old == "":
+			fmt.Printf("  * [new branch]      %s\n", branch)
+		case force:
+			fmt.Printf("  + %s...%s %s (forced update)\n", result.Old[:7], result.New[:7], branch)
+		default:
+			fmt.Printf("    %s..%s  %s\n", result.Old[:7], result.New[:7], branch)
+		}
+		for _, change := range result.Metadata {
+			if change.Old == "" {
+				fmt.Printf("  * [new]             %s\n", change.Name)
+			} else {
+				fmt.Printf("    %s..%s  %s\n", change.Old[:7], change.New[:7], change.Name)
+			}
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(pushCmd)
+	pushCmd.Flags().BoolP("force", "f", false, "Overwrite the remote branch even if it is not an ancestor of the local branch")
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/stanlocht/snap/pkg/remote"
+	"github.com/stanlocht/snap/pkg/repository"
+)
+
+// remoteCmd represents the remote command
+var remoteCmd = &cobra.Command{
+	Use:   "remote",
+	Short: "Manage remote repositories",
+	Long: `Manage the set of repositories ("remotes") whose branches you track.
+Remotes are stored in .snap/config as [remote "<name>"] sections.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Without a subcommand, list the remotes
+		remoteListCmd.Run(cmd, args)
+	},
+}
+
+// remoteAddCmd represents the remote add command
+var remoteAddCmd = &cobra.Command{
+	Use:   "add [name] [url]",
+	Short: "Add a remote",
+	Long:  `Add a remote repository. The URL can be a path to another Snap repository.`,
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Get current directory
+		currentDir, err := os.Getwd()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error getting current directory: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Find repository
+		repo, err := repository.Find(currentDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Add remote
+		r, err := remote.AddRemote(repo, args[0], args[1])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error adding remote: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Added remote %s -> %s\n", r.Name, r.URL)
+	},
+}
+
+// remoteRemoveCmd represents the remote remove command
+var remoteRemoveCmd = &cobra.Command{
+	Use:   "remove [name]",
+	Short: "Remove a remote",
+	Long:  `Remove a remote and all of its remote-tracking references.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Get current directory
+		currentDir, err := os.Getwd()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error getting current directory: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Find repository
+		repo, err := repository.Find(currentDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Remove remote
+		if err := remote.RemoveRemote(repo, args[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error removing remote: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Removed remote %s\n", args[0])
+	},
+}
+
+// remoteListCmd represents the remote list command
+var remoteListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List remotes",
+	Long:  `List the configured remotes and their URLs.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Get current directory
+		currentDir, err := os.Getwd()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error getting current directory: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Find repository
+		repo, err := repository.Find(currentDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		// List remotes
+		remotes, err := remote.GetRemotes(repo)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error listing remotes: %v\n", err)
+			os.Exit(1)
+		}
+
+		if len(remotes) == 0 {
+			fmt.Println("No remotes configured")
+			return
+		}
+
+		for _, r := range remotes {
+			fmt.Printf("%s\t%s\n", r.Name, r.URL)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(remoteCmd)
+	remoteCmd.AddCommand(remoteAddCmd)
+	remoteCmd.AddCommand(remoteRemoveCmd)
+	remoteCmd.AddCommand(remoteListCmd)
+}
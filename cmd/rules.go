@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/stanlocht/snap/pkg/repository"
+	"github.com/stanlocht/snap/pkg/user"
+)
+
+// openUserManager finds the repository in the current directory and creates a
+// user manager for it, exiting on failure
+func openUserManager() *user.UserManager {
+	// Get current directory
+	currentDir, err := os.Getwd()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error getting current directory: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Find repository
+	repo, err := repository.Find(currentDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	return user.NewUserManager(repo.Path)
+}
+
+// formatNumber formats a float without trailing zeros
+func formatNumber(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// describeRule describes the points a rule gives, e.g. "10 points, +1 per file"
+func describeRule(rule *user.Rule) string {
+	parts := []string{fmt.Sprintf("%d points", rule.Points)}
+	if rule.PerEstimatedHour > 0 {
+		parts = append(parts, fmt.Sprintf("or %s per estimated hour", formatNumber(rule.PerEstimatedHour)))
+	}
+	if rule.PerFile > 0 {
+		perFile := fmt.Sprintf("+%s per file", formatNumber(rule.PerFile))
+		if rule.MaxFiles > 0 {
+			perFile += fmt.Sprintf(" (up to %d files)", rule.MaxFiles)
+		}
+		parts = append(parts, perFile)
+	}
+
+	emojis := make([]string, 0, len(rule.Multipliers))
+	for emoji := range rule.Multipliers {
+		emojis = append(emojis, emoji)
+	}
+	sort.Strings(emojis)
+	for _, emoji := range emojis {
+		parts = append(parts, fmt.Sprintf("×%s for %s", formatNumber(rule.Multipliers[emoji]), emoji))
+	}
+
+	if rule.Cap > 0 {
+		parts = append(parts, fmt.Sprintf("at most %d per action", rule.Cap))
+	}
+	if rule.RapidMinutes > 0 {
+		parts = append(parts, fmt.Sprintf("×%s for every other one in the %d minutes before", formatNumber(rule.RapidDecay), rule.RapidMinutes))
+	}
+	if rule.DailyCap > 0 {
+		parts = append(parts, fmt.Sprintf("at most %d per day", rule.DailyCap))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// rulesCmd represents the rules command
+var rulesCmd = &cobra.Command{
+	Use:   "rules",
+	Short: "Show or check the point rules",
+	Long: `Show or check the rules that decide how many points actions earn.
+
+Rules are defined per action in .snap/rules.json:
+
+  {
+    "actions": {
+      "commit": {
+        "points": 10,
+        "per_file": 1,
+        "max_files": 20,
+        "multipliers": {"🔒": 2, ":bug:": 1.5},
+        "cap": 50,
+        "daily_cap": 200,
+        "rapid_minutes": 2,
+        "rapid_decay": 0.5
+      },
+      "issue_close": {"points": 15, "per_estimated_hour": 5}
+    }
+  }
+
+An action done again within rapid_minutes of earlier ones earns its points
+multiplied by rapid_decay once for each of them. Commits that change no files
+and reverts earn nothing, and reverting or popping a commit takes back its points.
+
+Actions in the file replace the default rule for that action; other actions
+keep their default. Single fields can also be set in the config, e.g.
+snap config set rules.commit.points 12, or
+snap config set rules.commit.multipliers "🔒=2, 🐛=1.5".`,
+}
+
+// rulesShowCmd represents the rules show command
+var rulesShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the point rules",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		userManager := openUserManager()
+
+		// Get rules
+		rules, err := userManager.Rules()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Println("Points per action:")
+		for _, action := range rules.SortedActions() {
+			fmt.Printf("  %-15s %s\n", action, describeRule(rules.Actions[action]))
+		}
+	},
+}
+
+// rulesValidateCmd represents the rules validate command
+var rulesValidateCmd = &cobra.Command{
+	Use:   "validate [file]",
+	Short: "Check a rule file",
+	Long: `Check a rule file for mistakes, such as unknown fields, negative points or
+multipliers for emojis that aren't snapmojis. A file is checked against the
+snapmojis of the repository it's run in, if any. Without a file, the
+repository's .snap/rules.json and the rules in its config are checked.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Check a given file
+		if len(args) == 1 {
+			data, err := os.ReadFile(args[0])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error reading rule file: %v\n", err)
+				os.Exit(1)
+			}
+			parse := user.ParseRules
+			if currentDir, err := os.Getwd(); err == nil {
+				if repo, err := repository.Find(currentDir); err == nil {
+					parse = user.NewUserManager(repo.Path).ParseRules
+				}
+			}
+			rules, err := parse(data)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %s: %v\n", args[0], err)
+				os.Exit(1)
+			}
+			fmt.Printf("%s is valid (%d actions)\n", args[0], len(rules.Actions))
+			return
+		}
+
+		// Or the repository's rules
+		userManager := openUserManager()
+		if _, err := os.Stat(userManager.RulesPath()); errors.Is(err, fs.ErrNotExist) {
+			fmt.Printf("No .snap/%s; the default rules apply\n", user.RulesFileName)
+		}
+		rules, err := userManager.Rules()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Rules are valid (%d actions)\n", len(rules.Actions))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(rulesCmd)
+	rulesCmd.AddCommand(rulesShowCmd)
+	rulesCmd.AddCommand(rulesValidateCmd)
+}
@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/stanlocht/snap/pkg/snapmoji"
+)
+
+// loadSnapmojis returns the repository's snapmoji registry, exiting on an invalid snapmoji file
+func loadSnapmojis(repoPath string) *snapmoji.Registry {
+	registry, err := snapmoji.Load(repoPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	return registry
+}
+
+// readSnapmojiFile returns the repository's snapmoji file and path, exiting on an invalid file
+func readSnapmojiFile() (*snapmoji.RegistryFile, string) {
+	repoPath := openUserManager().RepoPath
+	file, err := snapmoji.ReadRegistryFile(repoPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	return file, repoPath
+}
+
+// snapmojiCmd represents the snapmoji command
+var snapmojiCmd = &cobra.Command{
+	Use:   "snapmoji",
+	Short: "Manage the repository's snapmojis",
+	Long: `Manage the snapmojis commit messages in the repository may start with.
+
+The defaults can be extended in .snap/snapmojis.json, which lists snapmojis with
+their emoji, code, description, category, semver impact and point multiplier:
+
+  {
+    "snapmojis": [
+      {"emoji": "🌐", "code": ":globe_with_meridians:", "description": "Translations",
+       "category": "i18n", "semver": "patch", "multiplier": 1.5}
+    ],
+    "remove": [":construction:"]
+  }
+
+Snapmojis sharing an emoji or code with a default replace it, "remove" leaves
+defaults out, and "replace": true uses only the file's snapmojis.`,
+}
+
+// snapmojiListCmd represents the snapmoji list command
+var snapmojiListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the snapmojis",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		registry := loadSnapmojis(openUserManager().RepoPath)
+
+		for _, s := range registry.Snapmojis {
+			semver := string(s.Semver)
+			if semver == "" {
+				semver = string(snapmoji.SemverNone)
+			}
+			multiplier := ""
+			if s.Multiplier > 0 {
+				multiplier = "x" + formatNumber(s.Multiplier)
+			}
+			fmt.Printf("%s %-24s %-13s %-6s %-5s %s\n", s.Emoji, s.Code, s.Category, semver, multiplier, s.Description)
+		}
+	},
+}
+
+// snapmojiAddCmd represents the snapmoji add command
+var snapmojiAddCmd = &cobra.Command{
+	Use:   "add [emoji] [code] [description]",
+	Short: "Add a snapmoji, or replace one with the same emoji or code",
+	Args:  cobra.ExactArgs(3),
+	Run: func(cmd *cobra.Command, args []string) {
+		category, _ := cmd.Flags().GetString("category")
+		semver, _ := cmd.Flags().GetString("semver")
+		multiplier, _ := cmd.Flags().GetFloat64("multiplier")
+		file, repoPath := readSnapmojiFile()
+
+		// Build snapmoji, with the colons of the code optional
+		code := args[1]
+		if !strings.HasPrefix(code, ":") {
+			code = ":" + strings.TrimSuffix(code, ":") + ":"
+		}
+		added := snapmoji.Snapmoji{
+			Emoji:       args[0],
+			Code:        code,
+			Description: args[2],
+			Category:    category,
+			Semver:      snapmoji.Semver(semver),
+			Multiplier:  multiplier,
+		}
+
+		// Replace the snapmojis of the file it shares its emoji or code with
+		existing := &snapmoji.Registry{Snapmojis: []snapmoji.Snapmoji{added}}
+		var snapmojis []snapmoji.Snapmoji
+		for _, s := range file.Snapmojis {
+			if _, ok := existing.Find(s.Emoji); !ok {
+				if _, ok := existing.Find(s.Code); !ok {
+					snapmojis = append(snapmojis, s)
+				}
+			}
+		}
+		file.Snapmojis = append(snapmojis, added)
+
+		// A removed default comes back when it's added again
+		var remove []string
+		for _, key := range file.Remove {
+			if _, ok := existing.Find(key); !ok {
+				remove = append(remove, key)
+			}
+		}
+		file.Remove = remove
+
+		if err := snapmoji.WriteRegistryFile(repoPath, file); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Added %s %s - %s\n", added.Emoji, added.Code, added.Description)
+	},
+}
+
+// snapmojiRemoveCmd represents the snapmoji remove command
+var snapmojiRemoveCmd = &cobra.Command{
+	Use:   "remove [emoji or code]",
+	Short: "Remove a snapmoji",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		key := args[0]
+		file, repoPath := readSnapmojiFile()
+
+		// Find snapmoji
+		removed, ok := file.Registry().Find(key)
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Error: %s is not a snapmoji of the repository\n", key)
+			os.Exit(1)
+		}
+
+		// Drop it from the file, then leave out the defaults it replaced
+		var snapmojis []snapmoji.Snapmoji
+		for _, s := range file.Snapmojis {
+			if s.Emoji != removed.Emoji {
+				snapmojis = append(snapmojis, s)
+			}
+		}
+		file.Snapmojis = snapmojis
+		for _, k := range []string{removed.Emoji, removed.Code} {
+			if s, ok := file.Registry().Find(k); ok {
+				file.Remove = append(file.Remove, s.Code)
+			}
+		}
+
+		if err := snapmoji.WriteRegistryFile(repoPath, file); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Removed %s %s\n", removed.Emoji, removed.Code)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(snapmojiCmd)
+	snapmojiCmd.AddCommand(snapmojiListCmd)
+	snapmojiCmd.AddCommand(snapmojiAddCmd)
+	snapmojiCmd.AddCommand(snapmojiRemoveCmd)
+	snapmojiAddCmd.Flags().String("category", "", "Kind of change, e.g. fix")
+	snapmojiAddCmd.Flags().String("semver", "", "Version bump the change calls for: major, minor, patch or none")
+	snapmojiAddCmd.Flags().Float64("multiplier", 0, "Multiplies the points commits earn")
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/stanlocht/snap/pkg/repository"
+)
+
+// tagCmd represents the tag command
+var tagCmd = &cobra.Command{
+	Use:   "tag [name] [revision]",
+	Short: "List, create or delete tags",
+	Long: `List, create or delete tags, which name commits such as releases.
+
+  snap tag                  List the tags
+  snap tag v1.0.0           Tag HEAD as v1.0.0
+  snap tag v1.0.0 abc1234   Tag another commit, branch or tag
+  snap tag -d v1.0.0        Delete a tag
+
+Tags can't be moved; delete one to tag another commit. They are kept in the
+repository and not pushed or fetched.`,
+	Args: cobra.MaximumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		deleteTag, _ := cmd.Flags().GetBool("delete")
+
+		// Get current directory
+		currentDir, err := os.Getwd()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error getting current directory: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Find repository
+		repo, err := repository.Find(currentDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Delete tag
+		if deleteTag {
+			if len(args) != 1 {
+				fmt.Fprintln(os.Stderr, "Error: snap tag -d takes the name of the tag to delete")
+				os.Exit(1)
+			}
+			if err := repo.DeleteTag(args[0]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error deleting tag: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("Deleted tag %s\n", args[0])
+			return
+		}
+
+		// List tags
+		if len(args) == 0 {
+			tags, err := repo.ListTags()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error listing tags: %v\n", err)
+				os.Exit(1)
+			}
+			if len(tags) == 0 {
+				fmt.Println("No tags yet")
+				return
+			}
+			for _, tag := range tags {
+				fmt.Printf("%-20s %s\n", tag.Name, tag.CommitID[:7])
+			}
+			return
+		}
+
+		// Create tag
+		revision := "HEAD"
+		if len(args) == 2 {
+			revision = args[1]
+		}
+		commitID, err := repo.ResolveRevision(revision)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := repo.CreateTag(args[0], commitID); err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating tag: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Tagged %s as %s\n", commitID[:7], args[0])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(tagCmd)
+	tagCmd.Flags().BoolP("delete", "d", false, "Delete the tag")
+}
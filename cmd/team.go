@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/stanlocht/snap/pkg/issue"
+)
+
+// teamCmd represents the team command
+var teamCmd = &cobra.Command{
+	Use:   "team",
+	Short: "Show teams and their stats",
+	Long: `Show the teams of the repository and their combined stats.
+
+Teams are configured in the config, listing members by name or email:
+
+  snap config set team.backend.members "alice, bob@example.com"
+
+Issues can be assigned to a team with snap issue assign <id> team:<name>, and
+teams ranked with snap leaderboard --teams.`,
+}
+
+// teamListCmd represents the team list command
+var teamListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the teams and their members",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		userManager := openUserManager()
+
+		// Get teams
+		teams, err := userManager.Teams()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if len(teams) == 0 {
+			fmt.Println("No teams configured; add one with snap config set team.<name>.members \"alice, bob\"")
+			return
+		}
+
+		for _, team := range teams {
+			fmt.Printf("%-15s %s\n", team.Name, strings.Join(team.Members, ", "))
+		}
+	},
+}
+
+// teamShowCmd represents the team show command
+var teamShowCmd = &cobra.Command{
+	Use:   "show [team]",
+	Short: "Show a team's combined stats, streaks and achievements",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		showHeatmap, _ := cmd.Flags().GetBool("heatmap")
+		userManager := openUserManager()
+
+		// Get team
+		team, err := userManager.GetTeam(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		stats, err := userManager.TeamStats(team)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error getting team stats: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Print team stats
+		fmt.Printf("Team: %s\n", team.Name)
+		fmt.Printf("Members: %s\n", strings.Join(team.Members, ", "))
+		fmt.Printf("Stats: %s\n", describeStats(stats))
+
+		// Print achievements
+		if badges := stats.Badges(); len(badges) > 0 {
+			fmt.Println("\nTeam achievements:")
+			for _, badge := range badges {
+				fmt.Printf("- %s %s – %s\n", badge.Emoji, badge.Name, badge.Description)
+			}
+		}
+
+		// Print streaks and the activity heatmap
+		if err := printActivity(userManager, stats, showHeatmap); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Print the open issues assigned to the team
+		issues, err := issue.NewIssueManager(userManager.RepoPath).ListIssues(false)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error listing issues: %v\n", err)
+			os.Exit(1)
+		}
+		var assigned []*issue.Issue
+		for _, i := range issues {
+			if i.AssignedTeam() == team.Name {
+				assigned = append(assigned, i)
+			}
+		}
+		if len(assigned) > 0 {
+			fmt.Println("\nAssigned issues:")
+			for _, i := range assigned {
+				fmt.Printf("- #%d %s\n", i.ID, i.Title)
+			}
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(teamCmd)
+	teamCmd.AddCommand(teamListCmd)
+	teamCmd.AddCommand(teamShowCmd)
+	teamShowCmd.Flags().Bool("heatmap", false, "Show a heatmap of the team's last year of activity")
+}
@@ -3,6 +3,8 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/stanlocht/snap/pkg/repository"
 	"github.com/stanlocht/snap/pkg/user"
@@ -15,6 +17,8 @@ var meCmd = &cobra.Command{
 	Short: "Show user stats",
 	Long:  `Show user stats and contribution points.`,
 	Run: func(cmd *cobra.Command, args []string) {
+		showHeatmap, _ := cmd.Flags().GetBool("heatmap")
+
 		// Get current directory
 		currentDir, err := os.Getwd()
 		if err != nil {
@@ -56,6 +60,32 @@ var meCmd = &cobra.Command{
 		fmt.Printf("Commits: %d\n", user.Commits)
 		fmt.Printf("Issues opened: %d\n", user.IssuesOpen)
 		fmt.Printf("Issues closed: %d\n", user.IssuesClosed)
+		teams, err := userManager.TeamsOf(user.Name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error getting teams: %v\n", err)
+			os.Exit(1)
+		}
+		if len(teams) > 0 {
+			names := make([]string, 0, len(teams))
+			for _, team := range teams {
+				names = append(names, team.Name)
+			}
+			fmt.Printf("Teams: %s\n", strings.Join(names, ", "))
+		}
+
+		// Print achievements
+		if badges := user.Badges(); len(badges) > 0 {
+			fmt.Println("\nAchievements:")
+			for _, badge := range badges {
+				fmt.Printf("- %s %s – %s\n", badge.Emoji, badge.Name, badge.Description)
+			}
+		}
+
+		// Print streaks and the activity heatmap
+		if err := printActivity(userManager, user, showHeatmap); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
 
 		// Print recent actions
 		if len(user.ActionLog) > 0 {
@@ -73,51 +103,284 @@ var meCmd = &cobra.Command{
 	},
 }
 
+// heatmapShades are the characters for the activity levels of a heatmap
+var heatmapShades = []string{"·", "░", "▒", "▓", "█"}
+
+// printActivity prints a user's streaks and, if asked, a heatmap of their last year
+func printActivity(userManager *user.UserManager, u *user.User, showHeatmap bool) error {
+	loc, err := userManager.Location()
+	if err != nil {
+		return err
+	}
+	now := time.Now().In(loc)
+	current, longest := u.Streaks(loc, now)
+	fmt.Printf("\nCurrent streak: %s (longest: %s)\n", pluralDays(current), pluralDays(longest))
+
+	if !showHeatmap {
+		return nil
+	}
+	grid := user.Heatmap(u.DailyActivity(loc), now, 53)
+
+	// Month labels above the first week of each month
+	labels := []rune(strings.Repeat(" ", len(grid)+3))
+	for week := 1; week < len(grid); week++ {
+		sunday, _ := time.Parse("2006-01-02", grid[week][0].Date)
+		previous, _ := time.Parse("2006-01-02", grid[week-1][0].Date)
+		if sunday.Month() != previous.Month() && labels[week-1] == ' ' {
+			copy(labels[week:], []rune(sunday.Format("Jan")))
+		}
+	}
+	fmt.Printf("\n    %s\n", strings.TrimRight(string(labels), " "))
+
+	for weekday, name := range []string{"", "Mon", "", "Wed", "", "Fri", ""} {
+		var row strings.Builder
+		for _, days := range grid {
+			cell := days[weekday]
+			if cell.Future {
+				row.WriteString(" ")
+				continue
+			}
+			row.WriteString(heatmapShades[cell.Level])
+		}
+		fmt.Printf("%-3s %s\n", name, strings.TrimRight(row.String(), " "))
+	}
+	fmt.Printf("    Less %s More\n", strings.Join(heatmapShades, ""))
+	return nil
+}
+
+// pluralDays formats a number of days, e.g. "1 day" or "3 days"
+func pluralDays(n int) string {
+	if n == 1 {
+		return "1 day"
+	}
+	return fmt.Sprintf("%d days", n)
+}
+
 // leaderboardCmd represents the leaderboard command
 var leaderboardCmd = &cobra.Command{
 	Use:   "leaderboard",
 	Short: "Show top contributors",
-	Long:  `Show top contributors in the repository.`,
+	Long: `Show top contributors in the repository.
+
+By default users are ranked by lifetime points. Use --since to count only
+recent activity (24h, 7d, 2w or a date such as 2026-01-31), --season for a
+quarter such as 2026-Q4 or a season configured with
+snap config set season.<name>.start/end YYYY-MM-DD, and --category to rank by
+commits, issues_closed or bugs_fixed instead of points. The standings of a
+season that has ended are archived in .snap/seasons the first time this
+command shows them; snap web only reads the archive.
+
+Teams are configured with snap config set team.<name>.members "alice, bob",
+listing members by name or email. Use --teams to rank teams by their members'
+combined scores, or --team to rank only a team's members.`,
+	Args: cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		// Get current directory
-		currentDir, err := os.Getwd()
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error getting current directory: %v\n", err)
+		// Get flags
+		sinceValue, _ := cmd.Flags().GetString("since")
+		seasonName, _ := cmd.Flags().GetString("season")
+		categoryValue, _ := cmd.Flags().GetString("category")
+		teams, _ := cmd.Flags().GetBool("teams")
+		teamName, _ := cmd.Flags().GetString("team")
+		if sinceValue != "" && seasonName != "" {
+			fmt.Fprintln(os.Stderr, "Error: --since and --season can't be combined")
 			os.Exit(1)
 		}
-
-		// Find repository
-		repo, err := repository.Find(currentDir)
+		if teams && teamName != "" {
+			fmt.Fprintln(os.Stderr, "Error: --teams and --team can't be combined")
+			os.Exit(1)
+		}
+		category, err := user.ParseCategory(categoryValue)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
 
 		// Create user manager
-		userManager := user.NewUserManager(repo.Path)
+		userManager := openUserManager()
+		loc, err := userManager.Location()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		now := time.Now().In(loc)
 
 		// Get leaderboard
-		users, err := userManager.GetLeaderboard()
+		var standings []user.Standing
+		title := "Leaderboard"
+		leaderboard, seasonLeaderboard := userManager.Leaderboard, userManager.SeasonLeaderboard
+		if teams {
+			title = "Team leaderboard"
+			leaderboard, seasonLeaderboard = userManager.TeamLeaderboard, userManager.SeasonTeamLeaderboard
+		}
+		switch {
+		case seasonName != "":
+			// Keep the final standings of an ended season from changing later
+			if _, err := userManager.ArchiveSeason(seasonName, now); err != nil {
+				fmt.Fprintf(os.Stderr, "Error archiving season: %v\n", err)
+				os.Exit(1)
+			}
+			var final bool
+			standings, final, err = seasonLeaderboard(seasonName, category, now)
+			title += fmt.Sprintf(" for season %s", seasonName)
+			if final {
+				title += " (final)"
+			}
+		case sinceValue != "":
+			var since time.Time
+			since, err = user.ParseSince(sinceValue, now, loc)
+			if err == nil {
+				standings, err = leaderboard(category, user.Window{Since: since})
+			}
+			title += fmt.Sprintf(" since %s", since.Format("2006-01-02 15:04"))
+		default:
+			standings, err = leaderboard(category, user.Window{})
+		}
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error getting leaderboard: %v\n", err)
 			os.Exit(1)
 		}
 
-		if len(users) == 0 {
-			fmt.Println("No users found")
+		// Keep a team's members
+		if teamName != "" {
+			team, err := userManager.GetTeam(teamName)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			members := standings[:0]
+			for _, standing := range standings {
+				if team.Has(standing.Name) {
+					members = append(members, standing)
+				}
+			}
+			standings = members
+			title += fmt.Sprintf(" of team %s", team.Name)
+		}
+
+		if len(standings) == 0 {
+			if teams {
+				fmt.Println("No teams found")
+			} else {
+				fmt.Println("No users found")
+			}
 			return
 		}
 
 		// Print leaderboard
-		fmt.Println("Leaderboard:")
-		fmt.Println("------------")
-		for i, user := range users {
-			fmt.Printf("%d. %s - %d points\n", i+1, user.Name, user.Points)
+		if category != user.CategoryPoints {
+			title += fmt.Sprintf(" by %s", category.Unit())
+		}
+		fmt.Println(title + ":")
+		fmt.Println(strings.Repeat("-", len([]rune(title))+1))
+		ranks := user.Ranks(standings)
+		for i, standing := range standings {
+			fmt.Printf("%d. %s - %d %s\n", ranks[i], standing.Name, standing.Score, category.Unit())
+		}
+	},
+}
+
+// leaderboardSeasonsCmd represents the leaderboard seasons command
+var leaderboardSeasonsCmd = &cobra.Command{
+	Use:   "seasons",
+	Short: "List the configured seasons",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		userManager := openUserManager()
+
+		// Get seasons
+		seasons, err := userManager.Seasons()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if len(seasons) == 0 {
+			fmt.Println("No seasons configured; quarters such as 2026-Q4 can always be used")
+			return
+		}
+
+		now := time.Now()
+		for _, season := range seasons {
+			status := "upcoming"
+			switch {
+			case !now.Before(season.End):
+				status = "ended"
+			case !now.Before(season.Start):
+				status = "current"
+			}
+			last := season.End.AddDate(0, 0, -1)
+			fmt.Printf("%-15s %s to %s (%s)\n", season.Name, season.Start.Format("2006-01-02"), last.Format("2006-01-02"), status)
+		}
+	},
+}
+
+// userCmd represents the user command
+var userCmd = &cobra.Command{
+	Use:   "user",
+	Short: "Manage users",
+	Long: `Manage the users points are kept for.
+
+People who commit under several names or emails can be mapped to one
+canonical identity in a .snapmailmap file in the repository root, in the
+format of git's .mailmap. A line may also map a name used without an email:
+
+  Alice Smith <alice@example.com> alice
+  Alice Smith <alice@example.com> <alice@old-job.example>`,
+}
+
+// userMergeCmd represents the user merge command
+var userMergeCmd = &cobra.Command{
+	Use:   "merge [alias] [user]",
+	Short: "Merge one user's stats into another's",
+	Long: `Merge the points, counters, action log and achievements of one user into
+another's and remove the first, e.g. to combine an alias with its canonical user.
+Add the alias to .snapmailmap as well, so later activity is recorded for the
+canonical user.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		userManager := openUserManager()
+
+		// Merged files would be rebuilt as they were
+		cached, err := userManager.CacheEnabled()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if cached {
+			fmt.Fprintf(os.Stderr, "Error: user files are rebuilt from history; map %s to %s in %s instead\n", args[0], args[1], user.MailmapFileName)
+			os.Exit(1)
+		}
+
+		// Merge users
+		merged, err := userManager.MergeUsers(args[0], args[1])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error merging users: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Merged %s into %s (%s)\n", args[0], merged.Name, describeStats(merged))
+
+		// Point out a missing mailmap entry
+		identity, err := userManager.Resolve(args[0], "")
+		if err == nil && identity.Name != merged.Name {
+			email := merged.Email
+			if email == "" {
+				email = "email"
+			}
+			fmt.Printf("To record %s's future activity for %s, add this line to %s:\n  %s <%s> %s\n", args[0], merged.Name, user.MailmapFileName, merged.Name, email, args[0])
 		}
 	},
 }
 
 func init() {
+	rootCmd.AddCommand(userCmd)
+	userCmd.AddCommand(userMergeCmd)
+
 	rootCmd.AddCommand(meCmd)
+	meCmd.Flags().Bool("heatmap", false, "Show a heatmap of the last year's activity")
 	rootCmd.AddCommand(leaderboardCmd)
+	leaderboardCmd.AddCommand(leaderboardSeasonsCmd)
+	leaderboardCmd.Flags().String("since", "", "Count only activity since a time, e.g. 7d or 2026-01-31")
+	leaderboardCmd.Flags().String("season", "", "Rank a season, e.g. 2026-Q4")
+	leaderboardCmd.Flags().String("category", "points", "Rank by points, commits, issues_closed or bugs_fixed")
+	leaderboardCmd.Flags().Bool("teams", false, "Rank teams instead of users")
+	leaderboardCmd.Flags().String("team", "", "Rank only the members of a team")
 }
@@ -45,53 +45,57 @@ This command analyzes the emojis used in recent commits to determine the mood of
 
 		// Define snapmoji categories for mood analysis
 		emojiCategories := map[string]struct {
-			emojis  []string
-			mood    string
-			emoji   string
-			message string
+			categories []string
+			mood       string
+			emoji      string
+			message    string
 		}{
 			"productive": {
-				emojis:  []string{"✨", "📚", "📝", "🚀"},
-				mood:    "Productive",
-				emoji:   "📈",
-				message: "The team is making great progress!",
+				categories: []string{"feature", "docs", "deploy"},
+				mood:       "Productive",
+				emoji:      "📈",
+				message:    "The team is making great progress!",
 			},
 			"bugfix": {
-				emojis:  []string{"🐛", "🚑", "🔧"},
-				mood:    "Bugfixing",
-				emoji:   "🔨",
-				message: "The team is squashing bugs and improving stability.",
+				categories: []string{"fix", "config"},
+				mood:       "Bugfixing",
+				emoji:      "🔨",
+				message:    "The team is squashing bugs and improving stability.",
 			},
 			"refactoring": {
-				emojis:  []string{"♻️", "🎨", "✅"},
-				mood:    "Refactoring",
-				emoji:   "👾",
-				message: "The codebase is getting cleaner and more maintainable.",
+				categories: []string{"refactor", "test"},
+				mood:       "Refactoring",
+				emoji:      "👾",
+				message:    "The codebase is getting cleaner and more maintainable.",
 			},
 			"security": {
-				emojis:  []string{"🔒", "🔍"},
-				mood:    "Security-focused",
-				emoji:   "👮",
-				message: "The team is prioritizing security and protection.",
+				categories: []string{"security"},
+				mood:       "Security-focused",
+				emoji:      "👮",
+				message:    "The team is prioritizing security and protection.",
 			},
 			"cleanup": {
-				emojis:  []string{"🔥", "🚮"},
-				mood:    "Cleaning up",
-				emoji:   "🧹",
-				message: "The team is removing old code and cleaning up the codebase.",
+				categories: []string{"removal"},
+				mood:       "Cleaning up",
+				emoji:      "🧹",
+				message:    "The team is removing old code and cleaning up the codebase.",
 			},
 		}
 
+		// Map the categories of the repository's snapmojis to moods
+		snapmojis := loadSnapmojis(repo.Path)
+		moods := make(map[string]string)
+		for mood, info := range emojiCategories {
+			for _, category := range info.categories {
+				moods[category] = mood
+			}
+		}
+
 		// Count snapmojis in commit messages
 		emojiCounts := make(map[string]int)
 		for _, commit := range history {
-			for category, info := range emojiCategories {
-				for _, emoji := range info.emojis {
-					if strings.Contains(commit.Message, emoji) {
-						emojiCounts[category]++
-						break
-					}
-				}
+			if s, ok := snapmojis.Match(commit.Message); ok && moods[s.Category] != "" {
+				emojiCounts[moods[s.Category]]++
 			}
 		}
 
@@ -118,13 +122,8 @@ This command analyzes the emojis used in recent commits to determine the mood of
 			}
 
 			// Try to extract snapmoji from commit message
-			for _, info := range emojiCategories {
-				for _, emoji := range info.emojis {
-					if strings.Contains(commit.Message, emoji) {
-						recentEmojis = append(recentEmojis, emoji)
-						break
-					}
-				}
+			if s, ok := snapmojis.Match(commit.Message); ok {
+				recentEmojis = append(recentEmojis, s.Emoji)
 			}
 		}
 
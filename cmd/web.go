@@ -16,7 +16,14 @@ var webCmd = &cobra.Command{
 	Use:   "web",
 	Short: "Start a web interface for the repository",
 	Long: `Start a lightweight web interface for browsing the repository.
-The web interface provides access to commits, issues, and user statistics.`,
+The web interface provides access to commits, issues, and user statistics.
+
+The server also speaks the snap transport protocol, so teammates can
+clone and fetch with "snap clone http://host:8123/". Pushing is only
+accepted when the server is started with --allow-push.
+
+Creating and moving issues works from this machine. Other machines can
+only browse unless the server is started with --allow-edits.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		// Get port from flag
 		port, _ := cmd.Flags().GetInt("port")
@@ -24,6 +31,10 @@ The web interface provides access to commits, issues, and user statistics.`,
 		// Get open flag
 		openBrowser, _ := cmd.Flags().GetBool("open")
 
+		// Get allow-push and allow-edits flags
+		allowPush, _ := cmd.Flags().GetBool("allow-push")
+		allowEdits, _ := cmd.Flags().GetBool("allow-edits")
+
 		// Get current directory
 		currentDir, err := os.Getwd()
 		if err != nil {
@@ -41,6 +52,12 @@ The web interface provides access to commits, issues, and user statistics.`,
 		// Start web server
 		serverURL := fmt.Sprintf("http://localhost:%d", port)
 		fmt.Printf("Starting Snap web interface at %s\n", serverURL)
+		if allowPush {
+			fmt.Println("Accepting pushes from remote clients")
+		}
+		if allowEdits {
+			fmt.Println("Accepting issue changes from other machines")
+		}
 		fmt.Println("Press Ctrl+C to stop the server")
 
 		// Open browser if requested
@@ -52,7 +69,7 @@ The web interface provides access to commits, issues, and user statistics.`,
 		}
 
 		// Start the web server
-		if err := web.StartServer(repo, port); err != nil {
+		if err := web.StartServer(repo, port, allowPush, allowEdits); err != nil {
 			fmt.Fprintf(os.Stderr, "Error starting web server: %v\n", err)
 			os.Exit(1)
 		}
@@ -83,4 +100,6 @@ func init() {
 	rootCmd.AddCommand(webCmd)
 	webCmd.Flags().IntP("port", "p", 8123, "Port to run the web server on")
 	webCmd.Flags().BoolP("open", "o", false, "Open the web interface in the default browser")
+	webCmd.Flags().Bool("allow-push", false, "Accept pushes from remote clients")
+	webCmd.Flags().Bool("allow-edits", false, "Accept issue changes from other machines")
 }
@@ -0,0 +1,321 @@
+package changelog
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/stanlocht/snap/pkg/issue"
+	"github.com/stanlocht/snap/pkg/repository"
+	"github.com/stanlocht/snap/pkg/snapmoji"
+	"github.com/stanlocht/snap/pkg/user"
+)
+
+// Changelog describes the commits between two revisions, grouped by the category of their snapmoji
+type Changelog struct {
+	From         string          `json:"from,omitempty"` // Revision the changes are since, "" for the start of history
+	To           string          `json:"to"`
+	Version      string          `json:"version,omitempty"` // Tag of the last commit, if any
+	Date         time.Time       `json:"date"`              // Of the last commit
+	Bump         snapmoji.Semver `json:"bump"`              // Largest semver impact of the changes
+	Sections     []Section       `json:"sections"`
+	Contributors []Contributor   `json:"contributors"`
+	Issues       []IssueRef      `json:"issues"`
+}
+
+// Section holds the changes of one or more snapmoji categories
+type Section struct {
+	Title   string  `json:"title"`
+	Entries []Entry `json:"entries"`
+}
+
+// Entry is a commit in a changelog
+type Entry struct {
+	Commit   string `json:"commit"`
+	Emoji    string `json:"emoji,omitempty"`
+	Category string `json:"category,omitempty"`
+	Subject  string `json:"subject"` // First line of the message, without the snapmoji
+	Author   string `json:"author"`
+	Issues   []int  `json:"issues,omitempty"`
+}
+
+// Contributor is an author of the changes, by canonical identity
+type Contributor struct {
+	Name    string `json:"name"`
+	Commits int    `json:"commits"`
+}
+
+// IssueRef is an issue the changes reference
+type IssueRef struct {
+	ID     int    `json:"id"`
+	Title  string `json:"title,omitempty"` // "" for issues that don't exist here
+	Closed bool   `json:"closed"`          // Closed by one of the changes
+}
+
+// sections orders the categories of the default snapmojis into sections.
+// Custom categories get a section of their own, and the rest end up under
+// "Other changes".
+var sections = []struct {
+	Title      string
+	Categories []string
+}{
+	{"Features", []string{"feature"}},
+	{"Fixes", []string{"fix"}},
+	{"Performance", []string{"performance"}},
+	{"Security", []string{"security"}},
+	{"Refactoring", []string{"refactor"}},
+	{"Documentation", []string{"docs"}},
+	{"UI", []string{"ui"}},
+	{"Tests", []string{"test"}},
+	{"Removals", []string{"removal"}},
+	{"Dependencies", []string{"dependencies"}},
+}
+
+// otherCategories are the categories of the default snapmojis listed under "Other changes"
+var otherCategories = []string{"config", "ci", "deploy", "move", "release", "wip", ""}
+
+// OtherTitle is the title of the section for changes without a section of their own
+const OtherTitle = "Other changes"
+
+// semverOrder ranks semver impacts from smallest to largest
+var semverOrder = map[snapmoji.Semver]int{"": 0, snapmoji.SemverNone: 0, snapmoji.SemverPatch: 1, snapmoji.SemverMinor: 2, snapmoji.SemverMajor: 3}
+
+// Generate builds the changelog of the commits reachable from to but not from
+// from, as in from..to. Merge commits are left out. An empty from starts at
+// the beginning of history, and an empty to is HEAD.
+func Generate(repo *repository.Repository, from, to string) (*Changelog, error) {
+	if to == "" {
+		to = "HEAD"
+	}
+	toID, err := repo.ResolveRevision(to)
+	if err != nil {
+		return nil, err
+	}
+	excluded := make(map[string]bool)
+	if from != "" {
+		fromID, err := repo.ResolveRevision(from)
+		if err != nil {
+			return nil, err
+		}
+		ids, err := repo.GetCommitHistoryIDs(fromID, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get commit history: %w", err)
+		}
+		for _, id := range ids {
+			excluded[id] = true
+		}
+	}
+
+	registry, err := snapmoji.Load(repo.Path)
+	if err != nil {
+		return nil, err
+	}
+	mailmap, err := user.NewUserManager(repo.Path).Mailmap()
+	if err != nil {
+		return nil, err
+	}
+
+	changelog := &Changelog{From: from, To: to, Bump: snapmoji.SemverNone, Sections: []Section{}, Contributors: []Contributor{}, Issues: []IssueRef{}}
+	if changelog.Version, err = TagOf(repo, toID); err != nil {
+		return nil, err
+	}
+
+	// Collect the commits in the range, newest first
+	var entries []Entry
+	commits := make(map[string]int)
+	closed := make(map[int]bool)
+	var issueIDs []int
+	err = repo.WalkCommits(toID, func(commit *repository.Commit) bool {
+		if excluded[commit.ID] {
+			return true
+		}
+		if changelog.Date.IsZero() {
+			changelog.Date = commit.Timestamp
+		}
+		if commit.MergeParentID != "" {
+			return true
+		}
+
+		subject, _, _ := strings.Cut(commit.Message, "\n")
+		entry := Entry{Commit: commit.ID, Subject: strings.TrimSpace(subject), Author: mailmap.Resolve(commit.Author, commit.Email).Name}
+		if s, ok := registry.Match(subject); ok {
+			entry.Emoji, entry.Category = s.Emoji, s.Category
+			entry.Subject = registry.Strip(entry.Subject)
+			if semverOrder[s.Semver] > semverOrder[changelog.Bump] {
+				changelog.Bump = s.Semver
+			}
+		}
+		for _, ref := range issue.ParseReferences(commit.Message) {
+			entry.Issues = append(entry.Issues, ref.ID)
+			if _, ok := closed[ref.ID]; !ok {
+				issueIDs = append(issueIDs, ref.ID)
+			}
+			closed[ref.ID] = closed[ref.ID] || ref.Closes
+		}
+		entries = append(entries, entry)
+		commits[entry.Author]++
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk commit history: %w", err)
+	}
+
+	// Group them into sections
+	byTitle := make(map[string]*Section)
+	var custom []string
+	for _, entry := range entries {
+		title := sectionTitle(entry.Category)
+		if byTitle[title] == nil {
+			byTitle[title] = &Section{Title: title}
+			if !isKnownTitle(title) {
+				custom = append(custom, title)
+			}
+		}
+		byTitle[title].Entries = append(byTitle[title].Entries, entry)
+	}
+	sort.Strings(custom)
+	var titles []string
+	for _, section := range sections {
+		titles = append(titles, section.Title)
+	}
+	titles = append(append(titles, custom...), OtherTitle)
+	for _, title := range titles {
+		if section := byTitle[title]; section != nil {
+			changelog.Sections = append(changelog.Sections, *section)
+		}
+	}
+
+	// Contributors, those with the most commits first
+	for name, count := range commits {
+		changelog.Contributors = append(changelog.Contributors, Contributor{Name: name, Commits: count})
+	}
+	sort.Slice(changelog.Contributors, func(i, j int) bool {
+		a, b := changelog.Contributors[i], changelog.Contributors[j]
+		if a.Commits != b.Commits {
+			return a.Commits > b.Commits
+		}
+		return a.Name < b.Name
+	})
+
+	// Referenced issues, in order of ID
+	issueManager := issue.NewIssueManager(repo.Path)
+	sort.Ints(issueIDs)
+	for _, id := range issueIDs {
+		ref := IssueRef{ID: id, Closed: closed[id]}
+		if i, err := issueManager.GetIssue(id); err == nil {
+			ref.Title = i.Title
+		}
+		changelog.Issues = append(changelog.Issues, ref)
+	}
+	return changelog, nil
+}
+
+// sectionTitle returns the title of the section for a snapmoji category
+func sectionTitle(category string) string {
+	for _, section := range sections {
+		for _, c := range section.Categories {
+			if c == category {
+				return section.Title
+			}
+		}
+	}
+	for _, c := range otherCategories {
+		if c == category {
+			return OtherTitle
+		}
+	}
+	title := strings.ReplaceAll(category, "-", " ")
+	title = strings.ReplaceAll(title, "_", " ")
+	return strings.ToUpper(title[:1]) + title[1:]
+}
+
+// isKnownTitle reports whether a section title is one of the default snapmojis'
+func isKnownTitle(title string) bool {
+	if title == OtherTitle {
+		return true
+	}
+	for _, section := range sections {
+		if section.Title == title {
+			return true
+		}
+	}
+	return false
+}
+
+// TagOf returns the name of a tag of a commit, the first by name if there are several, or ""
+func TagOf(repo *repository.Repository, commitID string) (string, error) {
+	tags, err := repo.ListTags()
+	if err != nil {
+		return "", err
+	}
+	for _, tag := range tags {
+		if tag.CommitID == commitID {
+			return tag.Name, nil
+		}
+	}
+	return "", nil
+}
+
+// Release is a tag and the tag before it in history, whose changes its release notes list
+type Release struct {
+	Tag      string    `json:"tag"`
+	Previous string    `json:"previous,omitempty"` // "" for the first release
+	Date     time.Time `json:"date"`               // Of the tagged commit
+}
+
+// Releases returns the releases of the repository, one per tag, newest first
+func Releases(repo *repository.Repository) ([]Release, error) {
+	tags, err := repo.ListTags()
+	if err != nil {
+		return nil, err
+	}
+	var releases []Release
+	for _, tag := range tags {
+		commit, err := repo.GetCommit(tag.CommitID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get commit of tag %s: %w", tag.Name, err)
+		}
+		previous, err := PreviousTag(repo, tag.CommitID)
+		if err != nil {
+			return nil, err
+		}
+		releases = append(releases, Release{Tag: tag.Name, Previous: previous, Date: commit.Timestamp})
+	}
+	sort.SliceStable(releases, func(i, j int) bool { return releases[i].Date.After(releases[j].Date) })
+	return releases, nil
+}
+
+// PreviousTag returns the closest tag before a commit in its history, or ""
+// if there is none
+func PreviousTag(repo *repository.Repository, commitID string) (string, error) {
+	tags, err := repo.ListTags()
+	if err != nil {
+		return "", err
+	}
+	previous, previousID := "", ""
+	for _, tag := range tags {
+		if tag.CommitID == commitID || tag.CommitID == previousID {
+			continue
+		}
+		ancestor, err := repo.IsAncestor(tag.CommitID, commitID)
+		if err != nil {
+			return "", fmt.Errorf("failed to compare tag %s: %w", tag.Name, err)
+		}
+		if !ancestor {
+			continue
+		}
+		// Of two tags in the history, the one after the other is closer
+		if previousID != "" {
+			closer, err := repo.IsAncestor(previousID, tag.CommitID)
+			if err != nil {
+				return "", fmt.Errorf("failed to compare tag %s: %w", tag.Name, err)
+			}
+			if !closer {
+				continue
+			}
+		}
+		previous, previousID = tag.Name, tag.CommitID
+	}
+	return previous, nil
+}
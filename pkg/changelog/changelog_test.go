@@ -0,0 +1,135 @@
+package changelog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stanlocht/snap/pkg/issue"
+	"github.com/stanlocht/snap/pkg/repository"
+	"github.com/stanlocht/snap/pkg/snapmoji"
+)
+
+func TestGenerate(t *testing.T) {
+	repo, err := repository.Init(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to initialize repository: %v", err)
+	}
+	if _, err := issue.NewIssueManager(repo.Path).CreateIssue("Crash on start", "", "alice"); err != nil {
+		t.Fatalf("Failed to create issue: %v", err)
+	}
+	mailmap := "Alice <alice@example.com> al\n"
+	if err := os.WriteFile(filepath.Join(repo.Path, ".snapmailmap"), []byte(mailmap), 0644); err != nil {
+		t.Fatalf("Failed to write mailmap: %v", err)
+	}
+
+	ids := make(map[string]string)
+	for i, c := range []struct{ message, author string }{
+		{"🎉 Initial commit", "alice"},
+		{"✨ Add dark mode", "alice"},
+		{"🐛 Fix crash on start\n\nFixes #1", "bob"},
+		{"⚡️ Speed up the log (#7)", "al"},
+		{"🔧 Tweak config", "bob"},
+		{"Update readme", "carol"},
+	} {
+		commit, err := repo.CreateCommit(c.message, c.author, "", &repository.Tree{Entries: map[string]string{"a.txt": string(rune('a' + i))}})
+		if err != nil {
+			t.Fatalf("Failed to create commit: %v", err)
+		}
+		ids[c.message] = commit.ID
+	}
+	if err := repo.CreateTag("v1.0.0", ids["✨ Add dark mode"]); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+	if err := repo.CreateTag("v1.1.0", ids["🔧 Tweak config"]); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+
+	changelog, err := Generate(repo, "v1.0.0", "v1.1.0")
+	if err != nil {
+		t.Fatalf("Failed to generate changelog: %v", err)
+	}
+	var titles []string
+	for _, section := range changelog.Sections {
+		titles = append(titles, section.Title)
+	}
+	if got := strings.Join(titles, ","); got != "Fixes,Performance,"+OtherTitle {
+		t.Errorf("Expected sections Fixes, Performance and Other changes, got %s", got)
+	}
+	if changelog.Version != "v1.1.0" || changelog.Bump != snapmoji.SemverPatch {
+		t.Errorf("Expected version v1.1.0 with a patch bump, got %s with %s", changelog.Version, changelog.Bump)
+	}
+	if fix := changelog.Sections[0].Entries[0]; fix.Subject != "Fix crash on start" || fix.Emoji != "🐛" || len(fix.Issues) != 1 {
+		t.Errorf("Unexpected fix entry %+v", fix)
+	}
+	if len(changelog.Contributors) != 2 || changelog.Contributors[0] != (Contributor{Name: "bob", Commits: 2}) || changelog.Contributors[1].Name != "Alice" {
+		t.Errorf("Expected bob and Alice as contributors, got %v", changelog.Contributors)
+	}
+	expectedIssues := []IssueRef{{ID: 1, Title: "Crash on start", Closed: true}, {ID: 7}}
+	if len(changelog.Issues) != 2 || changelog.Issues[0] != expectedIssues[0] || changelog.Issues[1] != expectedIssues[1] {
+		t.Errorf("Expected issues %v, got %v", expectedIssues, changelog.Issues)
+	}
+
+	// From the start of history, new features make a minor bump
+	changelog, err = Generate(repo, "", "v1.0.0")
+	if err != nil {
+		t.Fatalf("Failed to generate changelog: %v", err)
+	}
+	if changelog.Bump != snapmoji.SemverMinor || len(changelog.Sections) != 2 {
+		t.Errorf("Expected features and other changes with a minor bump, got %+v", changelog)
+	}
+
+	// Formats
+	markdown := changelog.Markdown("https://example.com/issues/%d")
+	if !strings.Contains(markdown, "## v1.0.0") || !strings.Contains(markdown, "### Features\n\n- ✨ Add dark mode") {
+		t.Errorf("Unexpected Markdown:\n%s", markdown)
+	}
+	changelog, _ = Generate(repo, "v1.1.0", "")
+	keep := changelog.KeepAChangelog("")
+	if !strings.Contains(keep, "## [Unreleased]") || !strings.Contains(keep, "### Changed\n\n- Update readme") {
+		t.Errorf("Unexpected Keep a Changelog:\n%s", keep)
+	}
+	changelog, _ = Generate(repo, "v1.0.0", "v1.1.0")
+	if keep := changelog.KeepAChangelog("https://example.com/issues/%d"); !strings.Contains(keep, "### Fixed\n\n- Fix crash on start ([#1](https://example.com/issues/1))") {
+		t.Errorf("Unexpected Keep a Changelog:\n%s", keep)
+	}
+	if markdown := changelog.Markdown("https://example.com/issues/%d"); !strings.Contains(markdown, "- ⚡️ Speed up the log ([#7](https://example.com/issues/7)) – Alice") {
+		t.Errorf("Expected issue references in subjects to be linked once:\n%s", markdown)
+	}
+
+	if _, err := Generate(repo, "v9.9.9", ""); err == nil {
+		t.Errorf("Expected an unknown revision to be rejected")
+	}
+}
+
+func TestReleases(t *testing.T) {
+	repo, err := repository.Init(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to initialize repository: %v", err)
+	}
+	var ids []string
+	for i := 0; i < 3; i++ {
+		commit, err := repo.CreateCommit("✨ Change", "alice", "", &repository.Tree{Entries: map[string]string{"a.txt": string(rune('a' + i))}})
+		if err != nil {
+			t.Fatalf("Failed to create commit: %v", err)
+		}
+		ids = append(ids, commit.ID)
+	}
+	// Created out of order, so neither names nor creation order give history
+	for name, id := range map[string]string{"v0.2": ids[2], "v0.10": ids[1], "v0.1": ids[0]} {
+		if err := repo.CreateTag(name, id); err != nil {
+			t.Fatalf("Failed to create tag: %v", err)
+		}
+	}
+
+	for commitID, expected := range map[string]string{ids[2]: "v0.10", ids[1]: "v0.1", ids[0]: ""} {
+		if got, err := PreviousTag(repo, commitID); err != nil || got != expected {
+			t.Errorf("PreviousTag(%s) = %q, %v, want %q", commitID[:7], got, err, expected)
+		}
+	}
+	releases, err := Releases(repo)
+	if err != nil || len(releases) != 3 {
+		t.Fatalf("Expected 3 releases, got %v (%v)", releases, err)
+	}
+}
@@ -0,0 +1,175 @@
+package changelog
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Formats lists the formats a changelog can be written in
+var Formats = []string{"markdown", "json", "keepachangelog"}
+
+// keepAChangelogTypes maps snapmoji categories to the types of change of
+// Keep a Changelog; the rest are "Changed"
+var keepAChangelogTypes = map[string]string{
+	"feature":  "Added",
+	"fix":      "Fixed",
+	"security": "Security",
+	"removal":  "Removed",
+}
+
+// keepAChangelogOrder orders the types of change of Keep a Changelog
+var keepAChangelogOrder = []string{"Added", "Changed", "Deprecated", "Removed", "Fixed", "Security"}
+
+// Title returns the heading of a changelog: its version, or the range of its changes
+func (c *Changelog) Title() string {
+	switch {
+	case c.Version != "":
+		return c.Version
+	case c.From != "":
+		return c.From + ".." + c.To
+	}
+	return "Unreleased"
+}
+
+// issueLinks formats issue references, as links if an issue URL such as
+// "https://example.com/issue/%d" is given
+func issueLinks(ids []int, issueURL string) string {
+	links := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if issueURL != "" {
+			links = append(links, fmt.Sprintf("[#%d](%s)", id, fmt.Sprintf(issueURL, id)))
+		} else {
+			links = append(links, fmt.Sprintf("#%d", id))
+		}
+	}
+	return strings.Join(links, ", ")
+}
+
+// subjectReference matches issue references in a subject
+var subjectReference = regexp.MustCompile(`#(\d+)\b`)
+
+// UnmentionedIssues returns the issues an entry references in the body of its
+// message but not in its subject
+func (e Entry) UnmentionedIssues() []int {
+	mentioned := make(map[int]bool)
+	for _, match := range subjectReference.FindAllStringSubmatch(e.Subject, -1) {
+		if id, err := strconv.Atoi(match[1]); err == nil {
+			mentioned[id] = true
+		}
+	}
+	var ids []int
+	for _, id := range e.Issues {
+		if !mentioned[id] {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// entryLine formats an entry as a Markdown list item
+func entryLine(entry Entry, issueURL string, emoji bool) string {
+	line := "- "
+	if emoji && entry.Emoji != "" {
+		line += entry.Emoji + " "
+	}
+	subject := entry.Subject
+	if issueURL != "" {
+		subject = subjectReference.ReplaceAllStringFunc(subject, func(ref string) string {
+			id, _ := strconv.Atoi(ref[1:])
+			return issueLinks([]int{id}, issueURL)
+		})
+	}
+	line += subject
+	if ids := entry.UnmentionedIssues(); len(ids) > 0 {
+		line += " (" + issueLinks(ids, issueURL) + ")"
+	}
+	return line + fmt.Sprintf(" – %s (%s)\n", entry.Author, entry.Commit[:7])
+}
+
+// Markdown formats a changelog as release notes, with sections by category,
+// the contributors and the referenced issues
+func (c *Changelog) Markdown(issueURL string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## %s", c.Title())
+	if !c.Date.IsZero() {
+		fmt.Fprintf(&b, " (%s)", c.Date.Format("2006-01-02"))
+	}
+	b.WriteString("\n\n")
+	if len(c.Sections) == 0 {
+		b.WriteString("No changes.\n")
+		return b.String()
+	}
+	if c.From != "" {
+		fmt.Fprintf(&b, "Changes since %s. ", c.From)
+	}
+	fmt.Fprintf(&b, "Semver impact: %s.\n", c.Bump)
+
+	for _, section := range c.Sections {
+		fmt.Fprintf(&b, "\n### %s\n\n", section.Title)
+		for _, entry := range section.Entries {
+			b.WriteString(entryLine(entry, issueURL, true))
+		}
+	}
+
+	b.WriteString("\n### Contributors\n\n")
+	for _, contributor := range c.Contributors {
+		fmt.Fprintf(&b, "- %s (%d %s)\n", contributor.Name, contributor.Commits, plural(contributor.Commits, "commit", "commits"))
+	}
+
+	if len(c.Issues) > 0 {
+		b.WriteString("\n### Issues\n\n")
+		for _, ref := range c.Issues {
+			line := "- " + issueLinks([]int{ref.ID}, issueURL)
+			if ref.Title != "" {
+				line += " " + ref.Title
+			}
+			if ref.Closed {
+				line += " (closed)"
+			}
+			b.WriteString(line + "\n")
+		}
+	}
+	return b.String()
+}
+
+// KeepAChangelog formats a changelog in the format of keepachangelog.com
+func (c *Changelog) KeepAChangelog(issueURL string) string {
+	var b strings.Builder
+	b.WriteString("# Changelog\n\n")
+	if c.Version != "" {
+		fmt.Fprintf(&b, "## [%s] - %s\n", c.Version, c.Date.Format("2006-01-02"))
+	} else {
+		b.WriteString("## [Unreleased]\n")
+	}
+
+	byType := make(map[string][]Entry)
+	for _, section := range c.Sections {
+		for _, entry := range section.Entries {
+			changeType, ok := keepAChangelogTypes[entry.Category]
+			if !ok {
+				changeType = "Changed"
+			}
+			byType[changeType] = append(byType[changeType], entry)
+		}
+	}
+	for _, changeType := range keepAChangelogOrder {
+		if len(byType[changeType]) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n### %s\n\n", changeType)
+		for _, entry := range byType[changeType] {
+			b.WriteString(entryLine(entry, issueURL, false))
+		}
+	}
+	return b.String()
+}
+
+// plural returns the singular or plural of a word for a count
+func plural(count int, singular, plural string) string {
+	if count == 1 {
+		return singular
+	}
+	return plural
+}
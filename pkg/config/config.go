@@ -7,6 +7,49 @@ import (
 	"strings"
 )
 
+// parseKey splits a key into section, optional subsection and name.
+// "user.name" has no subsection; "remote.origin.url" has subsection "origin".
+func parseKey(key string) (string, string, string, error) {
+	parts := strings.Split(key, ".")
+	if len(parts) < 2 {
+		return "", "", "", fmt.Errorf("invalid key format, expected 'section.name' or 'section.subsection.name'")
+	}
+
+	section := parts[0]
+	name := parts[len(parts)-1]
+	subsection := strings.Join(parts[1:len(parts)-1], ".")
+	if section == "" || name == "" {
+		return "", "", "", fmt.Errorf("invalid key format, expected 'section.name' or 'section.subsection.name'")
+	}
+
+	return section, subsection, name, nil
+}
+
+// parseSectionHeader parses a section header line such as [core] or [remote "origin"].
+// It returns false if the line is not a section header.
+func parseSectionHeader(line string) (string, string, bool) {
+	if !strings.HasPrefix(line, "[") || !strings.HasSuffix(line, "]") {
+		return "", "", false
+	}
+
+	header := strings.TrimSpace(line[1 : len(line)-1])
+	section, rest, found := strings.Cut(header, " ")
+	if !found {
+		return header, "", true
+	}
+
+	subsection := strings.Trim(strings.TrimSpace(rest), `"`)
+	return section, subsection, true
+}
+
+// formatSectionHeader formats a section header for the given section and subsection
+func formatSectionHeader(section, subsection string) string {
+	if subsection == "" {
+		return fmt.Sprintf("[%s]", section)
+	}
+	return fmt.Sprintf("[%s \"%s\"]", section, subsection)
+}
+
 // GetValue gets a configuration value from the config file
 func GetValue(configPath, key string) (string, error) {
 	// Read config file
@@ -16,13 +59,11 @@ func GetValue(configPath, key string) (string, error) {
 	}
 	defer file.Close()
 
-	// Parse key into section and name
-	parts := strings.Split(key, ".")
-	if len(parts) != 2 {
-		return "", fmt.Errorf("invalid key format, expected 'section.name'")
+	// Parse key into section, subsection and name
+	section, subsection, name, err := parseKey(key)
+	if err != nil {
+		return "", err
 	}
-	section := parts[0]
-	name := parts[1]
 
 	// Read config file line by line
 	scanner := bufio.NewScanner(file)
@@ -31,9 +72,8 @@ func GetValue(configPath, key string) (string, error) {
 		line := strings.TrimSpace(scanner.Text())
 
 		// Check if we're entering a section
-		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
-			sectionName := line[1 : len(line)-1]
-			inSection = (sectionName == section)
+		if sectionName, subsectionName, ok := parseSectionHeader(line); ok {
+			inSection = (sectionName == section && subsectionName == subsection)
 			continue
 		}
 
@@ -44,14 +84,6 @@ func GetValue(configPath, key string) (string, error) {
 				return strings.TrimSpace(parts[1]), nil
 			}
 		}
-
-		// If we're in the right section and it's a tab-indented line
-		if inSection && strings.HasPrefix(line, "\t") {
-			parts := strings.SplitN(line, "=", 2)
-			if len(parts) == 2 && strings.TrimSpace(parts[0]) == name {
-				return strings.TrimSpace(parts[1]), nil
-			}
-		}
 	}
 
 	if err := scanner.Err(); err != nil {
@@ -63,13 +95,11 @@ func GetValue(configPath, key string) (string, error) {
 
 // SetValue sets a configuration value in the config file
 func SetValue(configPath, key, value string) error {
-	// Parse key into section and name
-	parts := strings.Split(key, ".")
-	if len(parts) != 2 {
-		return fmt.Errorf("invalid key format, expected 'section.name'")
+	// Parse key into section, subsection and name
+	section, subsection, name, err := parseKey(key)
+	if err != nil {
+		return err
 	}
-	section := parts[0]
-	name := parts[1]
 
 	// Read config file
 	content, err := os.ReadFile(configPath)
@@ -82,14 +112,15 @@ func SetValue(configPath, key, value string) error {
 
 	// Find section and update value
 	inSection := false
+	sectionFound := false
 	updated := false
 	for i, line := range lines {
 		trimmedLine := strings.TrimSpace(line)
 
 		// Check if we're entering a section
-		if strings.HasPrefix(trimmedLine, "[") && strings.HasSuffix(trimmedLine, "]") {
-			sectionName := trimmedLine[1 : len(trimmedLine)-1]
-			inSection = (sectionName == section)
+		if sectionName, subsectionName, ok := parseSectionHeader(trimmedLine); ok {
+			inSection = (sectionName == section && subsectionName == subsection)
+			sectionFound = sectionFound || inSection
 			continue
 		}
 
@@ -102,38 +133,31 @@ func SetValue(configPath, key, value string) error {
 				break
 			}
 		}
-
-		// If we're in the right section and it's a tab-indented line
-		if inSection && strings.HasPrefix(line, "\t") {
-			parts := strings.SplitN(line, "=", 2)
-			if len(parts) == 2 && strings.TrimSpace(parts[0]) == name {
-				lines[i] = fmt.Sprintf("\t%s = %s", name, value)
-				updated = true
-				break
-			}
-		}
 	}
 
 	// If we didn't update an existing value, add it to the section
-	if !updated {
+	if !updated && sectionFound {
 		// Find the section again
 		newLines := []string{}
-		inSection = false
 		for _, line := range lines {
 			newLines = append(newLines, line)
 			trimmedLine := strings.TrimSpace(line)
 
 			// Check if we're entering the section
-			if strings.HasPrefix(trimmedLine, "[") && strings.HasSuffix(trimmedLine, "]") {
-				sectionName := trimmedLine[1 : len(trimmedLine)-1]
-				if sectionName == section {
-					inSection = true
+			if sectionName, subsectionName, ok := parseSectionHeader(trimmedLine); ok {
+				if sectionName == section && subsectionName == subsection {
 					// Add the new value after the section header
 					newLines = append(newLines, fmt.Sprintf("\t%s = %s", name, value))
 				}
 			}
 		}
 		lines = newLines
+	} else if !updated {
+		// The section doesn't exist yet, so append it to the end of the file
+		for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+			lines = lines[:len(lines)-1]
+		}
+		lines = append(lines, formatSectionHeader(section, subsection), fmt.Sprintf("\t%s = %s", name, value), "")
 	}
 
 	// Write updated content back to file
@@ -144,3 +168,67 @@ func SetValue(configPath, key, value string) error {
 
 	return nil
 }
+
+// GetSubsections returns the names of all subsections of a section,
+// e.g. the remote names for the "remote" section
+func GetSubsections(configPath, section string) ([]string, error) {
+	// Read config file
+	file, err := os.Open(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open config file: %w", err)
+	}
+	defer file.Close()
+
+	// Collect subsection names in file order
+	var subsections []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if sectionName, subsectionName, ok := parseSectionHeader(line); ok {
+			if sectionName == section && subsectionName != "" {
+				subsections = append(subsections, subsectionName)
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading config file: %w", err)
+	}
+
+	return subsections, nil
+}
+
+// RemoveSection removes a section and all of its values from the config file
+func RemoveSection(configPath, section, subsection string) error {
+	// Read config file
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	// Copy every line except those belonging to the section
+	lines := strings.Split(string(content), "\n")
+	newLines := []string{}
+	inSection := false
+	found := false
+	for _, line := range lines {
+		if sectionName, subsectionName, ok := parseSectionHeader(strings.TrimSpace(line)); ok {
+			inSection = (sectionName == section && subsectionName == subsection)
+			found = found || inSection
+		}
+		if !inSection {
+			newLines = append(newLines, line)
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("no such section: %s", formatSectionHeader(section, subsection))
+	}
+
+	// Write updated content back to file
+	if err := os.WriteFile(configPath, []byte(strings.Join(newLines, "\n")), 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTestConfig writes a config file into a temporary directory
+func writeTestConfig(t *testing.T, content string) string {
+	t.Helper()
+
+	configPath := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	return configPath
+}
+
+func TestGetAndSetValue(t *testing.T) {
+	configPath := writeTestConfig(t, "[core]\n\tfilemode = true\n[user]\n\tname = \n\temail = \n")
+
+	// Set a value in an existing section
+	if err := SetValue(configPath, "user.name", "alice"); err != nil {
+		t.Fatalf("Failed to set value: %v", err)
+	}
+
+	value, err := GetValue(configPath, "user.name")
+	if err != nil {
+		t.Fatalf("Failed to get value: %v", err)
+	}
+	if value != "alice" {
+		t.Errorf("Expected user.name to be 'alice', got '%s'", value)
+	}
+
+	// Set a value in a section that doesn't exist yet
+	if err := SetValue(configPath, "web.port", "9000"); err != nil {
+		t.Fatalf("Failed to set value: %v", err)
+	}
+
+	value, err = GetValue(configPath, "web.port")
+	if err != nil {
+		t.Fatalf("Failed to get value: %v", err)
+	}
+	if value != "9000" {
+		t.Errorf("Expected web.port to be '9000', got '%s'", value)
+	}
+
+	// Invalid keys are rejected
+	if _, err := GetValue(configPath, "nosection"); err == nil {
+		t.Errorf("Expected an error for a key without a section")
+	}
+}
+
+func TestSubsections(t *testing.T) {
+	configPath := writeTestConfig(t, "[core]\n\tfilemode = true\n")
+
+	// Add two remotes
+	if err := SetValue(configPath, "remote.origin.url", "/srv/snap/project"); err != nil {
+		t.Fatalf("Failed to set value: %v", err)
+	}
+	if err := SetValue(configPath, "remote.backup.url", "/mnt/backup/project"); err != nil {
+		t.Fatalf("Failed to set value: %v", err)
+	}
+
+	// Check the file uses quoted subsection headers
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("Failed to read config file: %v", err)
+	}
+	if !strings.Contains(string(content), "[remote \"origin\"]") {
+		t.Errorf("Expected config to contain [remote \"origin\"], got:\n%s", content)
+	}
+
+	// Values are scoped to their subsection
+	value, err := GetValue(configPath, "remote.backup.url")
+	if err != nil {
+		t.Fatalf("Failed to get value: %v", err)
+	}
+	if value != "/mnt/backup/project" {
+		t.Errorf("Expected remote.backup.url to be '/mnt/backup/project', got '%s'", value)
+	}
+
+	// List subsections
+	names, err := GetSubsections(configPath, "remote")
+	if err != nil {
+		t.Fatalf("Failed to get subsections: %v", err)
+	}
+	if len(names) != 2 || names[0] != "origin" || names[1] != "backup" {
+		t.Errorf("Expected subsections [origin backup], got %v", names)
+	}
+
+	// Remove a subsection
+	if err := RemoveSection(configPath, "remote", "origin"); err != nil {
+		t.Fatalf("Failed to remove section: %v", err)
+	}
+	value, err = GetValue(configPath, "remote.origin.url")
+	if err != nil {
+		t.Fatalf("Failed to get value: %v", err)
+	}
+	if value != "" {
+		t.Errorf("Expected remote.origin.url to be removed, got '%s'", value)
+	}
+	value, _ = GetValue(configPath, "core.filemode")
+	if value != "true" {
+		t.Errorf("Expected core.filemode to be kept, got '%s'", value)
+	}
+}
@@ -0,0 +1,243 @@
+package issue
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Comment represents a comment on an issue. Bodies are markdown.
+type Comment struct {
+	ID        string        `json:"id"`
+	Author    string        `json:"author"`
+	Body      string        `json:"body"`
+	CreatedAt time.Time     `json:"created_at"`
+	ReplyTo   string        `json:"reply_to,omitempty"` // ID of the comment this one answers
+	Edits     []CommentEdit `json:"edits,omitempty"`    // Earlier versions, oldest first
+	Imported  bool          `json:"imported,omitempty"` // Copied from another tracker, so it earned no points here
+}
+
+// CommentEdit records the body a comment had before it was edited
+type CommentEdit struct {
+	Body     string    `json:"body"`
+	EditedAt time.Time `json:"edited_at"`
+}
+
+// ThreadedComment is a comment with its depth in the discussion
+type ThreadedComment struct {
+	*Comment
+	Depth int
+}
+
+// Edited reports whether a comment was changed after it was posted
+func (c *Comment) Edited() bool {
+	return len(c.Edits) > 0
+}
+
+// LastModified returns when a comment was posted or last edited
+func (c *Comment) LastModified() time.Time {
+	if len(c.Edits) > 0 {
+		return c.Edits[len(c.Edits)-1].EditedAt
+	}
+	return c.CreatedAt
+}
+
+// FindComment finds a comment by ID or ID prefix
+func (i *Issue) FindComment(id string) (*Comment, error) {
+	var match *Comment
+	for j := range i.Comments {
+		if !strings.HasPrefix(i.Comments[j].ID, id) || id == "" {
+			continue
+		}
+		if match != nil {
+			return nil, fmt.Errorf("comment ID %s is ambiguous", id)
+		}
+		match = &i.Comments[j]
+	}
+	if match == nil {
+		return nil, fmt.Errorf("comment %s not found on issue #%d", id, i.ID)
+	}
+	return match, nil
+}
+
+// CommentThread returns the comments in discussion order: each comment is
+// followed by its replies. Replies to missing comments are shown at the top level.
+func (i *Issue) CommentThread() []ThreadedComment {
+	known := make(map[string]bool)
+	for _, comment := range i.Comments {
+		known[comment.ID] = true
+	}
+
+	children := make(map[string][]*Comment)
+	for j := range i.Comments {
+		comment := &i.Comments[j]
+		parent := comment.ReplyTo
+		if !known[parent] || parent == comment.ID {
+			parent = ""
+		}
+		children[parent] = append(children[parent], comment)
+	}
+
+	var thread []ThreadedComment
+	visited := make(map[string]bool)
+	var visit func(parent string, depth int)
+	visit = func(parent string, depth int) {
+		for _, comment := range children[parent] {
+			if visited[comment.ID] {
+				continue
+			}
+			visited[comment.ID] = true
+			thread = append(thread, ThreadedComment{Comment: comment, Depth: depth})
+			visit(comment.ID, depth+1)
+		}
+	}
+	visit("", 0)
+
+	return thread
+}
+
+// AddComment adds a comment to an issue. replyTo may name the comment being answered.
+func (im *IssueManager) AddComment(id int, author, body, replyTo string) (*Comment, error) {
+	if strings.TrimSpace(body) == "" {
+		return nil, fmt.Errorf("comment body is empty")
+	}
+
+	issue, err := im.GetIssue(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if replyTo != "" {
+		parent, err := issue.FindComment(replyTo)
+		if err != nil {
+			return nil, err
+		}
+		replyTo = parent.ID
+	}
+
+	uid, err := newUID()
+	if err != nil {
+		return nil, err
+	}
+	comment := Comment{
+		ID:        uid[:8],
+		Author:    author,
+		Body:      body,
+		CreatedAt: time.Now(),
+		ReplyTo:   replyTo,
+	}
+	issue.Comments = append(issue.Comments, comment)
+
+	if err := im.saveIssue(issue, author, fmt.Sprintf("💬 Comment on issue #%d", issue.ID)); err != nil {
+		return nil, err
+	}
+
+	return &comment, nil
+}
+
+// EditComment replaces the body of a comment, keeping the old body in its edit history.
+// Only the comment's author may edit it.
+func (im *IssueManager) EditComment(id int, commentID, author, body string) (*Comment, error) {
+	if strings.TrimSpace(body) == "" {
+		return nil, fmt.Errorf("comment body is empty")
+	}
+
+	issue, err := im.GetIssue(id)
+	if err != nil {
+		return nil, err
+	}
+
+	comment, err := issue.FindComment(commentID)
+	if err != nil {
+		return nil, err
+	}
+	if comment.Author != author {
+		return nil, fmt.Errorf("comment %s was written by %s and can only be edited by them", comment.ID, comment.Author)
+	}
+	if comment.Body == body {
+		return comment, nil
+	}
+
+	comment.Edits = append(comment.Edits, CommentEdit{Body: comment.Body, EditedAt: time.Now()})
+	comment.Body = body
+
+	if err := im.saveIssue(issue, author, fmt.Sprintf("💬 Edit comment on issue #%d", issue.ID)); err != nil {
+		return nil, err
+	}
+
+	return comment, nil
+}
+
+// mergeComments merges the comments of two versions of an issue. Comments are
+// never lost: the result holds every comment from either side, and a comment
+// edited on both sides keeps the most recent edit.
+func mergeComments(baseValue, ourValue, theirValue []byte) ([]byte, error) {
+	decode := func(value []byte) (map[string]Comment, error) {
+		comments := make(map[string]Comment)
+		if value == nil {
+			return comments, nil
+		}
+		var list []Comment
+		if err := json.Unmarshal(value, &list); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal comments: %w", err)
+		}
+		for _, comment := range list {
+			comments[comment.ID] = comment
+		}
+		return comments, nil
+	}
+
+	base, err := decode(baseValue)
+	if err != nil {
+		return nil, err
+	}
+	ours, err := decode(ourValue)
+	if err != nil {
+		return nil, err
+	}
+	theirs, err := decode(theirValue)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := make(map[string]Comment)
+	for id, comment := range ours {
+		merged[id] = comment
+	}
+	for id, theirComment := range theirs {
+		ourComment, ok := ours[id]
+		if !ok {
+			merged[id] = theirComment
+			continue
+		}
+
+		ourData, _ := json.Marshal(ourComment)
+		theirData, _ := json.Marshal(theirComment)
+		baseData, _ := json.Marshal(base[id])
+		switch {
+		case bytes.Equal(ourData, baseData):
+			merged[id] = theirComment
+		case bytes.Equal(theirData, baseData):
+			merged[id] = ourComment
+		case theirComment.LastModified().After(ourComment.LastModified()),
+			theirComment.LastModified().Equal(ourComment.LastModified()) && bytes.Compare(theirData, ourData) > 0:
+			merged[id] = theirComment
+		}
+	}
+
+	list := make([]Comment, 0, len(merged))
+	for _, comment := range merged {
+		list = append(list, comment)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].CreatedAt.Equal(list[j].CreatedAt) {
+			return list[i].CreatedAt.Before(list[j].CreatedAt)
+		}
+		return list[i].ID < list[j].ID
+	})
+
+	return json.Marshal(list)
+}
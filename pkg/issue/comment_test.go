@@ -0,0 +1,117 @@
+package issue
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCommentsAndThreads(t *testing.T) {
+	manager := NewIssueManager(t.TempDir())
+
+	created, err := manager.CreateIssue("Flaky test", "", "alice")
+	if err != nil {
+		t.Fatalf("Failed to create issue: %v", err)
+	}
+
+	// Add a comment and a reply
+	first, err := manager.AddComment(created.ID, "bob", "I can reproduce this", "")
+	if err != nil {
+		t.Fatalf("Failed to add comment: %v", err)
+	}
+	reply, err := manager.AddComment(created.ID, "alice", "On which **platform**?", first.ID[:4])
+	if err != nil {
+		t.Fatalf("Failed to add reply: %v", err)
+	}
+	if reply.ReplyTo != first.ID {
+		t.Errorf("Expected reply to point at %s, got '%s'", first.ID, reply.ReplyTo)
+	}
+	second, err := manager.AddComment(created.ID, "carol", "Me too", "")
+	if err != nil {
+		t.Fatalf("Failed to add comment: %v", err)
+	}
+
+	// Empty comments and replies to unknown comments are refused
+	if _, err := manager.AddComment(created.ID, "bob", "  ", ""); err == nil {
+		t.Errorf("Expected an error for an empty comment")
+	}
+	if _, err := manager.AddComment(created.ID, "bob", "Hi", "nope"); err == nil {
+		t.Errorf("Expected an error for a reply to an unknown comment")
+	}
+
+	// Check discussion order and depth
+	issue, err := manager.GetIssue(created.ID)
+	if err != nil {
+		t.Fatalf("Failed to get issue: %v", err)
+	}
+	thread := issue.CommentThread()
+	if len(thread) != 3 {
+		t.Fatalf("Expected 3 comments, got %d", len(thread))
+	}
+	expected := []struct {
+		id    string
+		depth int
+	}{{first.ID, 0}, {reply.ID, 1}, {second.ID, 0}}
+	for i, want := range expected {
+		if thread[i].ID != want.id || thread[i].Depth != want.depth {
+			t.Errorf("Expected comment %s at depth %d in position %d, got %s at depth %d", want.id, want.depth, i, thread[i].ID, thread[i].Depth)
+		}
+	}
+
+	// Only the author can edit, and the old body is kept
+	if _, err := manager.EditComment(created.ID, first.ID, "alice", "Hijacked"); err == nil {
+		t.Errorf("Expected an error when editing someone else's comment")
+	}
+	edited, err := manager.EditComment(created.ID, first.ID, "bob", "I can reproduce this on Linux")
+	if err != nil {
+		t.Fatalf("Failed to edit comment: %v", err)
+	}
+	if !edited.Edited() || edited.Edits[0].Body != "I can reproduce this" {
+		t.Errorf("Expected the original body in the edit history, got %v", edited.Edits)
+	}
+}
+
+func TestMergeIssuesKeepsCommentsFromBothSides(t *testing.T) {
+	manager := NewIssueManager(t.TempDir())
+
+	created, err := manager.CreateIssue("Docs are outdated", "", "alice")
+	if err != nil {
+		t.Fatalf("Failed to create issue: %v", err)
+	}
+	shared, err := manager.AddComment(created.ID, "alice", "Which page?", "")
+	if err != nil {
+		t.Fatalf("Failed to add comment: %v", err)
+	}
+
+	// Both sides comment, and both edit the shared comment
+	theirsID := divergeIssues(t, manager,
+		func() {
+			time.Sleep(10 * time.Millisecond)
+			manager.AddComment(created.ID, "alice", "Ours", "")
+			if _, err := manager.EditComment(created.ID, shared.ID, "alice", "Which page? (newest edit)"); err != nil {
+				t.Fatalf("Failed to edit comment: %v", err)
+			}
+		},
+		func() {
+			manager.AddComment(created.ID, "bob", "Theirs", shared.ID)
+			if _, err := manager.EditComment(created.ID, shared.ID, "alice", "Which page? (older edit)"); err != nil {
+				t.Fatalf("Failed to edit comment: %v", err)
+			}
+		})
+
+	result, err := manager.Merge(theirsID)
+	if err != nil {
+		t.Fatalf("Failed to merge issues: %v", err)
+	}
+	if len(result.Conflicts) != 0 {
+		t.Errorf("Expected comments to merge without conflicts, got %v", result.Conflicts)
+	}
+
+	issue, _ := manager.GetIssue(created.ID)
+	if len(issue.Comments) != 3 {
+		t.Fatalf("Expected 3 comments after merge, got %d", len(issue.Comments))
+	}
+	merged, _ := issue.FindComment(shared.ID)
+	if merged.Body != "Which page? (newest edit)" {
+		t.Errorf("Expected the newest edit to win, got '%s'", merged.Body)
+	}
+}
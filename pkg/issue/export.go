@@ -0,0 +1,265 @@
+package issue
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ExportResult describes what an export did
+type ExportResult struct {
+	Exported int
+	Dropped  map[string]int // Fields the format can't hold, with the number of issues that had them
+}
+
+// githubUser is a user as `gh issue list --json` writes it
+type githubUser struct {
+	Login string `json:"login"`
+}
+
+// githubLabel is a label as `gh issue list --json` writes it
+type githubLabel struct {
+	Name        string `json:"name"`
+	Color       string `json:"color"` // Without the leading #
+	Description string `json:"description,omitempty"`
+}
+
+// githubMilestone is a milestone as `gh issue list --json` writes it
+type githubMilestone struct {
+	Title       string     `json:"title"`
+	Description string     `json:"description,omitempty"`
+	DueOn       *time.Time `json:"dueOn,omitempty"`
+}
+
+// githubComment is a comment as `gh issue list --json` writes it
+type githubComment struct {
+	Author    githubUser `json:"author"`
+	Body      string     `json:"body"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// githubIssue is an issue as `gh issue list --json` writes it
+type githubIssue struct {
+	Number    int              `json:"number"`
+	Title     string           `json:"title"`
+	Body      string           `json:"body"`
+	State     string           `json:"state"`
+	Author    githubUser       `json:"author"`
+	Assignees []githubUser     `json:"assignees"`
+	Labels    []githubLabel    `json:"labels"`
+	Milestone *githubMilestone `json:"milestone,omitempty"`
+	Comments  []githubComment  `json:"comments"`
+	CreatedAt time.Time        `json:"createdAt"`
+	UpdatedAt time.Time        `json:"updatedAt"`
+	ClosedAt  *time.Time       `json:"closedAt,omitempty"`
+}
+
+// gitlabUser is a user as the GitLab API writes it
+type gitlabUser struct {
+	Username string `json:"username"`
+}
+
+// gitlabMilestone is a milestone as the GitLab API writes it
+type gitlabMilestone struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	DueDate     string `json:"due_date,omitempty"` // YYYY-MM-DD
+}
+
+// gitlabNote is a comment as the GitLab API writes it
+type gitlabNote struct {
+	Author    gitlabUser `json:"author"`
+	Body      string     `json:"body"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// gitlabIssue is an issue as the GitLab API writes it, with its notes
+type gitlabIssue struct {
+	IID         int              `json:"iid"`
+	Title       string           `json:"title"`
+	Description string           `json:"description"`
+	State       string           `json:"state"`
+	Author      gitlabUser       `json:"author"`
+	Assignees   []gitlabUser     `json:"assignees"`
+	Labels      []string         `json:"labels"`
+	Milestone   *gitlabMilestone `json:"milestone,omitempty"`
+	Notes       []gitlabNote     `json:"notes"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+	ClosedAt    *time.Time       `json:"closed_at,omitempty"`
+}
+
+// csvHeader is the header row of exported CSV files
+var csvHeader = []string{"id", "title", "description", "state", "author", "assignee", "labels", "milestone", "priority", "created_at", "updated_at", "closed_at"}
+
+// closedAt returns when an issue was closed, or nil if it is open
+func closedAt(issue *Issue) *time.Time {
+	if issue.Status != StatusClosed || issue.ClosedAt.IsZero() {
+		return nil
+	}
+	t := issue.ClosedAt
+	return &t
+}
+
+// Export writes every issue in a format other trackers can import. Data the
+// format has no place for, such as links between issues, is counted in the result.
+func (im *IssueManager) Export(format Format, w io.Writer) (*ExportResult, error) {
+	snap, err := im.current()
+	if err != nil {
+		return nil, err
+	}
+	issues := snap.sortedIssues()
+
+	result := &ExportResult{Exported: len(issues), Dropped: make(map[string]int)}
+	for _, issue := range issues {
+		if len(issue.Links) > 0 {
+			result.Dropped["links"]++
+		}
+		if len(issue.Commits) > 0 {
+			result.Dropped["commits"]++
+		}
+		if issue.Priority != PriorityNone && format != FormatCSV {
+			result.Dropped["priority"]++
+		}
+		if len(issue.Comments) > 0 && format == FormatCSV {
+			result.Dropped["comments"]++
+		}
+	}
+
+	switch format {
+	case FormatGitHub:
+		list := make([]githubIssue, 0, len(issues))
+		for _, issue := range issues {
+			exported := githubIssue{
+				Number:    issue.ID,
+				Title:     issue.Title,
+				Body:      issue.Description,
+				State:     strings.ToUpper(string(issue.Status)),
+				Author:    githubUser{Login: issue.CreatedBy},
+				Assignees: []githubUser{},
+				Labels:    []githubLabel{},
+				Comments:  []githubComment{},
+				CreatedAt: issue.CreatedAt,
+				UpdatedAt: issue.UpdatedAt,
+				ClosedAt:  closedAt(issue),
+			}
+			if issue.AssignedTo != "" {
+				exported.Assignees = append(exported.Assignees, githubUser{Login: issue.AssignedTo})
+			}
+			for _, name := range issue.Labels {
+				label := githubLabel{Name: name, Color: strings.TrimPrefix(defaultLabelColor(name), "#")}
+				if defined, ok := snap.Labels[name]; ok {
+					label.Color = strings.TrimPrefix(defined.Color, "#")
+					label.Description = defined.Description
+				}
+				exported.Labels = append(exported.Labels, label)
+			}
+			if milestone, ok := snap.Milestones[issue.Milestone]; ok {
+				exported.Milestone = &githubMilestone{Title: milestone.Name, Description: milestone.Description}
+				if !milestone.DueDate.IsZero() {
+					due := milestone.DueDate
+					exported.Milestone.DueOn = &due
+				}
+			}
+			for _, comment := range issue.CommentThread() {
+				exported.Comments = append(exported.Comments, githubComment{
+					Author:    githubUser{Login: comment.Author},
+					Body:      comment.Body,
+					CreatedAt: comment.CreatedAt,
+				})
+			}
+			list = append(list, exported)
+		}
+		err = writeJSON(w, list)
+
+	case FormatGitLab:
+		list := make([]gitlabIssue, 0, len(issues))
+		for _, issue := range issues {
+			state := "opened"
+			if issue.Status == StatusClosed {
+				state = "closed"
+			}
+			exported := gitlabIssue{
+				IID:         issue.ID,
+				Title:       issue.Title,
+				Description: issue.Description,
+				State:       state,
+				Author:      gitlabUser{Username: issue.CreatedBy},
+				Assignees:   []gitlabUser{},
+				Labels:      []string{},
+				Notes:       []gitlabNote{},
+				CreatedAt:   issue.CreatedAt,
+				UpdatedAt:   issue.UpdatedAt,
+				ClosedAt:    closedAt(issue),
+			}
+			if issue.AssignedTo != "" {
+				exported.Assignees = append(exported.Assignees, gitlabUser{Username: issue.AssignedTo})
+			}
+			exported.Labels = append(exported.Labels, issue.Labels...)
+			if milestone, ok := snap.Milestones[issue.Milestone]; ok {
+				exported.Milestone = &gitlabMilestone{Title: milestone.Name, Description: milestone.Description}
+				if !milestone.DueDate.IsZero() {
+					exported.Milestone.DueDate = milestone.DueDate.Format("2006-01-02")
+				}
+			}
+			for _, comment := range issue.CommentThread() {
+				exported.Notes = append(exported.Notes, gitlabNote{
+					Author:    gitlabUser{Username: comment.Author},
+					Body:      comment.Body,
+					CreatedAt: comment.CreatedAt,
+				})
+			}
+			list = append(list, exported)
+		}
+		err = writeJSON(w, list)
+
+	case FormatCSV:
+		writer := csv.NewWriter(w)
+		writer.Write(csvHeader)
+		for _, issue := range issues {
+			closed := ""
+			if t := closedAt(issue); t != nil {
+				closed = t.Format(time.RFC3339)
+			}
+			priority := ""
+			if issue.Priority != PriorityNone {
+				priority = string(issue.Priority)
+			}
+			writer.Write([]string{
+				strconv.Itoa(issue.ID),
+				issue.Title,
+				issue.Description,
+				string(issue.Status),
+				issue.CreatedBy,
+				issue.AssignedTo,
+				strings.Join(issue.Labels, ", "),
+				issue.Milestone,
+				priority,
+				issue.CreatedAt.Format(time.RFC3339),
+				issue.UpdatedAt.Format(time.RFC3339),
+				closed,
+			})
+		}
+		writer.Flush()
+		err = writer.Error()
+
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to write issues: %w", err)
+	}
+
+	return result, nil
+}
+
+// writeJSON writes a value as indented JSON
+func writeJSON(w io.Writer, value interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
@@ -0,0 +1,251 @@
+package issue
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Event records a change to an issue. The events of an issue form an append-only audit trail.
+type Event struct {
+	ID    string    `json:"id"`
+	Actor string    `json:"actor"`
+	At    time.Time `json:"at"`
+	Field string    `json:"field"` // JSON name of the changed field
+	Old   string    `json:"old,omitempty"`
+	New   string    `json:"new,omitempty"`
+}
+
+// Describe describes an event, to be preceded by its actor's name
+func (e *Event) Describe() string {
+	switch e.Field {
+	case "title":
+		return fmt.Sprintf("changed the title from %q to %q", e.Old, e.New)
+	case "description":
+		return "edited the description"
+	case "status":
+		if Status(e.New) == StatusClosed {
+			return "closed the issue"
+		}
+		return "reopened the issue"
+	case "closed_by":
+		return fmt.Sprintf("closed the issue in commit %s", shortCommitID(e.New))
+	case "assigned_to":
+		switch {
+		case e.New == "":
+			return fmt.Sprintf("unassigned %s", e.Old)
+		case e.Old == "":
+			return fmt.Sprintf("assigned the issue to %s", e.New)
+		default:
+			return fmt.Sprintf("reassigned the issue from %s to %s", e.Old, e.New)
+		}
+	case "labels":
+		if e.New != "" {
+			return fmt.Sprintf("added label %s", e.New)
+		}
+		return fmt.Sprintf("removed label %s", e.Old)
+	case "priority":
+		if e.New == "" {
+			return fmt.Sprintf("cleared the priority (was %s)", e.Old)
+		}
+		return fmt.Sprintf("set the priority to %s", e.New)
+	case "milestone":
+		if e.New == "" {
+			return fmt.Sprintf("removed the issue from milestone %s", e.Old)
+		}
+		return fmt.Sprintf("moved the issue to milestone %s", e.New)
+	case "estimate":
+		switch {
+		case e.New == "":
+			return fmt.Sprintf("removed the estimate (was %s)", e.Old)
+		case e.Old == "":
+			return fmt.Sprintf("estimated the issue at %s", e.New)
+		default:
+			return fmt.Sprintf("changed the estimate from %s to %s", e.Old, e.New)
+		}
+	case "state":
+		return fmt.Sprintf("moved the issue from %s to %s", e.Old, e.New)
+	case "links":
+		if e.New != "" {
+			return fmt.Sprintf("marked the issue as %s", e.New)
+		}
+		return fmt.Sprintf("removed the link: %s", e.Old)
+	case "commits":
+		return fmt.Sprintf("referenced the issue in commit %s", shortCommitID(e.New))
+	default:
+		return fmt.Sprintf("changed %s", e.Field)
+	}
+}
+
+// History returns the events of an issue, oldest first
+func (i *Issue) History() []Event {
+	events := append([]Event(nil), i.Events...)
+	sortEvents(events)
+	return events
+}
+
+// sortEvents orders events by time
+func sortEvents(events []Event) {
+	sort.SliceStable(events, func(i, j int) bool {
+		if !events[i].At.Equal(events[j].At) {
+			return events[i].At.Before(events[j].At)
+		}
+		return events[i].ID < events[j].ID
+	})
+}
+
+// copyIssue returns a deep copy of an issue
+func copyIssue(issue *Issue) (*Issue, error) {
+	data, err := json.Marshal(issue)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal issue: %w", err)
+	}
+	var copied Issue
+	if err := json.Unmarshal(data, &copied); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal issue: %w", err)
+	}
+	return &copied, nil
+}
+
+// recordChanges appends an event to an issue for every change since its previous version.
+// Comments and time entries are not recorded, as they keep their own history.
+func recordChanges(old, issue *Issue, actor string, at time.Time) error {
+	if old == nil {
+		return nil
+	}
+
+	var events []Event
+	add := func(field, oldValue, newValue string) {
+		events = append(events, Event{Actor: actor, At: at, Field: field, Old: oldValue, New: newValue})
+	}
+	changed := func(field, oldValue, newValue string) {
+		if oldValue != newValue {
+			add(field, oldValue, newValue)
+		}
+	}
+
+	changed("title", old.Title, issue.Title)
+	changed("description", old.Description, issue.Description)
+	if old.Status != issue.Status {
+		if issue.Status == StatusClosed && issue.ClosedBy != "" {
+			add("closed_by", "", issue.ClosedBy)
+		} else {
+			add("status", string(old.Status), string(issue.Status))
+		}
+	}
+	changed("assigned_to", old.AssignedTo, issue.AssignedTo)
+	for _, label := range issue.Labels {
+		if !old.HasLabel(label) {
+			add("labels", "", label)
+		}
+	}
+	for _, label := range old.Labels {
+		if !issue.HasLabel(label) {
+			add("labels", label, "")
+		}
+	}
+	changed("priority", string(old.Priority), string(issue.Priority))
+	changed("milestone", old.Milestone, issue.Milestone)
+	changed("estimate", FormatDuration(old.Estimate), FormatDuration(issue.Estimate))
+	for _, commitID := range issue.Commits {
+		if !old.HasCommit(commitID) {
+			add("commits", "", commitID)
+		}
+	}
+
+	for _, event := range events {
+		if err := issue.addEvent(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addEvent gives an event an ID and appends it to an issue's history
+func (i *Issue) addEvent(event Event) error {
+	uid, err := newUID()
+	if err != nil {
+		return err
+	}
+	event.ID = uid[:8]
+	i.Events = append(i.Events, event)
+	return nil
+}
+
+// mergeEvents merges the events of two versions of an issue. Events are never
+// changed once recorded, so the result holds every event from either side.
+func mergeEvents(baseValue, ourValue, theirValue []byte) ([]byte, error) {
+	var ours, theirs []Event
+	if ourValue != nil {
+		if err := json.Unmarshal(ourValue, &ours); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal events: %w", err)
+		}
+	}
+	if theirValue != nil {
+		if err := json.Unmarshal(theirValue, &theirs); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal events: %w", err)
+		}
+	}
+
+	seen := make(map[string]bool)
+	var merged []Event
+	for _, event := range append(ours, theirs...) {
+		if !seen[event.ID] {
+			seen[event.ID] = true
+			merged = append(merged, event)
+		}
+	}
+	sortEvents(merged)
+
+	return json.Marshal(merged)
+}
+
+// EditIssue changes the title or description of an issue. Empty values are left unchanged.
+func (im *IssueManager) EditIssue(id int, title, description string) error {
+	issue, err := im.GetIssue(id)
+	if err != nil {
+		return err
+	}
+
+	if title != "" {
+		issue.Title = title
+	}
+	if description != "" {
+		issue.Description = description
+	}
+
+	return im.saveIssue(issue, im.author(), fmt.Sprintf("📝 Edit issue #%d", issue.ID))
+}
+
+// ReopenIssue reopens a closed issue
+func (im *IssueManager) ReopenIssue(id int) error {
+	issue, err := im.GetIssue(id)
+	if err != nil {
+		return err
+	}
+	if issue.Status != StatusClosed {
+		return fmt.Errorf("issue #%d is not closed", issue.ID)
+	}
+
+	issue.Status = StatusOpen
+	issue.ClosedAt = time.Time{}
+	issue.ClosedBy = ""
+
+	return im.saveIssue(issue, im.author(), fmt.Sprintf("📝 Reopen issue #%d", issue.ID))
+}
+
+// UnassignIssue removes the assignee of an issue
+func (im *IssueManager) UnassignIssue(id int) error {
+	issue, err := im.GetIssue(id)
+	if err != nil {
+		return err
+	}
+	if issue.AssignedTo == "" {
+		return fmt.Errorf("issue #%d is not assigned", issue.ID)
+	}
+
+	issue.AssignedTo = ""
+
+	return im.saveIssue(issue, im.author(), fmt.Sprintf("📝 Unassign issue #%d", issue.ID))
+}
@@ -0,0 +1,111 @@
+package issue
+
+import (
+	"testing"
+)
+
+func TestIssueHistory(t *testing.T) {
+	manager := NewIssueManager(t.TempDir())
+	manager.Author = "alice"
+
+	issue, err := manager.CreateIssue("Crash", "It crashes", "alice")
+	if err != nil {
+		t.Fatalf("Failed to create issue: %v", err)
+	}
+	if _, err := manager.CreateLabel("bug", "", ""); err != nil {
+		t.Fatalf("Failed to create label: %v", err)
+	}
+
+	if err := manager.EditIssue(issue.ID, "Crash on start", ""); err != nil {
+		t.Fatalf("Failed to edit issue: %v", err)
+	}
+	if err := manager.AssignIssue(issue.ID, "bob"); err != nil {
+		t.Fatalf("Failed to assign issue: %v", err)
+	}
+	if err := manager.AddLabels(issue.ID, "bug"); err != nil {
+		t.Fatalf("Failed to add label: %v", err)
+	}
+	if err := manager.UnassignIssue(issue.ID); err != nil {
+		t.Fatalf("Failed to unassign issue: %v", err)
+	}
+	if err := manager.UnassignIssue(issue.ID); err == nil {
+		t.Errorf("Expected unassigning an unassigned issue to fail")
+	}
+	if _, err := manager.LinkCommit("0123456789abcdef", "🐛 Fixes #1"); err != nil {
+		t.Fatalf("Failed to link commit: %v", err)
+	}
+	if err := manager.CloseIssue(issue.ID, ""); err == nil {
+		t.Errorf("Expected closing a closed issue to fail")
+	}
+	if err := manager.ReopenIssue(issue.ID); err != nil {
+		t.Fatalf("Failed to reopen issue: %v", err)
+	}
+	if err := manager.ReopenIssue(issue.ID); err == nil {
+		t.Errorf("Expected reopening an open issue to fail")
+	}
+
+	issue, err = manager.GetIssue(issue.ID)
+	if err != nil {
+		t.Fatalf("Failed to get issue: %v", err)
+	}
+	if issue.Title != "Crash on start" || issue.Description != "It crashes" {
+		t.Errorf("Expected the title to change and the description to stay, got %q and %q", issue.Title, issue.Description)
+	}
+	if issue.Status != StatusOpen || issue.ClosedBy != "" || !issue.ClosedAt.IsZero() {
+		t.Errorf("Expected the issue to be open again")
+	}
+
+	expected := []string{
+		`changed the title from "Crash" to "Crash on start"`,
+		"assigned the issue to bob",
+		"added label bug",
+		"unassigned bob",
+		"referenced the issue in commit 0123456",
+		"closed the issue in commit 0123456",
+		"reopened the issue",
+	}
+	history := issue.History()
+	if len(history) != len(expected) {
+		t.Fatalf("Expected %d events, got %d", len(expected), len(history))
+	}
+	for i, event := range history {
+		if event.Describe() != expected[i] {
+			t.Errorf("Expected event %d to be %q, got %q", i, expected[i], event.Describe())
+		}
+		if event.Actor != "alice" || event.ID == "" {
+			t.Errorf("Expected event %d to be recorded for alice with an ID, got %q", i, event.Actor)
+		}
+	}
+}
+
+func TestMergeIssuesKeepsEventsFromBothSides(t *testing.T) {
+	manager := NewIssueManager(t.TempDir())
+
+	issue, err := manager.CreateIssue("Crash", "", "alice")
+	if err != nil {
+		t.Fatalf("Failed to create issue: %v", err)
+	}
+
+	theirsID := divergeIssues(t, manager, func() {
+		if err := manager.AssignIssue(issue.ID, "bob"); err != nil {
+			t.Fatalf("Failed to assign issue: %v", err)
+		}
+	}, func() {
+		if err := manager.EditIssue(issue.ID, "Crash on start", ""); err != nil {
+			t.Fatalf("Failed to edit issue: %v", err)
+		}
+	})
+
+	if _, err := manager.Merge(theirsID); err != nil {
+		t.Fatalf("Failed to merge issues: %v", err)
+	}
+
+	issue, err = manager.GetIssue(issue.ID)
+	if err != nil {
+		t.Fatalf("Failed to get issue: %v", err)
+	}
+	history := issue.History()
+	if len(history) != 2 || history[0].Field != "title" || history[1].Field != "assigned_to" {
+		t.Errorf("Expected the title and assignee events from both sides, got %d events", len(history))
+	}
+}
@@ -0,0 +1,780 @@
+package issue
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Format is a file format other trackers use for issues
+type Format string
+
+const (
+	// FormatGitHub is a JSON array of GitHub issues, from the REST API or `gh issue list --json`
+	FormatGitHub Format = "github"
+	// FormatGitLab is a JSON array of GitLab issues from the REST API, optionally with their notes
+	FormatGitLab Format = "gitlab"
+	// FormatCSV is a table of issues with a header row
+	FormatCSV Format = "csv"
+)
+
+// Formats lists the supported formats
+var Formats = []Format{FormatGitHub, FormatGitLab, FormatCSV}
+
+// ParseFormat parses the name of a format
+func ParseFormat(s string) (Format, error) {
+	for _, format := range Formats {
+		if string(format) == strings.ToLower(strings.TrimSpace(s)) {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("unknown format %q (use github, gitlab or csv)", s)
+}
+
+// ImportResult describes what an import did
+type ImportResult struct {
+	Imported   []*Issue       // New issues, ordered by alias
+	Skipped    []int          // Original IDs of issues that were imported before
+	Renumbered map[int]int    // Original ID to alias, for issues whose ID was taken
+	Unmapped   map[string]int // Fields that couldn't be imported, with the number of issues that had them
+}
+
+// importedIssue is an issue read from another tracker, before it is added to the repository
+type importedIssue struct {
+	issue     *Issue // ID is the original ID
+	labels    []*Label
+	milestone *Milestone
+	unmapped  []string
+}
+
+// fields reads the fields of an imported JSON object, remembering which ones were used
+type fields struct {
+	values map[string]interface{}
+	used   map[string]bool
+}
+
+func newFields(values map[string]interface{}) *fields {
+	return &fields{values: values, used: make(map[string]bool)}
+}
+
+// lookup returns the first of some keys that has a value
+func (f *fields) lookup(keys ...string) (string, interface{}) {
+	for _, key := range keys {
+		if value, ok := f.values[key]; ok && value != nil {
+			return key, value
+		}
+	}
+	return "", nil
+}
+
+// string returns a string or number field
+func (f *fields) string(keys ...string) string {
+	key, value := f.lookup(keys...)
+	switch value := value.(type) {
+	case string:
+		f.used[key] = true
+		return value
+	case json.Number:
+		f.used[key] = true
+		return value.String()
+	}
+	return ""
+}
+
+// int returns a number field
+func (f *fields) int(keys ...string) int {
+	key, value := f.lookup(keys...)
+	if number, ok := value.(json.Number); ok {
+		if n, err := strconv.Atoi(number.String()); err == nil {
+			f.used[key] = true
+			return n
+		}
+	}
+	return 0
+}
+
+// time returns a timestamp field, or the zero time if it is missing
+func (f *fields) time(keys ...string) (time.Time, error) {
+	key, value := f.lookup(keys...)
+	s, ok := value.(string)
+	if !ok || s == "" {
+		return time.Time{}, nil
+	}
+	t, err := parseImportTime(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	f.used[key] = true
+	return t, nil
+}
+
+// object returns an object field, or nil if it is missing
+func (f *fields) object(keys ...string) *fields {
+	key, value := f.lookup(keys...)
+	if object, ok := value.(map[string]interface{}); ok {
+		f.used[key] = true
+		return newFields(object)
+	}
+	return nil
+}
+
+// list returns an array field
+func (f *fields) list(keys ...string) []interface{} {
+	key, value := f.lookup(keys...)
+	if list, ok := value.([]interface{}); ok {
+		f.used[key] = true
+		return list
+	}
+	return nil
+}
+
+// unused lists the fields with content that weren't read and aren't ignored
+func (f *fields) unused(ignored map[string]bool) []string {
+	var keys []string
+	for key, value := range f.values {
+		if !f.used[key] && !ignored[key] && !isURLField(key) && !isEmptyValue(value) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// isURLField reports whether a field holds a link to another tracker's API or web pages
+func isURLField(key string) bool {
+	return strings.HasSuffix(strings.ToLower(key), "url")
+}
+
+// isEmptyValue reports whether a JSON value holds no content. Objects are empty
+// when all their fields are, so that e.g. reaction counts of zero aren't reported.
+func isEmptyValue(value interface{}) bool {
+	switch value := value.(type) {
+	case nil:
+		return true
+	case string:
+		return value == ""
+	case bool:
+		return !value
+	case json.Number:
+		f, err := value.Float64()
+		return err == nil && f == 0
+	case []interface{}:
+		return len(value) == 0
+	case map[string]interface{}:
+		for key, v := range value {
+			if !isURLField(key) && !isEmptyValue(v) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// importTimeLayouts are the timestamp layouts accepted on import
+var importTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05 MST",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// parseImportTime parses a timestamp in one of the layouts other trackers use
+func parseImportTime(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range importTimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized time %q", s)
+}
+
+// importLabelColor turns a label color from another tracker into #rrggbb, or ""
+// to pick one from the label's name
+func importLabelColor(color string) string {
+	if !strings.HasPrefix(color, "#") {
+		color = "#" + color
+	}
+	if !labelColorPattern.MatchString(color) {
+		return ""
+	}
+	return strings.ToLower(color)
+}
+
+// decodeJSONIssues decodes a JSON array of objects, or a single object
+func decodeJSONIssues(r io.Reader) ([]*fields, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read issues: %w", err)
+	}
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '{' {
+		data = append(append([]byte("["), data...), ']')
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var objects []map[string]interface{}
+	if err := decoder.Decode(&objects); err != nil {
+		return nil, fmt.Errorf("failed to parse issues: %w", err)
+	}
+
+	list := make([]*fields, 0, len(objects))
+	for _, object := range objects {
+		list = append(list, newFields(object))
+	}
+	return list, nil
+}
+
+// userName returns the name of a user object: GitHub logins and GitLab usernames
+func userName(user *fields) string {
+	if user == nil {
+		return ""
+	}
+	return user.string("login", "username", "name")
+}
+
+// importComments reads comments: GitHub comments from `gh` or GitLab notes.
+// System notes (GitLab's own events) are returned as skipped.
+func importComments(list []interface{}) (comments []Comment, skipped int, err error) {
+	for _, value := range list {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			skipped++
+			continue
+		}
+		comment := newFields(object)
+		if system, _ := object["system"].(bool); system {
+			skipped++
+			continue
+		}
+
+		body := comment.string("body")
+		if strings.TrimSpace(body) == "" {
+			continue
+		}
+		createdAt, err := comment.time("createdAt", "created_at")
+		if err != nil {
+			return nil, 0, fmt.Errorf("comment: %w", err)
+		}
+		uid, err := newUID()
+		if err != nil {
+			return nil, 0, err
+		}
+		comments = append(comments, Comment{
+			ID:        uid[:8],
+			Author:    userName(comment.object("author", "user")),
+			Body:      body,
+			CreatedAt: createdAt,
+			Imported:  true,
+		})
+	}
+	return comments, skipped, nil
+}
+
+// githubIgnoredFields are GitHub fields that describe GitHub itself rather than the issue
+var githubIgnoredFields = map[string]bool{
+	"id": true, "node_id": true, "assignee": true, "locked": true, "active_lock_reason": true,
+	"author_association": true, "performed_via_github_app": true, "state_reason": true,
+	"stateReason": true, "closed_by": true, "draft": true, "isPinned": true, "sub_issues_summary": true,
+}
+
+// decodeGitHub reads issues exported from GitHub. Pull requests are left out.
+func decodeGitHub(r io.Reader) ([]*importedIssue, int, error) {
+	objects, err := decodeJSONIssues(r)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var imported []*importedIssue
+	pullRequests := 0
+	for _, object := range objects {
+		if object.object("pull_request") != nil {
+			pullRequests++
+			continue
+		}
+
+		record := &importedIssue{issue: &Issue{
+			ID:          object.int("number"),
+			Title:       object.string("title"),
+			Description: object.string("body"),
+			CreatedBy:   userName(object.object("user", "author")),
+		}}
+		record.issue.Source = object.string("html_url", "url")
+		if strings.EqualFold(object.string("state"), "closed") {
+			record.issue.Status = StatusClosed
+		}
+		if err := readTimes(object, record.issue, "createdAt", "created_at", "updatedAt", "updated_at", "closedAt", "closed_at"); err != nil {
+			return nil, 0, fmt.Errorf("issue #%d: %w", record.issue.ID, err)
+		}
+
+		for _, value := range object.list("assignees") {
+			if assignee, ok := value.(map[string]interface{}); ok {
+				record.addAssignee(userName(newFields(assignee)))
+			}
+		}
+		for _, value := range object.list("labels") {
+			switch value := value.(type) {
+			case string:
+				record.labels = append(record.labels, &Label{Name: value})
+			case map[string]interface{}:
+				label := newFields(value)
+				record.labels = append(record.labels, &Label{
+					Name:        label.string("name"),
+					Color:       importLabelColor(label.string("color")),
+					Description: label.string("description"),
+				})
+			}
+		}
+		if milestone := object.object("milestone"); milestone != nil {
+			due, err := milestone.time("dueOn", "due_on")
+			if err != nil {
+				return nil, 0, fmt.Errorf("issue #%d: milestone: %w", record.issue.ID, err)
+			}
+			record.milestone = &Milestone{Name: milestone.string("title"), Description: milestone.string("description"), DueDate: due}
+		}
+
+		// The REST API only counts comments; `gh issue list --json comments` includes them
+		comments, _, err := importComments(object.list("comments"))
+		if err != nil {
+			return nil, 0, fmt.Errorf("issue #%d: %w", record.issue.ID, err)
+		}
+		record.issue.Comments = comments
+
+		record.unmapped = append(record.unmapped, object.unused(githubIgnoredFields)...)
+		imported = append(imported, record)
+	}
+
+	return imported, pullRequests, nil
+}
+
+// gitlabIgnoredFields are GitLab fields that describe GitLab itself rather than the issue
+var gitlabIgnoredFields = map[string]bool{
+	"id": true, "project_id": true, "assignee": true, "_links": true, "references": true,
+	"user_notes_count": true, "merge_requests_count": true, "subscribed": true, "has_tasks": true,
+	"task_status": true, "task_completion_status": true, "blocking_issues_count": true,
+	"moved_to_id": true, "service_desk_reply_to": true, "issue_type": true, "type": true,
+	"discussion_locked": true, "closed_by": true, "imported": true, "imported_from": true,
+}
+
+// decodeGitLab reads issues exported from GitLab
+func decodeGitLab(r io.Reader) ([]*importedIssue, int, error) {
+	objects, err := decodeJSONIssues(r)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var imported []*importedIssue
+	systemNotes := 0
+	for _, object := range objects {
+		record := &importedIssue{issue: &Issue{
+			ID:          object.int("iid"),
+			Title:       object.string("title"),
+			Description: object.string("description"),
+			CreatedBy:   userName(object.object("author")),
+			Source:      object.string("web_url"),
+		}}
+		if object.string("state") == "closed" {
+			record.issue.Status = StatusClosed
+		}
+		if err := readTimes(object, record.issue, "created_at", "created_at", "updated_at", "updated_at", "closed_at", "closed_at"); err != nil {
+			return nil, 0, fmt.Errorf("issue #%d: %w", record.issue.ID, err)
+		}
+
+		for _, value := range object.list("assignees") {
+			if assignee, ok := value.(map[string]interface{}); ok {
+				record.addAssignee(userName(newFields(assignee)))
+			}
+		}
+		for _, value := range object.list("labels") {
+			switch value := value.(type) {
+			case string:
+				record.labels = append(record.labels, &Label{Name: value})
+			case map[string]interface{}:
+				// Labels fetched with with_labels_details
+				label := newFields(value)
+				record.labels = append(record.labels, &Label{
+					Name:        label.string("name"),
+					Color:       importLabelColor(label.string("color")),
+					Description: label.string("description"),
+				})
+			}
+		}
+		if milestone := object.object("milestone"); milestone != nil {
+			due, err := milestone.time("due_date")
+			if err != nil {
+				return nil, 0, fmt.Errorf("issue #%d: milestone: %w", record.issue.ID, err)
+			}
+			if !due.IsZero() {
+				due = due.AddDate(0, 0, 1).Add(-time.Second)
+			}
+			record.milestone = &Milestone{Name: milestone.string("title"), Description: milestone.string("description"), DueDate: due}
+		}
+
+		comments, skipped, err := importComments(object.list("notes"))
+		if err != nil {
+			return nil, 0, fmt.Errorf("issue #%d: %w", record.issue.ID, err)
+		}
+		record.issue.Comments = comments
+		systemNotes += skipped
+
+		record.unmapped = append(record.unmapped, object.unused(gitlabIgnoredFields)...)
+		imported = append(imported, record)
+	}
+
+	return imported, systemNotes, nil
+}
+
+// readTimes reads the creation, update and close times of an issue, each from either of two fields
+func readTimes(object *fields, issue *Issue, created1, created2, updated1, updated2, closed1, closed2 string) error {
+	var err error
+	if issue.CreatedAt, err = object.time(created1, created2); err != nil {
+		return err
+	}
+	if issue.UpdatedAt, err = object.time(updated1, updated2); err != nil {
+		return err
+	}
+	if issue.ClosedAt, err = object.time(closed1, closed2); err != nil {
+		return err
+	}
+	return nil
+}
+
+// addAssignee records an assignee. Snap issues have one assignee, so others are reported.
+func (r *importedIssue) addAssignee(name string) {
+	switch {
+	case name == "":
+	case r.issue.AssignedTo == "":
+		r.issue.AssignedTo = name
+	case r.issue.AssignedTo != name:
+		r.unmapped = append(r.unmapped, "additional assignees")
+	}
+}
+
+// csvColumnPattern matches what is dropped from CSV headers to compare them
+var csvColumnPattern = regexp.MustCompile(`\([^)]*\)|[^a-z0-9]`)
+
+// csvColumns lists the CSV headers each issue field is read from, normalized
+var csvColumns = map[string][]string{
+	"id":          {"id", "number", "iid", "issueid"},
+	"title":       {"title"},
+	"description": {"description", "body"},
+	"state":       {"state"},
+	"status":      {"status"},
+	"author":      {"author", "authorusername", "createdby"},
+	"assignee":    {"assignee", "assignees", "assigneeusername", "assignedto"},
+	"labels":      {"labels"},
+	"milestone":   {"milestone"},
+	"priority":    {"priority"},
+	"created_at":  {"createdat", "created"},
+	"updated_at":  {"updatedat", "updated"},
+	"closed_at":   {"closedat", "closed"},
+	"source":      {"url", "weburl", "htmlurl"},
+}
+
+// csvField returns the issue field a CSV header refers to, or ""
+func csvField(header string) string {
+	header = csvColumnPattern.ReplaceAllString(strings.ToLower(header), "")
+	for field, names := range csvColumns {
+		for _, name := range names {
+			if name == header {
+				return field
+			}
+		}
+	}
+	return ""
+}
+
+// splitList splits a CSV cell holding several values
+func splitList(cell string) []string {
+	var values []string
+	for _, value := range strings.FieldsFunc(cell, func(r rune) bool { return r == ',' || r == ';' }) {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// decodeCSV reads issues from a CSV file with a header row
+func decodeCSV(r io.Reader) ([]*importedIssue, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CSV: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("CSV file is empty")
+	}
+
+	// Match the header to issue fields. The first of two columns for the same field wins.
+	header := rows[0]
+	columns := make(map[string]int)
+	unknown := make(map[int]string)
+	for i, name := range header {
+		field := csvField(name)
+		if field == "" {
+			unknown[i] = strings.TrimSpace(name)
+			continue
+		}
+		if _, ok := columns[field]; !ok {
+			columns[field] = i
+		}
+	}
+	if _, ok := columns["title"]; !ok {
+		return nil, fmt.Errorf("CSV file has no title column")
+	}
+
+	var imported []*importedIssue
+	for n, row := range rows[1:] {
+		line := n + 2
+		cell := func(field string) string {
+			if i, ok := columns[field]; ok && i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+
+		record := &importedIssue{issue: &Issue{
+			Title:       cell("title"),
+			Description: cell("description"),
+			CreatedBy:   cell("author"),
+			Source:      cell("source"),
+			Milestone:   cell("milestone"),
+		}}
+		if record.issue.Title == "" {
+			return nil, fmt.Errorf("line %d: missing title", line)
+		}
+		if id := cell("id"); id != "" {
+			record.issue.ID, err = strconv.Atoi(strings.TrimPrefix(id, "#"))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid ID %q", line, id)
+			}
+		}
+
+		state := strings.ToLower(cell("state"))
+		if state == "" {
+			state = strings.ToLower(cell("status"))
+		}
+		if state == "closed" || state == "done" {
+			record.issue.Status = StatusClosed
+		}
+
+		for field, target := range map[string]*time.Time{"created_at": &record.issue.CreatedAt, "updated_at": &record.issue.UpdatedAt, "closed_at": &record.issue.ClosedAt} {
+			if value := cell(field); value != "" {
+				if *target, err = parseImportTime(value); err != nil {
+					return nil, fmt.Errorf("line %d: invalid %s: %w", line, field, err)
+				}
+			}
+		}
+
+		for _, assignee := range splitList(cell("assignee")) {
+			record.addAssignee(assignee)
+		}
+		for _, name := range splitList(cell("labels")) {
+			record.labels = append(record.labels, &Label{Name: name})
+		}
+		if record.issue.Milestone != "" {
+			record.milestone = &Milestone{Name: record.issue.Milestone}
+		}
+		if priority, err := ParsePriority(cell("priority")); err == nil {
+			record.issue.Priority = priority
+		} else {
+			record.unmapped = append(record.unmapped, "priority")
+		}
+
+		for i, name := range unknown {
+			if i < len(row) && strings.TrimSpace(row[i]) != "" {
+				record.unmapped = append(record.unmapped, name)
+			}
+		}
+		imported = append(imported, record)
+	}
+
+	return imported, nil
+}
+
+// Import adds the issues in a file exported from another tracker. Issues keep
+// their original numbers as aliases unless the number is taken, along with their
+// labels, milestones and comments; labels and milestones are created as needed.
+// Issues imported before, recognized by their source URL, are skipped.
+func (im *IssueManager) Import(format Format, r io.Reader) (*ImportResult, error) {
+	var records []*importedIssue
+	var err error
+	result := &ImportResult{Renumbered: make(map[int]int), Unmapped: make(map[string]int)}
+	switch format {
+	case FormatGitHub:
+		var pullRequests int
+		records, pullRequests, err = decodeGitHub(r)
+		if pullRequests > 0 {
+			result.Unmapped["pull requests"] = pullRequests
+		}
+	case FormatGitLab:
+		var systemNotes int
+		records, systemNotes, err = decodeGitLab(r)
+		if systemNotes > 0 {
+			result.Unmapped["system notes"] = systemNotes
+		}
+	case FormatCSV:
+		records, err = decodeCSV(r)
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	snap, err := im.current()
+	if err != nil {
+		return nil, err
+	}
+
+	// Find the issues imported before and the aliases in use
+	sources := make(map[string]bool)
+	taken := make(map[int]bool)
+	for _, issue := range snap.Issues {
+		if issue.Source != "" {
+			sources[issue.Source] = true
+		}
+		taken[issue.ID] = true
+	}
+
+	// Keep the original IDs where possible, then number the rest after them
+	var fresh, renumber []*importedIssue
+	for _, record := range records {
+		issue := record.issue
+		if issue.Source == "" && issue.ID > 0 {
+			issue.Source = fmt.Sprintf("%s#%d", format, issue.ID)
+		}
+		if issue.Source != "" && sources[issue.Source] {
+			result.Skipped = append(result.Skipped, issue.ID)
+			continue
+		}
+		if issue.Source != "" {
+			sources[issue.Source] = true
+		}
+
+		if issue.ID > 0 && !taken[issue.ID] {
+			taken[issue.ID] = true
+		} else {
+			renumber = append(renumber, record)
+		}
+		fresh = append(fresh, record)
+	}
+	next := 1
+	for id := range taken {
+		if id >= next {
+			next = id + 1
+		}
+	}
+	for _, record := range renumber {
+		if record.issue.ID > 0 {
+			result.Renumbered[record.issue.ID] = next
+		}
+		record.issue.ID = next
+		next++
+	}
+
+	now := time.Now()
+	for _, record := range fresh {
+		issue := record.issue
+		uid, err := newUID()
+		if err != nil {
+			return nil, err
+		}
+		issue.UID = uid
+		if issue.Status != StatusClosed {
+			issue.Status = StatusOpen
+			issue.ClosedAt = time.Time{}
+		}
+		if issue.CreatedAt.IsZero() {
+			issue.CreatedAt = now
+		}
+		if issue.UpdatedAt.IsZero() {
+			issue.UpdatedAt = issue.CreatedAt
+		}
+		if issue.Status == StatusClosed && issue.ClosedAt.IsZero() {
+			issue.ClosedAt = issue.UpdatedAt
+		}
+		if issue.CreatedBy == "" {
+			issue.CreatedBy = im.author()
+		}
+		for i := range issue.Comments {
+			if issue.Comments[i].CreatedAt.IsZero() {
+				issue.Comments[i].CreatedAt = issue.CreatedAt
+			}
+			if issue.Comments[i].Author == "" {
+				issue.Comments[i].Author = issue.CreatedBy
+			}
+			if issue.Comments[i].CreatedAt.After(issue.UpdatedAt) {
+				issue.UpdatedAt = issue.Comments[i].CreatedAt
+			}
+		}
+		if issue.ClosedAt.After(issue.UpdatedAt) {
+			issue.UpdatedAt = issue.ClosedAt
+		}
+
+		// Define the labels and milestone the issue refers to
+		for _, label := range record.labels {
+			if validateName("label", label.Name) != nil {
+				record.unmapped = append(record.unmapped, "labels")
+				continue
+			}
+			if _, ok := snap.Labels[label.Name]; !ok {
+				if label.Color == "" {
+					label.Color = defaultLabelColor(label.Name)
+				}
+				snap.Labels[label.Name] = label
+			}
+			if !issue.HasLabel(label.Name) {
+				issue.Labels = append(issue.Labels, label.Name)
+			}
+		}
+		issue.Milestone = ""
+		if milestone := record.milestone; milestone != nil {
+			if validateName("milestone", milestone.Name) != nil {
+				record.unmapped = append(record.unmapped, "milestone")
+			} else {
+				if _, ok := snap.Milestones[milestone.Name]; !ok {
+					milestone.Status = StatusOpen
+					milestone.CreatedAt = now
+					snap.Milestones[milestone.Name] = milestone
+				}
+				issue.Milestone = milestone.Name
+			}
+		}
+
+		counted := make(map[string]bool)
+		for _, field := range record.unmapped {
+			if !counted[field] {
+				counted[field] = true
+				result.Unmapped[field]++
+			}
+		}
+
+		snap.Issues[issue.UID] = issue
+		result.Imported = append(result.Imported, issue)
+	}
+	sort.Slice(result.Imported, func(i, j int) bool { return result.Imported[i].ID < result.Imported[j].ID })
+
+	if len(result.Imported) == 0 {
+		return result, nil
+	}
+	message := fmt.Sprintf("📥 Import %d issues from %s", len(result.Imported), format)
+	if err := im.commit(snap, im.author(), message, ""); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
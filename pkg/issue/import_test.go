@@ -0,0 +1,285 @@
+package issue
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const githubExport = `[
+  {
+    "url": "https://api.github.com/repos/acme/app/issues/7",
+    "html_url": "https://github.com/acme/app/issues/7",
+    "id": 1001,
+    "node_id": "I_kw",
+    "number": 7,
+    "title": "Crash on start",
+    "body": "It crashes",
+    "state": "closed",
+    "user": {"login": "alice", "id": 1},
+    "assignee": {"login": "bob"},
+    "assignees": [{"login": "bob"}, {"login": "carol"}],
+    "labels": [{"name": "bug", "color": "d73a4a", "description": "Something is broken"}],
+    "milestone": {"title": "v1.0", "due_on": "2026-12-31T08:00:00Z"},
+    "comments": 2,
+    "reactions": {"url": "https://api.github.com/x", "total_count": 3, "+1": 3},
+    "locked": false,
+    "created_at": "2026-01-02T10:00:00Z",
+    "updated_at": "2026-01-05T10:00:00Z",
+    "closed_at": "2026-01-04T10:00:00Z"
+  },
+  {
+    "html_url": "https://github.com/acme/app/pull/8",
+    "number": 8,
+    "title": "Fix crash",
+    "state": "open",
+    "pull_request": {"url": "https://api.github.com/repos/acme/app/pulls/8"}
+  },
+  {
+    "number": 1,
+    "title": "Add dark mode",
+    "body": null,
+    "state": "OPEN",
+    "author": {"login": "dave"},
+    "assignees": [],
+    "labels": [{"name": "feature", "color": "a2eeef"}],
+    "comments": [{"author": {"login": "alice"}, "body": "Yes please", "createdAt": "2026-02-01T09:00:00Z"}],
+    "createdAt": "2026-01-31T09:00:00Z",
+    "updatedAt": "2026-02-01T09:00:00Z",
+    "url": "https://github.com/acme/app/issues/1"
+  }
+]`
+
+const gitlabExport = `[
+  {
+    "id": 501,
+    "iid": 3,
+    "project_id": 9,
+    "title": "Slow search",
+    "description": "Takes ages",
+    "state": "opened",
+    "author": {"username": "erin"},
+    "assignees": [{"username": "frank"}],
+    "labels": ["performance"],
+    "milestone": {"title": "Q3", "due_date": "2026-09-30"},
+    "weight": 3,
+    "created_at": "2026-03-01T12:00:00.000Z",
+    "updated_at": "2026-03-02T12:00:00.000Z",
+    "web_url": "https://gitlab.com/acme/app/-/issues/3",
+    "notes": [
+      {"author": {"username": "frank"}, "body": "Looking into it", "created_at": "2026-03-02T12:00:00.000Z", "system": false},
+      {"author": {"username": "frank"}, "body": "assigned to @frank", "created_at": "2026-03-02T11:00:00.000Z", "system": true}
+    ]
+  }
+]`
+
+const csvExport = `Issue ID,Title,Description,State,Author Username,Assignee Username(s),Labels,Priority,Created At (UTC),Estimate
+1,Write docs,"Explain ""snap""",Open,alice,bob,"docs, help",high,2026-04-01 10:00:00,3h
+2,Old task,,Closed,alice,,,,2026-04-02 10:00:00,
+`
+
+func TestImportGitHub(t *testing.T) {
+	manager := NewIssueManager(t.TempDir())
+	manager.Author = "importer"
+
+	// Issue #1 exists already, so the imported #1 is renumbered
+	if _, err := manager.CreateIssue("Existing", "", "alice"); err != nil {
+		t.Fatalf("Failed to create issue: %v", err)
+	}
+
+	result, err := manager.Import(FormatGitHub, strings.NewReader(githubExport))
+	if err != nil {
+		t.Fatalf("Failed to import issues: %v", err)
+	}
+	if len(result.Imported) != 2 || result.Imported[0].ID != 7 || result.Imported[1].ID != 8 {
+		t.Fatalf("Expected #7 and the renumbered #1 to be imported, got %d issues", len(result.Imported))
+	}
+	if result.Renumbered[1] != 8 {
+		t.Errorf("Expected #1 to be renumbered to #8, got %v", result.Renumbered)
+	}
+	for _, field := range []string{"pull requests", "comments", "reactions", "additional assignees"} {
+		if result.Unmapped[field] != 1 {
+			t.Errorf("Expected %s to be reported for 1 issue, got %d", field, result.Unmapped[field])
+		}
+	}
+	for _, field := range []string{"id", "node_id", "locked", "assignee", "url"} {
+		if _, ok := result.Unmapped[field]; ok {
+			t.Errorf("Expected %s not to be reported", field)
+		}
+	}
+
+	crash, err := manager.GetIssue(7)
+	if err != nil {
+		t.Fatalf("Failed to get issue: %v", err)
+	}
+	if crash.Title != "Crash on start" || crash.Status != StatusClosed || crash.CreatedBy != "alice" || crash.AssignedTo != "bob" {
+		t.Errorf("Expected the issue's fields to be imported, got %+v", crash)
+	}
+	if crash.ClosedAt.Format("2006-01-02") != "2026-01-04" || crash.CreatedAt.Format("2006-01-02") != "2026-01-02" {
+		t.Errorf("Expected the original timestamps, got %v and %v", crash.CreatedAt, crash.ClosedAt)
+	}
+	if !crash.HasLabel("bug") || crash.Milestone != "v1.0" || crash.Source != "https://github.com/acme/app/issues/7" {
+		t.Errorf("Expected the label, milestone and source to be imported")
+	}
+	label, err := manager.GetLabel("bug")
+	if err != nil || label.Color != "#d73a4a" || label.Description != "Something is broken" {
+		t.Errorf("Expected the bug label to be created with its color")
+	}
+	if _, err := manager.GetMilestone("v1.0"); err != nil {
+		t.Errorf("Expected the milestone to be created: %v", err)
+	}
+
+	darkMode, err := manager.GetIssue(8)
+	if err != nil {
+		t.Fatalf("Failed to get issue: %v", err)
+	}
+	if darkMode.CreatedBy != "dave" || len(darkMode.Comments) != 1 || darkMode.Comments[0].Author != "alice" || darkMode.Comments[0].Body != "Yes please" {
+		t.Errorf("Expected the issue from gh to be imported with its comment")
+	}
+
+	// Importing again skips the issues
+	result, err = manager.Import(FormatGitHub, strings.NewReader(githubExport))
+	if err != nil {
+		t.Fatalf("Failed to import issues again: %v", err)
+	}
+	if len(result.Imported) != 0 || len(result.Skipped) != 2 {
+		t.Errorf("Expected both issues to be skipped, got %d imported and %d skipped", len(result.Imported), len(result.Skipped))
+	}
+}
+
+func TestImportGitLabAndCSV(t *testing.T) {
+	manager := NewIssueManager(t.TempDir())
+
+	result, err := manager.Import(FormatGitLab, strings.NewReader(gitlabExport))
+	if err != nil {
+		t.Fatalf("Failed to import GitLab issues: %v", err)
+	}
+	if len(result.Imported) != 1 || result.Unmapped["weight"] != 1 || result.Unmapped["system notes"] != 1 {
+		t.Errorf("Expected 1 issue with its weight and system note reported, got %d issues and %v", len(result.Imported), result.Unmapped)
+	}
+	search, err := manager.GetIssue(3)
+	if err != nil {
+		t.Fatalf("Failed to get issue: %v", err)
+	}
+	if search.Status != StatusOpen || search.AssignedTo != "frank" || !search.HasLabel("performance") || len(search.Comments) != 1 {
+		t.Errorf("Expected the GitLab issue to be imported, got %+v", search)
+	}
+	milestone, err := manager.GetMilestone("Q3")
+	if err != nil || milestone.DueDate.Format("2006-01-02") != "2026-09-30" {
+		t.Errorf("Expected milestone Q3 due on 2026-09-30")
+	}
+
+	result, err = manager.Import(FormatCSV, strings.NewReader(csvExport))
+	if err != nil {
+		t.Fatalf("Failed to import CSV issues: %v", err)
+	}
+	if len(result.Imported) != 2 || result.Unmapped["Estimate"] != 1 {
+		t.Errorf("Expected 2 issues with the estimate reported once, got %d issues and %v", len(result.Imported), result.Unmapped)
+	}
+	docs, err := manager.GetIssue(1)
+	if err != nil {
+		t.Fatalf("Failed to get issue: %v", err)
+	}
+	if docs.Description != `Explain "snap"` || docs.Priority != PriorityHigh || len(docs.Labels) != 2 || docs.AssignedTo != "bob" {
+		t.Errorf("Expected the CSV issue to be imported, got %+v", docs)
+	}
+	old, err := manager.GetIssue(2)
+	if err != nil {
+		t.Fatalf("Failed to get issue: %v", err)
+	}
+	if old.Status != StatusClosed || old.ClosedAt.IsZero() {
+		t.Errorf("Expected the closed CSV issue to be closed")
+	}
+
+	// A closed issue was last updated when it was closed
+	result, err = manager.Import(FormatCSV, strings.NewReader("Title,State,Created At,Closed At\nShipped,Closed,2026-05-01 10:00:00,2026-05-03 10:00:00\n"))
+	if err != nil || len(result.Imported) != 1 {
+		t.Fatalf("Failed to import the closed CSV issue: %v", err)
+	}
+	if shipped := result.Imported[0]; !shipped.UpdatedAt.Equal(shipped.ClosedAt) || shipped.UpdatedAt.Format("2006-01-02") != "2026-05-03" {
+		t.Errorf("Expected the closed issue to be updated when it was closed, got %v", shipped.UpdatedAt)
+	}
+
+	invalid := []string{
+		"ID,Title\n1,\n",
+		"ID,Title\nx,Broken\n",
+		"Title,Created At\nBroken,yesterday\n",
+		"Description\nNo title column\n",
+	}
+	for _, data := range invalid {
+		if _, err := manager.Import(FormatCSV, strings.NewReader(data)); err == nil {
+			t.Errorf("Expected importing %q to fail", data)
+		}
+	}
+}
+
+func TestExportRoundTrip(t *testing.T) {
+	source := NewIssueManager(t.TempDir())
+	source.Author = "alice"
+
+	issue, err := source.CreateIssue("Crash", "It crashes", "alice")
+	if err != nil {
+		t.Fatalf("Failed to create issue: %v", err)
+	}
+	if _, err := source.CreateLabel("bug", "#d73a4a", ""); err != nil {
+		t.Fatalf("Failed to create label: %v", err)
+	}
+	if err := source.AddLabels(issue.ID, "bug"); err != nil {
+		t.Fatalf("Failed to add label: %v", err)
+	}
+	if _, err := source.AddComment(issue.ID, "bob", "Same here", ""); err != nil {
+		t.Fatalf("Failed to comment: %v", err)
+	}
+	if err := source.SetPriority(issue.ID, PriorityHigh); err != nil {
+		t.Fatalf("Failed to set priority: %v", err)
+	}
+	if _, err := source.CreateIssue("Typo", "", "bob"); err != nil {
+		t.Fatalf("Failed to create issue: %v", err)
+	}
+	if err := source.CloseIssue(2, ""); err != nil {
+		t.Fatalf("Failed to close issue: %v", err)
+	}
+
+	for _, format := range Formats {
+		var buf bytes.Buffer
+		result, err := source.Export(format, &buf)
+		if err != nil {
+			t.Fatalf("Failed to export %s: %v", format, err)
+		}
+		if result.Exported != 2 {
+			t.Errorf("Expected 2 issues to be exported as %s, got %d", format, result.Exported)
+		}
+
+		target := NewIssueManager(t.TempDir())
+		imported, err := target.Import(format, &buf)
+		if err != nil {
+			t.Fatalf("Failed to import %s: %v", format, err)
+		}
+		if len(imported.Imported) != 2 || len(imported.Unmapped) != 0 {
+			t.Errorf("Expected %s to round-trip cleanly, got %d issues and %v", format, len(imported.Imported), imported.Unmapped)
+		}
+
+		crash, err := target.GetIssue(1)
+		if err != nil {
+			t.Fatalf("Failed to get issue: %v", err)
+		}
+		if crash.Title != "Crash" || crash.Description != "It crashes" || !crash.HasLabel("bug") || crash.CreatedAt.Unix() != issue.CreatedAt.Unix() {
+			t.Errorf("Expected %s to keep the issue's fields, got %+v", format, crash)
+		}
+		typo, err := target.GetIssue(2)
+		if err != nil || typo.Status != StatusClosed {
+			t.Errorf("Expected %s to keep the closed issue closed", format)
+		}
+
+		switch format {
+		case FormatCSV:
+			if crash.Priority != PriorityHigh || result.Dropped["comments"] != 1 {
+				t.Errorf("Expected csv to keep the priority and drop the comment")
+			}
+		default:
+			if len(crash.Comments) != 1 || crash.Comments[0].Author != "bob" || result.Dropped["priority"] != 1 {
+				t.Errorf("Expected %s to keep the comment and drop the priority", format)
+			}
+		}
+	}
+}
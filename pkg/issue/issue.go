@@ -1,10 +1,9 @@
 package issue
 
 import (
-	"encoding/json"
 	"fmt"
-	"os"
-	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -20,206 +19,259 @@ const (
 
 // Issue represents a tracked issue in the repository
 type Issue struct {
-	ID          int       `json:"id"`
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	Status      Status    `json:"status"`
-	CreatedAt   time.Time `json:"created_at"`
-	ClosedAt    time.Time `json:"closed_at,omitempty"`
-	AssignedTo  string    `json:"assigned_to,omitempty"`
-	CreatedBy   string    `json:"created_by"`
+	ID          int           `json:"id"`  // Short alias, unique within a repository
+	UID         string        `json:"uid"` // Globally unique ID
+	Title       string        `json:"title"`
+	Description string        `json:"description"`
+	Status      Status        `json:"status"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+	ClosedAt    time.Time     `json:"closed_at,omitempty"`
+	AssignedTo  string        `json:"assigned_to,omitempty"`
+	CreatedBy   string        `json:"created_by"`
+	Comments    []Comment     `json:"comments,omitempty"`
+	Labels      []string      `json:"labels,omitempty"`
+	Priority    Priority      `json:"priority,omitempty"`
+	Milestone   string        `json:"milestone,omitempty"`
+	Commits     []string      `json:"commits,omitempty"`   // IDs of the commits that reference the issue
+	ClosedBy    string        `json:"closed_by,omitempty"` // ID of the commit that closed the issue, if any
+	Events      []Event       `json:"events,omitempty"`
+	Links       []Link        `json:"links,omitempty"`
+	State       string        `json:"state,omitempty"`        // Workflow state; see Workflow.StateOf
+	Source      string        `json:"source,omitempty"`       // Where an imported issue came from
+	Estimate    time.Duration `json:"estimate,omitempty"`     // Expected working time
+	TimeEntries []TimeEntry   `json:"time_entries,omitempty"` // Time spent working on the issue
 }
 
-// IssueManager manages issues in a repository
-type IssueManager struct {
-	RepoPath string
+// Filter selects issues when listing them. Empty fields match every issue.
+type Filter struct {
+	ShowClosed bool
+	Label      string
+	Milestone  string
+	Priority   Priority
+	Assignee   string
 }
 
-// NewIssueManager creates a new issue manager for a repository
-func NewIssueManager(repoPath string) *IssueManager {
-	return &IssueManager{
-		RepoPath: repoPath,
+// Matches reports whether an issue passes a filter
+func (f Filter) Matches(issue *Issue) bool {
+	switch {
+	case !f.ShowClosed && issue.Status == StatusClosed:
+		return false
+	case f.Label != "" && !issue.HasLabel(f.Label):
+		return false
+	case f.Milestone != "" && issue.Milestone != f.Milestone:
+		return false
+	case f.Priority != PriorityNone && issue.Priority != f.Priority:
+		return false
+	case f.Assignee != "" && issue.AssignedTo != f.Assignee:
+		return false
 	}
+	return true
 }
 
-// getIssuesDir returns the path to the issues directory
-func (im *IssueManager) getIssuesDir() string {
-	return filepath.Join(im.RepoPath, ".snap", "issues")
+// ShortUID returns an abbreviated form of the issue's UID
+func (i *Issue) ShortUID() string {
+	if len(i.UID) > 8 {
+		return i.UID[:8]
+	}
+	return i.UID
 }
 
-// getIssueFilePath returns the path to an issue file
-func (im *IssueManager) getIssueFilePath(id int) string {
-	return filepath.Join(im.getIssuesDir(), fmt.Sprintf("%d.json", id))
+// IssueManager manages issues in a repository. Issues are stored as objects
+// in the repository's object store, with their history on IssuesRef.
+type IssueManager struct {
+	RepoPath string
+	Author   string // Recorded on the commits that change issues
 }
 
-// getNextIssueID returns the next available issue ID
-func (im *IssueManager) getNextIssueID() (int, error) {
-	// Read all issue files
-	issuesDir := im.getIssuesDir()
-	files, err := os.ReadDir(issuesDir)
-	if err != nil {
-		if os.IsNotExist(err) {
-			// If the directory doesn't exist, create it
-			if err := os.MkdirAll(issuesDir, 0755); err != nil {
-				return 0, fmt.Errorf("failed to create issues directory: %w", err)
-			}
-			return 1, nil
-		}
-		return 0, fmt.Errorf("failed to read issues directory: %w", err)
-	}
-
-	// Find the highest issue ID
-	maxID := 0
-	for _, file := range files {
-		if file.IsDir() {
-			continue
-		}
-
-		var id int
-		_, err := fmt.Sscanf(file.Name(), "%d.json", &id)
-		if err != nil {
-			continue
-		}
-
-		if id > maxID {
-			maxID = id
-		}
+// NewIssueManager creates a new issue manager for a repository
+func NewIssueManager(repoPath string) *IssueManager {
+	return &IssueManager{
+		RepoPath: repoPath,
 	}
-
-	return maxID + 1, nil
 }
 
 // CreateIssue creates a new issue
 func (im *IssueManager) CreateIssue(title, description, createdBy string) (*Issue, error) {
-	// Get next issue ID
-	id, err := im.getNextIssueID()
+	snap, err := im.current()
+	if err != nil {
+		return nil, err
+	}
+
+	uid, err := newUID()
 	if err != nil {
 		return nil, err
 	}
 
 	// Create issue
+	now := time.Now()
 	issue := &Issue{
-		ID:          id,
+		ID:          snap.nextID(),
+		UID:         uid,
 		Title:       title,
 		Description: description,
 		Status:      StatusOpen,
-		CreatedAt:   time.Now(),
+		CreatedAt:   now,
+		UpdatedAt:   now,
 		CreatedBy:   createdBy,
 	}
 
 	// Save issue
-	if err := im.SaveIssue(issue); err != nil {
+	snap.Issues[issue.UID] = issue
+	author := im.Author
+	if author == "" {
+		author = createdBy
+	}
+	if err := im.commit(snap, author, fmt.Sprintf("📝 Open issue #%d: %s", issue.ID, issue.Title), ""); err != nil {
 		return nil, err
 	}
 
 	return issue, nil
 }
 
-// SaveIssue saves an issue to disk
+// SaveIssue saves a changed issue
 func (im *IssueManager) SaveIssue(issue *Issue) error {
-	// Create issues directory if it doesn't exist
-	issuesDir := im.getIssuesDir()
-	if err := os.MkdirAll(issuesDir, 0755); err != nil {
-		return fmt.Errorf("failed to create issues directory: %w", err)
-	}
+	return im.saveIssue(issue, im.author(), fmt.Sprintf("📝 Update issue #%d", issue.ID))
+}
 
-	// Marshal issue to JSON
-	data, err := json.MarshalIndent(issue, "", "  ")
+// saveIssue saves a changed issue with a specific commit author and message
+func (im *IssueManager) saveIssue(issue *Issue, author, message string) error {
+	snap, err := im.current()
 	if err != nil {
-		return fmt.Errorf("failed to marshal issue: %w", err)
+		return err
 	}
 
-	// Write issue to file
-	filePath := im.getIssueFilePath(issue.ID)
-	if err := os.WriteFile(filePath, data, 0644); err != nil {
-		return fmt.Errorf("failed to write issue file: %w", err)
+	if issue.UID == "" {
+		uid, err := newUID()
+		if err != nil {
+			return err
+		}
+		issue.UID = uid
+	}
+	issue.UpdatedAt = time.Now()
+	if err := recordChanges(snap.Issues[issue.UID], issue, author, issue.UpdatedAt); err != nil {
+		return err
 	}
+	snap.Issues[issue.UID] = issue
 
-	return nil
+	return im.commit(snap, author, message, "")
 }
 
-// GetIssue gets an issue by ID
+// GetIssue gets an issue by its alias
 func (im *IssueManager) GetIssue(id int) (*Issue, error) {
-	// Read issue file
-	filePath := im.getIssueFilePath(id)
-	data, err := os.ReadFile(filePath)
+	snap, err := im.current()
 	if err != nil {
-		return nil, fmt.Errorf("failed to read issue file: %w", err)
-	}
-
-	// Unmarshal issue from JSON
-	var issue Issue
-	if err := json.Unmarshal(data, &issue); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal issue: %w", err)
+		return nil, err
 	}
 
-	return &issue, nil
+	return snap.issue(id)
 }
 
-// ListIssues lists all issues
-func (im *IssueManager) ListIssues(showClosed bool) ([]*Issue, error) {
-	// Read all issue files
-	issuesDir := im.getIssuesDir()
-	files, err := os.ReadDir(issuesDir)
+// ResolveIssue finds an issue by alias ("12" or "#12") or by a prefix of its UID
+func (im *IssueManager) ResolveIssue(ref string) (*Issue, error) {
+	ref = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(ref), "#"))
+	if ref == "" {
+		return nil, fmt.Errorf("empty issue reference")
+	}
+
+	snap, err := im.current()
 	if err != nil {
-		if os.IsNotExist(err) {
-			// If the directory doesn't exist, return an empty list
-			return []*Issue{}, nil
-		}
-		return nil, fmt.Errorf("failed to read issues directory: %w", err)
+		return nil, err
 	}
 
-	// Read each issue file
-	var issues []*Issue
-	for _, file := range files {
-		if file.IsDir() {
-			continue
+	// Numbers are aliases first
+	if id, err := strconv.Atoi(ref); err == nil {
+		for _, issue := range snap.Issues {
+			if issue.ID == id {
+				return issue, nil
+			}
 		}
+	}
 
-		var id int
-		_, err := fmt.Sscanf(file.Name(), "%d.json", &id)
-		if err != nil {
-			continue
+	// Otherwise look for a UID prefix
+	var matches []*Issue
+	if len(ref) >= 4 {
+		for _, issue := range snap.sortedIssues() {
+			if strings.HasPrefix(issue.UID, ref) {
+				matches = append(matches, issue)
+			}
 		}
+	}
 
-		issue, err := im.GetIssue(id)
-		if err != nil {
-			continue
-		}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("issue %s not found", ref)
+	case 1:
+		return matches[0], nil
+	default:
+		return nil, fmt.Errorf("issue ID %s is ambiguous (%d matches)", ref, len(matches))
+	}
+}
 
-		// Skip closed issues if showClosed is false
-		if !showClosed && issue.Status == StatusClosed {
-			continue
-		}
+// ListIssues lists all issues, ordered by alias
+func (im *IssueManager) ListIssues(showClosed bool) ([]*Issue, error) {
+	return im.FilterIssues(Filter{ShowClosed: showClosed})
+}
 
-		issues = append(issues, issue)
+// FilterIssues lists the issues that pass a filter, ordered by alias
+func (im *IssueManager) FilterIssues(filter Filter) ([]*Issue, error) {
+	snap, err := im.current()
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []*Issue
+	for _, issue := range snap.sortedIssues() {
+		if filter.Matches(issue) {
+			issues = append(issues, issue)
+		}
 	}
 
 	return issues, nil
 }
 
-// CloseIssue closes an issue
-func (im *IssueManager) CloseIssue(id int) error {
+// CloseIssue closes an issue. commitID names the commit that fixed it, if any.
+func (im *IssueManager) CloseIssue(id int, commitID string) error {
 	// Get issue
 	issue, err := im.GetIssue(id)
 	if err != nil {
 		return err
 	}
 
+	if issue.Status == StatusClosed {
+		return fmt.Errorf("issue #%d is already closed", issue.ID)
+	}
+
 	// Update issue
 	issue.Status = StatusClosed
 	issue.ClosedAt = time.Now()
+	issue.ClosedBy = commitID
+	message := fmt.Sprintf("📝 Close issue #%d", issue.ID)
+	if commitID != "" {
+		if !issue.HasCommit(commitID) {
+			issue.Commits = append(issue.Commits, commitID)
+		}
+		message = fmt.Sprintf("📝 Close issue #%d in commit %s", issue.ID, shortCommitID(commitID))
+	}
 
 	// Save issue
-	if err := im.SaveIssue(issue); err != nil {
+	if err := im.saveIssue(issue, im.author(), message); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-// AssignIssue assigns an issue to a user
+// AssignIssue assigns an issue to a user, or to a configured team with an
+// assignee such as team:backend
 func (im *IssueManager) AssignIssue(id int, assignee string) error {
+	// Check team
+	if team, ok := strings.CutPrefix(assignee, TeamAssigneePrefix); ok {
+		if err := im.checkTeam(team); err != nil {
+			return err
+		}
+	}
+
 	// Get issue
 	issue, err := im.GetIssue(id)
 	if err != nil {
@@ -1,10 +1,15 @@
 package issue
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 	"time"
+
+	"github.com/stanlocht/snap/pkg/repository"
 )
 
 func TestNewIssueManager(t *testing.T) {
@@ -69,10 +74,15 @@ func TestCreateAndGetIssue(t *testing.T) {
 		t.Errorf("Expected issue created by to be 'testuser', got '%s'", issue.CreatedBy)
 	}
 
-	// Check if issue file was created
-	issueFilePath := filepath.Join(tempDir, ".snap", "issues", "1.json")
-	if _, err := os.Stat(issueFilePath); os.IsNotExist(err) {
-		t.Errorf("Expected issue file to exist at %s", issueFilePath)
+	// Check if the issue was committed to the issues reference
+	issueRefPath := filepath.Join(tempDir, ".snap", "refs", "snap", "issues")
+	if _, err := os.Stat(issueRefPath); os.IsNotExist(err) {
+		t.Errorf("Expected issues reference to exist at %s", issueRefPath)
+	}
+
+	// Check if the issue got a globally unique ID
+	if len(issue.UID) != 20 {
+		t.Errorf("Expected a 20 character UID, got '%s'", issue.UID)
 	}
 
 	// Get the issue
@@ -135,7 +145,7 @@ func TestListIssues(t *testing.T) {
 	}
 
 	// Close issue 2
-	err = manager.CloseIssue(2)
+	err = manager.CloseIssue(2, "")
 	if err != nil {
 		t.Fatalf("Failed to close issue 2: %v", err)
 	}
@@ -186,7 +196,7 @@ func TestCloseIssue(t *testing.T) {
 	}
 
 	// Close the issue
-	err = manager.CloseIssue(1)
+	err = manager.CloseIssue(1, "")
 	if err != nil {
 		t.Fatalf("Failed to close issue: %v", err)
 	}
@@ -246,4 +256,82 @@ func TestAssignIssue(t *testing.T) {
 	if issue.AssignedTo != "assignee" {
 		t.Errorf("Expected assigned to to be 'assignee', got '%s'", issue.AssignedTo)
 	}
+	if issue.AssignedTeam() != "" {
+		t.Errorf("Expected no team, got '%s'", issue.AssignedTeam())
+	}
+}
+
+func TestAssignIssueToTeam(t *testing.T) {
+	tempDir := t.TempDir()
+	manager := NewIssueManager(tempDir)
+	if _, err := manager.CreateIssue("Test Issue", "This is a test issue", "testuser"); err != nil {
+		t.Fatalf("Failed to create issue: %v", err)
+	}
+
+	// Teams must be configured
+	if err := manager.AssignIssue(1, "team:backend"); err == nil {
+		t.Errorf("Expected an unknown team to be rejected")
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, ".snap", "config"), []byte("[team \"backend\"]\n\tmembers = alice, bob\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if err := manager.AssignIssue(1, "team:backend"); err != nil {
+		t.Fatalf("Failed to assign issue: %v", err)
+	}
+
+	issue, err := manager.GetIssue(1)
+	if err != nil {
+		t.Fatalf("Failed to get issue: %v", err)
+	}
+	if issue.AssignedTo != "team:backend" || issue.AssignedTeam() != "backend" {
+		t.Errorf("Expected the issue to be assigned to team backend, got '%s'", issue.AssignedTo)
+	}
+}
+
+func TestConcurrentEdits(t *testing.T) {
+	manager := NewIssueManager(t.TempDir())
+	if _, err := manager.CreateIssue("First", "", "alice"); err != nil {
+		t.Fatalf("Failed to create issue: %v", err)
+	}
+
+	// A snapshot read before another edit can't be committed over it
+	stale, err := manager.current()
+	if err != nil {
+		t.Fatalf("Failed to read issues: %v", err)
+	}
+	if _, err := manager.CreateIssue("Second", "", "bob"); err != nil {
+		t.Fatalf("Failed to create issue: %v", err)
+	}
+	if err := manager.commit(stale, "alice", "📝 Stale edit", ""); !errors.Is(err, repository.ErrRefChanged) {
+		t.Errorf("Expected committing a stale snapshot to fail, got %v", err)
+	}
+
+	// Edits racing each other either succeed or fail, but are never lost
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	created := 2
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := manager.CreateIssue(fmt.Sprintf("Race %d", i), "", "alice")
+			if err != nil && !errors.Is(err, repository.ErrRefChanged) {
+				t.Errorf("Expected a racing edit to succeed or be refused, got %v", err)
+			}
+			if err == nil {
+				mu.Lock()
+				created++
+				mu.Unlock()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	issues, err := manager.ListIssues(true)
+	if err != nil {
+		t.Fatalf("Failed to list issues: %v", err)
+	}
+	if len(issues) != created {
+		t.Errorf("Expected the %d successful edits to be kept, got %d issues", created, len(issues))
+	}
 }
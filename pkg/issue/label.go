@@ -0,0 +1,287 @@
+package issue
+
+import (
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Label is a label defined in a repository that issues can carry
+type Label struct {
+	Name        string `json:"name"`
+	Color       string `json:"color"` // Hex color, e.g. "#d73a4a"
+	Description string `json:"description,omitempty"`
+}
+
+// Priority is the priority of an issue
+type Priority string
+
+const (
+	// PriorityNone means no priority was set
+	PriorityNone Priority = ""
+	// PriorityLow is for issues that can wait
+	PriorityLow Priority = "low"
+	// PriorityMedium is for normal issues
+	PriorityMedium Priority = "medium"
+	// PriorityHigh is for issues that should be worked on soon
+	PriorityHigh Priority = "high"
+	// PriorityCritical is for issues that block everything else
+	PriorityCritical Priority = "critical"
+)
+
+// Priorities lists the priority levels from lowest to highest
+var Priorities = []Priority{PriorityLow, PriorityMedium, PriorityHigh, PriorityCritical}
+
+// labelColorPattern matches the colors labels may have
+var labelColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// labelPalette is used for labels created without a color
+var labelPalette = []string{
+	"#d73a4a", "#0075ca", "#a2eeef", "#7057ff", "#008672",
+	"#e4e669", "#d876e3", "#f9d0c4", "#fbca04", "#0e8a16",
+}
+
+// ParsePriority parses a priority level. "none" and the empty string clear the priority.
+func ParsePriority(s string) (Priority, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" || s == "none" {
+		return PriorityNone, nil
+	}
+	for _, priority := range Priorities {
+		if string(priority) == s {
+			return priority, nil
+		}
+	}
+	return PriorityNone, fmt.Errorf("unknown priority %q (use low, medium, high, critical or none)", s)
+}
+
+// Rank orders priorities: 0 for none, up to 4 for critical
+func (p Priority) Rank() int {
+	for i, priority := range Priorities {
+		if priority == p {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// String returns the priority's name
+func (p Priority) String() string {
+	if p == PriorityNone {
+		return "none"
+	}
+	return string(p)
+}
+
+// HasLabel reports whether an issue carries a label
+func (i *Issue) HasLabel(name string) bool {
+	for _, label := range i.Labels {
+		if label == name {
+			return true
+		}
+	}
+	return false
+}
+
+// validateName checks the name of a label or milestone, which is also its path in the issue tree
+func validateName(kind, name string) error {
+	switch {
+	case strings.TrimSpace(name) == "":
+		return fmt.Errorf("%s name is empty", kind)
+	case name != strings.TrimSpace(name):
+		return fmt.Errorf("%s name %q has leading or trailing spaces", kind, name)
+	case strings.ContainsAny(name, "/\\\x00") || name == "." || name == "..":
+		return fmt.Errorf("%s name %q is not allowed", kind, name)
+	}
+	return nil
+}
+
+// defaultLabelColor picks a color for a label from its name
+func defaultLabelColor(name string) string {
+	hash := fnv.New32a()
+	hash.Write([]byte(name))
+	return labelPalette[hash.Sum32()%uint32(len(labelPalette))]
+}
+
+// CreateLabel defines a new label. An empty color picks one from the label's name.
+func (im *IssueManager) CreateLabel(name, color, description string) (*Label, error) {
+	if err := validateName("label", name); err != nil {
+		return nil, err
+	}
+	if color == "" {
+		color = defaultLabelColor(name)
+	}
+	if !labelColorPattern.MatchString(color) {
+		return nil, fmt.Errorf("invalid label color %q (use #rrggbb)", color)
+	}
+
+	snap, err := im.current()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := snap.Labels[name]; ok {
+		return nil, fmt.Errorf("label %s already exists", name)
+	}
+
+	label := &Label{Name: name, Color: strings.ToLower(color), Description: description}
+	snap.Labels[name] = label
+	if err := im.commit(snap, im.author(), fmt.Sprintf("🏷️ Create label %s", name), ""); err != nil {
+		return nil, err
+	}
+
+	return label, nil
+}
+
+// UpdateLabel changes the color or description of a label. Empty values are left unchanged.
+func (im *IssueManager) UpdateLabel(name, color, description string) (*Label, error) {
+	if color != "" && !labelColorPattern.MatchString(color) {
+		return nil, fmt.Errorf("invalid label color %q (use #rrggbb)", color)
+	}
+
+	snap, err := im.current()
+	if err != nil {
+		return nil, err
+	}
+	label, ok := snap.Labels[name]
+	if !ok {
+		return nil, fmt.Errorf("label %s not found", name)
+	}
+
+	if color != "" {
+		label.Color = strings.ToLower(color)
+	}
+	if description != "" {
+		label.Description = description
+	}
+	if err := im.commit(snap, im.author(), fmt.Sprintf("🏷️ Update label %s", name), ""); err != nil {
+		return nil, err
+	}
+
+	return label, nil
+}
+
+// GetLabel gets a label by name
+func (im *IssueManager) GetLabel(name string) (*Label, error) {
+	snap, err := im.current()
+	if err != nil {
+		return nil, err
+	}
+	label, ok := snap.Labels[name]
+	if !ok {
+		return nil, fmt.Errorf("label %s not found", name)
+	}
+	return label, nil
+}
+
+// ListLabels lists the labels defined in the repository, ordered by name
+func (im *IssueManager) ListLabels() ([]*Label, error) {
+	snap, err := im.current()
+	if err != nil {
+		return nil, err
+	}
+
+	labels := make([]*Label, 0, len(snap.Labels))
+	for _, label := range snap.Labels {
+		labels = append(labels, label)
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		return labels[i].Name < labels[j].Name
+	})
+
+	return labels, nil
+}
+
+// DeleteLabel deletes a label and removes it from every issue
+func (im *IssueManager) DeleteLabel(name string) error {
+	snap, err := im.current()
+	if err != nil {
+		return err
+	}
+	if _, ok := snap.Labels[name]; !ok {
+		return fmt.Errorf("label %s not found", name)
+	}
+
+	delete(snap.Labels, name)
+	now := time.Now()
+	for _, issue := range snap.Issues {
+		if issue.HasLabel(name) {
+			old, err := copyIssue(issue)
+			if err != nil {
+				return err
+			}
+			issue.Labels = removeString(issue.Labels, name)
+			issue.UpdatedAt = now
+			if err := recordChanges(old, issue, im.author(), now); err != nil {
+				return err
+			}
+		}
+	}
+
+	return im.commit(snap, im.author(), fmt.Sprintf("🏷️ Delete label %s", name), "")
+}
+
+// AddLabels adds labels to an issue. The labels must be defined.
+func (im *IssueManager) AddLabels(id int, names ...string) error {
+	snap, err := im.current()
+	if err != nil {
+		return err
+	}
+	issue, err := snap.issue(id)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		if _, ok := snap.Labels[name]; !ok {
+			return fmt.Errorf("label %s not found (create it with 'snap issue label create')", name)
+		}
+		if !issue.HasLabel(name) {
+			issue.Labels = append(issue.Labels, name)
+		}
+	}
+	sort.Strings(issue.Labels)
+
+	return im.saveIssue(issue, im.author(), fmt.Sprintf("🏷️ Label issue #%d: %s", issue.ID, strings.Join(names, ", ")))
+}
+
+// RemoveLabels removes labels from an issue
+func (im *IssueManager) RemoveLabels(id int, names ...string) error {
+	issue, err := im.GetIssue(id)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		if !issue.HasLabel(name) {
+			return fmt.Errorf("issue #%d does not have label %s", issue.ID, name)
+		}
+		issue.Labels = removeString(issue.Labels, name)
+	}
+
+	return im.saveIssue(issue, im.author(), fmt.Sprintf("🏷️ Unlabel issue #%d: %s", issue.ID, strings.Join(names, ", ")))
+}
+
+// SetPriority sets the priority of an issue
+func (im *IssueManager) SetPriority(id int, priority Priority) error {
+	issue, err := im.GetIssue(id)
+	if err != nil {
+		return err
+	}
+
+	issue.Priority = priority
+	return im.saveIssue(issue, im.author(), fmt.Sprintf("📝 Set priority of issue #%d to %s", issue.ID, priority))
+}
+
+// removeString returns a list without any occurrence of s
+func removeString(list []string, s string) []string {
+	var result []string
+	for _, item := range list {
+		if item != s {
+			result = append(result, item)
+		}
+	}
+	return result
+}
@@ -0,0 +1,237 @@
+package issue
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLabelsAndPriorities(t *testing.T) {
+	manager := NewIssueManager(t.TempDir())
+
+	issue, err := manager.CreateIssue("Crash on start", "", "alice")
+	if err != nil {
+		t.Fatalf("Failed to create issue: %v", err)
+	}
+
+	// Labels must be defined before they are used
+	if err := manager.AddLabels(issue.ID, "bug"); err == nil {
+		t.Errorf("Expected adding an undefined label to fail")
+	}
+
+	label, err := manager.CreateLabel("bug", "", "Something is broken")
+	if err != nil {
+		t.Fatalf("Failed to create label: %v", err)
+	}
+	if !labelColorPattern.MatchString(label.Color) {
+		t.Errorf("Expected a default color, got %q", label.Color)
+	}
+	if _, err := manager.CreateLabel("bug", "#ffffff", ""); err == nil {
+		t.Errorf("Expected creating a duplicate label to fail")
+	}
+	if _, err := manager.CreateLabel("ui", "red", ""); err == nil {
+		t.Errorf("Expected an invalid color to be rejected")
+	}
+	if _, err := manager.CreateLabel("a/b", "", ""); err == nil {
+		t.Errorf("Expected a label name with a slash to be rejected")
+	}
+	if _, err := manager.CreateLabel("ui", "#00FF00", ""); err != nil {
+		t.Fatalf("Failed to create label: %v", err)
+	}
+
+	if err := manager.AddLabels(issue.ID, "ui", "bug"); err != nil {
+		t.Fatalf("Failed to add labels: %v", err)
+	}
+	priority, err := ParsePriority("High")
+	if err != nil {
+		t.Fatalf("Failed to parse priority: %v", err)
+	}
+	if err := manager.SetPriority(issue.ID, priority); err != nil {
+		t.Fatalf("Failed to set priority: %v", err)
+	}
+	if _, err := ParsePriority("urgent"); err == nil {
+		t.Errorf("Expected an unknown priority to be rejected")
+	}
+
+	issue, err = manager.GetIssue(issue.ID)
+	if err != nil {
+		t.Fatalf("Failed to get issue: %v", err)
+	}
+	if len(issue.Labels) != 2 || issue.Labels[0] != "bug" || issue.Labels[1] != "ui" {
+		t.Errorf("Expected labels [bug ui], got %v", issue.Labels)
+	}
+	if issue.Priority != PriorityHigh || issue.Priority.Rank() <= PriorityMedium.Rank() {
+		t.Errorf("Expected priority high, got %s", issue.Priority)
+	}
+
+	// Filters
+	if _, err := manager.CreateIssue("Typo in docs", "", "bob"); err != nil {
+		t.Fatalf("Failed to create issue: %v", err)
+	}
+	issues, err := manager.FilterIssues(Filter{Label: "bug"})
+	if err != nil {
+		t.Fatalf("Failed to filter issues: %v", err)
+	}
+	if len(issues) != 1 || issues[0].ID != issue.ID {
+		t.Errorf("Expected only issue #%d to have label bug, got %d issues", issue.ID, len(issues))
+	}
+	issues, err = manager.FilterIssues(Filter{Priority: PriorityLow})
+	if err != nil {
+		t.Fatalf("Failed to filter issues: %v", err)
+	}
+	if len(issues) != 0 {
+		t.Errorf("Expected no low priority issues, got %d", len(issues))
+	}
+
+	// Deleting a label removes it from issues
+	if err := manager.DeleteLabel("ui"); err != nil {
+		t.Fatalf("Failed to delete label: %v", err)
+	}
+	issue, err = manager.GetIssue(issue.ID)
+	if err != nil {
+		t.Fatalf("Failed to get issue: %v", err)
+	}
+	if len(issue.Labels) != 1 || issue.Labels[0] != "bug" {
+		t.Errorf("Expected labels [bug] after deleting ui, got %v", issue.Labels)
+	}
+	labels, err := manager.ListLabels()
+	if err != nil {
+		t.Fatalf("Failed to list labels: %v", err)
+	}
+	if len(labels) != 1 {
+		t.Errorf("Expected 1 label, got %d", len(labels))
+	}
+}
+
+func TestMilestoneProgress(t *testing.T) {
+	manager := NewIssueManager(t.TempDir())
+
+	due := time.Now().Add(-24 * time.Hour)
+	if _, err := manager.CreateMilestone("v1.0", "First release", due); err != nil {
+		t.Fatalf("Failed to create milestone: %v", err)
+	}
+	if _, err := manager.CreateMilestone("v2.0", "", time.Time{}); err != nil {
+		t.Fatalf("Failed to create milestone: %v", err)
+	}
+
+	for _, title := range []string{"One", "Two", "Three", "Four"} {
+		issue, err := manager.CreateIssue(title, "", "alice")
+		if err != nil {
+			t.Fatalf("Failed to create issue: %v", err)
+		}
+		if title != "Four" {
+			if err := manager.SetMilestone(issue.ID, "v1.0"); err != nil {
+				t.Fatalf("Failed to set milestone: %v", err)
+			}
+		}
+	}
+	if err := manager.SetMilestone(4, "v3.0"); err == nil {
+		t.Errorf("Expected an unknown milestone to be rejected")
+	}
+	if err := manager.CloseIssue(1, ""); err != nil {
+		t.Fatalf("Failed to close issue: %v", err)
+	}
+
+	progress, err := manager.MilestoneProgress("v1.0")
+	if err != nil {
+		t.Fatalf("Failed to get progress: %v", err)
+	}
+	if progress.Total != 3 || progress.Closed != 1 || progress.Percent() != 33 {
+		t.Errorf("Expected 1 of 3 closed (33%%), got %d of %d (%d%%)", progress.Closed, progress.Total, progress.Percent())
+	}
+
+	milestones, err := manager.ListMilestones(false)
+	if err != nil {
+		t.Fatalf("Failed to list milestones: %v", err)
+	}
+	if len(milestones) != 2 || milestones[0].Name != "v1.0" {
+		t.Fatalf("Expected v1.0 first, got %d milestones", len(milestones))
+	}
+	if !milestones[0].Overdue(time.Now()) || milestones[1].Overdue(time.Now()) {
+		t.Errorf("Expected only v1.0 to be overdue")
+	}
+
+	if err := manager.CloseMilestone("v1.0"); err != nil {
+		t.Fatalf("Failed to close milestone: %v", err)
+	}
+	milestones, err = manager.ListMilestones(false)
+	if err != nil {
+		t.Fatalf("Failed to list milestones: %v", err)
+	}
+	if len(milestones) != 1 || milestones[0].Name != "v2.0" {
+		t.Errorf("Expected only v2.0 to be open, got %d milestones", len(milestones))
+	}
+
+	if err := manager.SetMilestone(2, ""); err != nil {
+		t.Fatalf("Failed to unset milestone: %v", err)
+	}
+	progress, err = manager.MilestoneProgress("v1.0")
+	if err != nil {
+		t.Fatalf("Failed to get progress: %v", err)
+	}
+	if progress.Total != 2 || progress.Percent() != 50 {
+		t.Errorf("Expected 1 of 2 closed (50%%), got %d of %d", progress.Closed, progress.Total)
+	}
+}
+
+func TestMergeIssuesLabels(t *testing.T) {
+	manager := NewIssueManager(t.TempDir())
+
+	issue, err := manager.CreateIssue("Slow search", "", "alice")
+	if err != nil {
+		t.Fatalf("Failed to create issue: %v", err)
+	}
+	for _, name := range []string{"bug", "perf", "ui"} {
+		if _, err := manager.CreateLabel(name, "", ""); err != nil {
+			t.Fatalf("Failed to create label: %v", err)
+		}
+	}
+	if err := manager.AddLabels(issue.ID, "bug"); err != nil {
+		t.Fatalf("Failed to add labels: %v", err)
+	}
+
+	theirsID := divergeIssues(t, manager, func() {
+		if err := manager.AddLabels(issue.ID, "perf"); err != nil {
+			t.Fatalf("Failed to add labels: %v", err)
+		}
+		if _, err := manager.CreateMilestone("v1.0", "", time.Time{}); err != nil {
+			t.Fatalf("Failed to create milestone: %v", err)
+		}
+	}, func() {
+		if err := manager.AddLabels(issue.ID, "ui"); err != nil {
+			t.Fatalf("Failed to add labels: %v", err)
+		}
+		if err := manager.RemoveLabels(issue.ID, "bug"); err != nil {
+			t.Fatalf("Failed to remove labels: %v", err)
+		}
+		if err := manager.DeleteLabel("ui"); err != nil {
+			t.Fatalf("Failed to delete label: %v", err)
+		}
+	})
+
+	result, err := manager.Merge(theirsID)
+	if err != nil {
+		t.Fatalf("Failed to merge issues: %v", err)
+	}
+	if len(result.Conflicts) != 0 {
+		t.Errorf("Expected label changes to merge without conflicts, got %v", result.Conflicts)
+	}
+
+	issue, err = manager.GetIssue(issue.ID)
+	if err != nil {
+		t.Fatalf("Failed to get issue: %v", err)
+	}
+	if len(issue.Labels) != 1 || issue.Labels[0] != "perf" {
+		t.Errorf("Expected labels [perf], got %v", issue.Labels)
+	}
+
+	labels, err := manager.ListLabels()
+	if err != nil {
+		t.Fatalf("Failed to list labels: %v", err)
+	}
+	if len(labels) != 2 {
+		t.Errorf("Expected the deleted label to stay deleted, got %d labels", len(labels))
+	}
+	if _, err := manager.GetMilestone("v1.0"); err != nil {
+		t.Errorf("Expected our milestone to survive the merge: %v", err)
+	}
+}
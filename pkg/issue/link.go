@@ -0,0 +1,148 @@
+package issue
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// referencePattern matches issue references in commit messages: "#12", and
+// closing references such as "fixes #12", "closes #12" or "resolved: #12"
+var referencePattern = regexp.MustCompile(`(?i)(?:^|[^\w&/#])(?:(fix|fixes|fixed|close|closes|closed|resolve|resolves|resolved):?\s+)?#(\d+)\b`)
+
+// Reference is an issue referenced from a commit message
+type Reference struct {
+	ID     int
+	Closes bool
+}
+
+// LinkResult describes the issues a commit was linked to
+type LinkResult struct {
+	Linked  []int // Issues the commit was linked to
+	Closed  []int // Issues the commit closed
+	Missing []int // Referenced issues that don't exist
+}
+
+// ParseReferences finds the issues a commit message refers to, in order of first mention.
+// An issue mentioned several times closes if any mention is a closing one.
+func ParseReferences(message string) []Reference {
+	var refs []Reference
+	index := make(map[int]int)
+	for _, match := range referencePattern.FindAllStringSubmatch(message, -1) {
+		id, err := strconv.Atoi(match[2])
+		if err != nil || id <= 0 {
+			continue
+		}
+		closes := match[1] != ""
+
+		if i, ok := index[id]; ok {
+			refs[i].Closes = refs[i].Closes || closes
+			continue
+		}
+		index[id] = len(refs)
+		refs = append(refs, Reference{ID: id, Closes: closes})
+	}
+	return refs
+}
+
+// HasCommit reports whether a commit is linked to an issue
+func (i *Issue) HasCommit(commitID string) bool {
+	for _, id := range i.Commits {
+		if id == commitID {
+			return true
+		}
+	}
+	return false
+}
+
+// LinkCommit links a commit to the issues its message refers to and closes
+// the open issues it says it fixes
+func (im *IssueManager) LinkCommit(commitID, message string) (*LinkResult, error) {
+	result := &LinkResult{}
+	refs := ParseReferences(message)
+	if len(refs) == 0 {
+		return result, nil
+	}
+
+	snap, err := im.current()
+	if err != nil {
+		return nil, err
+	}
+
+	// Record the links in one change
+	var toClose []int
+	now := time.Now()
+	for _, ref := range refs {
+		issue, err := snap.issue(ref.ID)
+		if err != nil {
+			result.Missing = append(result.Missing, ref.ID)
+			continue
+		}
+		if !issue.HasCommit(commitID) {
+			old, err := copyIssue(issue)
+			if err != nil {
+				return nil, err
+			}
+			issue.Commits = append(issue.Commits, commitID)
+			issue.UpdatedAt = now
+			if err := recordChanges(old, issue, im.author(), now); err != nil {
+				return nil, err
+			}
+			result.Linked = append(result.Linked, issue.ID)
+		}
+		if ref.Closes && issue.Status != StatusClosed {
+			toClose = append(toClose, issue.ID)
+		}
+	}
+	if len(result.Linked) > 0 {
+		message := fmt.Sprintf("🔗 Link commit %s to %s", shortCommitID(commitID), formatIssueList(result.Linked))
+		if err := im.commit(snap, im.author(), message, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	// Then close the issues the commit fixes
+	for _, id := range toClose {
+		if err := im.CloseIssue(id, commitID); err != nil {
+			return nil, err
+		}
+		result.Closed = append(result.Closed, id)
+	}
+
+	return result, nil
+}
+
+// IssuesForCommit lists the issues a commit is linked to, ordered by alias
+func (im *IssueManager) IssuesForCommit(commitID string) ([]*Issue, error) {
+	snap, err := im.current()
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []*Issue
+	for _, issue := range snap.sortedIssues() {
+		if issue.HasCommit(commitID) {
+			issues = append(issues, issue)
+		}
+	}
+	return issues, nil
+}
+
+// shortCommitID abbreviates a commit ID for messages
+func shortCommitID(id string) string {
+	if len(id) > 7 {
+		return id[:7]
+	}
+	return id
+}
+
+// formatIssueList formats issue aliases as "#1, #2"
+func formatIssueList(ids []int) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = fmt.Sprintf("#%d", id)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,87 @@
+package issue
+
+import (
+	"testing"
+)
+
+func TestParseReferences(t *testing.T) {
+	tests := []struct {
+		message  string
+		expected []Reference
+	}{
+		{"✨ Add search", nil},
+		{"🐛 Fix crash (#12)", []Reference{{ID: 12}}},
+		{"🐛 Fixes #3 and closes #4, see #5", []Reference{{ID: 3, Closes: true}, {ID: 4, Closes: true}, {ID: 5}}},
+		{"🐛 Resolved: #7", []Reference{{ID: 7, Closes: true}}},
+		{"📝 See #2, then fix #2", []Reference{{ID: 2, Closes: true}}},
+		{"📝 Prefixes #8 but not abc#9, &#10; or ##11", []Reference{{ID: 8}}},
+		{"#1 at the start", []Reference{{ID: 1}}},
+	}
+
+	for _, test := range tests {
+		refs := ParseReferences(test.message)
+		if len(refs) != len(test.expected) {
+			t.Errorf("ParseReferences(%q) = %v, expected %v", test.message, refs, test.expected)
+			continue
+		}
+		for i := range refs {
+			if refs[i] != test.expected[i] {
+				t.Errorf("ParseReferences(%q) = %v, expected %v", test.message, refs, test.expected)
+				break
+			}
+		}
+	}
+}
+
+func TestLinkCommit(t *testing.T) {
+	manager := NewIssueManager(t.TempDir())
+
+	for _, title := range []string{"Crash", "Slow", "Typo"} {
+		if _, err := manager.CreateIssue(title, "", "alice"); err != nil {
+			t.Fatalf("Failed to create issue: %v", err)
+		}
+	}
+
+	result, err := manager.LinkCommit("abc1234567", "🐛 Fix #1, see #2 and #9")
+	if err != nil {
+		t.Fatalf("Failed to link commit: %v", err)
+	}
+	if len(result.Linked) != 2 || len(result.Closed) != 1 || result.Closed[0] != 1 {
+		t.Errorf("Expected #1 and #2 linked and #1 closed, got %+v", result)
+	}
+	if len(result.Missing) != 1 || result.Missing[0] != 9 {
+		t.Errorf("Expected #9 to be missing, got %v", result.Missing)
+	}
+
+	issue, err := manager.GetIssue(1)
+	if err != nil {
+		t.Fatalf("Failed to get issue: %v", err)
+	}
+	if issue.Status != StatusClosed || issue.ClosedBy != "abc1234567" {
+		t.Errorf("Expected issue #1 to be closed by abc1234567, got %s by %q", issue.Status, issue.ClosedBy)
+	}
+	issue, err = manager.GetIssue(2)
+	if err != nil {
+		t.Fatalf("Failed to get issue: %v", err)
+	}
+	if issue.Status != StatusOpen || !issue.HasCommit("abc1234567") {
+		t.Errorf("Expected issue #2 to stay open with the commit linked")
+	}
+
+	// Linking again changes nothing, and closed issues stay closed by their first commit
+	result, err = manager.LinkCommit("abc1234567", "🐛 Fix #1, see #2 and #9")
+	if err != nil {
+		t.Fatalf("Failed to link commit: %v", err)
+	}
+	if len(result.Linked) != 0 || len(result.Closed) != 0 {
+		t.Errorf("Expected nothing to change on a second link, got %+v", result)
+	}
+
+	issues, err := manager.IssuesForCommit("abc1234567")
+	if err != nil {
+		t.Fatalf("Failed to get issues for commit: %v", err)
+	}
+	if len(issues) != 2 || issues[0].ID != 1 || issues[1].ID != 2 {
+		t.Errorf("Expected issues #1 and #2 for the commit, got %d issues", len(issues))
+	}
+}
@@ -0,0 +1,450 @@
+package issue
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// Merge outcomes
+const (
+	// MergeUpToDate means the local issues already contained the other side's changes
+	MergeUpToDate = "up-to-date"
+	// MergeFastForward means the local issues were moved forward to the other side
+	MergeFastForward = "fast-forward"
+	// MergeCommit means a merge commit was created
+	MergeCommit = "merge"
+)
+
+// FieldConflict records a field both sides changed to different values
+type FieldConflict struct {
+	Issue int
+	Field string
+}
+
+// Renumbering records an issue whose alias was changed because it collided with another issue
+type Renumbering struct {
+	UID string
+	Old int
+	New int
+}
+
+// MergeResult describes the outcome of merging two issue histories
+type MergeResult struct {
+	Outcome     string
+	Conflicts   []FieldConflict
+	Renumbered  []Renumbering
+	NewIssues   int // Issues that only existed on the other side
+	ChangedBoth int // Issues changed on both sides
+}
+
+// Merge integrates the issue history at theirsID (usually a remote-tracking
+// reference) into the local issues reference
+func (im *IssueManager) Merge(theirsID string) (*MergeResult, error) {
+	result := &MergeResult{Outcome: MergeUpToDate}
+	if theirsID == "" {
+		return result, nil
+	}
+
+	repo := im.repo()
+	oursID, err := im.head()
+	if err != nil {
+		return nil, err
+	}
+
+	// Fast-forward if we have nothing of our own
+	if oursID == "" {
+		if err := repo.CompareAndSwapRef(IssuesRef, "", theirsID); err != nil {
+			return nil, err
+		}
+		result.Outcome = MergeFastForward
+		return result, nil
+	}
+
+	upToDate, err := repo.IsAncestor(theirsID, oursID)
+	if err != nil {
+		return nil, err
+	}
+	if upToDate {
+		return result, nil
+	}
+
+	fastForward, err := repo.IsAncestor(oursID, theirsID)
+	if err != nil {
+		return nil, err
+	}
+	if fastForward {
+		if err := repo.CompareAndSwapRef(IssuesRef, oursID, theirsID); err != nil {
+			return nil, err
+		}
+		result.Outcome = MergeFastForward
+		return result, nil
+	}
+
+	// Both sides changed issues, merge them field by field
+	baseID, err := repo.MergeBase(oursID, theirsID)
+	if err != nil {
+		return nil, err
+	}
+	base, err := im.loadSnapshot(baseID)
+	if err != nil {
+		return nil, err
+	}
+	ours, err := im.loadSnapshot(oursID)
+	if err != nil {
+		return nil, err
+	}
+	theirs, err := im.loadSnapshot(theirsID)
+	if err != nil {
+		return nil, err
+	}
+
+	merged, err := mergeSnapshots(base, ours, theirs, result)
+	if err != nil {
+		return nil, err
+	}
+	result.Renumbered = renumber(merged)
+	merged.head = oursID
+
+	if err := im.commit(merged, im.author(), "🔀 Merge issues", theirsID); err != nil {
+		return nil, err
+	}
+	result.Outcome = MergeCommit
+	return result, nil
+}
+
+// mergeSnapshots performs a three-way merge of two issue tracker states
+func mergeSnapshots(base, ours, theirs *snapshot, result *MergeResult) (*snapshot, error) {
+	merged := newSnapshot()
+
+	// Issues
+	for uid, ourIssue := range ours.Issues {
+		theirIssue, ok := theirs.Issues[uid]
+		if !ok {
+			merged.Issues[uid] = ourIssue
+			continue
+		}
+
+		issue, conflicts, err := mergeIssue(base.Issues[uid], ourIssue, theirIssue)
+		if err != nil {
+			return nil, err
+		}
+		merged.Issues[uid] = issue
+		result.Conflicts = append(result.Conflicts, conflicts...)
+		if !sameIssue(base.Issues[uid], ourIssue) && !sameIssue(base.Issues[uid], theirIssue) {
+			result.ChangedBoth++
+		}
+	}
+	for uid, theirIssue := range theirs.Issues {
+		if _, ok := ours.Issues[uid]; !ok {
+			merged.Issues[uid] = theirIssue
+			result.NewIssues++
+		}
+	}
+
+	// Label and milestone definitions
+	var err error
+	if merged.Labels, err = mergeDefinitions(base.Labels, ours.Labels, theirs.Labels); err != nil {
+		return nil, err
+	}
+	if merged.Milestones, err = mergeDefinitions(base.Milestones, ours.Milestones, theirs.Milestones); err != nil {
+		return nil, err
+	}
+
+	// Other files: take whichever side changed, preferring ours when both did
+	for path, blobID := range ours.Files {
+		merged.Files[path] = blobID
+	}
+	for path, blobID := range theirs.Files {
+		ourID, ok := ours.Files[path]
+		if !ok || ourID == base.Files[path] {
+			merged.Files[path] = blobID
+		}
+	}
+
+	sort.Slice(result.Conflicts, func(i, j int) bool {
+		if result.Conflicts[i].Issue != result.Conflicts[j].Issue {
+			return result.Conflicts[i].Issue < result.Conflicts[j].Issue
+		}
+		return result.Conflicts[i].Field < result.Conflicts[j].Field
+	})
+
+	return merged, nil
+}
+
+// jsonFields returns a value's top-level JSON fields in compact form. A nil pointer has no fields.
+func jsonFields(value interface{}) (map[string][]byte, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %T: %w", value, err)
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %T: %w", value, err)
+	}
+
+	fields := make(map[string][]byte, len(raw))
+	for name, value := range raw {
+		fields[name] = []byte(value)
+	}
+	return fields, nil
+}
+
+// sameIssue reports whether two versions of an issue are identical
+func sameIssue(a, b *Issue) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	aData, errA := json.Marshal(a)
+	bData, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(aData, bData)
+}
+
+// fieldMergers merge fields that both sides changed and that hold collections,
+// where taking one side's value would lose the other side's additions
+var fieldMergers = map[string]func(baseValue, ourValue, theirValue []byte) ([]byte, error){
+	"comments": mergeComments,
+	"commits":  mergeSets,
+	"events":   mergeEvents,
+	"labels":   mergeSets,
+	"links":    mergeSets,
+	// Time entries are never changed once logged
+	"time_entries": mergeSets,
+}
+
+// mergeValues merges two versions of a JSON object field by field. A field changed
+// on only one side takes that side's value. When both sides changed a field to
+// different values, the newer side wins (newer is negative for ours, positive for
+// theirs); without a newer side the values are compared so every clone resolves
+// the conflict the same way. It returns the names of the conflicting fields.
+func mergeValues[T any](base, ours, theirs *T, newer int) (*T, []string, error) {
+	baseFields, err := jsonFields(base)
+	if err != nil {
+		return nil, nil, err
+	}
+	ourFields, err := jsonFields(ours)
+	if err != nil {
+		return nil, nil, err
+	}
+	theirFields, err := jsonFields(theirs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	names := make(map[string]bool)
+	for name := range ourFields {
+		names[name] = true
+	}
+	for name := range theirFields {
+		names[name] = true
+	}
+
+	merged := make(map[string]json.RawMessage)
+	var conflicts []string
+	for name := range names {
+		baseValue, ourValue, theirValue := baseFields[name], ourFields[name], theirFields[name]
+
+		var value []byte
+		switch {
+		case bytes.Equal(ourValue, theirValue), bytes.Equal(theirValue, baseValue):
+			value = ourValue
+		case bytes.Equal(ourValue, baseValue):
+			value = theirValue
+		case fieldMergers[name] != nil:
+			if value, err = fieldMergers[name](baseValue, ourValue, theirValue); err != nil {
+				return nil, nil, err
+			}
+		default:
+			conflicts = append(conflicts, name)
+			if newer > 0 || (newer == 0 && bytes.Compare(theirValue, ourValue) > 0) {
+				value = theirValue
+			} else {
+				value = ourValue
+			}
+		}
+		if value != nil {
+			merged[name] = value
+		}
+	}
+	sort.Strings(conflicts)
+
+	data, err := json.Marshal(merged)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal merged %T: %w", ours, err)
+	}
+	var result T
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal merged %T: %w", ours, err)
+	}
+
+	return &result, conflicts, nil
+}
+
+// mergeIssue merges two versions of an issue field by field. When both sides
+// changed a field, the most recently updated version of the issue wins.
+func mergeIssue(base, ours, theirs *Issue) (*Issue, []FieldConflict, error) {
+	newer := 0
+	switch {
+	case theirs.UpdatedAt.After(ours.UpdatedAt):
+		newer = 1
+	case ours.UpdatedAt.After(theirs.UpdatedAt):
+		newer = -1
+	}
+
+	issue, fields, err := mergeValues(base, ours, theirs, newer)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var conflicts []FieldConflict
+	for _, field := range fields {
+		// updated_at always differs when both sides changed something; it isn't a real conflict
+		if field != "updated_at" {
+			conflicts = append(conflicts, FieldConflict{Issue: ours.ID, Field: field})
+		}
+	}
+
+	return issue, conflicts, nil
+}
+
+// mergeDefinitions merges two versions of a set of named definitions such as labels.
+// Definitions deleted on one side stay deleted unless the other side changed them.
+func mergeDefinitions[T any](base, ours, theirs map[string]*T) (map[string]*T, error) {
+	same := func(a, b *T) bool {
+		aData, _ := json.Marshal(a)
+		bData, _ := json.Marshal(b)
+		return bytes.Equal(aData, bData)
+	}
+
+	names := make(map[string]bool)
+	for name := range ours {
+		names[name] = true
+	}
+	for name := range theirs {
+		names[name] = true
+	}
+
+	merged := make(map[string]*T)
+	for name := range names {
+		baseValue, ourValue, theirValue := base[name], ours[name], theirs[name]
+		switch {
+		case ourValue == nil && theirValue == nil:
+			// Deleted on both sides
+		case ourValue == nil:
+			if baseValue == nil || !same(baseValue, theirValue) {
+				merged[name] = theirValue
+			}
+		case theirValue == nil:
+			if baseValue == nil || !same(baseValue, ourValue) {
+				merged[name] = ourValue
+			}
+		default:
+			value, _, err := mergeValues(baseValue, ourValue, theirValue, 0)
+			if err != nil {
+				return nil, err
+			}
+			merged[name] = value
+		}
+	}
+
+	return merged, nil
+}
+
+// mergeSets merges two versions of a JSON array used as a set, such as labels:
+// additions and removals from both sides are kept
+func mergeSets(baseValue, ourValue, theirValue []byte) ([]byte, error) {
+	decode := func(value []byte) (map[string]json.RawMessage, error) {
+		set := make(map[string]json.RawMessage)
+		if value == nil {
+			return set, nil
+		}
+		var list []json.RawMessage
+		if err := json.Unmarshal(value, &list); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal set: %w", err)
+		}
+		for _, item := range list {
+			var compact bytes.Buffer
+			if err := json.Compact(&compact, item); err != nil {
+				return nil, fmt.Errorf("failed to compact set item: %w", err)
+			}
+			set[compact.String()] = compact.Bytes()
+		}
+		return set, nil
+	}
+
+	base, err := decode(baseValue)
+	if err != nil {
+		return nil, err
+	}
+	ours, err := decode(ourValue)
+	if err != nil {
+		return nil, err
+	}
+	theirs, err := decode(theirValue)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	for key := range ours {
+		// Keep unless they removed it
+		if _, inBase := base[key]; !inBase || theirs[key] != nil {
+			keys = append(keys, key)
+		}
+	}
+	for key := range theirs {
+		// Add what they added
+		if _, inBase := base[key]; !inBase && ours[key] == nil {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	sort.Strings(keys)
+
+	merged := make([]json.RawMessage, len(keys))
+	for i, key := range keys {
+		if item, ok := ours[key]; ok {
+			merged[i] = item
+		} else {
+			merged[i] = theirs[key]
+		}
+	}
+	return json.Marshal(merged)
+}
+
+// renumber gives every issue a unique alias. When several issues share an alias
+// the one created first keeps it and the others get new aliases past the highest
+// one in use. The order only depends on the issues themselves, so every clone
+// renumbers the same way.
+func renumber(snap *snapshot) []Renumbering {
+	issues := snap.sortedIssues()
+	sort.SliceStable(issues, func(i, j int) bool {
+		if !issues[i].CreatedAt.Equal(issues[j].CreatedAt) {
+			return issues[i].CreatedAt.Before(issues[j].CreatedAt)
+		}
+		return issues[i].UID < issues[j].UID
+	})
+
+	used := make(map[int]bool)
+	var colliding []*Issue
+	for _, issue := range issues {
+		if issue.ID <= 0 || used[issue.ID] {
+			colliding = append(colliding, issue)
+			continue
+		}
+		used[issue.ID] = true
+	}
+
+	var renumbered []Renumbering
+	next := snap.nextID()
+	for _, issue := range colliding {
+		renumbered = append(renumbered, Renumbering{UID: issue.UID, Old: issue.ID, New: next})
+		issue.ID = next
+		next++
+	}
+
+	return renumbered
+}
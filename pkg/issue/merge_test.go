@@ -0,0 +1,233 @@
+package issue
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stanlocht/snap/pkg/repository"
+)
+
+// divergeIssues runs ours and theirs on two branches of the issue history that
+// start at the current head, leaves the issues reference on our side and
+// returns the head of their side
+func divergeIssues(t *testing.T, manager *IssueManager, ours, theirs func()) string {
+	t.Helper()
+
+	repo := &repository.Repository{Path: manager.RepoPath}
+	base, err := repo.ResolveRef(IssuesRef)
+	if err != nil {
+		t.Fatalf("Failed to resolve issues reference: %v", err)
+	}
+
+	theirs()
+	theirsID, err := repo.ResolveRef(IssuesRef)
+	if err != nil {
+		t.Fatalf("Failed to resolve issues reference: %v", err)
+	}
+
+	if err := repo.UpdateRef(IssuesRef, base); err != nil {
+		t.Fatalf("Failed to reset issues reference: %v", err)
+	}
+	ours()
+
+	return theirsID
+}
+
+func TestMergeIssuesFieldByField(t *testing.T) {
+	manager := NewIssueManager(t.TempDir())
+
+	// Create an issue both sides start from
+	created, err := manager.CreateIssue("Crash on start", "It crashes", "alice")
+	if err != nil {
+		t.Fatalf("Failed to create issue: %v", err)
+	}
+
+	theirsID := divergeIssues(t, manager,
+		func() {
+			// We retitle the issue
+			issue, _ := manager.GetIssue(created.ID)
+			issue.Title = "Crash on start with empty config"
+			if err := manager.SaveIssue(issue); err != nil {
+				t.Fatalf("Failed to save issue: %v", err)
+			}
+		},
+		func() {
+			// They assign it
+			if err := manager.AssignIssue(created.ID, "bob"); err != nil {
+				t.Fatalf("Failed to assign issue: %v", err)
+			}
+		})
+
+	// Merge their changes into ours
+	result, err := manager.Merge(theirsID)
+	if err != nil {
+		t.Fatalf("Failed to merge issues: %v", err)
+	}
+	if result.Outcome != MergeCommit {
+		t.Errorf("Expected a merge commit, got %s", result.Outcome)
+	}
+	if len(result.Conflicts) != 0 {
+		t.Errorf("Expected no conflicts, got %v", result.Conflicts)
+	}
+
+	// Both changes survive
+	merged, err := manager.GetIssue(created.ID)
+	if err != nil {
+		t.Fatalf("Failed to get issue: %v", err)
+	}
+	if merged.Title != "Crash on start with empty config" {
+		t.Errorf("Expected our title, got '%s'", merged.Title)
+	}
+	if merged.AssignedTo != "bob" {
+		t.Errorf("Expected their assignee, got '%s'", merged.AssignedTo)
+	}
+
+	// Merging again is a no-op
+	result, err = manager.Merge(theirsID)
+	if err != nil {
+		t.Fatalf("Failed to merge issues: %v", err)
+	}
+	if result.Outcome != MergeUpToDate {
+		t.Errorf("Expected up-to-date, got %s", result.Outcome)
+	}
+}
+
+func TestMergeIssuesConflictKeepsNewest(t *testing.T) {
+	manager := NewIssueManager(t.TempDir())
+
+	created, err := manager.CreateIssue("Slow log", "", "alice")
+	if err != nil {
+		t.Fatalf("Failed to create issue: %v", err)
+	}
+
+	// They edit first, we edit later
+	theirsID := divergeIssues(t, manager,
+		func() {
+			time.Sleep(10 * time.Millisecond)
+			if err := manager.AssignIssue(created.ID, "carol"); err != nil {
+				t.Fatalf("Failed to assign issue: %v", err)
+			}
+		},
+		func() {
+			if err := manager.AssignIssue(created.ID, "dave"); err != nil {
+				t.Fatalf("Failed to assign issue: %v", err)
+			}
+		})
+
+	result, err := manager.Merge(theirsID)
+	if err != nil {
+		t.Fatalf("Failed to merge issues: %v", err)
+	}
+	if len(result.Conflicts) != 1 || result.Conflicts[0].Field != "assigned_to" {
+		t.Errorf("Expected one conflict on assigned_to, got %v", result.Conflicts)
+	}
+
+	merged, _ := manager.GetIssue(created.ID)
+	if merged.AssignedTo != "carol" {
+		t.Errorf("Expected the most recent assignee 'carol', got '%s'", merged.AssignedTo)
+	}
+}
+
+func TestMergeIssuesRenumbersCollidingAliases(t *testing.T) {
+	manager := NewIssueManager(t.TempDir())
+
+	if _, err := manager.CreateIssue("First", "", "alice"); err != nil {
+		t.Fatalf("Failed to create issue: %v", err)
+	}
+
+	// Both sides create issue #2
+	var ours, theirs *Issue
+	theirsID := divergeIssues(t, manager,
+		func() {
+			var err error
+			if ours, err = manager.CreateIssue("Ours", "", "alice"); err != nil {
+				t.Fatalf("Failed to create issue: %v", err)
+			}
+		},
+		func() {
+			var err error
+			if theirs, err = manager.CreateIssue("Theirs", "", "bob"); err != nil {
+				t.Fatalf("Failed to create issue: %v", err)
+			}
+		})
+	if ours.ID != 2 || theirs.ID != 2 {
+		t.Fatalf("Expected both new issues to be #2, got #%d and #%d", ours.ID, theirs.ID)
+	}
+
+	result, err := manager.Merge(theirsID)
+	if err != nil {
+		t.Fatalf("Failed to merge issues: %v", err)
+	}
+	if result.NewIssues != 1 {
+		t.Errorf("Expected 1 new issue, got %d", result.NewIssues)
+	}
+
+	// The issue created first keeps #2, the other one becomes #3
+	if len(result.Renumbered) != 1 || result.Renumbered[0].UID != ours.UID || result.Renumbered[0].New != 3 {
+		t.Errorf("Expected our issue to be renumbered to #3, got %v", result.Renumbered)
+	}
+	issues, err := manager.ListIssues(true)
+	if err != nil {
+		t.Fatalf("Failed to list issues: %v", err)
+	}
+	if len(issues) != 3 {
+		t.Fatalf("Expected 3 issues, got %d", len(issues))
+	}
+	for i, issue := range issues {
+		if issue.ID != i+1 {
+			t.Errorf("Expected issue #%d, got #%d", i+1, issue.ID)
+		}
+	}
+
+	// Both issues can still be found by UID
+	if issue, err := manager.ResolveIssue(theirs.ShortUID()); err != nil || issue.ID != 2 {
+		t.Errorf("Expected their issue to resolve to #2, got %v (%v)", issue, err)
+	}
+	if issue, err := manager.ResolveIssue("#3"); err != nil || issue.UID != ours.UID {
+		t.Errorf("Expected #3 to resolve to our issue, got %v (%v)", issue, err)
+	}
+}
+
+func TestMigrateLegacyIssues(t *testing.T) {
+	tempDir := t.TempDir()
+	legacyDir := filepath.Join(tempDir, ".snap", "issues")
+	if err := os.MkdirAll(legacyDir, 0755); err != nil {
+		t.Fatalf("Failed to create issues directory: %v", err)
+	}
+
+	// Write an issue the way older versions did
+	legacy := `{"id": 4, "title": "Old issue", "description": "", "status": "open", "created_at": "2024-01-02T03:04:05Z", "created_by": "alice"}`
+	if err := os.WriteFile(filepath.Join(legacyDir, "4.json"), []byte(legacy), 0644); err != nil {
+		t.Fatalf("Failed to write legacy issue: %v", err)
+	}
+
+	manager := NewIssueManager(tempDir)
+	issue, err := manager.GetIssue(4)
+	if err != nil {
+		t.Fatalf("Failed to get migrated issue: %v", err)
+	}
+	if issue.Title != "Old issue" || issue.UID == "" {
+		t.Errorf("Expected migrated issue with a UID, got %+v", issue)
+	}
+
+	// The UID is derived from the issue, so other clones migrate to the same ID
+	if issue.UID != legacyUID(issue) {
+		t.Errorf("Expected a stable UID for a migrated issue")
+	}
+
+	// The old directory is moved aside
+	if _, err := os.Stat(legacyDir); !os.IsNotExist(err) {
+		t.Errorf("Expected legacy issues directory to be moved")
+	}
+
+	// New issues continue the numbering
+	created, err := manager.CreateIssue("New issue", "", "bob")
+	if err != nil {
+		t.Fatalf("Failed to create issue: %v", err)
+	}
+	if created.ID != 5 {
+		t.Errorf("Expected new issue #5, got #%d", created.ID)
+	}
+}
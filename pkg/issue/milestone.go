@@ -0,0 +1,171 @@
+package issue
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Milestone groups issues that should be done by a date
+type Milestone struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	DueDate     time.Time `json:"due_date,omitempty"`
+	Status      Status    `json:"status"`
+	CreatedAt   time.Time `json:"created_at"`
+	ClosedAt    time.Time `json:"closed_at,omitempty"`
+}
+
+// MilestoneProgress counts the issues in a milestone
+type MilestoneProgress struct {
+	Total  int
+	Closed int
+}
+
+// Open returns the number of open issues in a milestone
+func (p MilestoneProgress) Open() int {
+	return p.Total - p.Closed
+}
+
+// Percent returns the share of closed issues in a milestone, from 0 to 100
+func (p MilestoneProgress) Percent() int {
+	if p.Total == 0 {
+		return 0
+	}
+	return p.Closed * 100 / p.Total
+}
+
+// Overdue reports whether a milestone is still open after its due date
+func (m *Milestone) Overdue(now time.Time) bool {
+	return m.Status == StatusOpen && !m.DueDate.IsZero() && now.After(m.DueDate)
+}
+
+// CreateMilestone creates a new milestone. due may be zero for a milestone without a due date.
+func (im *IssueManager) CreateMilestone(name, description string, due time.Time) (*Milestone, error) {
+	if err := validateName("milestone", name); err != nil {
+		return nil, err
+	}
+
+	snap, err := im.current()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := snap.Milestones[name]; ok {
+		return nil, fmt.Errorf("milestone %s already exists", name)
+	}
+
+	milestone := &Milestone{
+		Name:        name,
+		Description: description,
+		DueDate:     due,
+		Status:      StatusOpen,
+		CreatedAt:   time.Now(),
+	}
+	snap.Milestones[name] = milestone
+	if err := im.commit(snap, im.author(), fmt.Sprintf("🎯 Create milestone %s", name), ""); err != nil {
+		return nil, err
+	}
+
+	return milestone, nil
+}
+
+// GetMilestone gets a milestone by name
+func (im *IssueManager) GetMilestone(name string) (*Milestone, error) {
+	snap, err := im.current()
+	if err != nil {
+		return nil, err
+	}
+	milestone, ok := snap.Milestones[name]
+	if !ok {
+		return nil, fmt.Errorf("milestone %s not found", name)
+	}
+	return milestone, nil
+}
+
+// ListMilestones lists milestones, ordered by due date. Milestones without a due date come last.
+func (im *IssueManager) ListMilestones(showClosed bool) ([]*Milestone, error) {
+	snap, err := im.current()
+	if err != nil {
+		return nil, err
+	}
+
+	var milestones []*Milestone
+	for _, milestone := range snap.Milestones {
+		if !showClosed && milestone.Status == StatusClosed {
+			continue
+		}
+		milestones = append(milestones, milestone)
+	}
+	sort.Slice(milestones, func(i, j int) bool {
+		a, b := milestones[i], milestones[j]
+		if a.DueDate.IsZero() != b.DueDate.IsZero() {
+			return b.DueDate.IsZero()
+		}
+		if !a.DueDate.Equal(b.DueDate) {
+			return a.DueDate.Before(b.DueDate)
+		}
+		return a.Name < b.Name
+	})
+
+	return milestones, nil
+}
+
+// CloseMilestone closes a milestone
+func (im *IssueManager) CloseMilestone(name string) error {
+	snap, err := im.current()
+	if err != nil {
+		return err
+	}
+	milestone, ok := snap.Milestones[name]
+	if !ok {
+		return fmt.Errorf("milestone %s not found", name)
+	}
+
+	milestone.Status = StatusClosed
+	milestone.ClosedAt = time.Now()
+	return im.commit(snap, im.author(), fmt.Sprintf("🎯 Close milestone %s", name), "")
+}
+
+// SetMilestone puts an issue in a milestone. An empty name removes the issue from its milestone.
+func (im *IssueManager) SetMilestone(id int, name string) error {
+	snap, err := im.current()
+	if err != nil {
+		return err
+	}
+	issue, err := snap.issue(id)
+	if err != nil {
+		return err
+	}
+
+	message := fmt.Sprintf("🎯 Remove issue #%d from its milestone", issue.ID)
+	if name != "" {
+		if _, ok := snap.Milestones[name]; !ok {
+			return fmt.Errorf("milestone %s not found (create it with 'snap issue milestone create')", name)
+		}
+		message = fmt.Sprintf("🎯 Add issue #%d to milestone %s", issue.ID, name)
+	}
+
+	issue.Milestone = name
+	return im.saveIssue(issue, im.author(), message)
+}
+
+// MilestoneIssues lists the issues in a milestone, ordered by alias
+func (im *IssueManager) MilestoneIssues(name string) ([]*Issue, error) {
+	return im.FilterIssues(Filter{ShowClosed: true, Milestone: name})
+}
+
+// MilestoneProgress counts the open and closed issues in a milestone
+func (im *IssueManager) MilestoneProgress(name string) (MilestoneProgress, error) {
+	issues, err := im.MilestoneIssues(name)
+	if err != nil {
+		return MilestoneProgress{}, err
+	}
+
+	progress := MilestoneProgress{Total: len(issues)}
+	for _, issue := range issues {
+		if issue.Status == StatusClosed {
+			progress.Closed++
+		}
+	}
+	return progress, nil
+}
@@ -0,0 +1,386 @@
+package issue
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Sort keys for issue queries
+const (
+	SortID       = "id"
+	SortCreated  = "created"
+	SortUpdated  = "updated"
+	SortPriority = "priority"
+	SortTitle    = "title"
+)
+
+// Query selects and orders issues. Queries are written as space-separated
+// terms, for example:
+//
+//	is:open assignee:alice label:bug created:>2026-01-01 "crash on start" sort:updated
+//
+// Supported qualifiers are is:open|closed, author:, assignee:, label:,
+// milestone:, priority: (with optional >, >=, < or <=), no:assignee|label|milestone|priority,
+// created:, updated: and closed: (a YYYY-MM-DD date with optional >, >=, < or <=)
+// and sort:id|created|updated|priority|title with an optional -asc or -desc suffix.
+// A leading "-" negates a qualifier. Other words and "quoted phrases" must appear
+// in the title, description or comments of an issue.
+type Query struct {
+	Text       []string // Words and phrases to search for, lower case
+	Sort       string
+	Descending bool
+
+	conditions []condition
+}
+
+// condition is one qualifier of a query
+type condition struct {
+	negate bool
+	match  func(*Issue) bool
+}
+
+// SearchResult is one page of the issues matching a query
+type SearchResult struct {
+	Issues  []*Issue
+	Total   int // Number of matching issues on all pages
+	Page    int // 1-based
+	PerPage int // 0 when all issues are on one page
+}
+
+// Pages returns the number of pages in a search result
+func (r *SearchResult) Pages() int {
+	if r.PerPage <= 0 || r.Total == 0 {
+		return 1
+	}
+	return (r.Total + r.PerPage - 1) / r.PerPage
+}
+
+// defaultDescending tells which sort keys put the largest values first by default
+var defaultDescending = map[string]bool{
+	SortID:       false,
+	SortTitle:    false,
+	SortCreated:  true,
+	SortUpdated:  true,
+	SortPriority: true,
+}
+
+// ParseQuery parses an issue query. An empty query matches every issue.
+func ParseQuery(s string) (*Query, error) {
+	query := &Query{Sort: SortID}
+
+	tokens, err := tokenizeQuery(s)
+	if err != nil {
+		return nil, err
+	}
+	for _, token := range tokens {
+		if !token.qualifier {
+			query.Text = append(query.Text, strings.ToLower(token.value))
+			continue
+		}
+		if err := query.addQualifier(token); err != nil {
+			return nil, err
+		}
+	}
+
+	return query, nil
+}
+
+// queryToken is a term of a query
+type queryToken struct {
+	negate    bool
+	qualifier bool
+	key       string
+	value     string
+}
+
+// tokenizeQuery splits a query into terms. Double quotes group words, also in
+// qualifier values; a term that starts with a quote is always a phrase.
+func tokenizeQuery(s string) ([]queryToken, error) {
+	var tokens []queryToken
+	runes := []rune(s)
+
+	for i := 0; i < len(runes); {
+		if unicode.IsSpace(runes[i]) {
+			i++
+			continue
+		}
+
+		// Read one term, keeping quoted parts together
+		phrase := runes[i] == '"'
+		var term strings.Builder
+		inQuotes := false
+		for ; i < len(runes) && (inQuotes || !unicode.IsSpace(runes[i])); i++ {
+			if runes[i] == '"' {
+				inQuotes = !inQuotes
+				continue
+			}
+			term.WriteRune(runes[i])
+		}
+		if inQuotes {
+			return nil, fmt.Errorf("unterminated quote in query")
+		}
+
+		text := term.String()
+		if text == "" {
+			continue
+		}
+		token := queryToken{value: text}
+
+		// Split qualifiers into key and value
+		if key, value, found := strings.Cut(text, ":"); found && !phrase && key != "" && !strings.ContainsAny(key, " ") {
+			token.qualifier = true
+			token.key, token.value = strings.ToLower(key), value
+			if strings.HasPrefix(token.key, "-") {
+				token.negate = true
+				token.key = token.key[1:]
+			}
+		}
+		tokens = append(tokens, token)
+	}
+
+	return tokens, nil
+}
+
+// addQualifier adds the condition or sort order a qualifier describes
+func (q *Query) addQualifier(token queryToken) error {
+	value := token.value
+	if value == "" {
+		return fmt.Errorf("missing value for %s:", token.key)
+	}
+
+	var match func(*Issue) bool
+	switch token.key {
+	case "is", "state":
+		status := Status(strings.ToLower(value))
+		if status != StatusOpen && status != StatusClosed {
+			return fmt.Errorf("unknown issue state %q (use open or closed)", value)
+		}
+		match = func(issue *Issue) bool { return issue.Status == status }
+	case "author":
+		match = func(issue *Issue) bool { return strings.EqualFold(issue.CreatedBy, value) }
+	case "assignee":
+		match = func(issue *Issue) bool { return strings.EqualFold(issue.AssignedTo, value) }
+	case "label":
+		match = func(issue *Issue) bool { return issue.HasLabel(value) }
+	case "milestone":
+		match = func(issue *Issue) bool { return issue.Milestone == value }
+	case "priority":
+		op, level := splitOperator(value)
+		priority, err := ParsePriority(level)
+		if err != nil {
+			return err
+		}
+		compare := compareWith(op)
+		match = func(issue *Issue) bool { return compare(issue.Priority.Rank() - priority.Rank()) }
+	case "no":
+		switch strings.ToLower(value) {
+		case "assignee":
+			match = func(issue *Issue) bool { return issue.AssignedTo == "" }
+		case "label":
+			match = func(issue *Issue) bool { return len(issue.Labels) == 0 }
+		case "milestone":
+			match = func(issue *Issue) bool { return issue.Milestone == "" }
+		case "priority":
+			match = func(issue *Issue) bool { return issue.Priority == PriorityNone }
+		default:
+			return fmt.Errorf("unknown field %q for no: (use assignee, label, milestone or priority)", value)
+		}
+	case "created", "updated", "closed":
+		field := map[string]func(*Issue) time.Time{
+			"created": func(issue *Issue) time.Time { return issue.CreatedAt },
+			"updated": func(issue *Issue) time.Time { return issue.UpdatedAt },
+			"closed":  func(issue *Issue) time.Time { return issue.ClosedAt },
+		}[token.key]
+		inRange, err := parseDateCondition(value)
+		if err != nil {
+			return fmt.Errorf("invalid %s: date: %w", token.key, err)
+		}
+		match = func(issue *Issue) bool {
+			t := field(issue)
+			return !t.IsZero() && inRange(t)
+		}
+	case "sort":
+		if token.negate {
+			return fmt.Errorf("sort: can't be negated")
+		}
+		key, direction, _ := strings.Cut(strings.ToLower(value), "-")
+		descending, ok := defaultDescending[key]
+		if !ok {
+			return fmt.Errorf("unknown sort key %q (use id, created, updated, priority or title)", key)
+		}
+		switch direction {
+		case "":
+		case "asc":
+			descending = false
+		case "desc":
+			descending = true
+		default:
+			return fmt.Errorf("unknown sort direction %q (use asc or desc)", direction)
+		}
+		q.Sort, q.Descending = key, descending
+		return nil
+	default:
+		return fmt.Errorf("unknown search qualifier %q", token.key+":")
+	}
+
+	q.conditions = append(q.conditions, condition{negate: token.negate, match: match})
+	return nil
+}
+
+// splitOperator splits a comparison operator (>, >=, <, <=) off a value
+func splitOperator(value string) (string, string) {
+	for _, op := range []string{">=", "<=", ">", "<"} {
+		if strings.HasPrefix(value, op) {
+			return op, value[len(op):]
+		}
+	}
+	return "", value
+}
+
+// compareWith returns a test for the result of a comparison (negative, zero or positive)
+func compareWith(op string) func(int) bool {
+	switch op {
+	case ">":
+		return func(c int) bool { return c > 0 }
+	case ">=":
+		return func(c int) bool { return c >= 0 }
+	case "<":
+		return func(c int) bool { return c < 0 }
+	case "<=":
+		return func(c int) bool { return c <= 0 }
+	default:
+		return func(c int) bool { return c == 0 }
+	}
+}
+
+// parseDateCondition parses a date with an optional comparison operator. Dates are
+// whole days in local time, so created:>2026-01-01 starts on January 2nd.
+func parseDateCondition(value string) (func(time.Time) bool, error) {
+	op, date := splitOperator(value)
+	day, err := time.ParseInLocation("2006-01-02", date, time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("%q is not a YYYY-MM-DD date", date)
+	}
+	next := day.AddDate(0, 0, 1)
+
+	compare := compareWith(op)
+	return func(t time.Time) bool {
+		switch {
+		case t.Before(day):
+			return compare(-1)
+		case t.Before(next):
+			return compare(0)
+		default:
+			return compare(1)
+		}
+	}, nil
+}
+
+// AddFilter narrows a query down with a filter
+func (q *Query) AddFilter(filter Filter) {
+	q.conditions = append(q.conditions, condition{match: filter.Matches})
+}
+
+// Matches reports whether an issue matches a query
+func (q *Query) Matches(issue *Issue) bool {
+	for _, cond := range q.conditions {
+		if cond.match(issue) == cond.negate {
+			return false
+		}
+	}
+
+	if len(q.Text) == 0 {
+		return true
+	}
+	texts := []string{strings.ToLower(issue.Title), strings.ToLower(issue.Description)}
+	for _, comment := range issue.Comments {
+		texts = append(texts, strings.ToLower(comment.Body))
+	}
+	for _, term := range q.Text {
+		found := false
+		for _, text := range texts {
+			if strings.Contains(text, term) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// sortIssues orders issues as a query asks. Ties are broken by alias.
+func (q *Query) sortIssues(issues []*Issue) {
+	less := func(a, b *Issue) int {
+		switch q.Sort {
+		case SortCreated:
+			return a.CreatedAt.Compare(b.CreatedAt)
+		case SortUpdated:
+			return a.UpdatedAt.Compare(b.UpdatedAt)
+		case SortPriority:
+			return a.Priority.Rank() - b.Priority.Rank()
+		case SortTitle:
+			return strings.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title))
+		}
+		return 0
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		c := less(issues[i], issues[j])
+		if c == 0 {
+			c = issues[i].ID - issues[j].ID
+		}
+		if q.Descending {
+			return c > 0
+		}
+		return c < 0
+	})
+}
+
+// Search returns one page of the issues matching a query. Pages are numbered
+// from 1; a perPage of 0 returns every matching issue.
+func (im *IssueManager) Search(query *Query, page, perPage int) (*SearchResult, error) {
+	snap, err := im.current()
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []*Issue
+	for _, issue := range snap.sortedIssues() {
+		if query.Matches(issue) {
+			issues = append(issues, issue)
+		}
+	}
+	query.sortIssues(issues)
+
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 0 {
+		perPage = 0
+	}
+	result := &SearchResult{Total: len(issues), Page: page, PerPage: perPage}
+	if perPage == 0 {
+		result.Issues = issues
+		return result, nil
+	}
+
+	// Pages past the end are empty; checking before multiplying keeps huge pages from overflowing
+	if page-1 > len(issues)/perPage {
+		return result, nil
+	}
+	start := (page - 1) * perPage
+	if start < len(issues) {
+		end := start + perPage
+		if end > len(issues) {
+			end = len(issues)
+		}
+		result.Issues = issues[start:end]
+	}
+	return result, nil
+}
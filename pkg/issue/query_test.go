@@ -0,0 +1,142 @@
+package issue
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestParseQueryErrors(t *testing.T) {
+	for _, query := range []string{
+		"is:pending",
+		"color:red",
+		"created:>yesterday",
+		"priority:urgent",
+		"no:comments",
+		"sort:size",
+		"sort:id-up",
+		`"unterminated`,
+		"label:",
+	} {
+		if _, err := ParseQuery(query); err == nil {
+			t.Errorf("Expected ParseQuery(%q) to fail", query)
+		}
+	}
+}
+
+func TestSearchIssues(t *testing.T) {
+	manager := NewIssueManager(t.TempDir())
+
+	if _, err := manager.CreateLabel("bug", "", ""); err != nil {
+		t.Fatalf("Failed to create label: %v", err)
+	}
+	if _, err := manager.CreateLabel("good first issue", "", ""); err != nil {
+		t.Fatalf("Failed to create label: %v", err)
+	}
+	for _, title := range []string{"Crash on start", "Slow search", "Typo in README", "Crash when saving"} {
+		if _, err := manager.CreateIssue(title, "", "alice"); err != nil {
+			t.Fatalf("Failed to create issue: %v", err)
+		}
+	}
+	if err := manager.AddLabels(1, "bug"); err != nil {
+		t.Fatalf("Failed to add label: %v", err)
+	}
+	if err := manager.AddLabels(3, "good first issue"); err != nil {
+		t.Fatalf("Failed to add label: %v", err)
+	}
+	if err := manager.AssignIssue(1, "bob"); err != nil {
+		t.Fatalf("Failed to assign issue: %v", err)
+	}
+	if err := manager.SetPriority(2, PriorityHigh); err != nil {
+		t.Fatalf("Failed to set priority: %v", err)
+	}
+	if err := manager.SetPriority(4, PriorityLow); err != nil {
+		t.Fatalf("Failed to set priority: %v", err)
+	}
+	if err := manager.CloseIssue(4, ""); err != nil {
+		t.Fatalf("Failed to close issue: %v", err)
+	}
+	if _, err := manager.AddComment(2, "carol", "Happens with large indexes", ""); err != nil {
+		t.Fatalf("Failed to add comment: %v", err)
+	}
+
+	today := time.Now().Format("2006-01-02")
+	tests := []struct {
+		query    string
+		expected []int
+	}{
+		{"", []int{1, 2, 3, 4}},
+		{"is:open", []int{1, 2, 3}},
+		{"-is:open", []int{4}},
+		{"crash", []int{1, 4}},
+		{`"crash on"`, []int{1}},
+		{"is:open assignee:BOB label:bug crash", []int{1}},
+		{`label:"good first issue"`, []int{3}},
+		{"no:assignee no:label", []int{2, 4}},
+		{"priority:>=low sort:priority", []int{2, 4}},
+		{"priority:>low", []int{2}},
+		{"indexes", []int{2}},
+		{"created:" + today, []int{1, 2, 3, 4}},
+		{"created:>" + today, nil},
+		{"closed:<=" + today, []int{4}},
+		{"sort:title", []int{1, 4, 2, 3}},
+		{"sort:id-desc is:open", []int{3, 2, 1}},
+	}
+
+	for _, test := range tests {
+		query, err := ParseQuery(test.query)
+		if err != nil {
+			t.Errorf("Failed to parse %q: %v", test.query, err)
+			continue
+		}
+		result, err := manager.Search(query, 1, 0)
+		if err != nil {
+			t.Fatalf("Failed to search: %v", err)
+		}
+
+		var ids []int
+		for _, issue := range result.Issues {
+			ids = append(ids, issue.ID)
+		}
+		if len(ids) != len(test.expected) {
+			t.Errorf("Search(%q) = %v, expected %v", test.query, ids, test.expected)
+			continue
+		}
+		for i := range ids {
+			if ids[i] != test.expected[i] {
+				t.Errorf("Search(%q) = %v, expected %v", test.query, ids, test.expected)
+				break
+			}
+		}
+	}
+
+	// Pagination
+	query, err := ParseQuery("")
+	if err != nil {
+		t.Fatalf("Failed to parse query: %v", err)
+	}
+	query.AddFilter(Filter{ShowClosed: true, Assignee: ""})
+	result, err := manager.Search(query, 2, 3)
+	if err != nil {
+		t.Fatalf("Failed to search: %v", err)
+	}
+	if result.Total != 4 || result.Pages() != 2 || len(result.Issues) != 1 || result.Issues[0].ID != 4 {
+		t.Errorf("Expected the second page to hold issue #4 of 4, got %d issues of %d", len(result.Issues), result.Total)
+	}
+	result, err = manager.Search(query, 5, 3)
+	if err != nil {
+		t.Fatalf("Failed to search: %v", err)
+	}
+	if len(result.Issues) != 0 {
+		t.Errorf("Expected no issues past the last page, got %d", len(result.Issues))
+	}
+	for _, perPage := range []int{3, 20} {
+		result, err = manager.Search(query, math.MaxInt/perPage+2, perPage)
+		if err != nil {
+			t.Fatalf("Failed to search: %v", err)
+		}
+		if len(result.Issues) != 0 {
+			t.Errorf("Expected no issues on a huge page, got %d", len(result.Issues))
+		}
+	}
+}
@@ -0,0 +1,336 @@
+package issue
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// LinkType is the kind of relationship between two issues
+type LinkType string
+
+const (
+	// LinkBlocks means the target can't be finished before the issue
+	LinkBlocks LinkType = "blocks"
+	// LinkDuplicateOf means the issue reports the same thing as the target
+	LinkDuplicateOf LinkType = "duplicate_of"
+	// LinkRelatedTo means the issues are related; it applies both ways
+	LinkRelatedTo LinkType = "related_to"
+)
+
+// Link is a relationship from an issue to another issue, stored on the first one
+type Link struct {
+	Type   LinkType `json:"type"`
+	Target string   `json:"target"` // UID of the other issue
+}
+
+// Relations lists the issues related to an issue, ordered by alias
+type Relations struct {
+	Blocks      []*Issue
+	BlockedBy   []*Issue
+	DuplicateOf []*Issue
+	Duplicates  []*Issue // Issues that are duplicates of this one
+	Related     []*Issue
+}
+
+// GraphEdge is a relationship in a dependency graph
+type GraphEdge struct {
+	From *Issue
+	To   *Issue
+	Type LinkType
+}
+
+// Graph is the graph of relationships between issues
+type Graph struct {
+	Issues []*Issue // Issues with at least one relationship, ordered by alias
+	Edges  []GraphEdge
+
+	blockers map[string][]*Issue
+}
+
+// ParseLinkType parses a relationship as written on the command line: blocks,
+// blocked-by, duplicate-of or related-to. inverse is true for blocked-by, which
+// is stored as a blocks link on the other issue.
+func ParseLinkType(s string) (linkType LinkType, inverse bool, err error) {
+	switch strings.ReplaceAll(strings.ToLower(strings.TrimSpace(s)), "_", "-") {
+	case "blocks":
+		return LinkBlocks, false, nil
+	case "blocked-by", "depends-on":
+		return LinkBlocks, true, nil
+	case "duplicate-of", "duplicates":
+		return LinkDuplicateOf, false, nil
+	case "related-to", "relates-to", "related":
+		return LinkRelatedTo, false, nil
+	}
+	return "", false, fmt.Errorf("unknown relationship %q (use blocks, blocked-by, duplicate-of or related-to)", s)
+}
+
+// describeLink describes a link from the point of view of its source issue
+func describeLink(linkType LinkType, target *Issue) string {
+	switch linkType {
+	case LinkBlocks:
+		return fmt.Sprintf("blocking #%d", target.ID)
+	case LinkDuplicateOf:
+		return fmt.Sprintf("a duplicate of #%d", target.ID)
+	default:
+		return fmt.Sprintf("related to #%d", target.ID)
+	}
+}
+
+// HasLink reports whether an issue links to another issue
+func (i *Issue) HasLink(linkType LinkType, targetUID string) bool {
+	for _, link := range i.Links {
+		if link.Type == linkType && link.Target == targetUID {
+			return true
+		}
+	}
+	return false
+}
+
+// reaches reports whether from leads to to by following links of one type
+func (s *snapshot) reaches(from, to *Issue, linkType LinkType) bool {
+	visited := make(map[string]bool)
+	queue := []*Issue{from}
+	for len(queue) > 0 {
+		issue := queue[0]
+		queue = queue[1:]
+		if issue.UID == to.UID {
+			return true
+		}
+		if visited[issue.UID] {
+			continue
+		}
+		visited[issue.UID] = true
+
+		for _, link := range issue.Links {
+			if next, ok := s.Issues[link.Target]; ok && link.Type == linkType {
+				queue = append(queue, next)
+			}
+		}
+	}
+	return false
+}
+
+// AddLink records that issue from relates to issue to. Links that would make an
+// issue block itself or be a duplicate of itself are rejected.
+func (im *IssueManager) AddLink(from, to int, linkType LinkType) error {
+	snap, err := im.current()
+	if err != nil {
+		return err
+	}
+	source, err := snap.issue(from)
+	if err != nil {
+		return err
+	}
+	target, err := snap.issue(to)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case source.UID == target.UID:
+		return fmt.Errorf("an issue can't be linked to itself")
+	case source.HasLink(linkType, target.UID),
+		linkType == LinkRelatedTo && target.HasLink(LinkRelatedTo, source.UID):
+		return fmt.Errorf("issue #%d is already %s", source.ID, describeLink(linkType, target))
+	case linkType == LinkBlocks && snap.reaches(target, source, LinkBlocks):
+		return fmt.Errorf("issue #%d already blocks #%d, directly or through other issues, so #%d can't block it", target.ID, source.ID, source.ID)
+	case linkType == LinkDuplicateOf && snap.reaches(target, source, LinkDuplicateOf):
+		return fmt.Errorf("issue #%d is already a duplicate of #%d, directly or indirectly", target.ID, source.ID)
+	}
+
+	description := describeLink(linkType, target)
+	source.Links = append(source.Links, Link{Type: linkType, Target: target.UID})
+	if err := source.addEvent(Event{Actor: im.author(), At: time.Now(), Field: "links", New: description}); err != nil {
+		return err
+	}
+
+	return im.saveIssue(source, im.author(), fmt.Sprintf("🔗 Mark issue #%d as %s", source.ID, description))
+}
+
+// RemoveLink removes a relationship between two issues
+func (im *IssueManager) RemoveLink(from, to int, linkType LinkType) error {
+	snap, err := im.current()
+	if err != nil {
+		return err
+	}
+	source, err := snap.issue(from)
+	if err != nil {
+		return err
+	}
+	target, err := snap.issue(to)
+	if err != nil {
+		return err
+	}
+
+	// Related links apply both ways, so they may be stored on either issue
+	if !source.HasLink(linkType, target.UID) {
+		if linkType != LinkRelatedTo || !target.HasLink(linkType, source.UID) {
+			return fmt.Errorf("issue #%d is not %s", source.ID, describeLink(linkType, target))
+		}
+		source, target = target, source
+	}
+
+	var links []Link
+	for _, link := range source.Links {
+		if link.Type != linkType || link.Target != target.UID {
+			links = append(links, link)
+		}
+	}
+	source.Links = links
+
+	description := describeLink(linkType, target)
+	if err := source.addEvent(Event{Actor: im.author(), At: time.Now(), Field: "links", Old: description}); err != nil {
+		return err
+	}
+
+	return im.saveIssue(source, im.author(), fmt.Sprintf("🔗 Unmark issue #%d as %s", source.ID, description))
+}
+
+// Relations lists the issues related to an issue
+func (im *IssueManager) Relations(id int) (*Relations, error) {
+	snap, err := im.current()
+	if err != nil {
+		return nil, err
+	}
+	issue, err := snap.issue(id)
+	if err != nil {
+		return nil, err
+	}
+
+	relations := &Relations{}
+	for _, other := range snap.sortedIssues() {
+		for _, link := range issue.Links {
+			if link.Target != other.UID {
+				continue
+			}
+			switch link.Type {
+			case LinkBlocks:
+				relations.Blocks = append(relations.Blocks, other)
+			case LinkDuplicateOf:
+				relations.DuplicateOf = append(relations.DuplicateOf, other)
+			case LinkRelatedTo:
+				relations.Related = append(relations.Related, other)
+			}
+		}
+		for _, link := range other.Links {
+			if link.Target != issue.UID {
+				continue
+			}
+			switch link.Type {
+			case LinkBlocks:
+				relations.BlockedBy = append(relations.BlockedBy, other)
+			case LinkDuplicateOf:
+				relations.Duplicates = append(relations.Duplicates, other)
+			case LinkRelatedTo:
+				if !issue.HasLink(LinkRelatedTo, other.UID) {
+					relations.Related = append(relations.Related, other)
+				}
+			}
+		}
+	}
+
+	return relations, nil
+}
+
+// ErrBlocked is returned when an issue would be closed while open issues still block it
+var ErrBlocked = errors.New("issue is blocked by open issues")
+
+// OpenBlockers lists the open issues that block an issue
+func (im *IssueManager) OpenBlockers(id int) ([]*Issue, error) {
+	relations, err := im.Relations(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var open []*Issue
+	for _, blocker := range relations.BlockedBy {
+		if blocker.Status != StatusClosed {
+			open = append(open, blocker)
+		}
+	}
+	return open, nil
+}
+
+// DependencyGraph returns the graph of relationships between all issues
+func (im *IssueManager) DependencyGraph() (*Graph, error) {
+	snap, err := im.current()
+	if err != nil {
+		return nil, err
+	}
+
+	graph := &Graph{blockers: make(map[string][]*Issue)}
+	linked := make(map[string]bool)
+	for _, issue := range snap.sortedIssues() {
+		for _, link := range issue.Links {
+			target, ok := snap.Issues[link.Target]
+			if !ok {
+				continue
+			}
+			graph.Edges = append(graph.Edges, GraphEdge{From: issue, To: target, Type: link.Type})
+			if link.Type == LinkBlocks {
+				graph.blockers[target.UID] = append(graph.blockers[target.UID], issue)
+			}
+			linked[issue.UID], linked[target.UID] = true, true
+		}
+	}
+	for _, issue := range snap.sortedIssues() {
+		if linked[issue.UID] {
+			graph.Issues = append(graph.Issues, issue)
+		}
+	}
+	for _, blockers := range graph.blockers {
+		sort.Slice(blockers, func(i, j int) bool { return blockers[i].ID < blockers[j].ID })
+	}
+
+	return graph, nil
+}
+
+// Blockers lists the issues that block an issue in a graph, ordered by alias
+func (g *Graph) Blockers(issue *Issue) []*Issue {
+	return g.blockers[issue.UID]
+}
+
+// Blocks reports whether an issue blocks any other issue in a graph
+func (g *Graph) Blocks(issue *Issue) bool {
+	for _, edge := range g.Edges {
+		if edge.Type == LinkBlocks && edge.From.UID == issue.UID {
+			return true
+		}
+	}
+	return false
+}
+
+// Depths returns how many levels of blockers each issue has, by UID. Issues
+// without blockers have depth 0. Cycles, which merges can create, are cut.
+func (g *Graph) Depths() map[string]int {
+	depths := make(map[string]int)
+	visiting := make(map[string]bool)
+	var depth func(issue *Issue) int
+	depth = func(issue *Issue) int {
+		if d, ok := depths[issue.UID]; ok {
+			return d
+		}
+		if visiting[issue.UID] {
+			return 0
+		}
+		visiting[issue.UID] = true
+
+		d := 0
+		for _, blocker := range g.Blockers(issue) {
+			if blockerDepth := depth(blocker) + 1; blockerDepth > d {
+				d = blockerDepth
+			}
+		}
+		visiting[issue.UID] = false
+		depths[issue.UID] = d
+		return d
+	}
+
+	for _, issue := range g.Issues {
+		depth(issue)
+	}
+	return depths
+}
@@ -0,0 +1,172 @@
+package issue
+
+import (
+	"testing"
+)
+
+func TestIssueRelations(t *testing.T) {
+	manager := NewIssueManager(t.TempDir())
+	manager.Author = "alice"
+
+	for _, title := range []string{"Design", "Build", "Ship", "Crash", "Crash again"} {
+		if _, err := manager.CreateIssue(title, "", "alice"); err != nil {
+			t.Fatalf("Failed to create issue: %v", err)
+		}
+	}
+
+	// Design blocks Build, which blocks Ship
+	if err := manager.AddLink(1, 2, LinkBlocks); err != nil {
+		t.Fatalf("Failed to link issues: %v", err)
+	}
+	if err := manager.AddLink(2, 3, LinkBlocks); err != nil {
+		t.Fatalf("Failed to link issues: %v", err)
+	}
+	if err := manager.AddLink(5, 4, LinkDuplicateOf); err != nil {
+		t.Fatalf("Failed to link issues: %v", err)
+	}
+	if err := manager.AddLink(4, 1, LinkRelatedTo); err != nil {
+		t.Fatalf("Failed to link issues: %v", err)
+	}
+
+	invalid := []struct {
+		from, to int
+		linkType LinkType
+	}{
+		{1, 1, LinkBlocks},      // Self link
+		{1, 2, LinkBlocks},      // Duplicate link
+		{3, 1, LinkBlocks},      // Indirect cycle
+		{2, 1, LinkBlocks},      // Direct cycle
+		{4, 5, LinkDuplicateOf}, // Duplicate cycle
+		{1, 4, LinkRelatedTo},   // Related links apply both ways
+	}
+	for _, link := range invalid {
+		if err := manager.AddLink(link.from, link.to, link.linkType); err == nil {
+			t.Errorf("Expected linking #%d %s #%d to fail", link.from, link.linkType, link.to)
+		}
+	}
+
+	relations, err := manager.Relations(2)
+	if err != nil {
+		t.Fatalf("Failed to get relations: %v", err)
+	}
+	if len(relations.BlockedBy) != 1 || relations.BlockedBy[0].ID != 1 || len(relations.Blocks) != 1 || relations.Blocks[0].ID != 3 {
+		t.Errorf("Expected #2 to be blocked by #1 and block #3")
+	}
+	relations, err = manager.Relations(1)
+	if err != nil {
+		t.Fatalf("Failed to get relations: %v", err)
+	}
+	if len(relations.Related) != 1 || relations.Related[0].ID != 4 {
+		t.Errorf("Expected #1 to be related to #4")
+	}
+	relations, err = manager.Relations(4)
+	if err != nil {
+		t.Fatalf("Failed to get relations: %v", err)
+	}
+	if len(relations.Duplicates) != 1 || relations.Duplicates[0].ID != 5 || len(relations.Related) != 1 {
+		t.Errorf("Expected #4 to be duplicated by #5 and related to #1")
+	}
+
+	// Blockers only count while they are open
+	blockers, err := manager.OpenBlockers(3)
+	if err != nil {
+		t.Fatalf("Failed to get blockers: %v", err)
+	}
+	if len(blockers) != 1 || blockers[0].ID != 2 {
+		t.Errorf("Expected #3 to be blocked by #2")
+	}
+	if err := manager.CloseIssue(2, ""); err != nil {
+		t.Fatalf("Failed to close issue: %v", err)
+	}
+	if blockers, _ := manager.OpenBlockers(3); len(blockers) != 0 {
+		t.Errorf("Expected #3 to have no open blockers, got %d", len(blockers))
+	}
+
+	// Related links can be removed from either side
+	if err := manager.RemoveLink(1, 4, LinkRelatedTo); err != nil {
+		t.Fatalf("Failed to remove link: %v", err)
+	}
+	if err := manager.RemoveLink(1, 3, LinkBlocks); err == nil {
+		t.Errorf("Expected removing a missing link to fail")
+	}
+
+	issue, err := manager.GetIssue(4)
+	if err != nil {
+		t.Fatalf("Failed to get issue: %v", err)
+	}
+	history := issue.History()
+	if len(issue.Links) != 0 || len(history) != 2 || history[0].Describe() != "marked the issue as related to #1" || history[1].Describe() != "removed the link: related to #1" {
+		t.Errorf("Expected the related link to be added and removed, got %d links and %d events", len(issue.Links), len(history))
+	}
+}
+
+func TestDependencyGraph(t *testing.T) {
+	manager := NewIssueManager(t.TempDir())
+
+	for _, title := range []string{"Design", "Build", "Ship", "Docs", "Unrelated"} {
+		if _, err := manager.CreateIssue(title, "", "alice"); err != nil {
+			t.Fatalf("Failed to create issue: %v", err)
+		}
+	}
+	for _, link := range [][2]int{{1, 2}, {2, 3}, {4, 3}} {
+		if err := manager.AddLink(link[0], link[1], LinkBlocks); err != nil {
+			t.Fatalf("Failed to link issues: %v", err)
+		}
+	}
+
+	graph, err := manager.DependencyGraph()
+	if err != nil {
+		t.Fatalf("Failed to build graph: %v", err)
+	}
+	if len(graph.Issues) != 4 || len(graph.Edges) != 3 {
+		t.Fatalf("Expected 4 linked issues and 3 edges, got %d and %d", len(graph.Issues), len(graph.Edges))
+	}
+
+	depths := graph.Depths()
+	expected := map[int]int{1: 0, 2: 1, 3: 2, 4: 0}
+	for _, issue := range graph.Issues {
+		if depths[issue.UID] != expected[issue.ID] {
+			t.Errorf("Expected #%d to have depth %d, got %d", issue.ID, expected[issue.ID], depths[issue.UID])
+		}
+	}
+
+	ship := graph.Issues[2]
+	if blockers := graph.Blockers(ship); len(blockers) != 2 || blockers[0].ID != 2 || blockers[1].ID != 4 {
+		t.Errorf("Expected #3 to be blocked by #2 and #4")
+	}
+	if graph.Blocks(ship) || !graph.Blocks(graph.Issues[0]) {
+		t.Errorf("Expected #1 to block an issue and #3 to block none")
+	}
+}
+
+func TestMergeIssuesKeepsLinksFromBothSides(t *testing.T) {
+	manager := NewIssueManager(t.TempDir())
+
+	for _, title := range []string{"Design", "Build", "Ship"} {
+		if _, err := manager.CreateIssue(title, "", "alice"); err != nil {
+			t.Fatalf("Failed to create issue: %v", err)
+		}
+	}
+
+	theirsID := divergeIssues(t, manager, func() {
+		if err := manager.AddLink(1, 2, LinkBlocks); err != nil {
+			t.Fatalf("Failed to link issues: %v", err)
+		}
+	}, func() {
+		if err := manager.AddLink(1, 3, LinkRelatedTo); err != nil {
+			t.Fatalf("Failed to link issues: %v", err)
+		}
+	})
+
+	if _, err := manager.Merge(theirsID); err != nil {
+		t.Fatalf("Failed to merge issues: %v", err)
+	}
+
+	relations, err := manager.Relations(1)
+	if err != nil {
+		t.Fatalf("Failed to get relations: %v", err)
+	}
+	if len(relations.Blocks) != 1 || len(relations.Related) != 1 {
+		t.Errorf("Expected the links from both sides, got %d blocking and %d related", len(relations.Blocks), len(relations.Related))
+	}
+}
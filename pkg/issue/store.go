@@ -0,0 +1,344 @@
+package issue
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/stanlocht/snap/pkg/repository"
+)
+
+// IssuesRef is the reference holding the history of the issue tracker
+const IssuesRef = repository.MetadataRefPrefix + "issues"
+
+// Directories of the issue tree
+const (
+	issuePathPrefix     = "issues/"
+	labelPathPrefix     = "labels/"
+	milestonePathPrefix = "milestones/"
+)
+
+// snapshot is the state of the issue tracker at one commit
+type snapshot struct {
+	Issues     map[string]*Issue     // UID to issue
+	Labels     map[string]*Label     // Name to label
+	Milestones map[string]*Milestone // Name to milestone
+	Files      map[string]string     // Other paths in the tree to blob IDs
+
+	head string // Commit the snapshot was read from, "" for an empty tracker
+}
+
+// blobCacheSize bounds the number of issue blobs kept in memory
+const blobCacheSize = 10000
+
+// blobCache keeps the contents of issue blobs that were read before. Blobs are
+// addressed by their content, so cached data never goes stale.
+var blobCache = struct {
+	sync.Mutex
+	data map[string][]byte
+}{data: make(map[string][]byte)}
+
+// readBlob reads a blob through the blob cache
+func (im *IssueManager) readBlob(blobID string) ([]byte, error) {
+	blobCache.Lock()
+	data, ok := blobCache.data[blobID]
+	blobCache.Unlock()
+	if ok {
+		return data, nil
+	}
+
+	data, err := im.repo().ReadBlob(blobID)
+	if err != nil {
+		return nil, err
+	}
+
+	blobCache.Lock()
+	if len(blobCache.data) >= blobCacheSize {
+		blobCache.data = make(map[string][]byte)
+	}
+	blobCache.data[blobID] = data
+	blobCache.Unlock()
+
+	return data, nil
+}
+
+// newSnapshot creates an empty snapshot
+func newSnapshot() *snapshot {
+	return &snapshot{
+		Issues:     make(map[string]*Issue),
+		Labels:     make(map[string]*Label),
+		Milestones: make(map[string]*Milestone),
+		Files:      make(map[string]string),
+	}
+}
+
+// newUID returns a new globally unique issue ID
+func newUID() (string, error) {
+	buf := make([]byte, 10)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate issue ID: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// issuePath returns the path of an issue in the issue tree
+func issuePath(uid string) string {
+	return issuePathPrefix + uid + ".json"
+}
+
+// repo returns the repository the issues are stored in
+func (im *IssueManager) repo() *repository.Repository {
+	return &repository.Repository{Path: im.RepoPath}
+}
+
+// author returns the name recorded on issue commits
+func (im *IssueManager) author() string {
+	if im.Author != "" {
+		return im.Author
+	}
+	return "snap"
+}
+
+// loadSnapshot reads the issue tracker state at a commit. An empty commit ID gives an empty snapshot.
+func (im *IssueManager) loadSnapshot(commitID string) (*snapshot, error) {
+	snap := newSnapshot()
+	snap.head = commitID
+	if commitID == "" {
+		return snap, nil
+	}
+
+	repo := im.repo()
+	commit, err := repo.GetCommit(commitID)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := repo.GetTree(commit.TreeID)
+	if err != nil {
+		return nil, err
+	}
+
+	for path, blobID := range tree.Entries {
+		var value interface{}
+		switch {
+		case strings.HasPrefix(path, issuePathPrefix):
+			value = &Issue{}
+		case strings.HasPrefix(path, labelPathPrefix):
+			value = &Label{}
+		case strings.HasPrefix(path, milestonePathPrefix):
+			value = &Milestone{}
+		default:
+			snap.Files[path] = blobID
+			continue
+		}
+
+		data, err := im.readBlob(blobID)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, value); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal %s: %w", path, err)
+		}
+
+		switch value := value.(type) {
+		case *Issue:
+			snap.Issues[value.UID] = value
+		case *Label:
+			snap.Labels[value.Name] = value
+		case *Milestone:
+			snap.Milestones[value.Name] = value
+		}
+	}
+
+	return snap, nil
+}
+
+// head returns the commit the issues reference points to, importing
+// issues from the legacy .snap/issues directory the first time
+func (im *IssueManager) head() (string, error) {
+	commitID, err := im.repo().ResolveRef(IssuesRef)
+	if err != nil {
+		return "", err
+	}
+	if commitID != "" {
+		return commitID, nil
+	}
+
+	return im.migrateLegacyIssues()
+}
+
+// current returns the current state of the issue tracker
+func (im *IssueManager) current() (*snapshot, error) {
+	commitID, err := im.head()
+	if err != nil {
+		return nil, err
+	}
+	return im.loadSnapshot(commitID)
+}
+
+// writeTree stores every issue, label and milestone in a snapshot and returns the resulting tree
+func (im *IssueManager) writeTree(snap *snapshot) (*repository.Tree, error) {
+	repo := im.repo()
+	tree := &repository.Tree{Entries: make(map[string]string)}
+
+	write := func(path string, value interface{}) error {
+		data, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s: %w", path, err)
+		}
+		blobID, err := repo.WriteBlob(data)
+		if err != nil {
+			return err
+		}
+		tree.Entries[path] = blobID
+		return nil
+	}
+
+	for path, blobID := range snap.Files {
+		tree.Entries[path] = blobID
+	}
+	for uid, issue := range snap.Issues {
+		if err := write(issuePath(uid), issue); err != nil {
+			return nil, err
+		}
+	}
+	for name, label := range snap.Labels {
+		if err := write(labelPathPrefix+name+".json", label); err != nil {
+			return nil, err
+		}
+	}
+	for name, milestone := range snap.Milestones {
+		if err := write(milestonePathPrefix+name+".json", milestone); err != nil {
+			return nil, err
+		}
+	}
+
+	return tree, nil
+}
+
+// commit records a new state of the issue tracker on the issues reference. It
+// fails with repository.ErrRefChanged if the issues changed since the snapshot was read,
+// rather than silently undoing the other change.
+func (im *IssueManager) commit(snap *snapshot, author, message, mergeParentID string) error {
+	tree, err := im.writeTree(snap)
+	if err != nil {
+		return err
+	}
+
+	commit, err := im.repo().CreateRefCommit(IssuesRef, snap.head, message, author, "", tree, mergeParentID)
+	if errors.Is(err, repository.ErrRefChanged) {
+		return fmt.Errorf("issues were changed at the same time, try again: %w", err)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to commit issues: %w", err)
+	}
+	snap.head = commit.ID
+	return nil
+}
+
+// legacyUID derives a stable UID for an issue imported from .snap/issues,
+// so clones that import the same legacy issue agree on its identity
+func legacyUID(issue *Issue) string {
+	hash := sha1.New()
+	fmt.Fprintf(hash, "legacy\x00%d\x00%s\x00%s", issue.ID, issue.CreatedBy, issue.CreatedAt.UTC().Format("2006-01-02T15:04:05.999999999Z"))
+	return hex.EncodeToString(hash.Sum(nil))[:20]
+}
+
+// migrateLegacyIssues imports issues stored as .snap/issues/<id>.json into the
+// issues reference and moves the old directory aside. It returns the new head, if any.
+func (im *IssueManager) migrateLegacyIssues() (string, error) {
+	legacyDir := filepath.Join(im.RepoPath, repository.SnapDirName, "issues")
+	files, err := os.ReadDir(legacyDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to read issues directory: %w", err)
+	}
+
+	snap := newSnapshot()
+	for _, file := range files {
+		var id int
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+		if _, err := fmt.Sscanf(file.Name(), "%d.json", &id); err != nil {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(legacyDir, file.Name()))
+		if err != nil {
+			return "", fmt.Errorf("failed to read issue file: %w", err)
+		}
+		var issue Issue
+		if err := json.Unmarshal(data, &issue); err != nil {
+			return "", fmt.Errorf("failed to unmarshal issue %s: %w", file.Name(), err)
+		}
+
+		issue.UID = legacyUID(&issue)
+		issue.UpdatedAt = issue.CreatedAt
+		if issue.ClosedAt.After(issue.UpdatedAt) {
+			issue.UpdatedAt = issue.ClosedAt
+		}
+		snap.Issues[issue.UID] = &issue
+	}
+
+	if len(snap.Issues) == 0 {
+		return "", nil
+	}
+
+	message := fmt.Sprintf("📝 Import %d issues from .snap/issues", len(snap.Issues))
+	if err := im.commit(snap, im.author(), message, ""); err != nil {
+		return "", err
+	}
+
+	// Keep the old files around, but out of the way
+	if err := os.Rename(legacyDir, legacyDir+".migrated"); err != nil {
+		return "", fmt.Errorf("failed to move legacy issues directory: %w", err)
+	}
+
+	return im.repo().ResolveRef(IssuesRef)
+}
+
+// sortedIssues returns the issues in a snapshot ordered by alias
+func (s *snapshot) sortedIssues() []*Issue {
+	issues := make([]*Issue, 0, len(s.Issues))
+	for _, issue := range s.Issues {
+		issues = append(issues, issue)
+	}
+	sort.Slice(issues, func(i, j int) bool {
+		if issues[i].ID != issues[j].ID {
+			return issues[i].ID < issues[j].ID
+		}
+		return issues[i].UID < issues[j].UID
+	})
+	return issues
+}
+
+// issue finds an issue in a snapshot by its alias
+func (s *snapshot) issue(id int) (*Issue, error) {
+	for _, issue := range s.Issues {
+		if issue.ID == id {
+			return issue, nil
+		}
+	}
+	return nil, fmt.Errorf("issue #%d not found", id)
+}
+
+// nextID returns the next free alias
+func (s *snapshot) nextID() int {
+	maxID := 0
+	for _, issue := range s.Issues {
+		if issue.ID > maxID {
+			maxID = issue.ID
+		}
+	}
+	return maxID + 1
+}
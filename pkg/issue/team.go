@@ -0,0 +1,38 @@
+package issue
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/stanlocht/snap/pkg/config"
+	"github.com/stanlocht/snap/pkg/repository"
+)
+
+// TeamAssigneePrefix marks an assignee that is a team rather than a user, e.g. team:backend
+const TeamAssigneePrefix = "team:"
+
+// AssignedTeam returns the team an issue is assigned to, or "" if it isn't assigned to a team
+func (i *Issue) AssignedTeam() string {
+	team, ok := strings.CutPrefix(i.AssignedTo, TeamAssigneePrefix)
+	if !ok {
+		return ""
+	}
+	return team
+}
+
+// checkTeam checks that a team is configured with [team "<name>"] in the config
+func (im *IssueManager) checkTeam(name string) error {
+	teams, err := config.GetSubsections(filepath.Join(im.RepoPath, repository.SnapDirName, "config"), "team")
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	for _, team := range teams {
+		if team == name {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown team %q: configure it with snap config set team.%s.members \"alice, bob\"", name, name)
+}
@@ -0,0 +1,313 @@
+package issue
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/stanlocht/snap/pkg/repository"
+)
+
+// TemplateDirName is the directory in .snap that holds issue templates
+const TemplateDirName = "issue_templates"
+
+// Template is an issue template: a markdown file with front matter, e.g.
+//
+//	---
+//	name: Bug report
+//	about: Something isn't working
+//	title: "[Bug] "
+//	labels: bug, needs-triage
+//	assignee: alice
+//	priority: high
+//	required: Steps to reproduce, Expected behavior
+//	---
+//	## Steps to reproduce
+//
+//	## Expected behavior
+type Template struct {
+	Name     string // File name without .md
+	Title    string // Display name
+	About    string
+	Prefix   string // Start of the title of new issues
+	Labels   []string
+	Assignee string
+	Priority Priority
+	Required []string // Sections that must be filled in
+	Body     string
+}
+
+var (
+	// markdownHeading matches a markdown heading and captures its text
+	markdownHeading = regexp.MustCompile(`^#{1,6}\s+(.*?)\s*#*\s*$`)
+	// htmlComment matches the hints templates leave for issue authors
+	htmlComment = regexp.MustCompile(`(?s)<!--.*?-->`)
+)
+
+// parseList parses a front matter list: "a, b", "[a, b]" or an empty value followed by "- a" lines
+func parseList(value string) []string {
+	value = strings.TrimSuffix(strings.TrimPrefix(value, "["), "]")
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = unquote(strings.TrimSpace(item)); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// unquote removes the quotes around a front matter value
+func unquote(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+// ParseTemplate parses an issue template. Unknown front matter keys are ignored.
+func ParseTemplate(name string, data []byte) (*Template, error) {
+	template := &Template{Name: name, Title: name}
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+
+	// Templates without front matter are all body
+	if !strings.HasPrefix(text, "---\n") {
+		template.Body = text
+		return template, nil
+	}
+	rest := text[3:]
+	end := strings.Index(rest, "\n---")
+	if end < 0 {
+		return nil, fmt.Errorf("template %s: front matter is not closed with ---", name)
+	}
+	frontMatter := strings.TrimPrefix(rest[:end], "\n")
+	template.Body = strings.TrimPrefix(rest[end+4:], "\n")
+
+	var listKey string
+	scanner := bufio.NewScanner(strings.NewReader(frontMatter))
+	for line := 2; scanner.Scan(); line++ {
+		raw := scanner.Text()
+		trimmed := strings.TrimSpace(raw)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		// Items of a block list
+		if strings.HasPrefix(trimmed, "- ") && listKey != "" {
+			item := unquote(strings.TrimSpace(trimmed[2:]))
+			switch listKey {
+			case "labels":
+				template.Labels = append(template.Labels, item)
+			case "required":
+				template.Required = append(template.Required, item)
+			}
+			continue
+		}
+
+		key, value, ok := strings.Cut(trimmed, ":")
+		if !ok {
+			return nil, fmt.Errorf("template %s: line %d: expected \"key: value\"", name, line)
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+		listKey = ""
+
+		switch key {
+		case "name":
+			template.Title = unquote(value)
+		case "about", "description":
+			template.About = unquote(value)
+		case "title":
+			template.Prefix = unquote(value)
+		case "labels", "required":
+			if value == "" {
+				listKey = key
+			}
+			if key == "labels" {
+				template.Labels = append(template.Labels, parseList(value)...)
+			} else {
+				template.Required = append(template.Required, parseList(value)...)
+			}
+		case "assignee", "assignees":
+			if assignees := parseList(value); len(assignees) > 0 {
+				template.Assignee = assignees[0]
+			}
+		case "priority":
+			priority, err := ParsePriority(unquote(value))
+			if err != nil {
+				return nil, fmt.Errorf("template %s: %w", name, err)
+			}
+			template.Priority = priority
+		}
+	}
+
+	// Required sections must be in the body, or no one could fill them in
+	sections := Sections(template.Body)
+	for _, required := range template.Required {
+		if _, ok := sections[strings.ToLower(required)]; !ok {
+			return nil, fmt.Errorf("template %s: required section %q is not in the template", name, required)
+		}
+	}
+
+	return template, nil
+}
+
+// Sections returns the text under each markdown heading of a description, by
+// lowercased heading. Comments are left out, so sections holding only the
+// template's hints are empty.
+func Sections(description string) map[string]string {
+	sections := make(map[string]string)
+	var heading string
+	var body []string
+	flush := func() {
+		if heading != "" {
+			sections[heading] = strings.TrimSpace(htmlComment.ReplaceAllString(strings.Join(body, "\n"), ""))
+		}
+	}
+
+	inCode := false
+	for _, line := range strings.Split(description, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCode = !inCode
+		}
+		if match := markdownHeading.FindStringSubmatch(line); match != nil && !inCode {
+			flush()
+			heading, body = strings.ToLower(match[1]), nil
+			continue
+		}
+		body = append(body, line)
+	}
+	flush()
+
+	return sections
+}
+
+// Validate checks that a description fills in every required section of a template
+func (t *Template) Validate(description string) error {
+	sections := Sections(description)
+	var missing []string
+	for _, required := range t.Required {
+		if sections[strings.ToLower(required)] == "" {
+			missing = append(missing, required)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("please fill in the required sections of the %s template: %s", t.Name, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// Draft returns the text issue authors start from: the title prefix on the first line, then the body
+func (t *Template) Draft() string {
+	return t.Prefix + "\n\n" + t.Body
+}
+
+// templateDir returns the directory holding the repository's issue templates
+func (im *IssueManager) templateDir() string {
+	return filepath.Join(im.RepoPath, repository.SnapDirName, TemplateDirName)
+}
+
+// ListTemplates lists the repository's issue templates, ordered by name
+func (im *IssueManager) ListTemplates() ([]*Template, error) {
+	entries, err := os.ReadDir(im.templateDir())
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read issue templates: %w", err)
+	}
+
+	var templates []*Template
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".md" {
+			continue
+		}
+		template, err := im.GetTemplate(strings.TrimSuffix(entry.Name(), ".md"))
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, template)
+	}
+	sort.Slice(templates, func(i, j int) bool { return templates[i].Name < templates[j].Name })
+
+	return templates, nil
+}
+
+// GetTemplate reads an issue template by name
+func (im *IssueManager) GetTemplate(name string) (*Template, error) {
+	if err := validateName("template", name); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(im.templateDir(), name+".md"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("template %s not found", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template %s: %w", name, err)
+	}
+	return ParseTemplate(name, data)
+}
+
+// CreateIssueFromTemplate creates an issue after checking that its description
+// fills in the template's required sections. The template's labels, assignee
+// and priority are applied, and labels that aren't defined yet are created.
+func (im *IssueManager) CreateIssueFromTemplate(template *Template, title, description, createdBy string) (*Issue, error) {
+	title = strings.TrimSpace(title)
+	if title == "" || title == strings.TrimSpace(template.Prefix) {
+		return nil, fmt.Errorf("issue title is required")
+	}
+	if err := template.Validate(description); err != nil {
+		return nil, err
+	}
+
+	snap, err := im.current()
+	if err != nil {
+		return nil, err
+	}
+	uid, err := newUID()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	issue := &Issue{
+		ID:          snap.nextID(),
+		UID:         uid,
+		Title:       title,
+		Description: strings.TrimSpace(htmlComment.ReplaceAllString(description, "")),
+		Status:      StatusOpen,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		CreatedBy:   createdBy,
+		AssignedTo:  template.Assignee,
+		Priority:    template.Priority,
+	}
+	for _, name := range template.Labels {
+		if err := validateName("label", name); err != nil {
+			return nil, fmt.Errorf("template %s: %w", template.Name, err)
+		}
+		if _, ok := snap.Labels[name]; !ok {
+			snap.Labels[name] = &Label{Name: name, Color: defaultLabelColor(name)}
+		}
+		if !issue.HasLabel(name) {
+			issue.Labels = append(issue.Labels, name)
+		}
+	}
+
+	snap.Issues[issue.UID] = issue
+	author := im.Author
+	if author == "" {
+		author = createdBy
+	}
+	if err := im.commit(snap, author, fmt.Sprintf("📝 Open issue #%d: %s", issue.ID, issue.Title), ""); err != nil {
+		return nil, err
+	}
+
+	return issue, nil
+}
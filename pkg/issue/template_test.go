@@ -0,0 +1,133 @@
+package issue
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const bugTemplate = `---
+name: Bug report
+about: Something isn't working
+title: "[Bug] "
+labels:
+  - bug
+  - needs-triage
+assignee: alice
+priority: high
+required: [Steps to reproduce, Expected behavior]
+---
+## Steps to reproduce
+<!-- How can we see the bug? -->
+
+## Expected behavior
+
+## Notes
+`
+
+func TestParseTemplate(t *testing.T) {
+	template, err := ParseTemplate("bug", []byte(bugTemplate))
+	if err != nil {
+		t.Fatalf("Failed to parse template: %v", err)
+	}
+	if template.Title != "Bug report" || template.About != "Something isn't working" || template.Prefix != "[Bug] " {
+		t.Errorf("Unexpected template header: %+v", template)
+	}
+	if strings.Join(template.Labels, ",") != "bug,needs-triage" || template.Assignee != "alice" || template.Priority != PriorityHigh {
+		t.Errorf("Unexpected template defaults: %+v", template)
+	}
+	if strings.Join(template.Required, ",") != "Steps to reproduce,Expected behavior" {
+		t.Errorf("Unexpected required sections: %v", template.Required)
+	}
+	if !strings.HasPrefix(template.Body, "## Steps to reproduce") {
+		t.Errorf("Expected the body to follow the front matter, got %q", template.Body)
+	}
+
+	// Templates without front matter are all body
+	template, err = ParseTemplate("plain", []byte("## Summary\n"))
+	if err != nil || template.Body != "## Summary\n" || template.Title != "plain" {
+		t.Errorf("Expected a plain template, got %+v, %v", template, err)
+	}
+
+	invalid := []string{
+		"---\nname: Unclosed\n",
+		"---\nno colon here\n---\n",
+		"---\npriority: urgent\n---\n",
+		"---\nrequired: Summary\n---\n## Details\n",
+	}
+	for _, data := range invalid {
+		if _, err := ParseTemplate("invalid", []byte(data)); err == nil {
+			t.Errorf("Expected template %q to be invalid", data)
+		}
+	}
+}
+
+func TestTemplateValidate(t *testing.T) {
+	template, err := ParseTemplate("bug", []byte(bugTemplate))
+	if err != nil {
+		t.Fatalf("Failed to parse template: %v", err)
+	}
+
+	// Hints and headings inside code blocks don't fill in sections
+	err = template.Validate("## Steps to reproduce\n<!-- How can we see the bug? -->\n\n## Expected behavior\n```\n## Notes\n```\n")
+	if err == nil || !strings.Contains(err.Error(), "Steps to reproduce") || strings.Contains(err.Error(), "Expected behavior") {
+		t.Errorf("Expected only Steps to reproduce to be missing, got %v", err)
+	}
+	if err := template.Validate("### steps to reproduce\nRun it\n## Expected Behavior ##\nNo crash"); err != nil {
+		t.Errorf("Expected headings to match regardless of case and level, got %v", err)
+	}
+}
+
+func TestCreateIssueFromTemplate(t *testing.T) {
+	dir := t.TempDir()
+	manager := NewIssueManager(dir)
+
+	// Without a template directory there are no templates
+	templates, err := manager.ListTemplates()
+	if err != nil || len(templates) != 0 {
+		t.Fatalf("Expected no templates, got %v, %v", templates, err)
+	}
+
+	templateDir := filepath.Join(dir, ".snap", TemplateDirName)
+	if err := os.MkdirAll(templateDir, 0755); err != nil {
+		t.Fatalf("Failed to create template directory: %v", err)
+	}
+	for name, data := range map[string]string{"bug.md": bugTemplate, "feature.md": "## Summary\n", "README.txt": "Not a template"} {
+		if err := os.WriteFile(filepath.Join(templateDir, name), []byte(data), 0644); err != nil {
+			t.Fatalf("Failed to write template: %v", err)
+		}
+	}
+	templates, err = manager.ListTemplates()
+	if err != nil || len(templates) != 2 || templates[0].Name != "bug" || templates[1].Name != "feature" {
+		t.Fatalf("Expected the bug and feature templates, got %v, %v", templates, err)
+	}
+	if _, err := manager.GetTemplate("missing"); err == nil {
+		t.Errorf("Expected a missing template to fail")
+	}
+
+	template := templates[0]
+	if _, err := manager.CreateIssueFromTemplate(template, "[Bug] ", "## Steps to reproduce\nRun it\n## Expected behavior\nNo crash", "bob"); err == nil {
+		t.Errorf("Expected an issue with only the title prefix to fail")
+	}
+	if _, err := manager.CreateIssueFromTemplate(template, "[Bug] Crash", template.Body, "bob"); err == nil {
+		t.Errorf("Expected an issue without its required sections to fail")
+	}
+
+	issue, err := manager.CreateIssueFromTemplate(template, "[Bug] Crash", "## Steps to reproduce\n<!-- How can we see the bug? -->\nRun it\n\n## Expected behavior\nNo crash", "bob")
+	if err != nil {
+		t.Fatalf("Failed to create issue: %v", err)
+	}
+	if issue.ID != 1 || issue.AssignedTo != "alice" || issue.Priority != PriorityHigh || !issue.HasLabel("needs-triage") {
+		t.Errorf("Expected the template's defaults to be applied, got %+v", issue)
+	}
+	if strings.Contains(issue.Description, "<!--") {
+		t.Errorf("Expected the template's hints to be removed, got %q", issue.Description)
+	}
+
+	// Missing labels are defined
+	labels, err := manager.ListLabels()
+	if err != nil || len(labels) != 2 {
+		t.Errorf("Expected the template's labels to be created, got %v, %v", labels, err)
+	}
+}
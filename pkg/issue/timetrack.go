@@ -0,0 +1,253 @@
+package issue
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Working time units accepted by ParseDuration
+const (
+	HoursPerDay = 8
+	DaysPerWeek = 5
+)
+
+// TimeEntry records time spent working on an issue
+type TimeEntry struct {
+	ID       string        `json:"id"`
+	Author   string        `json:"author"`
+	Duration time.Duration `json:"duration"`
+	Note     string        `json:"note,omitempty"`
+	Date     time.Time     `json:"date"` // When the work was done
+}
+
+// TimeReport sums up the estimated and logged time of a user or milestone
+type TimeReport struct {
+	Name      string
+	Issues    int           // Issues counted for the estimate
+	Estimate  time.Duration // Estimated time of those issues
+	Remaining time.Duration // Estimated time of those that are still open
+	Spent     time.Duration // Time logged
+}
+
+// durationPart matches one part of a duration such as "1.5h" or "2d"
+var durationPart = regexp.MustCompile(`^(\d+(?:\.\d+)?)([wdhm])`)
+
+// ParseDuration parses an amount of working time such as "45m", "1h30m", "1.5h"
+// or "2d". A day is HoursPerDay hours and a week DaysPerWeek days.
+func ParseDuration(s string) (time.Duration, error) {
+	text := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(s)), " ", "")
+	if text == "" {
+		return 0, fmt.Errorf("invalid duration %q: expected something like 45m, 1h30m or 2d", s)
+	}
+
+	units := map[string]time.Duration{
+		"w": DaysPerWeek * HoursPerDay * time.Hour,
+		"d": HoursPerDay * time.Hour,
+		"h": time.Hour,
+		"m": time.Minute,
+	}
+	var total time.Duration
+	for text != "" {
+		match := durationPart.FindStringSubmatch(text)
+		if match == nil {
+			return 0, fmt.Errorf("invalid duration %q: expected something like 45m, 1h30m or 2d", s)
+		}
+		amount, err := strconv.ParseFloat(match[1], 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q: %w", s, err)
+		}
+		total += time.Duration(amount * float64(units[match[2]]))
+		text = text[len(match[0]):]
+	}
+
+	total = total.Round(time.Minute)
+	if total <= 0 {
+		return 0, fmt.Errorf("invalid duration %q: must be at least a minute", s)
+	}
+	return total, nil
+}
+
+// FormatDuration formats an amount of time in hours and minutes, e.g. "1h30m".
+// Zero is formatted as an empty string.
+func FormatDuration(d time.Duration) string {
+	minutes := int(d.Round(time.Minute) / time.Minute)
+	switch {
+	case minutes == 0:
+		return ""
+	case minutes%60 == 0:
+		return fmt.Sprintf("%dh", minutes/60)
+	case minutes < 60:
+		return fmt.Sprintf("%dm", minutes)
+	default:
+		return fmt.Sprintf("%dh%dm", minutes/60, minutes%60)
+	}
+}
+
+// Spent returns the total time logged on an issue
+func (i *Issue) Spent() time.Duration {
+	var total time.Duration
+	for _, entry := range i.TimeEntries {
+		total += entry.Duration
+	}
+	return total
+}
+
+// TimeLog returns the time entries of an issue, oldest first
+func (i *Issue) TimeLog() []TimeEntry {
+	entries := append([]TimeEntry(nil), i.TimeEntries...)
+	sort.SliceStable(entries, func(a, b int) bool {
+		if !entries[a].Date.Equal(entries[b].Date) {
+			return entries[a].Date.Before(entries[b].Date)
+		}
+		return entries[a].ID < entries[b].ID
+	})
+	return entries
+}
+
+// SetEstimate sets how long an issue is expected to take. Zero removes the estimate.
+func (im *IssueManager) SetEstimate(id int, estimate time.Duration) error {
+	if estimate < 0 {
+		return fmt.Errorf("estimate can't be negative")
+	}
+
+	issue, err := im.GetIssue(id)
+	if err != nil {
+		return err
+	}
+	issue.Estimate = estimate.Round(time.Minute)
+
+	message := fmt.Sprintf("⏱️ Estimate issue #%d at %s", issue.ID, FormatDuration(issue.Estimate))
+	if issue.Estimate == 0 {
+		message = fmt.Sprintf("⏱️ Remove the estimate of issue #%d", issue.ID)
+	}
+	return im.saveIssue(issue, im.author(), message)
+}
+
+// LogTime records time spent on an issue. A zero date means now.
+func (im *IssueManager) LogTime(id int, author string, duration time.Duration, note string, date time.Time) (*TimeEntry, error) {
+	if duration < time.Minute {
+		return nil, fmt.Errorf("logged time must be at least a minute")
+	}
+	if date.IsZero() {
+		date = time.Now()
+	}
+
+	issue, err := im.GetIssue(id)
+	if err != nil {
+		return nil, err
+	}
+
+	uid, err := newUID()
+	if err != nil {
+		return nil, err
+	}
+	entry := TimeEntry{
+		ID:       uid[:8],
+		Author:   author,
+		Duration: duration.Round(time.Minute),
+		Note:     strings.TrimSpace(note),
+		Date:     date,
+	}
+	issue.TimeEntries = append(issue.TimeEntries, entry)
+
+	if err := im.saveIssue(issue, author, fmt.Sprintf("⏱️ Log %s on issue #%d", FormatDuration(entry.Duration), issue.ID)); err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+// UserTimeReport reports, for every user, the time they logged since a date
+// (all of it for a zero date) and the estimates of the issues assigned to them.
+// Users who logged the most time come first.
+func (im *IssueManager) UserTimeReport(since time.Time) ([]*TimeReport, error) {
+	snap, err := im.current()
+	if err != nil {
+		return nil, err
+	}
+
+	reports := make(map[string]*TimeReport)
+	report := func(name string) *TimeReport {
+		if reports[name] == nil {
+			reports[name] = &TimeReport{Name: name}
+		}
+		return reports[name]
+	}
+	for _, issue := range snap.Issues {
+		if issue.AssignedTo != "" {
+			addEstimate(report(issue.AssignedTo), issue)
+		}
+		for _, entry := range issue.TimeEntries {
+			if !entry.Date.Before(since) {
+				report(entry.Author).Spent += entry.Duration
+			}
+		}
+	}
+
+	list := make([]*TimeReport, 0, len(reports))
+	for _, report := range reports {
+		list = append(list, report)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Spent != list[j].Spent {
+			return list[i].Spent > list[j].Spent
+		}
+		return list[i].Name < list[j].Name
+	})
+
+	return list, nil
+}
+
+// MilestoneTimeReport reports, for every milestone, the estimates of its issues
+// and the time logged on them since a date (all of it for a zero date). Issues
+// without a milestone are reported last, under an empty name.
+func (im *IssueManager) MilestoneTimeReport(since time.Time) ([]*TimeReport, error) {
+	snap, err := im.current()
+	if err != nil {
+		return nil, err
+	}
+
+	reports := make(map[string]*TimeReport)
+	for name := range snap.Milestones {
+		reports[name] = &TimeReport{Name: name}
+	}
+	for _, issue := range snap.Issues {
+		report := reports[issue.Milestone]
+		if report == nil {
+			report = &TimeReport{Name: issue.Milestone}
+			reports[issue.Milestone] = report
+		}
+		addEstimate(report, issue)
+		for _, entry := range issue.TimeEntries {
+			if !entry.Date.Before(since) {
+				report.Spent += entry.Duration
+			}
+		}
+	}
+
+	list := make([]*TimeReport, 0, len(reports))
+	for _, report := range reports {
+		list = append(list, report)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if (list[i].Name == "") != (list[j].Name == "") {
+			return list[j].Name == ""
+		}
+		return list[i].Name < list[j].Name
+	})
+
+	return list, nil
+}
+
+// addEstimate counts an issue's estimate in a report
+func addEstimate(report *TimeReport, issue *Issue) {
+	report.Issues++
+	report.Estimate += issue.Estimate
+	if issue.Status != StatusClosed {
+		report.Remaining += issue.Estimate
+	}
+}
@@ -0,0 +1,179 @@
+package issue
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseDuration(t *testing.T) {
+	valid := map[string]time.Duration{
+		"45m":     45 * time.Minute,
+		"3h":      3 * time.Hour,
+		"1h30m":   90 * time.Minute,
+		"1.5h":    90 * time.Minute,
+		"1d 4h":   12 * time.Hour,
+		"1w":      40 * time.Hour,
+		" 2H 15M": 135 * time.Minute,
+	}
+	for value, expected := range valid {
+		duration, err := ParseDuration(value)
+		if err != nil || duration != expected {
+			t.Errorf("Expected %q to be %v, got %v, %v", value, expected, duration, err)
+		}
+	}
+
+	for _, value := range []string{"", "3", "h", "3x", "-1h", "0m", "10s", "1h 30"} {
+		if _, err := ParseDuration(value); err == nil {
+			t.Errorf("Expected %q to be invalid", value)
+		}
+	}
+
+	formatted := map[time.Duration]string{
+		0:                "",
+		45 * time.Minute: "45m",
+		3 * time.Hour:    "3h",
+		90 * time.Minute: "1h30m",
+		40 * time.Hour:   "40h",
+	}
+	for duration, expected := range formatted {
+		if value := FormatDuration(duration); value != expected {
+			t.Errorf("Expected %v to be formatted as %q, got %q", duration, expected, value)
+		}
+	}
+}
+
+func TestTimeTracking(t *testing.T) {
+	manager := NewIssueManager(t.TempDir())
+	manager.Author = "alice"
+
+	if _, err := manager.CreateMilestone("v1", "", time.Time{}); err != nil {
+		t.Fatalf("Failed to create milestone: %v", err)
+	}
+	for _, title := range []string{"Crash", "Typo", "Docs"} {
+		if _, err := manager.CreateIssue(title, "", "alice"); err != nil {
+			t.Fatalf("Failed to create issue: %v", err)
+		}
+	}
+	for _, id := range []int{1, 2} {
+		if err := manager.SetMilestone(id, "v1"); err != nil {
+			t.Fatalf("Failed to set milestone: %v", err)
+		}
+	}
+	if err := manager.AssignIssue(1, "bob"); err != nil {
+		t.Fatalf("Failed to assign issue: %v", err)
+	}
+
+	// Estimates are recorded in the timeline
+	if err := manager.SetEstimate(1, 3*time.Hour); err != nil {
+		t.Fatalf("Failed to set estimate: %v", err)
+	}
+	if err := manager.SetEstimate(2, time.Hour); err != nil {
+		t.Fatalf("Failed to set estimate: %v", err)
+	}
+	issue, err := manager.GetIssue(1)
+	if err != nil {
+		t.Fatalf("Failed to get issue: %v", err)
+	}
+	history := issue.History()
+	if last := history[len(history)-1]; issue.Estimate != 3*time.Hour || last.Describe() != "estimated the issue at 3h" {
+		t.Errorf("Expected an estimate of 3h in the timeline, got %v and %q", issue.Estimate, last.Describe())
+	}
+
+	// Time is logged per user
+	lastWeek := time.Now().AddDate(0, 0, -7)
+	if _, err := manager.LogTime(1, "bob", 2*time.Hour, "Reproduced it", lastWeek); err != nil {
+		t.Fatalf("Failed to log time: %v", err)
+	}
+	if _, err := manager.LogTime(1, "carol", 45*time.Minute, "", time.Time{}); err != nil {
+		t.Fatalf("Failed to log time: %v", err)
+	}
+	if _, err := manager.LogTime(3, "bob", 30*time.Minute, "", time.Time{}); err != nil {
+		t.Fatalf("Failed to log time: %v", err)
+	}
+	if _, err := manager.LogTime(3, "bob", 0, "", time.Time{}); err == nil {
+		t.Errorf("Expected logging no time to fail")
+	}
+	if err := manager.CloseIssue(2, ""); err != nil {
+		t.Fatalf("Failed to close issue: %v", err)
+	}
+
+	issue, err = manager.GetIssue(1)
+	if err != nil {
+		t.Fatalf("Failed to get issue: %v", err)
+	}
+	if issue.Spent() != 2*time.Hour+45*time.Minute || issue.TimeLog()[0].Note != "Reproduced it" {
+		t.Errorf("Expected 2h45m spent, starting with bob's entry, got %v", issue.Spent())
+	}
+
+	users, err := manager.UserTimeReport(time.Time{})
+	if err != nil {
+		t.Fatalf("Failed to get user report: %v", err)
+	}
+	if len(users) != 2 || users[0].Name != "bob" || users[0].Spent != 150*time.Minute || users[0].Estimate != 3*time.Hour {
+		t.Errorf("Expected bob first with 2h30m spent of 3h, got %+v", users[0])
+	}
+
+	// Only time logged since the date counts
+	users, err = manager.UserTimeReport(time.Now().AddDate(0, 0, -1))
+	if err != nil {
+		t.Fatalf("Failed to get user report: %v", err)
+	}
+	if users[0].Name != "carol" || users[0].Spent != 45*time.Minute {
+		t.Errorf("Expected carol first with 45m spent, got %+v", users[0])
+	}
+
+	milestones, err := manager.MilestoneTimeReport(time.Time{})
+	if err != nil {
+		t.Fatalf("Failed to get milestone report: %v", err)
+	}
+	if len(milestones) != 2 || milestones[0].Name != "v1" || milestones[1].Name != "" {
+		t.Fatalf("Expected v1 and issues without a milestone, got %d reports", len(milestones))
+	}
+	v1 := milestones[0]
+	if v1.Issues != 2 || v1.Estimate != 4*time.Hour || v1.Remaining != 3*time.Hour || v1.Spent != 2*time.Hour+45*time.Minute {
+		t.Errorf("Unexpected report for v1: %+v", v1)
+	}
+
+	// Removing the estimate
+	if err := manager.SetEstimate(1, 0); err != nil {
+		t.Fatalf("Failed to remove estimate: %v", err)
+	}
+	issue, err = manager.GetIssue(1)
+	if err != nil {
+		t.Fatalf("Failed to get issue: %v", err)
+	}
+	history = issue.History()
+	if last := history[len(history)-1]; issue.Estimate != 0 || !strings.Contains(last.Describe(), "removed the estimate") {
+		t.Errorf("Expected the estimate to be removed, got %v and %q", issue.Estimate, last.Describe())
+	}
+}
+
+func TestMergeIssuesKeepsTimeFromBothSides(t *testing.T) {
+	manager := NewIssueManager(t.TempDir())
+	if _, err := manager.CreateIssue("Crash", "", "alice"); err != nil {
+		t.Fatalf("Failed to create issue: %v", err)
+	}
+
+	theirsID := divergeIssues(t, manager, func() {
+		if _, err := manager.LogTime(1, "alice", time.Hour, "", time.Time{}); err != nil {
+			t.Fatalf("Failed to log time: %v", err)
+		}
+	}, func() {
+		if _, err := manager.LogTime(1, "bob", 30*time.Minute, "", time.Time{}); err != nil {
+			t.Fatalf("Failed to log time: %v", err)
+		}
+	})
+
+	result, err := manager.Merge(theirsID)
+	if err != nil {
+		t.Fatalf("Failed to merge issues: %v", err)
+	}
+	issue, err := manager.GetIssue(1)
+	if err != nil {
+		t.Fatalf("Failed to get issue: %v", err)
+	}
+	if issue.Spent() != 90*time.Minute || len(result.Conflicts) != 0 {
+		t.Errorf("Expected the time from both sides without conflicts, got %v and %v", issue.Spent(), result.Conflicts)
+	}
+}
@@ -0,0 +1,153 @@
+package issue
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/stanlocht/snap/pkg/config"
+	"github.com/stanlocht/snap/pkg/repository"
+)
+
+// WorkflowConfigKey is the config key listing the workflow states, in order
+const WorkflowConfigKey = "workflow.states"
+
+// DefaultStates are the workflow states used when none are configured
+var DefaultStates = []string{"todo", "in-progress", "review", "done"}
+
+// Workflow is the ordered list of states an issue moves through. Issues start
+// in the first state and are closed in the last one.
+type Workflow struct {
+	States []string
+}
+
+// ParseWorkflow parses a comma-separated list of workflow states
+func ParseWorkflow(value string) (*Workflow, error) {
+	workflow := &Workflow{}
+	for _, state := range strings.Split(value, ",") {
+		state = strings.ToLower(strings.TrimSpace(state))
+		if err := validateName("state", state); err != nil {
+			return nil, err
+		}
+		if strings.ContainsAny(state, " \t") {
+			return nil, fmt.Errorf("state name %q contains spaces", state)
+		}
+		if workflow.Has(state) {
+			return nil, fmt.Errorf("state %s is listed twice", state)
+		}
+		workflow.States = append(workflow.States, state)
+	}
+
+	if len(workflow.States) < 2 {
+		return nil, fmt.Errorf("a workflow needs at least two states")
+	}
+	return workflow, nil
+}
+
+// Has reports whether a state is part of a workflow
+func (w *Workflow) Has(state string) bool {
+	for _, s := range w.States {
+		if s == state {
+			return true
+		}
+	}
+	return false
+}
+
+// Initial returns the state new issues are in
+func (w *Workflow) Initial() string {
+	return w.States[0]
+}
+
+// Done returns the state of closed issues
+func (w *Workflow) Done() string {
+	return w.States[len(w.States)-1]
+}
+
+// StateOf returns the state of an issue. Closed issues are always done, and
+// open issues in a state the workflow doesn't know are in the initial state.
+func (w *Workflow) StateOf(i *Issue) string {
+	switch {
+	case i.Status == StatusClosed:
+		return w.Done()
+	case i.State != w.Done() && w.Has(i.State):
+		return i.State
+	default:
+		return w.Initial()
+	}
+}
+
+// Workflow returns the workflow configured for the repository, or the default one
+func (im *IssueManager) Workflow() (*Workflow, error) {
+	value, err := config.GetValue(filepath.Join(im.RepoPath, repository.SnapDirName, "config"), WorkflowConfigKey)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	if value == "" {
+		return &Workflow{States: DefaultStates}, nil
+	}
+
+	workflow, err := ParseWorkflow(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s in config: %w", WorkflowConfigKey, err)
+	}
+	return workflow, nil
+}
+
+// MoveIssue moves an issue to another workflow state. Moving an issue to the
+// last state closes it, which is refused with ErrBlocked while open issues
+// block it unless force is set. Moving it out of the last state reopens it.
+func (im *IssueManager) MoveIssue(id int, state string, force bool) error {
+	workflow, err := im.Workflow()
+	if err != nil {
+		return err
+	}
+	state = strings.ToLower(strings.TrimSpace(state))
+	if !workflow.Has(state) {
+		return fmt.Errorf("unknown state %q (states are %s)", state, strings.Join(workflow.States, ", "))
+	}
+
+	issue, err := im.GetIssue(id)
+	if err != nil {
+		return err
+	}
+	from := workflow.StateOf(issue)
+	if from == state {
+		return fmt.Errorf("issue #%d is already in %s", issue.ID, state)
+	}
+
+	// Like closing, finishing an issue has to wait for the issues blocking it
+	if state == workflow.Done() && issue.Status != StatusClosed && !force {
+		blockers, err := im.OpenBlockers(issue.ID)
+		if err != nil {
+			return err
+		}
+		if len(blockers) > 0 {
+			ids := make([]string, len(blockers))
+			for i, blocker := range blockers {
+				ids[i] = fmt.Sprintf("#%d", blocker.ID)
+			}
+			return fmt.Errorf("%w: #%d is blocked by %s", ErrBlocked, issue.ID, strings.Join(ids, ", "))
+		}
+	}
+
+	now := time.Now()
+	issue.State = state
+	if state == workflow.Done() {
+		issue.Status = StatusClosed
+		issue.ClosedAt = now
+		issue.ClosedBy = ""
+	} else if issue.Status == StatusClosed {
+		issue.Status = StatusOpen
+		issue.ClosedAt = time.Time{}
+		issue.ClosedBy = ""
+	}
+	if err := issue.addEvent(Event{Actor: im.author(), At: now, Field: "state", Old: from, New: state}); err != nil {
+		return err
+	}
+
+	return im.saveIssue(issue, im.author(), fmt.Sprintf("📋 Move issue #%d from %s to %s", issue.ID, from, state))
+}
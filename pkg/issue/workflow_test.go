@@ -0,0 +1,143 @@
+package issue
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseWorkflow(t *testing.T) {
+	workflow, err := ParseWorkflow("Backlog, doing ,done")
+	if err != nil {
+		t.Fatalf("Failed to parse workflow: %v", err)
+	}
+	if len(workflow.States) != 3 || workflow.Initial() != "backlog" || workflow.States[1] != "doing" || workflow.Done() != "done" {
+		t.Errorf("Expected backlog, doing and done, got %v", workflow.States)
+	}
+
+	for _, value := range []string{"", "todo", "todo, todo", "todo, in progress, done", "todo,,done"} {
+		if _, err := ParseWorkflow(value); err == nil {
+			t.Errorf("Expected workflow %q to be invalid", value)
+		}
+	}
+}
+
+func TestMoveIssue(t *testing.T) {
+	dir := t.TempDir()
+	manager := NewIssueManager(dir)
+	manager.Author = "alice"
+
+	// Without a config the default workflow is used
+	workflow, err := manager.Workflow()
+	if err != nil {
+		t.Fatalf("Failed to get workflow: %v", err)
+	}
+	if len(workflow.States) != len(DefaultStates) {
+		t.Errorf("Expected the default workflow, got %v", workflow.States)
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, ".snap"), 0755); err != nil {
+		t.Fatalf("Failed to create config directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".snap", "config"), []byte("[workflow]\n\tstates = backlog, doing, done\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	workflow, err = manager.Workflow()
+	if err != nil {
+		t.Fatalf("Failed to get workflow: %v", err)
+	}
+
+	issue, err := manager.CreateIssue("Crash", "", "alice")
+	if err != nil {
+		t.Fatalf("Failed to create issue: %v", err)
+	}
+	if state := workflow.StateOf(issue); state != "backlog" {
+		t.Errorf("Expected a new issue to be in backlog, got %s", state)
+	}
+
+	if err := manager.MoveIssue(issue.ID, "Doing", false); err != nil {
+		t.Fatalf("Failed to move issue: %v", err)
+	}
+	if err := manager.MoveIssue(issue.ID, "doing", false); err == nil {
+		t.Errorf("Expected moving an issue to its own state to fail")
+	}
+	if err := manager.MoveIssue(issue.ID, "review", false); err == nil {
+		t.Errorf("Expected moving an issue to an unknown state to fail")
+	}
+
+	// The last state closes the issue, and leaving it reopens the issue
+	if err := manager.MoveIssue(issue.ID, "done", false); err != nil {
+		t.Fatalf("Failed to move issue: %v", err)
+	}
+	issue, err = manager.GetIssue(issue.ID)
+	if err != nil {
+		t.Fatalf("Failed to get issue: %v", err)
+	}
+	if issue.Status != StatusClosed || issue.ClosedAt.IsZero() {
+		t.Errorf("Expected moving the issue to done to close it")
+	}
+	if err := manager.MoveIssue(issue.ID, "doing", false); err != nil {
+		t.Fatalf("Failed to move issue: %v", err)
+	}
+
+	// Closing and reopening an issue keeps its state consistent
+	if err := manager.CloseIssue(issue.ID, ""); err != nil {
+		t.Fatalf("Failed to close issue: %v", err)
+	}
+	issue, err = manager.GetIssue(issue.ID)
+	if err != nil {
+		t.Fatalf("Failed to get issue: %v", err)
+	}
+	if state := workflow.StateOf(issue); state != "done" {
+		t.Errorf("Expected a closed issue to be done, got %s", state)
+	}
+	if err := manager.ReopenIssue(issue.ID); err != nil {
+		t.Fatalf("Failed to reopen issue: %v", err)
+	}
+	issue, err = manager.GetIssue(issue.ID)
+	if err != nil {
+		t.Fatalf("Failed to get issue: %v", err)
+	}
+	if state := workflow.StateOf(issue); state != "doing" {
+		t.Errorf("Expected a reopened issue to go back to doing, got %s", state)
+	}
+
+	var moves []string
+	for _, event := range issue.History() {
+		if event.Field == "state" {
+			moves = append(moves, event.Describe())
+		}
+	}
+	expected := []string{
+		"moved the issue from backlog to doing",
+		"moved the issue from doing to done",
+		"moved the issue from done to doing",
+	}
+	if len(moves) != len(expected) {
+		t.Fatalf("Expected %d moves, got %v", len(expected), moves)
+	}
+	for i := range expected {
+		if moves[i] != expected[i] {
+			t.Errorf("Expected move %d to be %q, got %q", i, expected[i], moves[i])
+		}
+	}
+
+	// Like closing, finishing an issue waits for the issues blocking it
+	blocker, err := manager.CreateIssue("Design", "", "alice")
+	if err != nil {
+		t.Fatalf("Failed to create issue: %v", err)
+	}
+	if err := manager.AddLink(blocker.ID, issue.ID, LinkBlocks); err != nil {
+		t.Fatalf("Failed to link issues: %v", err)
+	}
+	if err := manager.MoveIssue(issue.ID, "done", false); !errors.Is(err, ErrBlocked) {
+		t.Errorf("Expected moving a blocked issue to done to fail, got %v", err)
+	}
+	if blocked, _ := manager.GetIssue(issue.ID); blocked.Status == StatusClosed {
+		t.Errorf("Expected the blocked issue to stay open")
+	}
+	if err := manager.MoveIssue(issue.ID, "done", true); err != nil {
+		t.Errorf("Expected a forced move to close the blocked issue, got %v", err)
+	}
+}
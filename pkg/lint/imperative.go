@@ -0,0 +1,60 @@
+package lint
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// verbs are the verbs commit subjects commonly start with
+var verbs = []string{
+	"add", "adjust", "allow", "apply", "avoid", "bump", "build", "cache", "change", "check",
+	"clean", "configure", "convert", "correct", "create", "delete", "deploy", "deprecate",
+	"disable", "document", "drop", "enable", "ensure", "expose", "extract", "fix", "format",
+	"handle", "hide", "implement", "improve", "install", "introduce", "load", "log", "make",
+	"merge", "migrate", "move", "optimize", "parse", "prevent", "print", "reduce", "refactor",
+	"register", "release", "remove", "rename", "render", "reorder", "replace", "restore",
+	"revert", "rewrite", "save", "show", "simplify", "sort", "speed", "split", "store",
+	"support", "test", "tidy", "translate", "tweak", "update", "upgrade", "use", "validate",
+	"wrap", "write",
+}
+
+// irregular are past tenses of verbs that don't end in -ed
+var irregular = map[string]string{
+	"built": "build", "made": "make", "wrote": "write", "rewrote": "rewrite", "hid": "hide",
+	"shown": "show",
+}
+
+// nonImperative maps the other forms of the verbs to the verbs, e.g. "fixed" and "fixes" to "fix"
+var nonImperative = func() map[string]string {
+	forms := make(map[string]string)
+	for _, verb := range verbs {
+		stem := strings.TrimSuffix(verb, "e")
+		last := verb[len(verb)-1:]
+		for _, form := range []string{verb + "s", verb + "es", stem + "ed", stem + "ing", verb + last + "ed", verb + last + "ing"} {
+			forms[form] = verb
+		}
+		if strings.HasSuffix(verb, "y") {
+			forms[strings.TrimSuffix(verb, "y")+"ies"] = verb
+			forms[strings.TrimSuffix(verb, "y")+"ied"] = verb
+		}
+	}
+	for form, verb := range irregular {
+		forms[form] = verb
+	}
+	return forms
+}()
+
+// imperative returns the imperative of a subject's first word, capitalized as
+// the word is, if the word is another form of a common verb
+func imperative(word string) (string, bool) {
+	word = strings.TrimRight(word, ":,.")
+	verb, ok := nonImperative[strings.ToLower(word)]
+	if !ok {
+		return "", false
+	}
+	if r, _ := utf8.DecodeRuneInString(word); unicode.IsUpper(r) {
+		verb = strings.ToUpper(verb[:1]) + verb[1:]
+	}
+	return verb, true
+}
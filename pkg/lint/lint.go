@@ -0,0 +1,302 @@
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/stanlocht/snap/pkg/config"
+	"github.com/stanlocht/snap/pkg/issue"
+	"github.com/stanlocht/snap/pkg/snapmoji"
+)
+
+// Severity is how the findings of a rule are treated
+type Severity string
+
+const (
+	// SeverityError rejects the message
+	SeverityError Severity = "error"
+	// SeverityWarning reports the finding but accepts the message
+	SeverityWarning Severity = "warning"
+	// SeverityOff turns the rule off
+	SeverityOff Severity = "off"
+)
+
+// The rules a commit message is checked against
+const (
+	RuleSnapmoji       = "snapmoji"        // Starts with a snapmoji of the repository, always an error
+	RuleSubjectLength  = "subject-length"  // The subject is neither empty nor too long
+	RuleImperative     = "imperative"      // The subject starts with a verb in the imperative mood
+	RuleBlankLine      = "blank-line"      // A blank line separates the subject from the body
+	RuleIssueReference = "issue-reference" // Commits by some snapmojis reference an issue
+	RuleBannedWords    = "banned-words"    // The message uses no banned words
+	RuleScope          = "scope"           // The scope, as in "✨ (web) Add dark mode", is valid
+)
+
+// Rules lists the rules in the order they are checked
+var Rules = []string{RuleSnapmoji, RuleScope, RuleSubjectLength, RuleImperative, RuleBlankLine, RuleIssueReference, RuleBannedWords}
+
+// Finding is a problem a rule found in a commit message
+type Finding struct {
+	Rule     string
+	Severity Severity
+	Message  string
+}
+
+// Policy configures the rules commit messages are checked against
+type Policy struct {
+	Severities       map[string]Severity
+	SubjectMaxLength int      // Characters in the first line, snapmoji included
+	SubjectMinLength int      // Characters in the subject after the snapmoji and scope
+	IssueSnapmojis   []string // Snapmojis whose commits must reference an issue, by emoji or code
+	BannedWords      []string
+	Scopes           []string // Scopes that may be used, any if empty
+	ScopeRequired    bool
+
+	snapmojis *snapmoji.Registry
+}
+
+// scopePattern matches a scope after the snapmoji, e.g. "(web)" or "(web):"
+var scopePattern = regexp.MustCompile(`^\(([^()]*)\):?(?:\s+|$)`)
+
+// scopeName matches the names scopes may have
+var scopeName = regexp.MustCompile(`^[a-z0-9][a-z0-9._/-]*$`)
+
+// DefaultPolicy returns the policy used when a repository configures none:
+// a snapmoji is required and banned words are rejected, while the other
+// rules, such as subjects of at most 72 characters, only warn
+func DefaultPolicy() *Policy {
+	return &Policy{
+		Severities: map[string]Severity{
+			RuleSnapmoji:       SeverityError,
+			RuleSubjectLength:  SeverityWarning,
+			RuleImperative:     SeverityWarning,
+			RuleBlankLine:      SeverityWarning,
+			RuleIssueReference: SeverityWarning,
+			RuleBannedWords:    SeverityError,
+			RuleScope:          SeverityWarning,
+		},
+		SubjectMaxLength: 72,
+		SubjectMinLength: 1,
+		IssueSnapmojis:   []string{":bug:"},
+		snapmojis:        snapmoji.Default(),
+	}
+}
+
+// Load returns a repository's policy: the defaults, changed field by field by
+// [lint "<rule>"] sections of the config
+func Load(repoPath string) (*Policy, error) {
+	policy := DefaultPolicy()
+	registry, err := snapmoji.Load(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	policy.snapmojis = registry
+
+	path := filepath.Join(repoPath, ".snap", "config")
+	rules, err := config.GetSubsections(path, "lint")
+	if errors.Is(err, fs.ErrNotExist) {
+		return policy, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, rule := range rules {
+		fields, ok := ruleFields[rule]
+		if !ok {
+			return nil, fmt.Errorf("unknown lint rule %q: use one of %s", rule, strings.Join(Rules, ", "))
+		}
+		for _, field := range append([]string{"severity"}, fields...) {
+			key := fmt.Sprintf("lint.%s.%s", rule, field)
+			value, err := config.GetValue(path, key)
+			if err != nil {
+				return nil, err
+			}
+			if value == "" {
+				continue
+			}
+			if err := policy.set(rule, field, value); err != nil {
+				return nil, fmt.Errorf("invalid %s in config: %w", key, err)
+			}
+		}
+	}
+	return policy, nil
+}
+
+// ruleFields lists the fields of each rule that can be configured besides its severity
+var ruleFields = map[string][]string{
+	RuleSnapmoji:       nil,
+	RuleSubjectLength:  {"max", "min"},
+	RuleImperative:     nil,
+	RuleBlankLine:      nil,
+	RuleIssueReference: {"snapmojis"},
+	RuleBannedWords:    {"words"},
+	RuleScope:          {"allowed", "required"},
+}
+
+// set sets a rule field from a config value
+func (p *Policy) set(rule, field, value string) error {
+	var err error
+	switch field {
+	case "severity":
+		severity := Severity(value)
+		if severity != SeverityError && severity != SeverityWarning && severity != SeverityOff {
+			return fmt.Errorf("expected error, warning or off, got %q", value)
+		}
+		if rule == RuleSnapmoji && severity != SeverityError {
+			return errors.New("commit messages must always start with a snapmoji")
+		}
+		p.Severities[rule] = severity
+	case "max":
+		p.SubjectMaxLength, err = strconv.Atoi(value)
+		if err == nil && p.SubjectMaxLength < 1 {
+			err = errors.New("must be at least 1")
+		}
+	case "min":
+		p.SubjectMinLength, err = strconv.Atoi(value)
+		if err == nil && p.SubjectMinLength < 0 {
+			err = errors.New("can't be negative")
+		}
+	case "snapmojis":
+		p.IssueSnapmojis = nil
+		for _, key := range splitList(value) {
+			if _, ok := p.snapmojis.Find(key); !ok {
+				return fmt.Errorf("%q is not a snapmoji", key)
+			}
+			p.IssueSnapmojis = append(p.IssueSnapmojis, key)
+		}
+	case "words":
+		p.BannedWords = splitList(value)
+	case "allowed":
+		p.Scopes = splitList(value)
+		for _, scope := range p.Scopes {
+			if !scopeName.MatchString(scope) {
+				return fmt.Errorf("invalid scope %q", scope)
+			}
+		}
+	case "required":
+		p.ScopeRequired, err = strconv.ParseBool(value)
+	}
+	return err
+}
+
+// splitList splits a comma-separated config value
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// HasErrors reports whether any finding rejects the message
+func HasErrors(findings []Finding) bool {
+	for _, finding := range findings {
+		if finding.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Lint checks a commit message against the policy, returning the findings of the rules that are on
+func (p *Policy) Lint(message string) []Finding {
+	var findings []Finding
+	report := func(rule, format string, args ...interface{}) {
+		if severity := p.Severities[rule]; severity != SeverityOff && severity != "" {
+			findings = append(findings, Finding{Rule: rule, Severity: severity, Message: fmt.Sprintf(format, args...)})
+		}
+	}
+
+	lines := strings.Split(strings.TrimRight(message, "\n"), "\n")
+	first := strings.TrimRight(lines[0], " \t\r")
+
+	// Snapmoji
+	matched, hasSnapmoji := p.snapmojis.Match(first)
+	if err := p.snapmojis.Validate(message); err != nil {
+		report(RuleSnapmoji, "%v", err)
+	}
+
+	// Scope
+	subject := p.snapmojis.Strip(first)
+	scope := ""
+	if match := scopePattern.FindStringSubmatch(subject); match != nil {
+		scope = match[1]
+		subject = subject[len(match[0]):]
+		switch {
+		case !scopeName.MatchString(scope):
+			report(RuleScope, "invalid scope %q: use lowercase letters, digits, dots, slashes and dashes", scope)
+		case len(p.Scopes) > 0 && !contains(p.Scopes, scope):
+			report(RuleScope, "unknown scope %q: use one of %s", scope, strings.Join(p.Scopes, ", "))
+		}
+	} else if strings.HasPrefix(subject, "(") {
+		report(RuleScope, "scope isn't closed: write it as (scope) after the snapmoji")
+	} else if p.ScopeRequired {
+		report(RuleScope, "the subject needs a scope, e.g. ✨ (web) Add dark mode")
+	}
+
+	// Subject length
+	if length := utf8.RuneCountInString(first); length > p.SubjectMaxLength {
+		report(RuleSubjectLength, "subject is %d characters, more than %d", length, p.SubjectMaxLength)
+	}
+	if length := utf8.RuneCountInString(strings.TrimSpace(subject)); length == 0 && p.SubjectMinLength > 0 {
+		report(RuleSubjectLength, "subject is empty")
+	} else if length < p.SubjectMinLength {
+		report(RuleSubjectLength, "subject is %d characters, less than %d", length, p.SubjectMinLength)
+	}
+
+	// Imperative mood
+	if word, _, _ := strings.Cut(strings.TrimSpace(subject), " "); word != "" {
+		if verb, ok := imperative(word); ok {
+			report(RuleImperative, "use the imperative mood: %q instead of %q", verb, strings.TrimRight(word, ":,."))
+		}
+	}
+
+	// Blank line
+	if len(lines) > 1 && strings.TrimSpace(lines[1]) != "" {
+		report(RuleBlankLine, "separate the subject from the body with a blank line")
+	}
+
+	// Issue reference
+	if hasSnapmoji && p.needsIssue(matched) && len(issue.ParseReferences(message)) == 0 {
+		report(RuleIssueReference, "%s commits must reference an issue, e.g. fixes #12", matched.Emoji)
+	}
+
+	// Banned words
+	for _, word := range p.BannedWords {
+		pattern := regexp.MustCompile(`(?i)(?:^|[^\p{L}\p{N}_])` + regexp.QuoteMeta(word) + `(?:$|[^\p{L}\p{N}_])`)
+		if pattern.MatchString(message) {
+			report(RuleBannedWords, "%q is banned from commit messages", word)
+		}
+	}
+
+	return findings
+}
+
+// needsIssue reports whether commits by a snapmoji must reference an issue
+func (p *Policy) needsIssue(s snapmoji.Snapmoji) bool {
+	for _, key := range p.IssueSnapmojis {
+		if found, ok := p.snapmojis.Find(key); ok && found.Emoji == s.Emoji {
+			return true
+		}
+	}
+	return false
+}
+
+// contains reports whether a list holds a value
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
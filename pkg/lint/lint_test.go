@@ -0,0 +1,114 @@
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// rules returns the rules and severities of findings, e.g. "subject-length:warning"
+func rules(findings []Finding) string {
+	var names []string
+	for _, finding := range findings {
+		names = append(names, finding.Rule+":"+string(finding.Severity))
+	}
+	return strings.Join(names, ",")
+}
+
+func TestLint(t *testing.T) {
+	policy := DefaultPolicy()
+	policy.BannedWords = []string{"wip", "fixup!"}
+	testCases := []struct {
+		message  string
+		expected string
+	}{
+		{"✨ Add dark mode", ""},
+		{"✨ (web) Add dark mode", ""},
+		{"✨ (web): Add dark mode\n\nUsers asked for it.", ""},
+		{"Add dark mode", "snapmoji:error"},
+		{"✨", "subject-length:warning"},
+		{"✨ Add " + strings.Repeat("a", 70), "subject-length:warning"},
+		{"✨ Added dark mode", "imperative:warning"},
+		{"♻️ Refactoring: the parser", "imperative:warning"},
+		{"✨ Add dark mode\nUsers asked for it.", "blank-line:warning"},
+		{"🐛 Fix crash", "issue-reference:warning"},
+		{"🐛 Fix crash\n\nFixes #12", ""},
+		{":bug: Fix crash (#3)", ""},
+		{"🚧 WIP on the parser", "banned-words:error"},
+		{"✨ Add wipe command", ""},
+		{"✨ (Web) Add dark mode", "scope:warning"},
+		{"✨ (web Add dark mode", "scope:warning"},
+	}
+	for _, tc := range testCases {
+		if got := rules(policy.Lint(tc.message)); got != tc.expected {
+			t.Errorf("Lint(%q) = %q, want %q", tc.message, got, tc.expected)
+		}
+	}
+
+	if HasErrors(policy.Lint("🐛 Fixed crash")) {
+		t.Errorf("Expected warnings not to reject a message")
+	}
+	if findings := policy.Lint("✨ Fixed crash"); len(findings) != 1 || !strings.Contains(findings[0].Message, `"Fix" instead of "Fixed"`) {
+		t.Errorf("Expected the imperative to be suggested, got %v", findings)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(tempDir, ".snap"), 0755); err != nil {
+		t.Fatalf("Failed to create .snap: %v", err)
+	}
+	configPath := filepath.Join(tempDir, ".snap", "config")
+
+	// Without a config, the defaults apply
+	policy, err := Load(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to load policy: %v", err)
+	}
+	if policy.SubjectMaxLength != 72 || policy.Severities[RuleImperative] != SeverityWarning {
+		t.Errorf("Expected the default policy, got %+v", policy)
+	}
+
+	config := "[lint \"subject-length\"]\n\tmax = 50\n\tseverity = error\n" +
+		"[lint \"imperative\"]\n\tseverity = off\n" +
+		"[lint \"issue-reference\"]\n\tsnapmojis = 🐛, :ambulance:\n\tseverity = error\n" +
+		"[lint \"scope\"]\n\tallowed = web, cli\n\trequired = true\n\tseverity = error\n"
+	if err := os.WriteFile(configPath, []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	policy, err = Load(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to load policy: %v", err)
+	}
+	testCases := []struct {
+		message  string
+		expected string
+	}{
+		{"✨ (web) Added dark mode", ""},
+		{"✨ Add dark mode", "scope:error"},
+		{"✨ (api) Add dark mode", "scope:error"},
+		{"✨ (web) Add " + strings.Repeat("a", 40), "subject-length:error"},
+		{"🚑 (cli) Fix crash", "issue-reference:error"},
+	}
+	for _, tc := range testCases {
+		if got := rules(policy.Lint(tc.message)); got != tc.expected {
+			t.Errorf("Lint(%q) = %q, want %q", tc.message, got, tc.expected)
+		}
+	}
+
+	for _, config := range []string{
+		"[lint \"snapmoji\"]\n\tseverity = off\n",
+		"[lint \"imperative\"]\n\tseverity = loud\n",
+		"[lint \"spelling\"]\n\tseverity = error\n",
+		"[lint \"subject-length\"]\n\tmax = 0\n",
+		"[lint \"issue-reference\"]\n\tsnapmojis = 🦄\n",
+	} {
+		if err := os.WriteFile(configPath, []byte(config), 0644); err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+		if _, err := Load(tempDir); err == nil {
+			t.Errorf("Expected config %q to be invalid", strings.TrimSpace(config))
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package remote
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/stanlocht/snap/pkg/repository"
+)
+
+// HTTP transport endpoints, relative to the repository URL
+const (
+	InfoRefsPath    = "/snap/info/refs"
+	UploadPackPath  = "/snap/upload-pack"
+	ReceivePackPath = "/snap/receive-pack"
+
+	// PackContentType is the content type of a pack stream
+	PackContentType = "application/x-snap-pack"
+)
+
+// Error codes reported by the receive endpoint, so clients can map them back to errors
+const (
+	codeNonFastForward = "non-fast-forward"
+	codeStaleRef       = "stale-ref"
+)
+
+// FetchRequest is sent to the upload-pack endpoint to negotiate which objects to send
+type FetchRequest struct {
+	Wants []string `json:"wants"`
+	Haves []string `json:"haves"`
+}
+
+// PushResponse is returned by the receive-pack endpoint
+type PushResponse struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+	Code  string `json:"code,omitempty"`
+}
+
+// NewPushResponse builds the response for the outcome of ReceivePack
+func NewPushResponse(err error) *PushResponse {
+	if err == nil {
+		return &PushResponse{OK: true}
+	}
+
+	response := &PushResponse{Error: err.Error()}
+	switch {
+	case errors.Is(err, ErrNonFastForward):
+		response.Code = codeNonFastForward
+	case errors.Is(err, ErrStaleRef):
+		response.Code = codeStaleRef
+	}
+	return response
+}
+
+// Err turns a push response back into an error
+func (r *PushResponse) Err() error {
+	if r.OK {
+		return nil
+	}
+
+	switch r.Code {
+	case codeNonFastForward:
+		return fmt.Errorf("remote rejected push: %w", ErrNonFastForward)
+	case codeStaleRef:
+		return fmt.Errorf("remote rejected push: %w", ErrStaleRef)
+	}
+	return fmt.Errorf("remote rejected push: %s", r.Error)
+}
+
+// WriteReceiveRequest writes a push request: the reference updates on one line, followed by a pack
+func WriteReceiveRequest(w io.Writer, objects []*repository.Object, updates []RefUpdate) error {
+	data, err := json.Marshal(updates)
+	if err != nil {
+		return fmt.Errorf("failed to encode reference updates: %w", err)
+	}
+	if _, err := fmt.Fprintf(w, "%s\n", data); err != nil {
+		return err
+	}
+
+	pack := NewPackWriter(w)
+	for _, object := range objects {
+		if err := pack.WriteObject(object); err != nil {
+			return err
+		}
+	}
+	return pack.Close()
+}
+
+// ReadReceiveRequest reads a push request written by WriteReceiveRequest,
+// refusing requests whose objects add up to more than maxBytes
+func ReadReceiveRequest(r io.Reader, maxBytes int64) ([]*repository.Object, []RefUpdate, error) {
+	br := bufio.NewReader(r)
+
+	line, err := br.ReadBytes('\n')
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read reference updates: %w", err)
+	}
+	var updates []RefUpdate
+	if err := json.Unmarshal(line, &updates); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse reference updates: %w", err)
+	}
+
+	pack := NewPackReader(br)
+	pack.Limit(maxBytes)
+	objects, err := pack.ReadAll()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return objects, updates, nil
+}
+
+// isHTTPURL reports whether a URL uses the HTTP transport
+func isHTTPURL(url string) bool {
+	return strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://")
+}
+
+// httpTransport talks to a repository served by snap web
+type httpTransport struct {
+	baseURL string
+	client  *http.Client
+}
+
+// openHTTP opens a transport to the repository served at url
+func openHTTP(url string) (Transport, error) {
+	return &httpTransport{
+		baseURL: strings.TrimRight(url, "/"),
+		client:  &http.Client{Timeout: 10 * time.Minute},
+	}, nil
+}
+
+// checkResponse turns unexpected HTTP statuses into errors
+func checkResponse(resp *http.Response) error {
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	message := strings.TrimSpace(string(body))
+	if message == "" {
+		message = resp.Status
+	}
+	return fmt.Errorf("server returned %s: %s", resp.Status, message)
+}
+
+// Advertise returns the references the remote offers
+func (t *httpTransport) Advertise() (*Advertisement, error) {
+	resp, err := t.client.Get(t.baseURL + InfoRefsPath)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := checkResponse(resp); err != nil {
+		return nil, err
+	}
+
+	var adv Advertisement
+	if err := json.NewDecoder(resp.Body).Decode(&adv); err != nil {
+		return nil, fmt.Errorf("failed to parse reference advertisement: %w", err)
+	}
+	if adv.Refs == nil {
+		adv.Refs = make(map[string]string)
+	}
+	return &adv, nil
+}
+
+// Fetch asks the server for the objects needed to get from haves to wants and
+// streams them to receive as they arrive
+func (t *httpTransport) Fetch(wants, haves []string, receive func(object *repository.Object) error) error {
+	body, err := json.Marshal(&FetchRequest{Wants: wants, Haves: haves})
+	if err != nil {
+		return err
+	}
+
+	resp, err := t.client.Post(t.baseURL+UploadPackPath, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := checkResponse(resp); err != nil {
+		return err
+	}
+
+	pack := NewPackReader(resp.Body)
+	for {
+		object, err := pack.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := receive(object); err != nil {
+			return err
+		}
+	}
+}
+
+// Push streams objects to the server and asks it to apply the reference updates
+func (t *httpTransport) Push(objects []*repository.Object, updates []RefUpdate) error {
+	reader, writer := io.Pipe()
+	go func() {
+		writer.CloseWithError(WriteReceiveRequest(writer, objects, updates))
+	}()
+
+	resp, err := t.client.Post(t.baseURL+ReceivePackPath, PackContentType, reader)
+	reader.Close()
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Rejected pushes carry a push response, other failures are plain text
+	var response PushResponse
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+		if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+			return fmt.Errorf("failed to parse push response: %w", err)
+		}
+		return response.Err()
+	}
+	if err := checkResponse(resp); err != nil {
+		return err
+	}
+	return nil
+}
+
+// DirFromURL returns the directory name clone uses when none is given
+func DirFromURL(rawURL string) string {
+	trimmed := strings.TrimRight(rawURL, "/\\")
+	if !isHTTPURL(rawURL) {
+		return path.Base(strings.ReplaceAll(strings.TrimPrefix(trimmed, "file://"), "\\", "/"))
+	}
+
+	u, err := url.Parse(trimmed)
+	if err != nil {
+		return path.Base(trimmed)
+	}
+	if base := path.Base(u.Path); base != "." && base != "/" {
+		return base
+	}
+	// Served from the root of the host, e.g. http://host:8123/
+	return u.Hostname()
+}
@@ -0,0 +1,49 @@
+package remote
+
+import (
+	"fmt"
+
+	"github.com/stanlocht/snap/pkg/repository"
+)
+
+// localTransport talks to a repository on the local filesystem
+type localTransport struct {
+	repo *repository.Repository
+}
+
+// openLocal opens a transport to the repository at path
+func openLocal(path string) (Transport, error) {
+	if !repository.IsInitialized(path) {
+		return nil, fmt.Errorf("'%s' does not appear to be a snap repository", path)
+	}
+
+	return &localTransport{
+		repo: &repository.Repository{Path: path},
+	}, nil
+}
+
+// Advertise returns the references the repository offers
+func (t *localTransport) Advertise() (*Advertisement, error) {
+	return AdvertiseRefs(t.repo)
+}
+
+// Fetch sends every object needed to get from haves to wants
+func (t *localTransport) Fetch(wants, haves []string, receive func(object *repository.Object) error) error {
+	objects, err := t.repo.ReachableObjects(wants, haves)
+	if err != nil {
+		return err
+	}
+
+	for _, object := range objects {
+		if err := receive(object); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Push stores objects in the repository and applies the reference updates
+func (t *localTransport) Push(objects []*repository.Object, updates []RefUpdate) error {
+	return ReceivePack(t.repo, objects, updates)
+}
@@ -0,0 +1,181 @@
+package remote
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/stanlocht/snap/pkg/repository"
+)
+
+// A pack is a stream of objects sent between repositories:
+//
+//	SNAPPACK 1
+//	<type> <id> <size>
+//	<size bytes of object data>
+//	...
+//	END
+const (
+	packSignature = "SNAPPACK 1"
+	packTrailer   = "END"
+
+	// MaxObjectSize is the largest object accepted in a pack
+	MaxObjectSize = 512 << 20
+)
+
+// PackWriter writes objects to a pack stream
+type PackWriter struct {
+	w           *bufio.Writer
+	wroteHeader bool
+}
+
+// NewPackWriter creates a pack writer on top of w
+func NewPackWriter(w io.Writer) *PackWriter {
+	return &PackWriter{w: bufio.NewWriter(w)}
+}
+
+// writeHeader writes the pack signature once
+func (pw *PackWriter) writeHeader() error {
+	if pw.wroteHeader {
+		return nil
+	}
+	pw.wroteHeader = true
+	_, err := fmt.Fprintf(pw.w, "%s\n", packSignature)
+	return err
+}
+
+// WriteObject appends an object to the pack
+func (pw *PackWriter) WriteObject(object *repository.Object) error {
+	if err := pw.writeHeader(); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(pw.w, "%s %s %d\n", object.Type, object.ID, len(object.Data)); err != nil {
+		return err
+	}
+	if _, err := pw.w.Write(object.Data); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Close writes the pack trailer and flushes buffered data. It doesn't close the underlying writer.
+func (pw *PackWriter) Close() error {
+	if err := pw.writeHeader(); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(pw.w, "%s\n", packTrailer); err != nil {
+		return err
+	}
+	return pw.w.Flush()
+}
+
+// ErrPackTooLarge is returned when a pack holds more data than its reader allows
+var ErrPackTooLarge = errors.New("pack is too large")
+
+// PackReader reads objects from a pack stream
+type PackReader struct {
+	r          *bufio.Reader
+	readHeader bool
+	done       bool
+	limited    bool
+	remaining  int64 // Object bytes still allowed when limited
+}
+
+// NewPackReader creates a pack reader on top of r
+func NewPackReader(r io.Reader) *PackReader {
+	if br, ok := r.(*bufio.Reader); ok {
+		return &PackReader{r: br}
+	}
+	return &PackReader{r: bufio.NewReader(r)}
+}
+
+// Limit caps the total size of the objects read from the pack. Objects over
+// the limit are refused before they are read.
+func (pr *PackReader) Limit(maxBytes int64) {
+	pr.limited = true
+	pr.remaining = maxBytes
+}
+
+// readLine reads a single header line without its newline
+func (pr *PackReader) readLine() (string, error) {
+	line, err := pr.r.ReadString('\n')
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return "", io.ErrUnexpectedEOF
+		}
+		return "", err
+	}
+	return strings.TrimSuffix(line, "\n"), nil
+}
+
+// Next returns the next object in the pack, or io.EOF after the last one
+func (pr *PackReader) Next() (*repository.Object, error) {
+	if pr.done {
+		return nil, io.EOF
+	}
+
+	if !pr.readHeader {
+		signature, err := pr.readLine()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read pack header: %w", err)
+		}
+		if signature != packSignature {
+			return nil, fmt.Errorf("not a snap pack (got %q)", signature)
+		}
+		pr.readHeader = true
+	}
+
+	line, err := pr.readLine()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pack entry: %w", err)
+	}
+	if line == packTrailer {
+		pr.done = true
+		return nil, io.EOF
+	}
+
+	// Parse "<type> <id> <size>"
+	fields := strings.Fields(line)
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("malformed pack entry: %q", line)
+	}
+	size, err := strconv.Atoi(fields[2])
+	if err != nil || size < 0 || size > MaxObjectSize {
+		return nil, fmt.Errorf("invalid object size in pack entry: %q", line)
+	}
+	if pr.limited {
+		if int64(size) > pr.remaining {
+			return nil, fmt.Errorf("object %s: %w", fields[1], ErrPackTooLarge)
+		}
+		pr.remaining -= int64(size)
+	}
+
+	object := &repository.Object{
+		Type: repository.ObjectType(fields[0]),
+		ID:   fields[1],
+		Data: make([]byte, size),
+	}
+	if _, err := io.ReadFull(pr.r, object.Data); err != nil {
+		return nil, fmt.Errorf("failed to read object %s: %w", object.ID, err)
+	}
+
+	return object, nil
+}
+
+// ReadAll reads every remaining object in the pack
+func (pr *PackReader) ReadAll() ([]*repository.Object, error) {
+	var objects []*repository.Object
+	for {
+		object, err := pr.Next()
+		if errors.Is(err, io.EOF) {
+			return objects, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, object)
+	}
+}
@@ -0,0 +1,121 @@
+package remote
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stanlocht/snap/pkg/repository"
+)
+
+func TestPackRoundTrip(t *testing.T) {
+	objects := []*repository.Object{
+		{Type: repository.ObjectBlob, ID: "b1", Data: []byte("hello\nworld\n")},
+		{Type: repository.ObjectTree, ID: "t1", Data: []byte(`{"entries":{}}`)},
+		{Type: repository.ObjectBlob, ID: "b2", Data: []byte{}},
+	}
+
+	// Write a pack
+	var buf bytes.Buffer
+	writer := NewPackWriter(&buf)
+	for _, object := range objects {
+		if err := writer.WriteObject(object); err != nil {
+			t.Fatalf("Failed to write object: %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Failed to close pack: %v", err)
+	}
+
+	// Read it back
+	read, err := NewPackReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to read pack: %v", err)
+	}
+	if len(read) != len(objects) {
+		t.Fatalf("Expected %d objects, got %d", len(objects), len(read))
+	}
+	for i, object := range objects {
+		if read[i].Type != object.Type || read[i].ID != object.ID || !bytes.Equal(read[i].Data, object.Data) {
+			t.Errorf("Expected object %v, got %v", object, read[i])
+		}
+	}
+
+	// An empty pack is valid
+	buf.Reset()
+	if err := NewPackWriter(&buf).Close(); err != nil {
+		t.Fatalf("Failed to write empty pack: %v", err)
+	}
+	if read, err := NewPackReader(&buf).ReadAll(); err != nil || len(read) != 0 {
+		t.Errorf("Expected an empty pack, got %d objects and error %v", len(read), err)
+	}
+
+	// Truncated and foreign streams are rejected
+	if _, err := NewPackReader(strings.NewReader("SNAPPACK 1\nblob b1 10\nabc")).ReadAll(); err == nil {
+		t.Errorf("Expected an error for a truncated pack")
+	}
+	if _, err := NewPackReader(strings.NewReader("<html>\n")).ReadAll(); err == nil {
+		t.Errorf("Expected an error for a stream that isn't a pack")
+	}
+}
+
+func TestPackLimit(t *testing.T) {
+	var buf bytes.Buffer
+	writer := NewPackWriter(&buf)
+	for _, id := range []string{"b1", "b2"} {
+		if err := writer.WriteObject(&repository.Object{Type: repository.ObjectBlob, ID: id, Data: []byte("0123456789")}); err != nil {
+			t.Fatalf("Failed to write object: %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Failed to close pack: %v", err)
+	}
+	data := buf.Bytes()
+
+	// Objects count against the limit together
+	reader := NewPackReader(bytes.NewReader(data))
+	reader.Limit(20)
+	if read, err := reader.ReadAll(); err != nil || len(read) != 2 {
+		t.Errorf("Expected 2 objects within the limit, got %d and error %v", len(read), err)
+	}
+	reader = NewPackReader(bytes.NewReader(data))
+	reader.Limit(15)
+	if _, err := reader.ReadAll(); !errors.Is(err, ErrPackTooLarge) {
+		t.Errorf("Expected ErrPackTooLarge, got %v", err)
+	}
+
+	// Oversized objects are refused from their header, before their data arrives
+	reader = NewPackReader(strings.NewReader("SNAPPACK 1\nblob b1 1000000\n"))
+	reader.Limit(1000)
+	if _, err := reader.ReadAll(); !errors.Is(err, ErrPackTooLarge) {
+		t.Errorf("Expected ErrPackTooLarge for an oversized header, got %v", err)
+	}
+}
+
+func TestPushResponseErrors(t *testing.T) {
+	// Rejections survive the round trip through a push response
+	response := NewPushResponse(ErrNonFastForward)
+	if !errors.Is(response.Err(), ErrNonFastForward) {
+		t.Errorf("Expected ErrNonFastForward, got %v", response.Err())
+	}
+	if err := NewPushResponse(nil).Err(); err != nil {
+		t.Errorf("Expected no error for a successful push, got %v", err)
+	}
+}
+
+func TestDirFromURL(t *testing.T) {
+	tests := map[string]string{
+		"/home/me/project":             "project",
+		"/home/me/project/":            "project",
+		"file:///srv/repos/app":        "app",
+		"http://host:8123/":            "host",
+		"http://host:8123":             "host",
+		"https://example.com/snap/app": "app",
+	}
+	for url, expected := range tests {
+		if dir := DirFromURL(url); dir != expected {
+			t.Errorf("Expected '%s' for %s, got '%s'", expected, url, dir)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package remote
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/stanlocht/snap/pkg/config"
+	"github.com/stanlocht/snap/pkg/repository"
+)
+
+// DefaultRemoteName is the name given to the remote a repository was cloned from
+const DefaultRemoteName = "origin"
+
+var (
+	// ErrNonFastForward is returned when a push would discard commits on the remote
+	ErrNonFastForward = errors.New("non-fast-forward update rejected")
+	// ErrStaleRef is returned when a remote reference changed since it was advertised
+	ErrStaleRef = errors.New("remote reference changed since it was fetched")
+)
+
+// Remote represents a configured remote repository
+type Remote struct {
+	Name string
+	URL  string
+}
+
+// Advertisement lists the references a repository offers to its peers
+type Advertisement struct {
+	Head string            `json:"head,omitempty"` // Reference HEAD points to, e.g. refs/heads/master
+	Refs map[string]string `json:"refs"`           // Reference name to commit ID
+}
+
+// RefUpdate asks the receiving side to move a reference from Old to New
+type RefUpdate struct {
+	Name  string `json:"name"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+	Force bool   `json:"force,omitempty"`
+}
+
+// Transport moves objects and references between two repositories
+type Transport interface {
+	// Advertise returns the references the remote offers
+	Advertise() (*Advertisement, error)
+	// Fetch sends every object needed to get from haves to wants to receive
+	Fetch(wants, haves []string, receive func(object *repository.Object) error) error
+	// Push stores objects on the remote and applies the reference updates
+	Push(objects []*repository.Object, updates []RefUpdate) error
+}
+
+// configPath returns the path to a repository's config file
+func configPath(repo *repository.Repository) string {
+	return filepath.Join(repo.Path, repository.SnapDirName, "config")
+}
+
+// validateName checks that a remote name can be stored in the config file and used in reference names
+func validateName(name string) error {
+	if name == "" || strings.ContainsAny(name, " \t\"/\\.[]") {
+		return fmt.Errorf("invalid remote name: %q", name)
+	}
+	return nil
+}
+
+// GetRemotes returns all remotes configured for a repository
+func GetRemotes(repo *repository.Repository) ([]*Remote, error) {
+	names, err := config.GetSubsections(configPath(repo), "remote")
+	if err != nil {
+		return nil, err
+	}
+
+	remotes := make([]*Remote, 0, len(names))
+	for _, name := range names {
+		remote, err := GetRemote(repo, name)
+		if err != nil {
+			return nil, err
+		}
+		remotes = append(remotes, remote)
+	}
+
+	return remotes, nil
+}
+
+// GetRemote returns a configured remote by name
+func GetRemote(repo *repository.Repository, name string) (*Remote, error) {
+	url, err := config.GetValue(configPath(repo), "remote."+name+".url")
+	if err != nil {
+		return nil, err
+	}
+	if url == "" {
+		return nil, fmt.Errorf("no such remote: %s", name)
+	}
+
+	return &Remote{Name: name, URL: url}, nil
+}
+
+// AddRemote adds a remote to a repository's config
+func AddRemote(repo *repository.Repository, name, url string) (*Remote, error) {
+	if err := validateName(name); err != nil {
+		return nil, err
+	}
+	if _, err := GetRemote(repo, name); err == nil {
+		return nil, fmt.Errorf("remote %s already exists", name)
+	}
+
+	url = normalizeURL(url)
+	if err := config.SetValue(configPath(repo), "remote."+name+".url", url); err != nil {
+		return nil, err
+	}
+
+	return &Remote{Name: name, URL: url}, nil
+}
+
+// RemoveRemote removes a remote and its remote-tracking references
+func RemoveRemote(repo *repository.Repository, name string) error {
+	if _, err := GetRemote(repo, name); err != nil {
+		return err
+	}
+
+	if err := config.RemoveSection(configPath(repo), "remote", name); err != nil {
+		return err
+	}
+
+	// Remove remote-tracking references
+	refs, err := repo.ListRefs(trackingPrefix(name))
+	if err != nil {
+		return err
+	}
+	for ref := range refs {
+		if err := repo.DeleteRef(ref); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isLocalURL reports whether a URL refers to a repository on the filesystem
+func isLocalURL(url string) bool {
+	return !strings.Contains(url, "://") || strings.HasPrefix(url, "file://")
+}
+
+// normalizeURL turns relative filesystem paths into absolute ones so the
+// remote keeps working when snap runs from another directory
+func normalizeURL(url string) string {
+	if !isLocalURL(url) {
+		return url
+	}
+
+	path := strings.TrimPrefix(url, "file://")
+	if absPath, err := filepath.Abs(path); err == nil {
+		return absPath
+	}
+	return path
+}
+
+// Open returns a transport for a remote URL
+func Open(url string) (Transport, error) {
+	if isLocalURL(url) {
+		return openLocal(strings.TrimPrefix(url, "file://"))
+	}
+	if isHTTPURL(url) {
+		return openHTTP(url)
+	}
+	return nil, fmt.Errorf("unsupported remote URL: %s", url)
+}
+
+// trackingPrefix returns the prefix of the remote-tracking references for a remote
+func trackingPrefix(remoteName string) string {
+	return repository.RemoteRefPrefix + remoteName + "/"
+}
+
+// TrackingRef returns the remote-tracking reference for a branch on a remote,
+// e.g. refs/remotes/origin/master
+func TrackingRef(remoteName, branch string) string {
+	return trackingPrefix(remoteName) + branch
+}
+
+// trackingRefFor returns the remote-tracking reference for any reference a remote
+// advertises, e.g. refs/snap/issues is tracked as refs/remotes/origin/snap/issues.
+// References that aren't tracked give "".
+func trackingRefFor(remoteName, ref string) string {
+	switch {
+	case strings.HasPrefix(ref, repository.BranchRefPrefix):
+		return TrackingRef(remoteName, strings.TrimPrefix(ref, repository.BranchRefPrefix))
+	case strings.HasPrefix(ref, repository.MetadataRefPrefix):
+		return trackingPrefix(remoteName) + strings.TrimPrefix(ref, "refs/")
+	default:
+		return ""
+	}
+}
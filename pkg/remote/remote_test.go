@@ -0,0 +1,464 @@
+package remote
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stanlocht/snap/pkg/issue"
+	"github.com/stanlocht/snap/pkg/lint"
+	"github.com/stanlocht/snap/pkg/repository"
+	"github.com/stanlocht/snap/pkg/snapmoji"
+	"github.com/stanlocht/snap/pkg/storage"
+)
+
+// setupTestRepo creates a repository in a temporary directory
+func setupTestRepo(t *testing.T) *repository.Repository {
+	t.Helper()
+
+	repo, err := repository.Init(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to initialize repository: %v", err)
+	}
+	return repo
+}
+
+// commitFile writes a file into the working tree, stages it and commits it
+func commitFile(t *testing.T, repo *repository.Repository, name, content, message string) *repository.Commit {
+	t.Helper()
+
+	index := stageFile(t, repo, name, content)
+	commit, err := repo.CreateCommit(message, "testuser", "test@example.com", &repository.Tree{Entries: index.Entries})
+	if err != nil {
+		t.Fatalf("Failed to create commit: %v", err)
+	}
+	return commit
+}
+
+// stageFile writes a file into the working tree and stages it
+func stageFile(t *testing.T, repo *repository.Repository, name, content string) *storage.Index {
+	t.Helper()
+
+	filePath := filepath.Join(repo.Path, name)
+	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	index, err := storage.LoadIndex(repo.Path)
+	if err != nil {
+		t.Fatalf("Failed to load index: %v", err)
+	}
+	if _, err := index.AddFile(repo.Path, filePath); err != nil {
+		t.Fatalf("Failed to add file: %v", err)
+	}
+	if err := index.SaveIndex(repo.Path); err != nil {
+		t.Fatalf("Failed to save index: %v", err)
+	}
+	return index
+}
+
+func TestRemoteConfig(t *testing.T) {
+	repo := setupTestRepo(t)
+	upstream := setupTestRepo(t)
+
+	// Add a remote
+	if _, err := AddRemote(repo, "origin", upstream.Path); err != nil {
+		t.Fatalf("Failed to add remote: %v", err)
+	}
+
+	// Adding it twice fails
+	if _, err := AddRemote(repo, "origin", upstream.Path); err == nil {
+		t.Errorf("Expected an error when adding a duplicate remote")
+	}
+
+	// Invalid names are rejected
+	if _, err := AddRemote(repo, "bad/name", upstream.Path); err == nil {
+		t.Errorf("Expected an error for an invalid remote name")
+	}
+
+	remotes, err := GetRemotes(repo)
+	if err != nil {
+		t.Fatalf("Failed to get remotes: %v", err)
+	}
+	if len(remotes) != 1 || remotes[0].URL != upstream.Path {
+		t.Errorf("Expected one remote pointing at %s, got %v", upstream.Path, remotes)
+	}
+
+	// Remove the remote
+	if err := RemoveRemote(repo, "origin"); err != nil {
+		t.Fatalf("Failed to remove remote: %v", err)
+	}
+	if _, err := GetRemote(repo, "origin"); err == nil {
+		t.Errorf("Expected remote to be removed")
+	}
+}
+
+func TestCloneFetchAndPull(t *testing.T) {
+	upstream := setupTestRepo(t)
+	first := commitFile(t, upstream, "README.md", "hello", "✨ Initial commit")
+
+	// Clone the repository
+	cloneDir := filepath.Join(t.TempDir(), "clone")
+	clone, _, err := Clone(upstream.Path, cloneDir)
+	if err != nil {
+		t.Fatalf("Failed to clone repository: %v", err)
+	}
+
+	// Check the working tree and refs
+	content, err := os.ReadFile(filepath.Join(cloneDir, "README.md"))
+	if err != nil {
+		t.Fatalf("Expected README.md to be checked out: %v", err)
+	}
+	if string(content) != "hello" {
+		t.Errorf("Expected README.md to contain 'hello', got '%s'", content)
+	}
+	head, _ := clone.GetHEADCommitID()
+	if head != first.ID {
+		t.Errorf("Expected HEAD to be %s, got %s", first.ID, head)
+	}
+	tracking, _ := clone.ResolveRef(TrackingRef("origin", "master"))
+	if tracking != first.ID {
+		t.Errorf("Expected origin/master to be %s, got %s", first.ID, tracking)
+	}
+
+	// A new upstream commit arrives with fetch, without moving the local branch
+	second := commitFile(t, upstream, "README.md", "hello again", "📚 Update README")
+	result, err := Fetch(clone, "origin")
+	if err != nil {
+		t.Fatalf("Failed to fetch: %v", err)
+	}
+	if len(result.Updated) != 1 {
+		t.Errorf("Expected one updated ref, got %d", len(result.Updated))
+	}
+	head, _ = clone.GetHEADCommitID()
+	if head != first.ID {
+		t.Errorf("Expected fetch not to move HEAD, got %s", head)
+	}
+
+	// Only the new commit, tree and blob should be sent
+	if result.Objects != 3 {
+		t.Errorf("Expected 3 objects to be fetched, got %d", result.Objects)
+	}
+
+	// Pull fast-forwards
+	pullResult, err := Pull(clone, "origin", "master", "testuser", "test@example.com")
+	if err != nil {
+		t.Fatalf("Failed to pull: %v", err)
+	}
+	if pullResult.Outcome != PullFastForward {
+		t.Errorf("Expected a fast-forward, got %s", pullResult.Outcome)
+	}
+	content, _ = os.ReadFile(filepath.Join(cloneDir, "README.md"))
+	if string(content) != "hello again" {
+		t.Errorf("Expected README.md to be updated, got '%s'", content)
+	}
+	head, _ = clone.GetHEADCommitID()
+	if head != second.ID {
+		t.Errorf("Expected HEAD to be %s after pull, got %s", second.ID, head)
+	}
+}
+
+func TestCloneRejectsUnsafeTrees(t *testing.T) {
+	upstream := setupTestRepo(t)
+	index := stageFile(t, upstream, "a.txt", "escaped")
+	for _, path := range []string{"../escaped.txt", ".snap/refs/heads/master"} {
+		tree := &repository.Tree{Entries: map[string]string{path: index.Entries["a.txt"]}}
+		if _, err := upstream.CreateCommit("✨ Escape", "mallory", "", tree); err != nil {
+			t.Fatalf("Failed to create commit: %v", err)
+		}
+
+		parent := t.TempDir()
+		if _, _, err := Clone(upstream.Path, filepath.Join(parent, "clone")); err == nil {
+			t.Errorf("Expected cloning a tree with %q to fail", path)
+		}
+		if _, err := os.Stat(filepath.Join(parent, "escaped.txt")); !os.IsNotExist(err) {
+			t.Errorf("Expected nothing to be written outside the clone")
+		}
+	}
+}
+
+func TestFetchRejectsUnsafeRefs(t *testing.T) {
+	commitID := "0123456789abcdef0123456789abcdef01234567"
+	for _, adv := range []*Advertisement{
+		{Refs: map[string]string{"refs/heads/../../../x": commitID}},
+		{Head: "refs/../../x", Refs: map[string]string{}},
+		{Refs: map[string]string{"refs/heads/master": "../../x"}},
+	} {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			json.NewEncoder(w).Encode(adv)
+		}))
+
+		parent := t.TempDir()
+		if _, _, err := Clone(server.URL, filepath.Join(parent, "clone")); err == nil || !strings.Contains(err.Error(), "invalid") {
+			t.Errorf("Expected cloning with advertisement %+v to be rejected, got %v", adv, err)
+		}
+		if _, err := os.Stat(filepath.Join(parent, "x")); !os.IsNotExist(err) {
+			t.Errorf("Expected nothing to be written outside the clone")
+		}
+		server.Close()
+	}
+}
+
+func TestPushRejectsNonFastForward(t *testing.T) {
+	upstream := setupTestRepo(t)
+	commitFile(t, upstream, "a.txt", "a", "✨ Initial commit")
+
+	// Two clones of the same repository
+	alice, _, err := Clone(upstream.Path, filepath.Join(t.TempDir(), "alice"))
+	if err != nil {
+		t.Fatalf("Failed to clone repository: %v", err)
+	}
+	bob, _, err := Clone(upstream.Path, filepath.Join(t.TempDir(), "bob"))
+	if err != nil {
+		t.Fatalf("Failed to clone repository: %v", err)
+	}
+
+	// Alice pushes first
+	aliceCommit := commitFile(t, alice, "b.txt", "b", "✨ Add b")
+	if _, err := Push(alice, "origin", "master", false); err != nil {
+		t.Fatalf("Failed to push: %v", err)
+	}
+	upstreamHead, _ := upstream.GetHEADCommitID()
+	if upstreamHead != aliceCommit.ID {
+		t.Errorf("Expected upstream to be at %s, got %s", aliceCommit.ID, upstreamHead)
+	}
+
+	// Bob's push would discard Alice's commit
+	bobCommit := commitFile(t, bob, "c.txt", "c", "✨ Add c")
+	_, err = Push(bob, "origin", "master", false)
+	if !errors.Is(err, ErrNonFastForward) {
+		t.Fatalf("Expected a non-fast-forward error, got %v", err)
+	}
+
+	// The remote checks the update before storing any objects
+	objects, err := bob.ReachableObjects([]string{bobCommit.ID}, nil)
+	if err != nil {
+		t.Fatalf("Failed to collect objects: %v", err)
+	}
+	update := RefUpdate{Name: repository.BranchRefPrefix + "master", Old: aliceCommit.ID, New: bobCommit.ID}
+	if err := ReceivePack(upstream, objects, []RefUpdate{update}); !errors.Is(err, ErrNonFastForward) {
+		t.Fatalf("Expected a non-fast-forward error, got %v", err)
+	}
+	if upstream.HasObject(repository.ObjectCommit, bobCommit.ID) {
+		t.Errorf("Expected a rejected push not to store its objects")
+	}
+
+	// Pulling merges Alice's work, after which Bob can push
+	pullResult, err := Pull(bob, "origin", "master", "bob", "bob@example.com")
+	if err != nil {
+		t.Fatalf("Failed to pull: %v", err)
+	}
+	if pullResult.Outcome != PullMerge {
+		t.Errorf("Expected a merge, got %s", pullResult.Outcome)
+	}
+	merge, err := bob.GetCommit(pullResult.CommitID)
+	if err != nil {
+		t.Fatalf("Failed to get merge commit: %v", err)
+	}
+	if err := snapmoji.Default().Validate(merge.Message); err != nil || lint.HasErrors(lint.DefaultPolicy().Lint(merge.Message)) {
+		t.Errorf("Expected the merge message %q to pass validation and lint", merge.Message)
+	}
+	if _, err := os.Stat(filepath.Join(bob.Path, "b.txt")); err != nil {
+		t.Errorf("Expected b.txt to be merged into Bob's working tree: %v", err)
+	}
+	if _, err := Push(bob, "origin", "master", false); err != nil {
+		t.Fatalf("Failed to push after pull: %v", err)
+	}
+
+	// Alice can now force-push over Bob's merge
+	if _, err := Push(alice, "origin", "master", true); err != nil {
+		t.Fatalf("Failed to force push: %v", err)
+	}
+	upstreamHead, _ = upstream.GetHEADCommitID()
+	if upstreamHead != aliceCommit.ID {
+		t.Errorf("Expected force push to reset upstream to %s, got %s", aliceCommit.ID, upstreamHead)
+	}
+}
+
+func TestPushUpdatesCheckedOutBranch(t *testing.T) {
+	upstream := setupTestRepo(t)
+	commitFile(t, upstream, "f.txt", "old", "✨ Initial commit")
+	clone, _, err := Clone(upstream.Path, filepath.Join(t.TempDir(), "clone"))
+	if err != nil {
+		t.Fatalf("Failed to clone repository: %v", err)
+	}
+
+	// Pushing to the branch checked out upstream updates its working tree and index
+	commitFile(t, clone, "f.txt", "new", "🐛 Fix f")
+	if _, err := Push(clone, "origin", "master", false); err != nil {
+		t.Fatalf("Failed to push: %v", err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(upstream.Path, "f.txt")); string(content) != "new" {
+		t.Errorf("Expected the pushed f.txt to be checked out upstream, got '%s'", content)
+	}
+
+	// So the next upstream commit builds on the pushed change instead of reverting it
+	commitFile(t, upstream, "g.txt", "g", "✨ Add g")
+	if _, err := Pull(clone, "origin", "master", "testuser", "test@example.com"); err != nil {
+		t.Fatalf("Failed to pull: %v", err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(clone.Path, "f.txt")); string(content) != "new" {
+		t.Errorf("Expected f.txt to keep the pushed change, got '%s'", content)
+	}
+
+	// Staged but uncommitted upstream changes block the push instead of being lost
+	stageFile(t, upstream, "h.txt", "h")
+	pushed := commitFile(t, clone, "f.txt", "newer", "🐛 Fix f again")
+	if _, err := Push(clone, "origin", "master", false); err == nil {
+		t.Fatalf("Expected a push to a branch with staged changes to be refused")
+	}
+	if head, _ := upstream.GetHEADCommitID(); head == pushed.ID || upstream.HasObject(repository.ObjectCommit, pushed.ID) {
+		t.Errorf("Expected the refused push to leave the upstream repository alone")
+	}
+	if staged, _ := upstream.StagedChanges(); len(staged) != 1 || staged[0] != "h.txt" {
+		t.Errorf("Expected h.txt to stay staged, got %v", staged)
+	}
+}
+
+func TestReceivePackRejectsUnsafePushes(t *testing.T) {
+	upstream := setupTestRepo(t)
+	base := commitFile(t, upstream, "a.txt", "a", "✨ Initial commit")
+	clone, _, err := Clone(upstream.Path, filepath.Join(t.TempDir(), "clone"))
+	if err != nil {
+		t.Fatalf("Failed to clone repository: %v", err)
+	}
+	ref := repository.BranchRefPrefix + "master"
+
+	// A push missing some of its objects would leave the branch pointing at nothing
+	commit := commitFile(t, clone, "b.txt", "b", "✨ Add b")
+	objects, err := clone.ReachableObjects([]string{commit.ID}, []string{base.ID})
+	if err != nil {
+		t.Fatalf("Failed to collect objects: %v", err)
+	}
+	var partial []*repository.Object
+	for _, object := range objects {
+		if object.Type != repository.ObjectBlob {
+			partial = append(partial, object)
+		}
+	}
+	update := RefUpdate{Name: ref, Old: base.ID, New: commit.ID}
+	if err := ReceivePack(upstream, partial, []RefUpdate{update}); err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Errorf("Expected a partial push to be rejected, got %v", err)
+	}
+	if head, _ := upstream.ResolveRef(ref); head != base.ID || upstream.HasObject(repository.ObjectCommit, commit.ID) {
+		t.Errorf("Expected the partial push to leave the upstream repository alone")
+	}
+
+	// Trees can't write outside the working tree of the checked-out branch
+	tree := &repository.Tree{Entries: map[string]string{"../escaped.txt": stageFile(t, clone, "c.txt", "c").Entries["c.txt"]}}
+	escape, err := clone.CreateCommit("✨ Escape", "mallory", "", tree)
+	if err != nil {
+		t.Fatalf("Failed to create commit: %v", err)
+	}
+	objects, err = clone.ReachableObjects([]string{escape.ID}, []string{base.ID})
+	if err != nil {
+		t.Fatalf("Failed to collect objects: %v", err)
+	}
+	update = RefUpdate{Name: ref, New: escape.ID, Force: true}
+	if err := ReceivePack(upstream, objects, []RefUpdate{update}); err == nil {
+		t.Errorf("Expected a push with an escaping tree to be rejected")
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(upstream.Path), "escaped.txt")); !os.IsNotExist(err) {
+		t.Errorf("Expected nothing to be written outside the upstream repository")
+	}
+}
+
+func TestPullKeepsStagedChanges(t *testing.T) {
+	upstream := setupTestRepo(t)
+	commitFile(t, upstream, "a.txt", "a", "✨ Initial commit")
+	clone, _, err := Clone(upstream.Path, filepath.Join(t.TempDir(), "clone"))
+	if err != nil {
+		t.Fatalf("Failed to clone repository: %v", err)
+	}
+	commitFile(t, upstream, "b.txt", "b", "✨ Add b")
+
+	// A pull would replace the index, so it's refused while something is staged
+	stageFile(t, clone, "c.txt", "c")
+	if _, err := Pull(clone, "origin", "master", "testuser", "test@example.com"); err == nil {
+		t.Fatalf("Expected a pull with staged changes to be refused")
+	}
+	if staged, _ := clone.StagedChanges(); len(staged) != 1 || staged[0] != "c.txt" {
+		t.Errorf("Expected c.txt to stay staged, got %v", staged)
+	}
+
+	// The same goes for pulls that merge
+	commitFile(t, clone, "c.txt", "c", "✨ Add c")
+	stageFile(t, clone, "d.txt", "d")
+	if _, err := Pull(clone, "origin", "master", "testuser", "test@example.com"); err == nil {
+		t.Fatalf("Expected a merging pull with staged changes to be refused")
+	}
+	if staged, _ := clone.StagedChanges(); len(staged) != 1 || staged[0] != "d.txt" {
+		t.Errorf("Expected d.txt to stay staged, got %v", staged)
+	}
+}
+
+func TestIssuesTravelWithPushAndPull(t *testing.T) {
+	upstream := setupTestRepo(t)
+	commitFile(t, upstream, "a.txt", "a", "✨ Initial commit")
+	if _, err := issue.NewIssueManager(upstream.Path).CreateIssue("Shared issue", "", "alice"); err != nil {
+		t.Fatalf("Failed to create issue: %v", err)
+	}
+
+	// Clones get the upstream issues
+	alice, _, err := Clone(upstream.Path, filepath.Join(t.TempDir(), "alice"))
+	if err != nil {
+		t.Fatalf("Failed to clone repository: %v", err)
+	}
+	bob, _, err := Clone(upstream.Path, filepath.Join(t.TempDir(), "bob"))
+	if err != nil {
+		t.Fatalf("Failed to clone repository: %v", err)
+	}
+	aliceIssues := issue.NewIssueManager(alice.Path)
+	bobIssues := issue.NewIssueManager(bob.Path)
+	if shared, err := aliceIssues.GetIssue(1); err != nil || shared.Title != "Shared issue" {
+		t.Fatalf("Expected the clone to have issue #1, got %v (%v)", shared, err)
+	}
+
+	// Both create issue #2 independently; Alice pushes first without any new commits
+	aliceIssue, _ := aliceIssues.CreateIssue("Alice's issue", "", "alice")
+	bobIssue, _ := bobIssues.CreateIssue("Bob's issue", "", "bob")
+	pushResult, err := Push(alice, "origin", "master", false)
+	if err != nil {
+		t.Fatalf("Failed to push issues: %v", err)
+	}
+	if len(pushResult.Metadata) != 1 || pushResult.Metadata[0].Name != issue.IssuesRef {
+		t.Errorf("Expected the issues reference to be pushed, got %v", pushResult.Metadata)
+	}
+
+	// Bob has to pull first
+	if _, err := Push(bob, "origin", "master", false); !errors.Is(err, ErrNonFastForward) {
+		t.Fatalf("Expected a non-fast-forward error for diverged issues, got %v", err)
+	}
+	pullResult, err := Pull(bob, "origin", "master", "bob", "bob@example.com")
+	if err != nil {
+		t.Fatalf("Failed to pull: %v", err)
+	}
+	if pullResult.Issues.Outcome != issue.MergeCommit || len(pullResult.Issues.Renumbered) != 1 {
+		t.Errorf("Expected issues to be merged with one renumbering, got %+v", pullResult.Issues)
+	}
+	if _, err := Push(bob, "origin", "master", false); err != nil {
+		t.Fatalf("Failed to push after pull: %v", err)
+	}
+
+	// Everyone converges on the same issues with unique aliases
+	if _, err := Pull(alice, "origin", "master", "alice", "alice@example.com"); err != nil {
+		t.Fatalf("Failed to pull: %v", err)
+	}
+	for _, manager := range []*issue.IssueManager{aliceIssues, bobIssues, issue.NewIssueManager(upstream.Path)} {
+		issues, err := manager.ListIssues(true)
+		if err != nil {
+			t.Fatalf("Failed to list issues: %v", err)
+		}
+		if len(issues) != 3 {
+			t.Fatalf("Expected 3 issues in %s, got %d", manager.RepoPath, len(issues))
+		}
+		if issues[1].UID != aliceIssue.UID || issues[2].UID != bobIssue.UID || issues[2].ID != 3 {
+			t.Errorf("Expected Alice's issue as #2 and Bob's as #3 in %s", manager.RepoPath)
+		}
+	}
+}
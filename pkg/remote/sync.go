@@ -0,0 +1,692 @@
+package remote
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/stanlocht/snap/pkg/issue"
+	"github.com/stanlocht/snap/pkg/repository"
+)
+
+// Pull outcomes
+const (
+	// PullUpToDate means the local branch already contained the remote commits
+	PullUpToDate = "up-to-date"
+	// PullFastForward means the local branch was moved forward to the remote commit
+	PullFastForward = "fast-forward"
+	// PullMerge means a merge commit was created
+	PullMerge = "merge"
+)
+
+// RefChange describes how a reference moved during a fetch or push
+type RefChange struct {
+	Name string
+	Old  string
+	New  string
+}
+
+// FetchResult describes the outcome of a fetch
+type FetchResult struct {
+	Remote        *Remote
+	Advertisement *Advertisement
+	Updated       []RefChange
+	Objects       int
+}
+
+// PushResult describes the outcome of a push
+type PushResult struct {
+	Remote   *Remote
+	Ref      string
+	Old      string
+	New      string
+	Objects  int
+	UpToDate bool
+	Metadata []RefChange // Metadata references pushed along with the branch
+}
+
+// PullResult describes the outcome of a pull
+type PullResult struct {
+	Fetch    *FetchResult
+	Outcome  string
+	CommitID string
+	Issues   *issue.MergeResult
+}
+
+// AdvertiseRefs lists the references a repository offers to its peers:
+// its branches and its metadata references, such as the issue tracker
+func AdvertiseRefs(repo *repository.Repository) (*Advertisement, error) {
+	refs, err := repo.ListRefs(repository.BranchRefPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	metadataRefs, err := repo.ListRefs(repository.MetadataRefPrefix)
+	if err != nil {
+		return nil, err
+	}
+	for name, commitID := range metadataRefs {
+		refs[name] = commitID
+	}
+
+	head, err := repo.HeadRef()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read HEAD: %w", err)
+	}
+
+	return &Advertisement{
+		Head: head,
+		Refs: refs,
+	}, nil
+}
+
+// ReceivePack checks reference updates against a repository, then stores the
+// pushed objects and applies the updates. Updates that would lose commits are
+// rejected unless forced. Updating the checked-out branch also updates the
+// working tree and index, and is refused if that would lose uncommitted changes.
+func ReceivePack(repo *repository.Repository, objects []*repository.Object, updates []RefUpdate) error {
+	// Verify the objects and collect what was pushed, without storing anything yet
+	pack := &pushedObjects{
+		commits: make(map[string]*repository.Commit),
+		trees:   make(map[string]*repository.Tree),
+		blobs:   make(map[string]bool),
+	}
+	for _, object := range objects {
+		if err := repository.VerifyObject(object); err != nil {
+			return err
+		}
+		switch object.Type {
+		case repository.ObjectCommit:
+			var commit repository.Commit
+			if err := json.Unmarshal(object.Data, &commit); err != nil {
+				return fmt.Errorf("invalid commit %s: %w", object.ID, err)
+			}
+			pack.commits[commit.ID] = &commit
+		case repository.ObjectTree:
+			var tree repository.Tree
+			if err := json.Unmarshal(object.Data, &tree); err != nil {
+				return fmt.Errorf("invalid tree %s: %w", object.ID, err)
+			}
+			pack.trees[object.ID] = &tree
+		case repository.ObjectBlob:
+			pack.blobs[object.ID] = true
+		}
+	}
+
+	headRef, err := repo.HeadRef()
+	if err != nil {
+		return fmt.Errorf("failed to read HEAD: %w", err)
+	}
+
+	// Check every update before applying any of them
+	moveCheckedOut, checkedOut := false, ""
+	currents := make(map[string]string)
+	for _, update := range updates {
+		if err := repository.ValidateRefName(update.Name); err != nil {
+			return err
+		}
+		if update.New == "" {
+			return fmt.Errorf("%s: deleting references is not supported", update.Name)
+		}
+		if !repository.IsObjectID(update.New) {
+			return fmt.Errorf("%s: invalid commit ID %q", update.Name, update.New)
+		}
+		if err := pack.checkComplete(repo, update.New); err != nil {
+			return fmt.Errorf("%s: %w", update.Name, err)
+		}
+
+		current, err := repo.ResolveRef(update.Name)
+		if err != nil {
+			return err
+		}
+		currents[update.Name] = current
+		if current == update.New {
+			continue
+		}
+		if update.Name == headRef {
+			staged, err := repo.StagedChanges()
+			if err != nil {
+				return err
+			}
+			if len(staged) > 0 {
+				return fmt.Errorf("%s is checked out in the remote repository, which has staged changes: %s", update.Name, strings.Join(staged, ", "))
+			}
+			moveCheckedOut, checkedOut = true, current
+		}
+		if update.Force || current == "" {
+			continue
+		}
+		if current != update.Old {
+			return fmt.Errorf("%s: %w", update.Name, ErrStaleRef)
+		}
+
+		isAncestor, err := pack.isAncestor(repo, current, update.New)
+		if err != nil {
+			return err
+		}
+		if !isAncestor {
+			return fmt.Errorf("%s: %w", update.Name, ErrNonFastForward)
+		}
+	}
+
+	// Store objects
+	for _, object := range objects {
+		if err := repo.WriteObject(object); err != nil {
+			return err
+		}
+	}
+
+	// Bring the working tree of the checked-out branch along, so the next
+	// commit there doesn't revert the pushed changes
+	for _, update := range updates {
+		if moveCheckedOut && update.Name == headRef {
+			if err := repo.Checkout(checkedOut, update.New); err != nil {
+				return fmt.Errorf("%s is checked out in the remote repository: %w", update.Name, err)
+			}
+		}
+	}
+
+	// Apply updates, unless the references moved since they were checked, e.g.
+	// because the issues were edited during the push
+	for _, update := range updates {
+		if err := repo.CompareAndSwapRef(update.Name, currents[update.Name], update.New); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// pushedObjects holds the objects of a push, parsed, before they are stored
+type pushedObjects struct {
+	commits map[string]*repository.Commit
+	trees   map[string]*repository.Tree
+	blobs   map[string]bool
+}
+
+// checkComplete returns an error unless every commit reachable from commitID
+// has its tree, files and parents, either pushed or already in the repository
+func (p *pushedObjects) checkComplete(repo *repository.Repository, commitID string) error {
+	seen := make(map[string]bool)
+	stack := []string{commitID}
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		commit, ok := p.commits[id]
+		if !ok {
+			// Commits the repository has were complete when they were stored
+			if !repo.HasObject(repository.ObjectCommit, id) {
+				return fmt.Errorf("missing commit %s", id)
+			}
+			continue
+		}
+
+		tree, ok := p.trees[commit.TreeID]
+		if !ok {
+			if !repo.HasObject(repository.ObjectTree, commit.TreeID) {
+				return fmt.Errorf("commit %s is missing its tree %s", id, commit.TreeID)
+			}
+			var err error
+			if tree, err = repo.GetTree(commit.TreeID); err != nil {
+				return err
+			}
+		}
+		for _, path := range repository.SortedRefNames(tree.Entries) {
+			blobID := tree.Entries[path]
+			if !p.blobs[blobID] && !repo.HasObject(repository.ObjectBlob, blobID) {
+				return fmt.Errorf("commit %s is missing %s (%s)", id, path, blobID)
+			}
+		}
+
+		stack = append(stack, commit.Parents()...)
+	}
+	return nil
+}
+
+// isAncestor reports whether ancestorID is reachable from commitID, walking
+// the pushed commits until it reaches commits the repository already has
+func (p *pushedObjects) isAncestor(repo *repository.Repository, ancestorID, commitID string) (bool, error) {
+	seen := make(map[string]bool)
+	stack := []string{commitID}
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if id == ancestorID {
+			return true, nil
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		if commit, ok := p.commits[id]; ok {
+			stack = append(stack, commit.Parents()...)
+			continue
+		}
+		if repo.HasObject(repository.ObjectCommit, id) {
+			isAncestor, err := repo.IsAncestor(ancestorID, id)
+			if err != nil || isAncestor {
+				return isAncestor, err
+			}
+		}
+	}
+	return false, nil
+}
+
+// validateAdvertisement checks the references a remote offers before any of them is written
+func validateAdvertisement(adv *Advertisement) error {
+	if adv.Head != "" {
+		if err := repository.ValidateRefName(adv.Head); err != nil {
+			return fmt.Errorf("remote HEAD: %w", err)
+		}
+	}
+	for name, commitID := range adv.Refs {
+		if err := repository.ValidateRefName(name); err != nil {
+			return fmt.Errorf("remote advertised an %w", err)
+		}
+		if !repository.IsObjectID(commitID) {
+			return fmt.Errorf("remote advertised invalid commit ID %q for %s", commitID, name)
+		}
+	}
+	return nil
+}
+
+// localTips returns the commit IDs of every reference in a repository
+func localTips(repo *repository.Repository) ([]string, error) {
+	refs, err := repo.ListRefs("refs/")
+	if err != nil {
+		return nil, err
+	}
+
+	tips := make([]string, 0, len(refs))
+	for _, name := range repository.SortedRefNames(refs) {
+		tips = append(tips, refs[name])
+	}
+	return tips, nil
+}
+
+// Fetch downloads missing objects from a remote and updates its remote-tracking references
+func Fetch(repo *repository.Repository, remoteName string) (*FetchResult, error) {
+	remote, err := GetRemote(repo, remoteName)
+	if err != nil {
+		return nil, err
+	}
+
+	transport, err := Open(remote.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	adv, err := transport.Advertise()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list remote references: %w", err)
+	}
+	if err := validateAdvertisement(adv); err != nil {
+		return nil, err
+	}
+
+	// Ask only for commits we don't have yet
+	var wants []string
+	for _, name := range repository.SortedRefNames(adv.Refs) {
+		commitID := adv.Refs[name]
+		if !repo.HasObject(repository.ObjectCommit, commitID) {
+			wants = append(wants, commitID)
+		}
+	}
+
+	result := &FetchResult{
+		Remote:        remote,
+		Advertisement: adv,
+	}
+
+	if len(wants) > 0 {
+		haves, err := localTips(repo)
+		if err != nil {
+			return nil, err
+		}
+
+		err = transport.Fetch(wants, haves, func(object *repository.Object) error {
+			result.Objects++
+			return repo.WriteObject(object)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch objects: %w", err)
+		}
+	}
+
+	// Update remote-tracking references
+	prefix := trackingPrefix(remote.Name)
+	existing, err := repo.ListRefs(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range repository.SortedRefNames(adv.Refs) {
+		trackingRef := trackingRefFor(remote.Name, name)
+		if trackingRef == "" {
+			continue
+		}
+		old := existing[trackingRef]
+		delete(existing, trackingRef)
+		if old == adv.Refs[name] {
+			continue
+		}
+
+		if err := repo.UpdateRef(trackingRef, adv.Refs[name]); err != nil {
+			return nil, err
+		}
+		result.Updated = append(result.Updated, RefChange{Name: trackingRef, Old: old, New: adv.Refs[name]})
+	}
+
+	// Prune tracking references for branches deleted on the remote
+	for _, name := range repository.SortedRefNames(existing) {
+		if err := repo.DeleteRef(name); err != nil {
+			return nil, err
+		}
+		result.Updated = append(result.Updated, RefChange{Name: name, Old: existing[name]})
+	}
+
+	return result, nil
+}
+
+// Push uploads a local branch to a remote. Unless force is set, the push is
+// refused if the remote branch contains commits the local branch doesn't.
+func Push(repo *repository.Repository, remoteName, branch string, force bool) (*PushResult, error) {
+	remote, err := GetRemote(repo, remoteName)
+	if err != nil {
+		return nil, err
+	}
+
+	ref := repository.BranchRefPrefix + branch
+	localID, err := repo.ResolveRef(ref)
+	if err != nil {
+		return nil, err
+	}
+	if localID == "" {
+		return nil, fmt.Errorf("branch %s has no commits", branch)
+	}
+
+	transport, err := Open(remote.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	adv, err := transport.Advertise()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list remote references: %w", err)
+	}
+	if err := validateAdvertisement(adv); err != nil {
+		return nil, err
+	}
+
+	result := &PushResult{
+		Remote: remote,
+		Ref:    ref,
+		Old:    adv.Refs[ref],
+		New:    localID,
+	}
+
+	var updates []RefUpdate
+	if result.Old != localID {
+		// Refuse to overwrite commits we haven't seen
+		if !force {
+			if err := checkFastForward(repo, ref, result.Old, localID); err != nil {
+				return nil, err
+			}
+		}
+		updates = append(updates, RefUpdate{Name: ref, Old: result.Old, New: localID, Force: force})
+	}
+
+	// Metadata such as issues travels with every push. It is never forced:
+	// diverged metadata has to be pulled and merged first.
+	metadataRefs, err := repo.ListRefs(repository.MetadataRefPrefix)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range repository.SortedRefNames(metadataRefs) {
+		oldID, newID := adv.Refs[name], metadataRefs[name]
+		if oldID == newID {
+			continue
+		}
+		if err := checkFastForward(repo, name, oldID, newID); err != nil {
+			return nil, err
+		}
+		updates = append(updates, RefUpdate{Name: name, Old: oldID, New: newID})
+		result.Metadata = append(result.Metadata, RefChange{Name: name, Old: oldID, New: newID})
+	}
+
+	if len(updates) == 0 {
+		result.UpToDate = true
+		return result, nil
+	}
+
+	// Send everything the remote is missing
+	var wants, haves []string
+	for _, update := range updates {
+		wants = append(wants, update.New)
+	}
+	for _, name := range repository.SortedRefNames(adv.Refs) {
+		haves = append(haves, adv.Refs[name])
+	}
+	objects, err := repo.ReachableObjects(wants, haves)
+	if err != nil {
+		return nil, err
+	}
+	result.Objects = len(objects)
+
+	if err := transport.Push(objects, updates); err != nil {
+		return nil, err
+	}
+
+	// Keep the remote-tracking references in step
+	for _, update := range updates {
+		if err := repo.UpdateRef(trackingRefFor(remote.Name, update.Name), update.New); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// checkFastForward returns an error unless moving ref from old to new keeps every commit
+func checkFastForward(repo *repository.Repository, ref, oldID, newID string) error {
+	if oldID == "" {
+		return nil
+	}
+	if !repo.HasObject(repository.ObjectCommit, oldID) {
+		return fmt.Errorf("%s: %w (fetch first)", ref, ErrNonFastForward)
+	}
+	isAncestor, err := repo.IsAncestor(oldID, newID)
+	if err != nil {
+		return err
+	}
+	if !isAncestor {
+		return fmt.Errorf("%s: %w (pull first)", ref, ErrNonFastForward)
+	}
+	return nil
+}
+
+// mergeIssues integrates the issues fetched from a remote into the local issue tracker
+func mergeIssues(repo *repository.Repository, remoteName, author string) (*issue.MergeResult, error) {
+	theirs, err := repo.ResolveRef(trackingRefFor(remoteName, issue.IssuesRef))
+	if err != nil {
+		return nil, err
+	}
+
+	issueManager := issue.NewIssueManager(repo.Path)
+	issueManager.Author = author
+	result, err := issueManager.Merge(theirs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge issues: %w", err)
+	}
+	return result, nil
+}
+
+// Pull fetches from a remote and integrates the remote branch into the current branch,
+// fast-forwarding when possible and otherwise creating a merge commit
+func Pull(repo *repository.Repository, remoteName, branch, author, email string) (*PullResult, error) {
+	fetchResult, err := Fetch(repo, remoteName)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PullResult{Fetch: fetchResult}
+
+	// Issues are merged whatever happens to the branch
+	result.Issues, err = mergeIssues(repo, remoteName, author)
+	if err != nil {
+		return nil, err
+	}
+
+	theirs, err := repo.ResolveRef(TrackingRef(remoteName, branch))
+	if err != nil {
+		return nil, err
+	}
+	if theirs == "" {
+		return nil, fmt.Errorf("remote %s has no branch %s", remoteName, branch)
+	}
+
+	ours, err := repo.GetHEADCommitID()
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to get HEAD commit ID: %w", err)
+	}
+
+	// Nothing new on the remote
+	if ours != "" {
+		upToDate, err := repo.IsAncestor(theirs, ours)
+		if err != nil {
+			return nil, err
+		}
+		if upToDate {
+			result.Outcome = PullUpToDate
+			result.CommitID = ours
+			return result, nil
+		}
+	}
+
+	// Fast-forward if we have no commits of our own
+	fastForward := ours == ""
+	if !fastForward {
+		fastForward, err = repo.IsAncestor(ours, theirs)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if fastForward {
+		if err := repo.Checkout(ours, theirs); err != nil {
+			return nil, err
+		}
+		if err := repo.UpdateHEAD(theirs); err != nil {
+			return nil, err
+		}
+		result.Outcome = PullFastForward
+		result.CommitID = theirs
+		return result, nil
+	}
+
+	// Both sides have new commits, so merge them
+	base, err := repo.MergeBase(ours, theirs)
+	if err != nil {
+		return nil, err
+	}
+	merged, conflicts, err := repo.MergeTrees(base, ours, theirs)
+	if err != nil {
+		return nil, err
+	}
+	if len(conflicts) > 0 {
+		return nil, fmt.Errorf("merge conflict in: %s", strings.Join(conflicts, ", "))
+	}
+
+	ourCommit, err := repo.GetCommit(ours)
+	if err != nil {
+		return nil, err
+	}
+	ourTree, err := repo.GetTree(ourCommit.TreeID)
+	if err != nil {
+		return nil, err
+	}
+	if err := repo.CheckoutTree(ourTree, merged); err != nil {
+		return nil, err
+	}
+
+	message := fmt.Sprintf("🔀 Merge branch '%s' of %s", branch, fetchResult.Remote.URL)
+	commit, err := repo.CreateMergeCommit(message, author, email, merged, theirs)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Outcome = PullMerge
+	result.CommitID = commit.ID
+	return result, nil
+}
+
+// Clone creates a new repository in dir from the repository at url
+func Clone(url, dir string) (*repository.Repository, *FetchResult, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Refuse to clone into a non-empty directory
+	if entries, err := os.ReadDir(dir); err == nil && len(entries) > 0 {
+		return nil, nil, fmt.Errorf("destination path '%s' already exists and is not an empty directory", dir)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	repo, err := repository.Init(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if _, err := AddRemote(repo, DefaultRemoteName, url); err != nil {
+		return nil, nil, err
+	}
+
+	fetchResult, err := Fetch(repo, DefaultRemoteName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Take the remote's issues
+	if _, err := mergeIssues(repo, DefaultRemoteName, ""); err != nil {
+		return nil, nil, err
+	}
+
+	// Check out the branch the remote HEAD points to
+	adv := fetchResult.Advertisement
+	headRef := adv.Head
+	if headRef == "" || adv.Refs[headRef] == "" {
+		// Empty remote, or a detached HEAD: fall back to the first branch
+		headRef = ""
+		for _, name := range repository.SortedRefNames(adv.Refs) {
+			if strings.HasPrefix(name, repository.BranchRefPrefix) {
+				headRef = name
+				break
+			}
+		}
+	}
+	if headRef == "" {
+		return repo, fetchResult, nil
+	}
+
+	if err := repo.SetHeadRef(headRef); err != nil {
+		return nil, nil, err
+	}
+	if err := repo.UpdateRef(headRef, adv.Refs[headRef]); err != nil {
+		return nil, nil, err
+	}
+	if err := repo.Checkout("", adv.Refs[headRef]); err != nil {
+		return nil, nil, err
+	}
+
+	return repo, fetchResult, nil
+}
@@ -0,0 +1,274 @@
+package repository
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// sortedTreePaths returns the paths in a tree in sorted order
+func sortedTreePaths(tree *Tree) []string {
+	paths := make([]string, 0, len(tree.Entries))
+	for path := range tree.Entries {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// commitTree returns the tree of a commit, or an empty tree for an empty commit ID
+func (r *Repository) commitTree(commitID string) (*Tree, error) {
+	if commitID == "" {
+		return &Tree{Entries: map[string]string{}}, nil
+	}
+
+	commit, err := r.GetCommit(commitID)
+	if err != nil {
+		return nil, err
+	}
+	return r.GetTree(commit.TreeID)
+}
+
+// workingFileID returns the object ID of a file in the working tree, or "" if it doesn't exist
+func (r *Repository) workingFileID(path string) (string, error) {
+	content, err := os.ReadFile(filepath.Join(r.Path, filepath.FromSlash(path)))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	hash := sha1.New()
+	hash.Write(content)
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// Checkout updates the working tree and index from the tree of one commit to
+// the tree of another. It refuses to drop staged changes or overwrite files
+// with local modifications.
+func (r *Repository) Checkout(fromCommitID, toCommitID string) error {
+	fromTree, err := r.commitTree(fromCommitID)
+	if err != nil {
+		return fmt.Errorf("failed to read current tree: %w", err)
+	}
+	toTree, err := r.commitTree(toCommitID)
+	if err != nil {
+		return fmt.Errorf("failed to read target tree: %w", err)
+	}
+
+	return r.CheckoutTree(fromTree, toTree)
+}
+
+// readIndex returns the entries of the index, or nil if there is no index yet
+func (r *Repository) readIndex() (map[string]string, error) {
+	content, err := os.ReadFile(filepath.Join(r.Path, SnapDirName, "index"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read index file: %w", err)
+	}
+
+	entries := make(map[string]string)
+	for _, line := range strings.Split(string(content), "\n") {
+		objectID, path, ok := strings.Cut(line, " ")
+		if ok {
+			entries[path] = objectID
+		}
+	}
+	return entries, nil
+}
+
+// stagedChanges returns the sorted paths whose index entries differ from a tree
+func (r *Repository) stagedChanges(tree *Tree) ([]string, error) {
+	entries, err := r.readIndex()
+	if err != nil || entries == nil {
+		return nil, err
+	}
+
+	var staged []string
+	for _, path := range unionTreePaths(tree, &Tree{Entries: entries}) {
+		if entries[path] != tree.Entries[path] {
+			staged = append(staged, path)
+		}
+	}
+	return staged, nil
+}
+
+// StagedChanges returns the sorted paths staged in the index but not committed
+func (r *Repository) StagedChanges() ([]string, error) {
+	headID, err := r.GetHEADCommitID()
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to get HEAD commit ID: %w", err)
+	}
+	tree, err := r.commitTree(headID)
+	if err != nil {
+		return nil, err
+	}
+	return r.stagedChanges(tree)
+}
+
+// CheckoutTree updates the working tree and index from one tree to another.
+// It refuses to drop staged changes or overwrite files with local modifications.
+func (r *Repository) CheckoutTree(fromTree, toTree *Tree) error {
+	// Replacing the index would silently drop anything staged
+	staged, err := r.stagedChanges(fromTree)
+	if err != nil {
+		return err
+	}
+	if len(staged) > 0 {
+		return fmt.Errorf("staged changes would be lost, commit them first: %s", strings.Join(staged, ", "))
+	}
+
+	// Work out which files change and make sure none of them have local edits
+	var conflicts []string
+	var changed []string
+	for _, path := range unionTreePaths(fromTree, toTree) {
+		fromID := fromTree.Entries[path]
+		toID := toTree.Entries[path]
+		if fromID == toID {
+			continue
+		}
+		if err := ValidatePath(path); err != nil {
+			return err
+		}
+
+		workingID, err := r.workingFileID(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		if workingID != fromID && workingID != toID {
+			conflicts = append(conflicts, path)
+			continue
+		}
+		changed = append(changed, path)
+	}
+
+	if len(conflicts) > 0 {
+		return fmt.Errorf("local changes would be overwritten: %s", strings.Join(conflicts, ", "))
+	}
+
+	// Update the working tree
+	for _, path := range changed {
+		fullPath := filepath.Join(r.Path, filepath.FromSlash(path))
+		toID, ok := toTree.Entries[path]
+		if !ok {
+			if err := os.Remove(fullPath); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to remove %s: %w", path, err)
+			}
+			continue
+		}
+
+		content, err := r.ReadBlob(toID)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", path, err)
+		}
+		if err := os.WriteFile(fullPath, content, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+
+	// Reset the index to the target tree
+	return r.writeIndex(toTree)
+}
+
+// writeIndex replaces the index with the entries of a tree
+func (r *Repository) writeIndex(tree *Tree) error {
+	var builder strings.Builder
+	for _, path := range sortedTreePaths(tree) {
+		builder.WriteString(fmt.Sprintf("%s %s\n", tree.Entries[path], path))
+	}
+
+	indexPath := filepath.Join(r.Path, SnapDirName, "index")
+	if err := os.WriteFile(indexPath, []byte(builder.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write index file: %w", err)
+	}
+	return nil
+}
+
+// unionTreePaths returns the sorted paths present in any of the given trees
+func unionTreePaths(trees ...*Tree) []string {
+	seen := make(map[string]bool)
+	var paths []string
+	for _, tree := range trees {
+		for path := range tree.Entries {
+			if !seen[path] {
+				seen[path] = true
+				paths = append(paths, path)
+			}
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// ChangedFiles returns the sorted paths that were added, changed or removed in
+// a tree compared to the tree of a parent commit (an empty parent ID compares
+// against an empty tree)
+func (r *Repository) ChangedFiles(parentID string, tree *Tree) ([]string, error) {
+	parentTree, err := r.commitTree(parentID)
+	if err != nil {
+		return nil, err
+	}
+
+	var changed []string
+	for _, path := range unionTreePaths(parentTree, tree) {
+		if parentTree.Entries[path] != tree.Entries[path] {
+			changed = append(changed, path)
+		}
+	}
+	return changed, nil
+}
+
+// MergeTrees performs a file-level three-way merge of two commits against their
+// merge base. It returns the merged tree and the paths changed differently on both sides.
+func (r *Repository) MergeTrees(baseID, oursID, theirsID string) (*Tree, []string, error) {
+	baseTree, err := r.commitTree(baseID)
+	if err != nil {
+		return nil, nil, err
+	}
+	oursTree, err := r.commitTree(oursID)
+	if err != nil {
+		return nil, nil, err
+	}
+	theirsTree, err := r.commitTree(theirsID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	merged := &Tree{Entries: make(map[string]string)}
+	var conflicts []string
+	for _, path := range unionTreePaths(baseTree, oursTree, theirsTree) {
+		base := baseTree.Entries[path]
+		ours := oursTree.Entries[path]
+		theirs := theirsTree.Entries[path]
+
+		var result string
+		switch {
+		case ours == theirs:
+			result = ours
+		case ours == base:
+			result = theirs
+		case theirs == base:
+			result = ours
+		default:
+			conflicts = append(conflicts, path)
+			result = ours
+		}
+
+		// An empty result means the file was deleted
+		if result != "" {
+			merged.Entries[path] = result
+		}
+	}
+
+	return merged, conflicts, nil
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 )
 
@@ -19,6 +20,9 @@ type Commit struct {
 	Timestamp time.Time `json:"timestamp"`
 	ParentID  string    `json:"parent_id,omitempty"`
 	TreeID    string    `json:"tree_id"`
+
+	// MergeParentID is the second parent of a merge commit
+	MergeParentID string `json:"merge_parent_id,omitempty"`
 }
 
 // Tree represents a tree object in the repository
@@ -28,19 +32,57 @@ type Tree struct {
 
 // CreateCommit creates a new commit in the repository
 func (r *Repository) CreateCommit(message, author, email string, tree *Tree) (*Commit, error) {
+	return r.CreateMergeCommit(message, author, email, tree, "")
+}
+
+// CreateMergeCommit creates a new commit on top of HEAD that also records
+// mergeParentID as a second parent. An empty mergeParentID creates a regular commit.
+func (r *Repository) CreateMergeCommit(message, author, email string, tree *Tree, mergeParentID string) (*Commit, error) {
 	// Get current HEAD commit ID
 	parentID, err := r.GetHEADCommitID()
 	if err != nil && !os.IsNotExist(err) {
 		return nil, fmt.Errorf("failed to get HEAD commit ID: %w", err)
 	}
 
+	commit, err := r.writeCommit(message, author, email, tree, parentID, mergeParentID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Update HEAD
+	if err := r.UpdateHEAD(commit.ID); err != nil {
+		return nil, fmt.Errorf("failed to update HEAD: %w", err)
+	}
+
+	return commit, nil
+}
+
+// CreateRefCommit creates a new commit on top of parentID and moves a reference
+// from parentID to it, leaving HEAD and the working tree alone. It fails with
+// ErrRefChanged if the reference no longer points at parentID.
+func (r *Repository) CreateRefCommit(ref, parentID, message, author, email string, tree *Tree, mergeParentID string) (*Commit, error) {
+	commit, err := r.writeCommit(message, author, email, tree, parentID, mergeParentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.CompareAndSwapRef(ref, parentID, commit.ID); err != nil {
+		return nil, err
+	}
+
+	return commit, nil
+}
+
+// writeCommit saves the tree and a new commit object with the given parents
+func (r *Repository) writeCommit(message, author, email string, tree *Tree, parentID, mergeParentID string) (*Commit, error) {
 	// Create commit object
 	commit := &Commit{
-		Message:   message,
-		Author:    author,
-		Email:     email,
-		Timestamp: time.Now(),
-		ParentID:  parentID,
+		Message:       message,
+		Author:        author,
+		Email:         email,
+		Timestamp:     time.Now(),
+		ParentID:      parentID,
+		MergeParentID: mergeParentID,
 	}
 
 	// Save tree
@@ -64,9 +106,9 @@ func (r *Repository) CreateCommit(message, author, email string, tree *Tree) (*C
 		return nil, fmt.Errorf("failed to save commit: %w", err)
 	}
 
-	// Update HEAD
-	if err := r.UpdateHEAD(commit.ID); err != nil {
-		return nil, fmt.Errorf("failed to update HEAD: %w", err)
+	// Record commit in the commit graph
+	if err := r.addToCommitGraph(commit); err != nil {
+		return nil, fmt.Errorf("failed to update commit graph: %w", err)
 	}
 
 	return commit, nil
@@ -161,66 +203,44 @@ func (r *Repository) GetTree(id string) (*Tree, error) {
 
 // GetHEADCommitID gets the current HEAD commit ID
 func (r *Repository) GetHEADCommitID() (string, error) {
-	// Read HEAD file
-	headPath := filepath.Join(r.Path, SnapDirName, "HEAD")
-	headContent, err := os.ReadFile(headPath)
+	// Find out what HEAD points to
+	ref, err := r.HeadRef()
 	if err != nil {
 		return "", err
 	}
 
-	// Parse HEAD content
-	head := string(headContent)
-	if len(head) > 0 && head[0] == 'r' {
-		// HEAD is a reference (e.g., "ref: refs/heads/master")
-		refPath := head[5:] // Remove "ref: " prefix
-		refPath = filepath.Join(r.Path, SnapDirName, refPath)
-		
-		// Read reference file
-		refContent, err := os.ReadFile(refPath)
-		if err != nil {
-			if os.IsNotExist(err) {
-				// Reference file doesn't exist yet (no commits)
-				return "", nil
-			}
-			return "", err
-		}
-		
-		return string(refContent), nil
+	if ref != "" {
+		// HEAD is a reference (e.g., "ref: refs/heads/master"); a missing
+		// reference file means there are no commits yet
+		return r.ResolveRef(ref)
 	}
-	
+
 	// HEAD is a commit ID
-	return head, nil
+	headPath := filepath.Join(r.Path, SnapDirName, "HEAD")
+	headContent, err := os.ReadFile(headPath)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(headContent)), nil
 }
 
 // UpdateHEAD updates the HEAD reference to point to a commit
 func (r *Repository) UpdateHEAD(commitID string) error {
-	// Read HEAD file to determine if it's a reference or a commit ID
-	headPath := filepath.Join(r.Path, SnapDirName, "HEAD")
-	headContent, err := os.ReadFile(headPath)
+	// Find out what HEAD points to
+	ref, err := r.HeadRef()
 	if err != nil {
 		return fmt.Errorf("failed to read HEAD file: %w", err)
 	}
 
-	head := string(headContent)
-	if len(head) > 0 && head[0] == 'r' {
-		// HEAD is a reference (e.g., "ref: refs/heads/master")
-		refPath := head[5:] // Remove "ref: " prefix
-		refPath = filepath.Join(r.Path, SnapDirName, refPath)
-		
-		// Create directory if it doesn't exist
-		if err := os.MkdirAll(filepath.Dir(refPath), 0755); err != nil {
-			return fmt.Errorf("failed to create reference directory: %w", err)
-		}
-		
-		// Write commit ID to reference file
-		if err := os.WriteFile(refPath, []byte(commitID), 0644); err != nil {
-			return fmt.Errorf("failed to write reference file: %w", err)
-		}
-	} else {
-		// HEAD is a commit ID, update it directly
-		if err := os.WriteFile(headPath, []byte(commitID), 0644); err != nil {
-			return fmt.Errorf("failed to write HEAD file: %w", err)
-		}
+	if ref != "" {
+		// HEAD is a reference, update the branch it points to
+		return r.UpdateRef(ref, commitID)
+	}
+
+	// HEAD is a commit ID, update it directly
+	headPath := filepath.Join(r.Path, SnapDirName, "HEAD")
+	if err := os.WriteFile(headPath, []byte(commitID), 0644); err != nil {
+		return fmt.Errorf("failed to write HEAD file: %w", err)
 	}
 
 	return nil
@@ -228,33 +248,5 @@ func (r *Repository) UpdateHEAD(commitID string) error {
 
 // GetCommitHistory gets the commit history starting from the given commit ID
 func (r *Repository) GetCommitHistory(startCommitID string) ([]*Commit, error) {
-	var history []*Commit
-	currentID := startCommitID
-
-	// If no start commit ID is provided, use HEAD
-	if currentID == "" {
-		var err error
-		currentID, err = r.GetHEADCommitID()
-		if err != nil {
-			return nil, fmt.Errorf("failed to get HEAD commit ID: %w", err)
-		}
-		
-		// If HEAD is empty (no commits yet), return empty history
-		if currentID == "" {
-			return history, nil
-		}
-	}
-
-	// Traverse commit history
-	for currentID != "" {
-		commit, err := r.GetCommit(currentID)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get commit %s: %w", currentID, err)
-		}
-
-		history = append(history, commit)
-		currentID = commit.ParentID
-	}
-
-	return history, nil
+	return r.GetRecentCommits(startCommitID, 0)
 }
@@ -3,6 +3,7 @@ package repository
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -233,6 +234,35 @@ func TestGetCommitHistory(t *testing.T) {
 	}
 }
 
+func TestChangedFiles(t *testing.T) {
+	repo, err := Init(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to initialize repository: %v", err)
+	}
+
+	// Everything is new in the first commit
+	tree1 := &Tree{Entries: map[string]string{"a.txt": "object1", "b.txt": "object2"}}
+	changed, err := repo.ChangedFiles("", tree1)
+	if err != nil || strings.Join(changed, ",") != "a.txt,b.txt" {
+		t.Errorf("Expected both files to be new, got %v, %v", changed, err)
+	}
+	commit1, err := repo.CreateCommit("✨ Initial commit", "testuser", "test@example.com", tree1)
+	if err != nil {
+		t.Fatalf("Failed to create commit: %v", err)
+	}
+
+	// Changed, added and removed files count; unchanged ones don't
+	tree2 := &Tree{Entries: map[string]string{"a.txt": "object3", "c.txt": "object4"}}
+	changed, err = repo.ChangedFiles(commit1.ID, tree2)
+	if err != nil || strings.Join(changed, ",") != "a.txt,b.txt,c.txt" {
+		t.Errorf("Expected a.txt, b.txt and c.txt to have changed, got %v, %v", changed, err)
+	}
+	changed, err = repo.ChangedFiles(commit1.ID, tree1)
+	if err != nil || len(changed) != 0 {
+		t.Errorf("Expected no changes, got %v, %v", changed, err)
+	}
+}
+
 func TestUndoLastCommit(t *testing.T) {
 	// Create a temporary directory for testing
 	tempDir, err := os.MkdirTemp("", "snap-test-")
@@ -0,0 +1,665 @@
+package repository
+
+import (
+	"container/heap"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// CommitGraphEntry holds the data needed to walk history without reading commit objects
+type CommitGraphEntry struct {
+	ID         string   `json:"id"`
+	Parents    []string `json:"parents,omitempty"`
+	TreeID     string   `json:"tree_id"`
+	Timestamp  int64    `json:"timestamp"`  // Commit time in Unix nanoseconds
+	Generation int      `json:"generation"` // 1 for root commits, 1 + max(parent generation) otherwise
+}
+
+// CommitGraph is an index of every known commit and its ancestry
+type CommitGraph struct {
+	Entries map[string]*CommitGraphEntry `json:"entries"`
+}
+
+// commitGraphCache keeps the last loaded graph for a repository path so that
+// repeated history queries (e.g. one per web request) skip the file read
+type commitGraphCache struct {
+	mu      sync.Mutex
+	graph   *CommitGraph
+	modTime time.Time
+	size    int64
+}
+
+var (
+	commitGraphCachesMu sync.Mutex
+	commitGraphCaches   = map[string]*commitGraphCache{}
+)
+
+// NewCommitGraph creates a new empty commit graph
+func NewCommitGraph() *CommitGraph {
+	return &CommitGraph{
+		Entries: make(map[string]*CommitGraphEntry),
+	}
+}
+
+// Parents returns the IDs of the parents of a commit
+func (c *Commit) Parents() []string {
+	var parents []string
+	if c.ParentID != "" {
+		parents = append(parents, c.ParentID)
+	}
+	if c.MergeParentID != "" {
+		parents = append(parents, c.MergeParentID)
+	}
+	return parents
+}
+
+// commitGraphPath returns the path to the commit-graph file
+func (r *Repository) commitGraphPath() string {
+	return filepath.Join(r.Path, SnapDirName, "objects", "info", "commit-graph")
+}
+
+// graphCache returns the shared commit graph cache for this repository
+func (r *Repository) graphCache() *commitGraphCache {
+	commitGraphCachesMu.Lock()
+	defer commitGraphCachesMu.Unlock()
+
+	cache, ok := commitGraphCaches[r.Path]
+	if !ok {
+		cache = &commitGraphCache{}
+		commitGraphCaches[r.Path] = cache
+	}
+	return cache
+}
+
+// LoadCommitGraph reads the commit-graph file, returning an empty graph if it doesn't exist
+func (r *Repository) LoadCommitGraph() (*CommitGraph, error) {
+	cache := r.graphCache()
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	return r.loadCommitGraphLocked(cache)
+}
+
+// loadCommitGraphLocked loads the graph, reusing the cached copy if the file is unchanged
+func (r *Repository) loadCommitGraphLocked(cache *commitGraphCache) (*CommitGraph, error) {
+	graphPath := r.commitGraphPath()
+	info, err := os.Stat(graphPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			cache.graph = NewCommitGraph()
+			cache.modTime = time.Time{}
+			cache.size = 0
+			return cache.graph, nil
+		}
+		return nil, fmt.Errorf("failed to stat commit-graph file: %w", err)
+	}
+
+	// Reuse the cached graph if the file hasn't changed since we read it
+	if cache.graph != nil && info.ModTime().Equal(cache.modTime) && info.Size() == cache.size {
+		return cache.graph, nil
+	}
+
+	data, err := os.ReadFile(graphPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit-graph file: %w", err)
+	}
+
+	graph := NewCommitGraph()
+	if err := json.Unmarshal(data, graph); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal commit-graph: %w", err)
+	}
+	if graph.Entries == nil {
+		graph.Entries = make(map[string]*CommitGraphEntry)
+	}
+
+	cache.graph = graph
+	cache.modTime = info.ModTime()
+	cache.size = info.Size()
+	return graph, nil
+}
+
+// WriteCommitGraph writes the commit graph to disk
+func (r *Repository) WriteCommitGraph(graph *CommitGraph) error {
+	cache := r.graphCache()
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	return r.writeCommitGraphLocked(cache, graph)
+}
+
+// writeCommitGraphLocked writes the graph and refreshes the cache
+func (r *Repository) writeCommitGraphLocked(cache *commitGraphCache, graph *CommitGraph) error {
+	graphPath := r.commitGraphPath()
+	if err := os.MkdirAll(filepath.Dir(graphPath), 0755); err != nil {
+		return fmt.Errorf("failed to create commit-graph directory: %w", err)
+	}
+
+	data, err := json.Marshal(graph)
+	if err != nil {
+		return fmt.Errorf("failed to marshal commit-graph: %w", err)
+	}
+
+	if err := os.WriteFile(graphPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write commit-graph file: %w", err)
+	}
+
+	info, err := os.Stat(graphPath)
+	if err != nil {
+		return fmt.Errorf("failed to stat commit-graph file: %w", err)
+	}
+
+	cache.graph = graph
+	cache.modTime = info.ModTime()
+	cache.size = info.Size()
+	return nil
+}
+
+// RebuildCommitGraph rebuilds the commit graph from every commit object in the repository
+func (r *Repository) RebuildCommitGraph() (*CommitGraph, error) {
+	commitsDir := filepath.Join(r.Path, SnapDirName, "objects", "commits")
+	files, err := os.ReadDir(commitsDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read commits directory: %w", err)
+	}
+
+	// Read every commit object
+	graph := NewCommitGraph()
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+
+		commit, err := r.GetCommit(file.Name())
+		if err != nil {
+			return nil, err
+		}
+		graph.Entries[commit.ID] = newCommitGraphEntry(commit)
+	}
+
+	// Compute generation numbers now that every entry is present
+	for id := range graph.Entries {
+		if _, err := graph.computeGeneration(id); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := r.WriteCommitGraph(graph); err != nil {
+		return nil, err
+	}
+
+	return graph, nil
+}
+
+// VerifyCommitGraph checks the commit graph against the commit objects on disk
+// and returns a list of problems found
+func (r *Repository) VerifyCommitGraph() ([]string, error) {
+	graph, err := r.LoadCommitGraph()
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []string
+	for id, entry := range graph.Entries {
+		commit, err := r.GetCommit(id)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: commit object missing", truncate(id)))
+			continue
+		}
+
+		expected := newCommitGraphEntry(commit)
+		if expected.TreeID != entry.TreeID {
+			problems = append(problems, fmt.Sprintf("%s: tree mismatch", truncate(id)))
+		}
+		if !equalStrings(expected.Parents, entry.Parents) {
+			problems = append(problems, fmt.Sprintf("%s: parents mismatch", truncate(id)))
+		}
+
+		// A commit's generation must be exactly one more than its highest parent
+		wantGeneration := 1
+		for _, parentID := range entry.Parents {
+			parent, ok := graph.Entries[parentID]
+			if !ok {
+				problems = append(problems, fmt.Sprintf("%s: parent %s missing from graph", truncate(id), truncate(parentID)))
+				continue
+			}
+			if parent.Generation+1 > wantGeneration {
+				wantGeneration = parent.Generation + 1
+			}
+		}
+		if entry.Generation != wantGeneration {
+			problems = append(problems, fmt.Sprintf("%s: generation %d, expected %d", truncate(id), entry.Generation, wantGeneration))
+		}
+	}
+
+	sort.Strings(problems)
+	return problems, nil
+}
+
+// addToCommitGraph records a newly created commit in the commit graph
+func (r *Repository) addToCommitGraph(commit *Commit) error {
+	cache := r.graphCache()
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	graph, err := r.loadCommitGraphLocked(cache)
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.ensureInGraph(graph, commit.ID); err != nil {
+		return err
+	}
+
+	return r.writeCommitGraphLocked(cache, graph)
+}
+
+// withCommitGraph runs fn against a graph that is guaranteed to contain the
+// ancestry of the given commits, persisting any entries that had to be filled in
+func (r *Repository) withCommitGraph(ids []string, fn func(graph *CommitGraph) error) error {
+	cache := r.graphCache()
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	graph, err := r.loadCommitGraphLocked(cache)
+	if err != nil {
+		return err
+	}
+
+	// Fill in commits that predate the graph or were copied in from elsewhere
+	dirty := false
+	for _, id := range ids {
+		added, err := r.ensureInGraph(graph, id)
+		if err != nil {
+			return err
+		}
+		dirty = dirty || added
+	}
+
+	if dirty {
+		if err := r.writeCommitGraphLocked(cache, graph); err != nil {
+			return err
+		}
+	}
+
+	return fn(graph)
+}
+
+// ensureInGraph adds a commit and any missing ancestors to the graph,
+// returning true if the graph was modified
+func (r *Repository) ensureInGraph(graph *CommitGraph, id string) (bool, error) {
+	if id == "" {
+		return false, nil
+	}
+	if _, ok := graph.Entries[id]; ok {
+		return false, nil
+	}
+
+	// Load missing commits iteratively to avoid deep recursion on long histories
+	pending := []string{id}
+	for len(pending) > 0 {
+		currentID := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if _, ok := graph.Entries[currentID]; ok {
+			continue
+		}
+
+		commit, err := r.GetCommit(currentID)
+		if err != nil {
+			return false, fmt.Errorf("failed to get commit %s: %w", currentID, err)
+		}
+		graph.Entries[commit.ID] = newCommitGraphEntry(commit)
+
+		for _, parentID := range commit.Parents() {
+			if _, ok := graph.Entries[parentID]; !ok {
+				pending = append(pending, parentID)
+			}
+		}
+	}
+
+	if _, err := graph.computeGeneration(id); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// newCommitGraphEntry creates a graph entry for a commit without a generation number
+func newCommitGraphEntry(commit *Commit) *CommitGraphEntry {
+	return &CommitGraphEntry{
+		ID:        commit.ID,
+		Parents:   commit.Parents(),
+		TreeID:    commit.TreeID,
+		Timestamp: commit.Timestamp.UnixNano(),
+	}
+}
+
+// computeGeneration fills in the generation number for a commit and its ancestors
+func (g *CommitGraph) computeGeneration(id string) (int, error) {
+	entry, ok := g.Entries[id]
+	if !ok {
+		return 0, fmt.Errorf("commit %s not found in commit graph", id)
+	}
+	if entry.Generation > 0 {
+		return entry.Generation, nil
+	}
+
+	// Post-order walk so that parents are numbered before their children
+	stack := []string{id}
+	for len(stack) > 0 {
+		currentID := stack[len(stack)-1]
+		current := g.Entries[currentID]
+		if current.Generation > 0 {
+			stack = stack[:len(stack)-1]
+			continue
+		}
+
+		generation := 1
+		ready := true
+		for _, parentID := range current.Parents {
+			parent, ok := g.Entries[parentID]
+			if !ok {
+				return 0, fmt.Errorf("commit %s not found in commit graph", parentID)
+			}
+			if parent.Generation == 0 {
+				stack = append(stack, parentID)
+				ready = false
+				continue
+			}
+			if parent.Generation+1 > generation {
+				generation = parent.Generation + 1
+			}
+		}
+
+		if ready {
+			current.Generation = generation
+			stack = stack[:len(stack)-1]
+		}
+	}
+
+	return entry.Generation, nil
+}
+
+// Walk visits the commits reachable from the start commits, newest first.
+// Walking stops early when fn returns false.
+func (g *CommitGraph) Walk(startIDs []string, fn func(entry *CommitGraphEntry) bool) {
+	queue := &graphQueue{}
+	seen := make(map[string]bool)
+	for _, id := range startIDs {
+		if entry, ok := g.Entries[id]; ok && !seen[id] {
+			seen[id] = true
+			heap.Push(queue, entry)
+		}
+	}
+
+	for queue.Len() > 0 {
+		entry := heap.Pop(queue).(*CommitGraphEntry)
+		if !fn(entry) {
+			return
+		}
+
+		for _, parentID := range entry.Parents {
+			if parent, ok := g.Entries[parentID]; ok && !seen[parentID] {
+				seen[parentID] = true
+				heap.Push(queue, parent)
+			}
+		}
+	}
+}
+
+// Count returns the number of commits reachable from a commit, including itself
+func (g *CommitGraph) Count(id string) int {
+	count := 0
+	g.Walk([]string{id}, func(*CommitGraphEntry) bool {
+		count++
+		return true
+	})
+	return count
+}
+
+// IsAncestor reports whether ancestor is reachable from descendant.
+// A commit is considered an ancestor of itself.
+func (g *CommitGraph) IsAncestor(ancestor, descendant string) bool {
+	target, ok := g.Entries[ancestor]
+	if !ok {
+		return false
+	}
+
+	found := false
+	g.Walk([]string{descendant}, func(entry *CommitGraphEntry) bool {
+		if entry.ID == ancestor {
+			found = true
+			return false
+		}
+		// Once the walk drops below the target's generation it can't be reached any more
+		return entry.Generation >= target.Generation
+	})
+	return found
+}
+
+// MergeBase returns the best common ancestor of two commits, or "" if they share no history
+func (g *CommitGraph) MergeBase(a, b string) string {
+	const (
+		fromA = 1 << iota
+		fromB
+	)
+
+	// Paint commits with the side(s) they are reachable from; the first
+	// commit painted by both sides in generation order is the merge base
+	flags := make(map[string]int)
+	queue := &graphQueue{}
+	for _, start := range []struct {
+		id   string
+		flag int
+	}{{a, fromA}, {b, fromB}} {
+		entry, ok := g.Entries[start.id]
+		if !ok {
+			return ""
+		}
+		if flags[start.id] == 0 {
+			heap.Push(queue, entry)
+		}
+		flags[start.id] |= start.flag
+	}
+
+	for queue.Len() > 0 {
+		entry := heap.Pop(queue).(*CommitGraphEntry)
+		flag := flags[entry.ID]
+		if flag == fromA|fromB {
+			return entry.ID
+		}
+
+		for _, parentID := range entry.Parents {
+			parent, ok := g.Entries[parentID]
+			if !ok {
+				continue
+			}
+			if flags[parentID]&flag != flag {
+				if flags[parentID] == 0 {
+					heap.Push(queue, parent)
+				}
+				flags[parentID] |= flag
+			}
+		}
+	}
+
+	return ""
+}
+
+// graphQueue is a priority queue of graph entries, highest generation first
+type graphQueue []*CommitGraphEntry
+
+func (q graphQueue) Len() int { return len(q) }
+
+func (q graphQueue) Less(i, j int) bool {
+	if q[i].Generation != q[j].Generation {
+		return q[i].Generation > q[j].Generation
+	}
+	if q[i].Timestamp != q[j].Timestamp {
+		return q[i].Timestamp > q[j].Timestamp
+	}
+	return q[i].ID < q[j].ID
+}
+
+func (q graphQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *graphQueue) Push(x any) { *q = append(*q, x.(*CommitGraphEntry)) }
+
+func (q *graphQueue) Pop() any {
+	old := *q
+	n := len(old)
+	entry := old[n-1]
+	*q = old[:n-1]
+	return entry
+}
+
+// resolveStart returns the given commit ID, or the HEAD commit ID if it is empty
+func (r *Repository) resolveStart(startCommitID string) (string, error) {
+	if startCommitID != "" {
+		return startCommitID, nil
+	}
+
+	headID, err := r.GetHEADCommitID()
+	if err != nil {
+		return "", fmt.Errorf("failed to get HEAD commit ID: %w", err)
+	}
+	return headID, nil
+}
+
+// GetCommitHistoryIDs returns the IDs of commits reachable from a commit, newest first.
+// A limit of zero or less returns the whole history.
+func (r *Repository) GetCommitHistoryIDs(startCommitID string, limit int) ([]string, error) {
+	startID, err := r.resolveStart(startCommitID)
+	if err != nil {
+		return nil, err
+	}
+	if startID == "" {
+		return []string{}, nil
+	}
+
+	ids := []string{}
+	err = r.withCommitGraph([]string{startID}, func(graph *CommitGraph) error {
+		graph.Walk([]string{startID}, func(entry *CommitGraphEntry) bool {
+			ids = append(ids, entry.ID)
+			return limit <= 0 || len(ids) < limit
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// GetRecentCommits gets at most limit commits reachable from the given commit ID, newest first
+func (r *Repository) GetRecentCommits(startCommitID string, limit int) ([]*Commit, error) {
+	ids, err := r.GetCommitHistoryIDs(startCommitID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	history := make([]*Commit, 0, len(ids))
+	for _, id := range ids {
+		commit, err := r.GetCommit(id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get commit %s: %w", id, err)
+		}
+		history = append(history, commit)
+	}
+
+	return history, nil
+}
+
+// WalkCommits calls fn for each commit reachable from the given commit ID, newest first,
+// reading commit objects only as they are visited. Walking stops when fn returns false.
+func (r *Repository) WalkCommits(startCommitID string, fn func(commit *Commit) bool) error {
+	ids, err := r.GetCommitHistoryIDs(startCommitID, 0)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		commit, err := r.GetCommit(id)
+		if err != nil {
+			return fmt.Errorf("failed to get commit %s: %w", id, err)
+		}
+		if !fn(commit) {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// CountCommits returns the number of commits reachable from the given commit ID (HEAD if empty)
+func (r *Repository) CountCommits(startCommitID string) (int, error) {
+	startID, err := r.resolveStart(startCommitID)
+	if err != nil {
+		return 0, err
+	}
+	if startID == "" {
+		return 0, nil
+	}
+
+	count := 0
+	err = r.withCommitGraph([]string{startID}, func(graph *CommitGraph) error {
+		count = graph.Count(startID)
+		return nil
+	})
+	return count, err
+}
+
+// IsAncestor reports whether ancestorID is reachable from descendantID
+func (r *Repository) IsAncestor(ancestorID, descendantID string) (bool, error) {
+	if ancestorID == "" || descendantID == "" {
+		return false, nil
+	}
+
+	var result bool
+	err := r.withCommitGraph([]string{ancestorID, descendantID}, func(graph *CommitGraph) error {
+		result = graph.IsAncestor(ancestorID, descendantID)
+		return nil
+	})
+	return result, err
+}
+
+// MergeBase returns the best common ancestor of two commits
+func (r *Repository) MergeBase(a, b string) (string, error) {
+	if a == "" || b == "" {
+		return "", errors.New("merge base requires two commits")
+	}
+
+	var base string
+	err := r.withCommitGraph([]string{a, b}, func(graph *CommitGraph) error {
+		base = graph.MergeBase(a, b)
+		return nil
+	})
+	return base, err
+}
+
+// truncate shortens a commit ID for display
+func truncate(id string) string {
+	if len(id) > 7 {
+		return id[:7]
+	}
+	return id
+}
+
+// equalStrings reports whether two string slices have the same contents in the same order
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// createLinearHistory creates n commits on the current branch and returns them oldest first
+func createLinearHistory(t *testing.T, repo *Repository, n int) []*Commit {
+	t.Helper()
+
+	var commits []*Commit
+	for i := 0; i < n; i++ {
+		tree := &Tree{
+			Entries: map[string]string{
+				"file.txt": string(rune('a' + i)),
+			},
+		}
+		commit, err := repo.CreateCommit("✨ Commit", "testuser", "test@example.com", tree)
+		if err != nil {
+			t.Fatalf("Failed to create commit %d: %v", i, err)
+		}
+		commits = append(commits, commit)
+	}
+
+	return commits
+}
+
+func TestCommitGraphMaintainedOnCommit(t *testing.T) {
+	// Create a temporary directory for testing
+	tempDir, err := os.MkdirTemp("", "snap-test-")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// Initialize repository
+	repo, err := Init(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to initialize repository: %v", err)
+	}
+
+	commits := createLinearHistory(t, repo, 3)
+
+	// Check if the commit-graph file was written
+	if _, err := os.Stat(filepath.Join(tempDir, ".snap", "objects", "info", "commit-graph")); err != nil {
+		t.Fatalf("Expected commit-graph file to exist: %v", err)
+	}
+
+	// Check entries and generation numbers
+	graph, err := repo.LoadCommitGraph()
+	if err != nil {
+		t.Fatalf("Failed to load commit graph: %v", err)
+	}
+	for i, commit := range commits {
+		entry, ok := graph.Entries[commit.ID]
+		if !ok {
+			t.Fatalf("Expected commit %s to be in the commit graph", commit.ID)
+		}
+		if entry.Generation != i+1 {
+			t.Errorf("Expected generation %d for commit %d, got %d", i+1, i, entry.Generation)
+		}
+		if entry.TreeID != commit.TreeID {
+			t.Errorf("Expected tree ID '%s', got '%s'", commit.TreeID, entry.TreeID)
+		}
+	}
+
+	// Check commit count
+	count, err := repo.CountCommits("")
+	if err != nil {
+		t.Fatalf("Failed to count commits: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("Expected 3 commits, got %d", count)
+	}
+
+	// Check limited history
+	recent, err := repo.GetRecentCommits("", 2)
+	if err != nil {
+		t.Fatalf("Failed to get recent commits: %v", err)
+	}
+	if len(recent) != 2 || recent[0].ID != commits[2].ID || recent[1].ID != commits[1].ID {
+		t.Errorf("Expected the two newest commits in order, got %d commits", len(recent))
+	}
+
+	// Check the graph verifies cleanly
+	problems, err := repo.VerifyCommitGraph()
+	if err != nil {
+		t.Fatalf("Failed to verify commit graph: %v", err)
+	}
+	if len(problems) != 0 {
+		t.Errorf("Expected no problems, got %v", problems)
+	}
+}
+
+func TestCommitGraphFillsMissingEntries(t *testing.T) {
+	// Create a temporary directory for testing
+	tempDir, err := os.MkdirTemp("", "snap-test-")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// Initialize repository
+	repo, err := Init(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to initialize repository: %v", err)
+	}
+
+	commits := createLinearHistory(t, repo, 3)
+
+	// Remove the commit graph to simulate a repository created before it existed
+	if err := os.Remove(filepath.Join(tempDir, ".snap", "objects", "info", "commit-graph")); err != nil {
+		t.Fatalf("Failed to remove commit graph: %v", err)
+	}
+
+	// History should still be complete
+	history, err := repo.GetCommitHistory("")
+	if err != nil {
+		t.Fatalf("Failed to get commit history: %v", err)
+	}
+	if len(history) != 3 {
+		t.Errorf("Expected 3 commits in history, got %d", len(history))
+	}
+
+	// The graph should have been filled in along the way
+	graph, err := repo.LoadCommitGraph()
+	if err != nil {
+		t.Fatalf("Failed to load commit graph: %v", err)
+	}
+	if len(graph.Entries) != 3 {
+		t.Errorf("Expected 3 entries in the commit graph, got %d", len(graph.Entries))
+	}
+	if graph.Entries[commits[2].ID].Generation != 3 {
+		t.Errorf("Expected generation 3 for the newest commit, got %d", graph.Entries[commits[2].ID].Generation)
+	}
+}
+
+func TestCommitGraphAncestry(t *testing.T) {
+	// Build a graph by hand with a fork:
+	//
+	//   a - b - c
+	//        \
+	//         d - e
+	base := time.Now().UnixNano()
+	graph := NewCommitGraph()
+	graph.Entries["a"] = &CommitGraphEntry{ID: "a", Timestamp: base}
+	graph.Entries["b"] = &CommitGraphEntry{ID: "b", Parents: []string{"a"}, Timestamp: base + 1}
+	graph.Entries["c"] = &CommitGraphEntry{ID: "c", Parents: []string{"b"}, Timestamp: base + 2}
+	graph.Entries["d"] = &CommitGraphEntry{ID: "d", Parents: []string{"b"}, Timestamp: base + 3}
+	graph.Entries["e"] = &CommitGraphEntry{ID: "e", Parents: []string{"d"}, Timestamp: base + 4}
+	for id := range graph.Entries {
+		if _, err := graph.computeGeneration(id); err != nil {
+			t.Fatalf("Failed to compute generation for %s: %v", id, err)
+		}
+	}
+
+	// Check generation numbers
+	if graph.Entries["e"].Generation != 4 {
+		t.Errorf("Expected generation 4 for e, got %d", graph.Entries["e"].Generation)
+	}
+
+	// Check reachability
+	if !graph.IsAncestor("a", "e") {
+		t.Errorf("Expected a to be an ancestor of e")
+	}
+	if graph.IsAncestor("c", "e") {
+		t.Errorf("Expected c not to be an ancestor of e")
+	}
+	if !graph.IsAncestor("e", "e") {
+		t.Errorf("Expected e to be an ancestor of itself")
+	}
+
+	// Check reachability past a commit of the same generation as the target
+	graph.Entries["m"] = &CommitGraphEntry{ID: "m", Parents: []string{"c", "e"}, Timestamp: base + 5}
+	if _, err := graph.computeGeneration("m"); err != nil {
+		t.Fatalf("Failed to compute generation for m: %v", err)
+	}
+	if !graph.IsAncestor("d", "m") {
+		t.Errorf("Expected d to be an ancestor of merge commit m")
+	}
+	if !graph.IsAncestor("c", "m") {
+		t.Errorf("Expected c to be an ancestor of merge commit m, past d of the same generation")
+	}
+
+	// Check merge base
+	if base := graph.MergeBase("c", "e"); base != "b" {
+		t.Errorf("Expected merge base of c and e to be b, got '%s'", base)
+	}
+	if base := graph.MergeBase("a", "e"); base != "a" {
+		t.Errorf("Expected merge base of a and e to be a, got '%s'", base)
+	}
+
+	// Check counting
+	if count := graph.Count("e"); count != 4 {
+		t.Errorf("Expected 4 commits reachable from e, got %d", count)
+	}
+}
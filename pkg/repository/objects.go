@@ -0,0 +1,288 @@
+package repository
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ObjectType identifies the kind of an object in the object store
+type ObjectType string
+
+const (
+	// ObjectCommit is a commit object
+	ObjectCommit ObjectType = "commit"
+	// ObjectTree is a tree object
+	ObjectTree ObjectType = "tree"
+	// ObjectBlob is a file content object
+	ObjectBlob ObjectType = "blob"
+)
+
+// Object is a raw object as stored in the object store
+type Object struct {
+	Type ObjectType `json:"type"`
+	ID   string     `json:"id"`
+	Data []byte     `json:"data"`
+}
+
+// objectID matches object IDs, the hex SHA-1 of an object's contents
+var objectID = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// IsObjectID reports whether id is a full object ID
+func IsObjectID(id string) bool {
+	return objectID.MatchString(id)
+}
+
+// ValidatePath checks that a tree path stays inside the working tree and out of .snap
+func ValidatePath(path string) error {
+	if path == "" || strings.HasPrefix(path, "/") || strings.ContainsAny(path, "\\\x00\n") || filepath.IsAbs(path) {
+		return fmt.Errorf("invalid path %q", path)
+	}
+	for i, segment := range strings.Split(path, "/") {
+		if segment == "" || segment == "." || segment == ".." || (i == 0 && strings.EqualFold(segment, SnapDirName)) {
+			return fmt.Errorf("invalid path %q", path)
+		}
+	}
+	return nil
+}
+
+// objectPath returns the path to an object file
+func (r *Repository) objectPath(objectType ObjectType, id string) (string, error) {
+	if len(id) < 3 || filepath.Base(id) != id {
+		return "", fmt.Errorf("invalid object ID: %q", id)
+	}
+
+	objectsDir := filepath.Join(r.Path, SnapDirName, "objects")
+	switch objectType {
+	case ObjectCommit:
+		return filepath.Join(objectsDir, "commits", id), nil
+	case ObjectTree:
+		return filepath.Join(objectsDir, "trees", id), nil
+	case ObjectBlob:
+		return filepath.Join(objectsDir, id[:2], id[2:]), nil
+	default:
+		return "", fmt.Errorf("unknown object type: %s", objectType)
+	}
+}
+
+// HasObject reports whether an object exists in the object store
+func (r *Repository) HasObject(objectType ObjectType, id string) bool {
+	objectPath, err := r.objectPath(objectType, id)
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(objectPath)
+	return err == nil
+}
+
+// ReadObject reads the raw contents of an object
+func (r *Repository) ReadObject(objectType ObjectType, id string) (*Object, error) {
+	objectPath, err := r.objectPath(objectType, id)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(objectPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s %s: %w", objectType, id, err)
+	}
+
+	return &Object{Type: objectType, ID: id, Data: data}, nil
+}
+
+// WriteObject verifies an object's contents match its ID and writes it to the object store
+func (r *Repository) WriteObject(object *Object) error {
+	if err := VerifyObject(object); err != nil {
+		return err
+	}
+
+	objectPath, err := r.objectPath(object.Type, object.ID)
+	if err != nil {
+		return err
+	}
+
+	// Create directory if it doesn't exist
+	if err := os.MkdirAll(filepath.Dir(objectPath), 0755); err != nil {
+		return fmt.Errorf("failed to create objects directory: %w", err)
+	}
+
+	if err := os.WriteFile(objectPath, object.Data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s %s: %w", object.Type, object.ID, err)
+	}
+
+	return nil
+}
+
+// VerifyObject checks that an object's contents hash to its ID
+func VerifyObject(object *Object) error {
+	var content []byte
+	switch object.Type {
+	case ObjectBlob:
+		content = object.Data
+	case ObjectTree:
+		// Trees from other repositories must not point outside the working tree
+		var tree Tree
+		if err := json.Unmarshal(object.Data, &tree); err != nil {
+			return fmt.Errorf("invalid tree %s: %w", object.ID, err)
+		}
+		for path, id := range tree.Entries {
+			if err := ValidatePath(path); err != nil {
+				return fmt.Errorf("tree %s: %w", object.ID, err)
+			}
+			if !IsObjectID(id) {
+				return fmt.Errorf("tree %s: invalid object ID %q for %s", object.ID, id, path)
+			}
+		}
+		content = object.Data
+	case ObjectCommit:
+		// Commit IDs are the hash of the commit without its ID field
+		var commit Commit
+		if err := json.Unmarshal(object.Data, &commit); err != nil {
+			return fmt.Errorf("invalid commit %s: %w", object.ID, err)
+		}
+		if commit.ID != object.ID {
+			return fmt.Errorf("commit %s has mismatched ID %s", object.ID, commit.ID)
+		}
+		for _, id := range append([]string{commit.TreeID}, commit.Parents()...) {
+			if !IsObjectID(id) {
+				return fmt.Errorf("commit %s refers to invalid object ID %q", object.ID, id)
+			}
+		}
+		commit.ID = ""
+		marshaled, err := json.Marshal(commit)
+		if err != nil {
+			return fmt.Errorf("failed to marshal commit: %w", err)
+		}
+		content = marshaled
+	default:
+		return fmt.Errorf("unknown object type: %s", object.Type)
+	}
+
+	hash := sha1.New()
+	hash.Write(content)
+	if hex.EncodeToString(hash.Sum(nil)) != object.ID {
+		return fmt.Errorf("%s %s is corrupt: contents don't match ID", object.Type, object.ID)
+	}
+
+	return nil
+}
+
+// ReadBlob reads the content of a file object
+func (r *Repository) ReadBlob(id string) ([]byte, error) {
+	object, err := r.ReadObject(ObjectBlob, id)
+	if err != nil {
+		return nil, err
+	}
+	return object.Data, nil
+}
+
+// WriteBlob stores content in the object store and returns its ID
+func (r *Repository) WriteBlob(data []byte) (string, error) {
+	hash := sha1.New()
+	hash.Write(data)
+	id := hex.EncodeToString(hash.Sum(nil))
+
+	if r.HasObject(ObjectBlob, id) {
+		return id, nil
+	}
+	if err := r.WriteObject(&Object{Type: ObjectBlob, ID: id, Data: data}); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// ReachableObjects returns every object needed to go from the commits in haveIDs
+// to the commits in wantIDs, in an order where each object's dependencies come first.
+// Commits in haveIDs (and their ancestors) are assumed to be present on the other side;
+// unknown have IDs are ignored.
+func (r *Repository) ReachableObjects(wantIDs, haveIDs []string) ([]*Object, error) {
+	// Only haves we know about can be used to trim the walk
+	var knownHaves []string
+	for _, id := range haveIDs {
+		if id != "" && r.HasObject(ObjectCommit, id) {
+			knownHaves = append(knownHaves, id)
+		}
+	}
+
+	var objects []*Object
+	err := r.withCommitGraph(append(append([]string{}, wantIDs...), knownHaves...), func(graph *CommitGraph) error {
+		// Everything reachable from a have is already on the other side
+		excluded := make(map[string]bool)
+		graph.Walk(knownHaves, func(entry *CommitGraphEntry) bool {
+			excluded[entry.ID] = true
+			return true
+		})
+
+		// Files in the have tips don't need to be sent again
+		sentBlobs := make(map[string]bool)
+		sentTrees := make(map[string]bool)
+		for _, id := range knownHaves {
+			entry := graph.Entries[id]
+			sentTrees[entry.TreeID] = true
+			tree, err := r.GetTree(entry.TreeID)
+			if err != nil {
+				return err
+			}
+			for _, blobID := range tree.Entries {
+				sentBlobs[blobID] = true
+			}
+		}
+
+		// Collect missing commits, oldest first so parents arrive before children
+		var missing []*CommitGraphEntry
+		graph.Walk(wantIDs, func(entry *CommitGraphEntry) bool {
+			if !excluded[entry.ID] {
+				missing = append(missing, entry)
+			}
+			return true
+		})
+
+		for i := len(missing) - 1; i >= 0; i-- {
+			entry := missing[i]
+
+			if !sentTrees[entry.TreeID] {
+				sentTrees[entry.TreeID] = true
+				tree, err := r.GetTree(entry.TreeID)
+				if err != nil {
+					return err
+				}
+				for _, path := range sortedTreePaths(tree) {
+					blobID := tree.Entries[path]
+					if sentBlobs[blobID] {
+						continue
+					}
+					sentBlobs[blobID] = true
+					blob, err := r.ReadObject(ObjectBlob, blobID)
+					if err != nil {
+						return err
+					}
+					objects = append(objects, blob)
+				}
+
+				treeObject, err := r.ReadObject(ObjectTree, entry.TreeID)
+				if err != nil {
+					return err
+				}
+				objects = append(objects, treeObject)
+			}
+
+			commitObject, err := r.ReadObject(ObjectCommit, entry.ID)
+			if err != nil {
+				return err
+			}
+			objects = append(objects, commitObject)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return objects, nil
+}
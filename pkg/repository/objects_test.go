@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// treeObject returns a tree as an object with a valid ID
+func treeObject(t *testing.T, entries map[string]string) *Object {
+	t.Helper()
+
+	data, err := json.Marshal(&Tree{Entries: entries})
+	if err != nil {
+		t.Fatalf("Failed to marshal tree: %v", err)
+	}
+	hash := sha1.Sum(data)
+	return &Object{Type: ObjectTree, ID: hex.EncodeToString(hash[:]), Data: data}
+}
+
+func TestVerifyObjectRejectsUnsafeTrees(t *testing.T) {
+	blobID := hex.EncodeToString(make([]byte, 20))
+	if err := VerifyObject(treeObject(t, map[string]string{"docs/a.txt": blobID})); err != nil {
+		t.Errorf("Expected a plain tree to be valid, got %v", err)
+	}
+
+	for _, path := range []string{"../escaped.txt", "docs/../../x", "/etc/passwd", "a//b", "./a", ".snap/HEAD", ".SNAP/refs/heads/master", `..\x`, ""} {
+		if err := VerifyObject(treeObject(t, map[string]string{path: blobID})); err == nil {
+			t.Errorf("Expected a tree with %q to be rejected", path)
+		}
+	}
+	if err := VerifyObject(treeObject(t, map[string]string{"a.txt": "../../x"})); err == nil {
+		t.Errorf("Expected a tree with an invalid object ID to be rejected")
+	}
+}
+
+func TestCheckoutTreeRejectsUnsafePaths(t *testing.T) {
+	parent := t.TempDir()
+	repo, err := Init(filepath.Join(parent, "repo"))
+	if err != nil {
+		t.Fatalf("Failed to initialize repository: %v", err)
+	}
+	blobID, err := repo.WriteBlob([]byte("escaped"))
+	if err != nil {
+		t.Fatalf("Failed to write blob: %v", err)
+	}
+
+	for _, path := range []string{"../escaped.txt", ".snap/index"} {
+		tree := &Tree{Entries: map[string]string{path: blobID}}
+		if err := repo.CheckoutTree(&Tree{Entries: map[string]string{}}, tree); err == nil {
+			t.Errorf("Expected checking out %q to fail", path)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(parent, "escaped.txt")); !os.IsNotExist(err) {
+		t.Errorf("Expected nothing to be written outside the repository")
+	}
+}
+
+func TestValidateRefName(t *testing.T) {
+	for _, name := range []string{"refs/heads/master", "refs/heads/feature/login", "refs/snap/issues", "refs/remotes/origin/snap/issues"} {
+		if err := ValidateRefName(name); err != nil {
+			t.Errorf("Expected %q to be valid, got %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "HEAD", "refs/heads/../../../x", "refs//heads", "refs/heads/", "/refs/heads/x", "refs/heads/a b", "refs/heads/x\n"} {
+		if err := ValidateRefName(name); err == nil {
+			t.Errorf("Expected %q to be invalid", name)
+		}
+	}
+
+	repo, err := Init(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to initialize repository: %v", err)
+	}
+	if err := repo.UpdateRef("refs/heads/../../../x", "abc"); err == nil {
+		t.Errorf("Expected UpdateRef to reject an escaping name")
+	}
+	if _, err := repo.ResolveRef("refs/../HEAD"); err == nil {
+		t.Errorf("Expected ResolveRef to reject an escaping name")
+	}
+}
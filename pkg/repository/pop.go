@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
 )
 
 // UndoLastCommit undoes the last commit, keeping the changes in the working tree
@@ -29,24 +28,19 @@ func (r *Repository) UndoLastCommit() error {
 	// If there is no parent commit, we're undoing the first commit
 	if currentCommit.ParentID == "" {
 		// Update HEAD to empty
-		headPath := filepath.Join(r.Path, SnapDirName, "HEAD")
-		headContent, err := os.ReadFile(headPath)
+		ref, err := r.HeadRef()
 		if err != nil {
 			return fmt.Errorf("failed to read HEAD file: %w", err)
 		}
 
-		head := string(headContent)
-		if strings.HasPrefix(head, "ref: ") {
-			// HEAD is a reference (e.g., "ref: refs/heads/master")
-			refPath := head[5:] // Remove "ref: " prefix
-			refPath = filepath.Join(r.Path, SnapDirName, refPath)
-			
-			// Remove the reference file
-			if err := os.Remove(refPath); err != nil {
-				return fmt.Errorf("failed to remove reference file: %w", err)
+		if ref != "" {
+			// HEAD is a reference (e.g., "ref: refs/heads/master"), remove the reference file
+			if err := r.DeleteRef(ref); err != nil {
+				return err
 			}
 		} else {
 			// HEAD is a commit ID, update it to empty
+			headPath := filepath.Join(r.Path, SnapDirName, "HEAD")
 			if err := os.WriteFile(headPath, []byte(""), 0644); err != nil {
 				return fmt.Errorf("failed to write HEAD file: %w", err)
 			}
@@ -0,0 +1,249 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// BranchRefPrefix is the prefix of local branch references
+	BranchRefPrefix = "refs/heads/"
+	// TagRefPrefix is the prefix of tag references
+	TagRefPrefix = "refs/tags/"
+	// RemoteRefPrefix is the prefix of remote-tracking references
+	RemoteRefPrefix = "refs/remotes/"
+	// MetadataRefPrefix is the prefix of references holding repository metadata such as issues
+	MetadataRefPrefix = "refs/snap/"
+)
+
+// ValidateRefName checks that a reference name is a path below refs/ that
+// stays inside the .snap directory, e.g. refs/heads/master
+func ValidateRefName(name string) error {
+	if !strings.HasPrefix(name, "refs/") || strings.ContainsAny(name, "\\:*?[]~^ \t\n\x00") {
+		return fmt.Errorf("invalid reference name %q", name)
+	}
+	for _, segment := range strings.Split(name, "/") {
+		if segment == "" || segment == "." || segment == ".." || strings.HasSuffix(segment, ".lock") {
+			return fmt.Errorf("invalid reference name %q", name)
+		}
+	}
+	return nil
+}
+
+// refPath returns the path to a reference file
+func (r *Repository) refPath(name string) string {
+	return filepath.Join(r.Path, SnapDirName, filepath.FromSlash(name))
+}
+
+// HeadRef returns the reference HEAD points to (e.g. "refs/heads/master"),
+// or "" if HEAD is detached
+func (r *Repository) HeadRef() (string, error) {
+	headPath := filepath.Join(r.Path, SnapDirName, "HEAD")
+	headContent, err := os.ReadFile(headPath)
+	if err != nil {
+		return "", err
+	}
+
+	head := strings.TrimSpace(string(headContent))
+	if !strings.HasPrefix(head, "ref: ") {
+		return "", nil
+	}
+	ref := strings.TrimSpace(strings.TrimPrefix(head, "ref: "))
+
+	// Older versions of snap kept the trailing newline of HEAD in the
+	// reference file name; move such a file to its proper place
+	legacyPath := r.refPath(ref) + "\n"
+	if _, err := os.Stat(legacyPath); err == nil {
+		if _, err := os.Stat(r.refPath(ref)); os.IsNotExist(err) {
+			if err := os.Rename(legacyPath, r.refPath(ref)); err != nil {
+				return "", fmt.Errorf("failed to migrate reference file: %w", err)
+			}
+		}
+	}
+
+	return ref, nil
+}
+
+// CurrentBranch returns the name of the checked out branch, or "" if HEAD is detached
+func (r *Repository) CurrentBranch() (string, error) {
+	ref, err := r.HeadRef()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimPrefix(ref, BranchRefPrefix), nil
+}
+
+// SetHeadRef points HEAD at a reference
+func (r *Repository) SetHeadRef(name string) error {
+	if err := ValidateRefName(name); err != nil {
+		return err
+	}
+	headPath := filepath.Join(r.Path, SnapDirName, "HEAD")
+	if err := os.WriteFile(headPath, []byte("ref: "+name+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write HEAD file: %w", err)
+	}
+	return nil
+}
+
+// ResolveRef returns the commit ID a reference points to, or "" if it doesn't exist
+func (r *Repository) ResolveRef(name string) (string, error) {
+	if err := ValidateRefName(name); err != nil {
+		return "", err
+	}
+	content, err := os.ReadFile(r.refPath(name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to read reference %s: %w", name, err)
+	}
+	return strings.TrimSpace(string(content)), nil
+}
+
+// ErrRefChanged is returned when a reference no longer points where an update expected
+var ErrRefChanged = errors.New("reference was changed by another update")
+
+// refLockTimeout bounds how long an update waits for another update of the same reference
+var refLockTimeout = 2 * time.Second
+
+// UpdateRef points a reference at a commit, creating it if needed
+func (r *Repository) UpdateRef(name, commitID string) error {
+	return r.updateRef(name, commitID, func(string) bool { return true })
+}
+
+// CompareAndSwapRef points a reference at newID if it still points at oldID
+// ("" for a reference that doesn't exist yet), and fails with ErrRefChanged otherwise
+func (r *Repository) CompareAndSwapRef(name, oldID, newID string) error {
+	return r.updateRef(name, newID, func(current string) bool { return current == oldID })
+}
+
+// updateRef writes a reference under its lock file if ok accepts the commit it points to
+func (r *Repository) updateRef(name, commitID string, ok func(current string) bool) error {
+	if err := ValidateRefName(name); err != nil {
+		return err
+	}
+	refPath := r.refPath(name)
+
+	// Create directory if it doesn't exist
+	if err := os.MkdirAll(filepath.Dir(refPath), 0755); err != nil {
+		return fmt.Errorf("failed to create reference directory: %w", err)
+	}
+
+	lock, err := lockRef(name, refPath+".lock")
+	if err != nil {
+		return err
+	}
+	if err := r.writeLockedRef(lock, name, commitID, ok); err != nil {
+		lock.Close()
+		os.Remove(lock.Name())
+		return err
+	}
+	return nil
+}
+
+// lockRef creates the lock file of a reference, waiting for other updates of it to finish
+func lockRef(name, lockPath string) (*os.File, error) {
+	deadline := time.Now().Add(refLockTimeout)
+	for {
+		lock, err := os.OpenFile(lockPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			return lock, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("failed to lock reference %s: %w", name, err)
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("reference %s is locked (remove %s if no other snap is running)", name, lockPath)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// writeLockedRef writes a commit ID to the lock file of a reference and moves it into place
+func (r *Repository) writeLockedRef(lock *os.File, name, commitID string, ok func(current string) bool) error {
+	current, err := r.ResolveRef(name)
+	if err != nil {
+		return err
+	}
+	if !ok(current) {
+		return fmt.Errorf("%s: %w", name, ErrRefChanged)
+	}
+
+	if _, err := lock.WriteString(commitID); err != nil {
+		return fmt.Errorf("failed to write reference file: %w", err)
+	}
+	if err := lock.Close(); err != nil {
+		return fmt.Errorf("failed to write reference file: %w", err)
+	}
+	if err := os.Rename(lock.Name(), r.refPath(name)); err != nil {
+		return fmt.Errorf("failed to write reference file: %w", err)
+	}
+	return nil
+}
+
+// DeleteRef removes a reference
+func (r *Repository) DeleteRef(name string) error {
+	if err := ValidateRefName(name); err != nil {
+		return err
+	}
+	if err := os.Remove(r.refPath(name)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove reference %s: %w", name, err)
+	}
+	return nil
+}
+
+// ListRefs returns all references whose name starts with prefix, mapped to their commit IDs
+func (r *Repository) ListRefs(prefix string) (map[string]string, error) {
+	refs := make(map[string]string)
+	refsDir := filepath.Join(r.Path, SnapDirName, "refs")
+
+	err := filepath.Walk(refsDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(filepath.Join(r.Path, SnapDirName), path)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(relPath)
+		if !strings.HasPrefix(name, prefix) || ValidateRefName(name) != nil {
+			return nil
+		}
+
+		commitID, err := r.ResolveRef(name)
+		if err != nil {
+			return err
+		}
+		if commitID != "" {
+			refs[name] = commitID
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list references: %w", err)
+	}
+
+	return refs, nil
+}
+
+// SortedRefNames returns the names of a set of references in sorted order
+func SortedRefNames(refs map[string]string) []string {
+	names := make([]string, 0, len(refs))
+	for name := range refs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
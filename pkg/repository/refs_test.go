@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestCompareAndSwapRef(t *testing.T) {
+	repo, err := Init(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to initialize repository: %v", err)
+	}
+	ref := MetadataRefPrefix + "issues"
+	first, second := "1111111111111111111111111111111111111111", "2222222222222222222222222222222222222222"
+
+	// Creating a reference expects it not to exist yet
+	if err := repo.CompareAndSwapRef(ref, "", first); err != nil {
+		t.Fatalf("Failed to create reference: %v", err)
+	}
+	if err := repo.CompareAndSwapRef(ref, "", second); !errors.Is(err, ErrRefChanged) {
+		t.Errorf("Expected creating an existing reference to fail, got %v", err)
+	}
+	if err := repo.CompareAndSwapRef(ref, first, second); err != nil {
+		t.Fatalf("Failed to move reference: %v", err)
+	}
+	if err := repo.CompareAndSwapRef(ref, first, first); !errors.Is(err, ErrRefChanged) {
+		t.Errorf("Expected moving a reference from a stale commit to fail, got %v", err)
+	}
+	if current, _ := repo.ResolveRef(ref); current != second {
+		t.Errorf("Expected the reference to point at %s, got %s", second, current)
+	}
+
+	// A lock left behind blocks updates instead of being overwritten
+	defer func(timeout time.Duration) { refLockTimeout = timeout }(refLockTimeout)
+	refLockTimeout = 50 * time.Millisecond
+	if err := os.WriteFile(repo.refPath(ref)+".lock", nil, 0644); err != nil {
+		t.Fatalf("Failed to write lock file: %v", err)
+	}
+	if err := repo.UpdateRef(ref, first); err == nil {
+		t.Errorf("Expected a locked reference not to be updated")
+	}
+	refs, err := repo.ListRefs(MetadataRefPrefix)
+	if err != nil || len(refs) != 1 {
+		t.Errorf("Expected the lock file not to be listed as a reference, got %v", refs)
+	}
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Tag names a commit, typically a release such as v1.2.0
+type Tag struct {
+	Name     string
+	CommitID string
+}
+
+// tagName matches the names tags may have
+var tagName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._/-]*$`)
+
+// validateTagName checks that a tag name is one tags may have
+func validateTagName(name string) error {
+	if !tagName.MatchString(name) || strings.Contains(name, "..") || strings.HasSuffix(name, "/") {
+		return fmt.Errorf("invalid tag name %q: use letters, digits, dots, dashes, underscores and slashes", name)
+	}
+	return nil
+}
+
+// CreateTag tags a commit. Existing tags aren't moved.
+func (r *Repository) CreateTag(name, commitID string) error {
+	if err := validateTagName(name); err != nil {
+		return err
+	}
+	existing, err := r.ResolveRef(TagRefPrefix + name)
+	if err != nil {
+		return err
+	}
+	if existing != "" {
+		return fmt.Errorf("tag %s already exists", name)
+	}
+	if !r.HasObject(ObjectCommit, commitID) {
+		return fmt.Errorf("commit %s not found", commitID)
+	}
+	return r.UpdateRef(TagRefPrefix+name, commitID)
+}
+
+// DeleteTag removes a tag
+func (r *Repository) DeleteTag(name string) error {
+	if err := validateTagName(name); err != nil {
+		return err
+	}
+	existing, err := r.ResolveRef(TagRefPrefix + name)
+	if err != nil {
+		return err
+	}
+	if existing == "" {
+		return fmt.Errorf("tag %s not found", name)
+	}
+	return r.DeleteRef(TagRefPrefix + name)
+}
+
+// ListTags returns the tags, ordered by name
+func (r *Repository) ListTags() ([]Tag, error) {
+	refs, err := r.ListRefs(TagRefPrefix)
+	if err != nil {
+		return nil, err
+	}
+	tags := make([]Tag, 0, len(refs))
+	for _, name := range SortedRefNames(refs) {
+		tags = append(tags, Tag{Name: strings.TrimPrefix(name, TagRefPrefix), CommitID: refs[name]})
+	}
+	return tags, nil
+}
+
+// ResolveRevision returns the commit ID of a revision: HEAD, a tag, a branch, a
+// full reference name or a commit ID, which may be abbreviated to 4 characters
+func (r *Repository) ResolveRevision(revision string) (string, error) {
+	if revision == "" || revision == "HEAD" {
+		headID, err := r.GetHEADCommitID()
+		if err != nil {
+			return "", fmt.Errorf("failed to get HEAD commit ID: %w", err)
+		}
+		if headID == "" {
+			return "", fmt.Errorf("no commits yet")
+		}
+		return headID, nil
+	}
+
+	for _, ref := range []string{TagRefPrefix + revision, BranchRefPrefix + revision, revision} {
+		if !strings.HasPrefix(ref, "refs/") || strings.Contains(ref, "..") {
+			continue
+		}
+		commitID, err := r.ResolveRef(ref)
+		if err != nil {
+			return "", err
+		}
+		if commitID != "" {
+			return commitID, nil
+		}
+	}
+
+	// Commit ID, possibly abbreviated
+	if len(revision) >= 4 && filepath.Base(revision) == revision {
+		entries, err := os.ReadDir(filepath.Join(r.Path, SnapDirName, "objects", "commits"))
+		if err != nil && !os.IsNotExist(err) {
+			return "", fmt.Errorf("failed to read commits: %w", err)
+		}
+		var matches []string
+		for _, entry := range entries {
+			if strings.HasPrefix(entry.Name(), revision) {
+				matches = append(matches, entry.Name())
+			}
+		}
+		sort.Strings(matches)
+		switch len(matches) {
+		case 1:
+			return matches[0], nil
+		case 0:
+		default:
+			return "", fmt.Errorf("commit ID %s is ambiguous", revision)
+		}
+	}
+	return "", fmt.Errorf("unknown revision %q: not a tag, branch or commit", revision)
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"testing"
+)
+
+func TestTags(t *testing.T) {
+	repo, err := Init(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to initialize repository: %v", err)
+	}
+	if _, err := repo.ResolveRevision("HEAD"); err == nil {
+		t.Errorf("Expected HEAD not to resolve without commits")
+	}
+
+	first, err := repo.CreateCommit("✨ First", "alice", "", &Tree{Entries: map[string]string{"a.txt": "1"}})
+	if err != nil {
+		t.Fatalf("Failed to create commit: %v", err)
+	}
+	second, err := repo.CreateCommit("🐛 Second", "alice", "", &Tree{Entries: map[string]string{"a.txt": "2"}})
+	if err != nil {
+		t.Fatalf("Failed to create commit: %v", err)
+	}
+
+	// Tags name commits and can't be moved or misnamed
+	if err := repo.CreateTag("v1.0.0", first.ID); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+	if err := repo.CreateTag("v1.0.0", second.ID); err == nil {
+		t.Errorf("Expected an existing tag not to be moved")
+	}
+	for _, name := range []string{"", "-v1", "v1..2", "v1 beta"} {
+		if err := repo.CreateTag(name, second.ID); err == nil {
+			t.Errorf("Expected tag name %q to be invalid", name)
+		}
+	}
+	if err := repo.CreateTag("v2.0.0", "0000000"); err == nil {
+		t.Errorf("Expected a tag of an unknown commit to be rejected")
+	}
+	if err := repo.CreateTag("v1.1.0", second.ID); err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+	tags, err := repo.ListTags()
+	if err != nil || len(tags) != 2 || tags[0] != (Tag{Name: "v1.0.0", CommitID: first.ID}) {
+		t.Errorf("Expected v1.0.0 and v1.1.0, got %v (%v)", tags, err)
+	}
+
+	// Revisions resolve tags, branches, HEAD and abbreviated commit IDs
+	branch, err := repo.CurrentBranch()
+	if err != nil {
+		t.Fatalf("Failed to get branch: %v", err)
+	}
+	for revision, expected := range map[string]string{
+		"v1.0.0":           first.ID,
+		"refs/tags/v1.1.0": second.ID,
+		"HEAD":             second.ID,
+		branch:             second.ID,
+		first.ID[:7]:       first.ID,
+	} {
+		if got, err := repo.ResolveRevision(revision); err != nil || got != expected {
+			t.Errorf("ResolveRevision(%q) = %q (%v), want %q", revision, got, err, expected)
+		}
+	}
+	if _, err := repo.ResolveRevision("v3"); err == nil {
+		t.Errorf("Expected an unknown revision to be rejected")
+	}
+
+	if err := repo.DeleteTag("v1.1.0"); err != nil {
+		t.Fatalf("Failed to delete tag: %v", err)
+	}
+	if err := repo.DeleteTag("v1.1.0"); err == nil {
+		t.Errorf("Expected a missing tag not to be deleted")
+	}
+}
@@ -0,0 +1,319 @@
+package snapmoji
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// RegistryFileName is the file in .snap where a repository defines its own snapmojis
+const RegistryFileName = "snapmojis.json"
+
+// RegistryFile is the format of a repository's snapmoji file
+type RegistryFile struct {
+	Replace   bool       `json:"replace,omitempty"`   // Use only these snapmojis instead of adding them to the defaults
+	Remove    []string   `json:"remove,omitempty"`    // Defaults to leave out, by emoji or code
+	Snapmojis []Snapmoji `json:"snapmojis,omitempty"` // Replace the defaults with the same emoji or code
+}
+
+// Registry holds the snapmojis a repository's commit messages may start with
+type Registry struct {
+	Snapmojis []Snapmoji
+}
+
+// codeName matches the codes snapmojis may have, e.g. :sparkles:
+var codeName = regexp.MustCompile(`^:[a-z0-9_+-]+:$`)
+
+// categoryName matches the categories snapmojis may have
+var categoryName = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+
+// Default returns a registry of the default snapmojis
+func Default() *Registry {
+	return &Registry{Snapmojis: append([]Snapmoji(nil), Snapmojis...)}
+}
+
+// RegistryPath returns the path to a repository's snapmoji file
+func RegistryPath(repoPath string) string {
+	return filepath.Join(repoPath, ".snap", RegistryFileName)
+}
+
+// Load returns a repository's registry: the defaults merged with, or replaced
+// by, the snapmojis of its snapmoji file
+func Load(repoPath string) (*Registry, error) {
+	file, err := ReadRegistryFile(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	return file.Registry(), nil
+}
+
+// ReadRegistryFile reads a repository's snapmoji file, which is empty if there is none
+func ReadRegistryFile(repoPath string) (*RegistryFile, error) {
+	data, err := os.ReadFile(RegistryPath(repoPath))
+	if errors.Is(err, fs.ErrNotExist) {
+		return &RegistryFile{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", RegistryFileName, err)
+	}
+	file, err := ParseRegistryFile(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", RegistryFileName, err)
+	}
+	return file, nil
+}
+
+// ParseRegistryFile parses a snapmoji file. Unknown fields are rejected so typos don't go unnoticed.
+func ParseRegistryFile(data []byte) (*RegistryFile, error) {
+	var file RegistryFile
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&file); err != nil {
+		return nil, fmt.Errorf("invalid snapmojis: %w", err)
+	}
+	if err := file.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid snapmojis: %w", err)
+	}
+	return &file, nil
+}
+
+// Validate checks the snapmojis of a file, which may not share an emoji or code
+func (f *RegistryFile) Validate() error {
+	if f.Replace && len(f.Snapmojis) == 0 {
+		return errors.New("replacing the defaults needs at least one snapmoji")
+	}
+	seen := make(map[string]bool)
+	for _, s := range f.Snapmojis {
+		if err := s.Validate(); err != nil {
+			return err
+		}
+		for _, key := range []string{trimVariation(s.Emoji), s.Code} {
+			if seen[key] {
+				return fmt.Errorf("%s is defined twice", key)
+			}
+			seen[key] = true
+		}
+	}
+	for _, key := range f.Remove {
+		if strings.TrimSpace(key) == "" {
+			return errors.New("remove lists an empty snapmoji")
+		}
+	}
+	return nil
+}
+
+// Validate checks a snapmoji's fields
+func (s Snapmoji) Validate() error {
+	r, _ := utf8.DecodeRuneInString(s.Emoji)
+	switch {
+	case s.Emoji == "" || r < utf8.RuneSelf || strings.ContainsAny(s.Emoji, " \t\n:"):
+		return fmt.Errorf("invalid emoji %q: use a single emoji", s.Emoji)
+	case !codeName.MatchString(s.Code):
+		return fmt.Errorf("%s: invalid code %q: use lowercase letters, digits and underscores between colons", s.Emoji, s.Code)
+	case strings.TrimSpace(s.Description) == "":
+		return fmt.Errorf("%s: description is required", s.Emoji)
+	case s.Category != "" && !categoryName.MatchString(s.Category):
+		return fmt.Errorf("%s: invalid category %q: use lowercase letters, digits, dashes and underscores", s.Emoji, s.Category)
+	case s.Multiplier < 0:
+		return fmt.Errorf("%s: multiplier can't be negative", s.Emoji)
+	}
+	switch s.Semver {
+	case "", SemverMajor, SemverMinor, SemverPatch, SemverNone:
+	default:
+		return fmt.Errorf("%s: invalid semver %q: use major, minor, patch or none", s.Emoji, s.Semver)
+	}
+	return nil
+}
+
+// Registry merges the file's snapmojis into the defaults, or replaces them
+func (f *RegistryFile) Registry() *Registry {
+	registry := &Registry{}
+	if !f.Replace {
+		removed := &Registry{}
+		for _, key := range f.Remove {
+			if s, ok := Default().Find(key); ok {
+				removed.Snapmojis = append(removed.Snapmojis, s)
+			}
+		}
+		for _, s := range Snapmojis {
+			if _, ok := removed.Find(s.Emoji); !ok {
+				registry.Snapmojis = append(registry.Snapmojis, s)
+			}
+		}
+	}
+
+	for _, custom := range f.Snapmojis {
+		replaced := false
+		for i, s := range registry.Snapmojis {
+			if s.same(custom) {
+				if !replaced {
+					registry.Snapmojis[i] = custom
+					replaced = true
+				} else {
+					registry.Snapmojis[i].Emoji = ""
+				}
+			}
+		}
+		if !replaced {
+			registry.Snapmojis = append(registry.Snapmojis, custom)
+		}
+	}
+
+	// Drop the snapmojis a custom one replaced besides the first it shares its emoji or code with
+	kept := registry.Snapmojis[:0]
+	for _, s := range registry.Snapmojis {
+		if s.Emoji != "" {
+			kept = append(kept, s)
+		}
+	}
+	registry.Snapmojis = kept
+	return registry
+}
+
+// WriteRegistryFile writes a repository's snapmoji file
+func WriteRegistryFile(repoPath string, f *RegistryFile) error {
+	if err := f.Validate(); err != nil {
+		return fmt.Errorf("invalid snapmojis: %w", err)
+	}
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode snapmojis: %w", err)
+	}
+	if err := os.WriteFile(RegistryPath(repoPath), append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", RegistryFileName, err)
+	}
+	return nil
+}
+
+// trimVariation drops the variation selector some emojis end with, so ⚡️ and ⚡ are the same
+func trimVariation(emoji string) string {
+	return strings.TrimSuffix(emoji, "\ufe0f")
+}
+
+// same reports whether two snapmojis share an emoji or a code
+func (s Snapmoji) same(other Snapmoji) bool {
+	return trimVariation(s.Emoji) == trimVariation(other.Emoji) || s.Code == other.Code
+}
+
+// Find returns the snapmoji given as an emoji (with or without its variation
+// selector) or a :code:
+func (r *Registry) Find(key string) (Snapmoji, bool) {
+	for _, s := range r.Snapmojis {
+		if trimVariation(key) == trimVariation(s.Emoji) || key == s.Code {
+			return s, true
+		}
+	}
+	return Snapmoji{}, false
+}
+
+// match returns the snapmoji a commit message starts with, and its length in the message
+func (r *Registry) match(message string) (Snapmoji, int, bool) {
+	var found Snapmoji
+	length := 0
+	for _, s := range r.Snapmojis {
+		for _, prefix := range []string{s.Emoji, trimVariation(s.Emoji), s.Code} {
+			if len(prefix) > length && strings.HasPrefix(message, prefix) {
+				found, length = s, len(prefix)
+			}
+		}
+	}
+	return found, length, length > 0
+}
+
+// Match returns the snapmoji a commit message starts with, if any
+func (r *Registry) Match(message string) (Snapmoji, bool) {
+	s, _, ok := r.match(message)
+	return s, ok
+}
+
+// Strip returns a commit message without the snapmoji it starts with
+func (r *Registry) Strip(message string) string {
+	if _, length, ok := r.match(message); ok {
+		return strings.TrimLeft(message[length:], " ")
+	}
+	return message
+}
+
+// Validate checks if a commit message starts with a snapmoji of the registry
+func (r *Registry) Validate(message string) error {
+	if message == "" {
+		return errors.New("commit message cannot be empty")
+	}
+	if _, ok := r.Match(message); ok {
+		return nil
+	}
+	if len(r.Snapmojis) == 0 {
+		return errors.New("commit message must start with a snapmoji")
+	}
+	example := r.Snapmojis[0]
+	return fmt.Errorf("commit message must start with a snapmoji (e.g., %s or %s)", example.Emoji, example.Code)
+}
+
+// AutoConvert converts a keyword like "feature:" at the beginning of a commit
+// message to the registry's snapmoji for it. Besides the usual keywords, the
+// category of any of the registry's snapmojis works, e.g. "i18n:".
+func (r *Registry) AutoConvert(message string) string {
+	colon := strings.Index(message, ":")
+	if colon <= 0 {
+		return message
+	}
+	keyword := strings.ToLower(message[:colon])
+	rest := strings.TrimSpace(message[colon+1:])
+
+	if code, ok := keywords[keyword+":"]; ok {
+		if s, ok := r.Find(code); ok {
+			return s.Code + " " + rest
+		}
+	}
+	for _, s := range r.Snapmojis {
+		if s.Category == keyword {
+			return s.Code + " " + rest
+		}
+	}
+	return message
+}
+
+// List returns a formatted list of the registry's snapmojis
+func (r *Registry) List() string {
+	var builder strings.Builder
+	builder.WriteString("Available Snapmojis:\n\n")
+
+	for _, snapmoji := range r.Snapmojis {
+		builder.WriteString(snapmoji.Emoji)
+		builder.WriteString(" ")
+		builder.WriteString(snapmoji.Code)
+		builder.WriteString(" - ")
+		builder.WriteString(snapmoji.Description)
+		builder.WriteString("\n")
+	}
+
+	return builder.String()
+}
+
+// NumberedList returns a numbered list of the registry's snapmojis for selection
+func (r *Registry) NumberedList() string {
+	var builder strings.Builder
+	builder.WriteString("Select a Snapmoji by number:\n\n")
+
+	for i, snapmoji := range r.Snapmojis {
+		builder.WriteString(fmt.Sprintf("%2d. %s %s - %s\n", i+1, snapmoji.Emoji, snapmoji.Code, snapmoji.Description))
+	}
+
+	return builder.String()
+}
+
+// ByNumber returns a snapmoji by its number in the list (1-based)
+func (r *Registry) ByNumber(number int) (Snapmoji, error) {
+	if number < 1 || number > len(r.Snapmojis) {
+		return Snapmoji{}, fmt.Errorf("invalid snapmoji number: %d (valid range: 1-%d)", number, len(r.Snapmojis))
+	}
+
+	return r.Snapmojis[number-1], nil
+}
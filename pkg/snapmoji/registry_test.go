@@ -0,0 +1,129 @@
+package snapmoji
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadRegistry(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(tempDir, ".snap"), 0755); err != nil {
+		t.Fatalf("Failed to create .snap: %v", err)
+	}
+
+	// Without a file, the defaults apply
+	registry, err := Load(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to load registry: %v", err)
+	}
+	if len(registry.Snapmojis) != len(Snapmojis) {
+		t.Errorf("Expected the %d defaults, got %d snapmojis", len(Snapmojis), len(registry.Snapmojis))
+	}
+
+	// Custom snapmojis are added, replace defaults with the same code, and removed defaults are left out
+	file := &RegistryFile{
+		Remove: []string{"🚧"},
+		Snapmojis: []Snapmoji{
+			{Emoji: "🌐", Code: ":globe_with_meridians:", Description: "Translations", Category: "i18n", Semver: SemverPatch, Multiplier: 1.5},
+			{Emoji: "🐞", Code: ":bug:", Description: "Squash a bug", Category: "fix", Semver: SemverPatch},
+		},
+	}
+	if err := WriteRegistryFile(tempDir, file); err != nil {
+		t.Fatalf("Failed to write registry: %v", err)
+	}
+	registry, err = Load(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to load registry: %v", err)
+	}
+	if len(registry.Snapmojis) != len(Snapmojis) {
+		t.Errorf("Expected %d snapmojis, got %d", len(Snapmojis), len(registry.Snapmojis))
+	}
+	if s, ok := registry.Find(":globe_with_meridians:"); !ok || s.Multiplier != 1.5 {
+		t.Errorf("Expected the custom snapmoji to be found, got %+v", s)
+	}
+	if s, ok := registry.Match(":bug: Fix crash"); !ok || s.Emoji != "🐞" {
+		t.Errorf("Expected :bug: to be replaced, got %+v", s)
+	}
+	if err := registry.Validate("🚧 Half done"); err == nil {
+		t.Errorf("Expected a removed snapmoji to be rejected")
+	}
+	if err := registry.Validate("🌐 Translate to Dutch"); err != nil {
+		t.Errorf("Expected a custom snapmoji to be accepted, got %v", err)
+	}
+
+	// Replacing the defaults keeps only the file's snapmojis
+	file.Replace = true
+	if err := WriteRegistryFile(tempDir, file); err != nil {
+		t.Fatalf("Failed to write registry: %v", err)
+	}
+	registry, err = Load(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to load registry: %v", err)
+	}
+	if len(registry.Snapmojis) != 2 || registry.Validate("✨ Add feature") == nil {
+		t.Errorf("Expected only the 2 custom snapmojis, got %+v", registry.Snapmojis)
+	}
+}
+
+func TestParseRegistryFileInvalid(t *testing.T) {
+	testCases := map[string]string{
+		"unknown field":  `{"snapmojis": [{"emoji": "🌐", "code": ":globe:", "description": "Translations", "color": "blue"}]}`,
+		"ascii emoji":    `{"snapmojis": [{"emoji": "x", "code": ":x:", "description": "Translations"}]}`,
+		"bad code":       `{"snapmojis": [{"emoji": "🌐", "code": "globe", "description": "Translations"}]}`,
+		"no description": `{"snapmojis": [{"emoji": "🌐", "code": ":globe:"}]}`,
+		"bad semver":     `{"snapmojis": [{"emoji": "🌐", "code": ":globe:", "description": "Translations", "semver": "huge"}]}`,
+		"negative":       `{"snapmojis": [{"emoji": "🌐", "code": ":globe:", "description": "Translations", "multiplier": -1}]}`,
+		"duplicate":      `{"snapmojis": [{"emoji": "🌐", "code": ":globe:", "description": "A"}, {"emoji": "🌍", "code": ":globe:", "description": "B"}]}`,
+		"empty replace":  `{"replace": true}`,
+	}
+	for name, data := range testCases {
+		if _, err := ParseRegistryFile([]byte(data)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestStrip(t *testing.T) {
+	registry := Default()
+	testCases := map[string]string{
+		"✨ Add feature":         "Add feature",
+		":sparkles:Add feature": "Add feature",
+		"⚡ Faster":              "Faster",
+		"⚡️ Faster":             "Faster",
+		"No snapmoji":           "No snapmoji",
+		"Text ✨ in the middle":  "Text ✨ in the middle",
+	}
+	for message, expected := range testCases {
+		if got := registry.Strip(message); got != expected {
+			t.Errorf("Strip(%q) = %q, want %q", message, got, expected)
+		}
+	}
+}
+
+func TestAutoConvert(t *testing.T) {
+	file := &RegistryFile{
+		Remove: []string{"🚧"},
+		Snapmojis: []Snapmoji{
+			{Emoji: "🌐", Code: ":globe_with_meridians:", Description: "Translations", Category: "i18n", Semver: SemverPatch},
+		},
+	}
+	registry := file.Registry()
+	testCases := map[string]string{
+		"feature: Add feature": ":sparkles: Add feature",
+		"I18N: Translate":      ":globe_with_meridians: Translate",
+		"wip: Half done":       "wip: Half done",
+		"No keyword":           "No keyword",
+	}
+	for message, expected := range testCases {
+		if got := registry.AutoConvert(message); got != expected {
+			t.Errorf("AutoConvert(%q) = %q, want %q", message, got, expected)
+		}
+	}
+
+	// Keywords only convert to snapmojis the registry has
+	file.Replace = true
+	if got := file.Registry().AutoConvert("feature: Add feature"); got != "feature: Add feature" {
+		t.Errorf("Expected feature: not to be converted without :sparkles:, got %q", got)
+	}
+}
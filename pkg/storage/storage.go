@@ -1,12 +1,13 @@
 package storage
 
 import (
+	"bufio"
 	"crypto/sha1"
 	"encoding/hex"
 	"fmt"
-	"io"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 // Index represents the staging area
@@ -111,26 +112,24 @@ func LoadIndex(repoPath string) (*Index, error) {
 	defer file.Close()
 
 	idx := NewIndex()
-	scanner := io.ReadSeeker(file)
-	
-	// Read each line from the index file
-	buf := make([]byte, 1024)
-	for {
-		n, err := scanner.Read(buf)
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return nil, fmt.Errorf("failed to read index file: %w", err)
+	scanner := bufio.NewScanner(file)
+
+	// Read each line from the index file ("<object-id> <path>")
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
 		}
 
-		// Process the buffer
-		// This is a simplified implementation
-		// In a real implementation, we would parse the buffer properly
-		// For now, we'll just return an empty index
-		if n > 0 {
-			// Placeholder for parsing logic
+		objectID, path, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
 		}
+		idx.Entries[path] = objectID
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read index file: %w", err)
 	}
 
 	return idx, nil
@@ -131,6 +131,13 @@ func TestSaveAndLoadIndex(t *testing.T) {
 	}
 
 	// Check if loaded index has the correct entries
-	// Note: Our current implementation of LoadIndex doesn't actually parse the index file,
-	// so we can't check the entries. This is a placeholder for when we implement it.
+	if len(loadedIndex.Entries) != 2 {
+		t.Errorf("Expected loaded index to have 2 entries, got %d", len(loadedIndex.Entries))
+	}
+	if loadedIndex.Entries["file1.txt"] != "object1" {
+		t.Errorf("Expected file1.txt to have object ID 'object1', got '%s'", loadedIndex.Entries["file1.txt"])
+	}
+	if loadedIndex.Entries["file2.txt"] != "object2" {
+		t.Errorf("Expected file2.txt to have object ID 'object2', got '%s'", loadedIndex.Entries["file2.txt"])
+	}
 }
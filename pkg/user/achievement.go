@@ -0,0 +1,274 @@
+package user
+
+import (
+	"sort"
+	"time"
+)
+
+// Achievement is a milestone users earn once, shown as a badge
+type Achievement struct {
+	ID          string
+	Emoji       string
+	Name        string
+	Description string
+
+	// earned returns when a user's history first met the achievement, if it has
+	earned func(h *achievementHistory) (string, bool)
+}
+
+// EarnedAchievement records when a user earned an achievement
+type EarnedAchievement struct {
+	ID       string `json:"id"`
+	EarnedAt string `json:"earned_at"` // RFC 3339, the time of the activity that earned it
+}
+
+// achievementHistory is what achievements are evaluated from: the user's
+// commits in history and the other actions in their log, oldest first
+type achievementHistory struct {
+	commits []Activity
+	actions []ActionRecord
+}
+
+// Achievements lists every achievement in the order badges are shown
+var Achievements = []*Achievement{
+	{
+		ID: "first_commit", Emoji: "🐣", Name: "First commit",
+		Description: "Made a first commit",
+		earned: func(h *achievementHistory) (string, bool) {
+			return h.nthCommit(1, func(Activity) bool { return true })
+		},
+	},
+	{
+		ID: "streak_7", Emoji: "🔥", Name: "On fire",
+		Description: "Contributed 7 days in a row",
+		earned: func(h *achievementHistory) (string, bool) {
+			return h.streak(7)
+		},
+	},
+	{
+		ID: "bug_squasher", Emoji: "🐛", Name: "Bug squasher",
+		Description: "Squashed 10 bugs with 🐛 commits",
+		earned: func(h *achievementHistory) (string, bool) {
+			return h.nthCommit(10, withSnapmoji(":bug:"))
+		},
+	},
+	{
+		ID: "security_fix", Emoji: "🔒", Name: "Locksmith",
+		Description: "Made a first security fix with a 🔒 commit",
+		earned: func(h *achievementHistory) (string, bool) {
+			return h.nthCommit(1, withSnapmoji(":lock:"))
+		},
+	},
+	{
+		ID: "issue_closer", Emoji: "✅", Name: "Issue closer",
+		Description: "Closed 10 issues",
+		earned: func(h *achievementHistory) (string, bool) {
+			return h.nthAction(10, ActionIssueClose)
+		},
+	},
+	{
+		ID: "night_owl", Emoji: "🦉", Name: "Night owl",
+		Description: "Committed between midnight and 5 AM",
+		earned: func(h *achievementHistory) (string, bool) {
+			return h.nthCommit(1, func(commit Activity) bool {
+				at, err := time.Parse(time.RFC3339, commit.Timestamp)
+				return err == nil && at.Hour() < 5
+			})
+		},
+	},
+}
+
+// GetAchievement returns the achievement with an ID, or nil if there is none
+func GetAchievement(id string) *Achievement {
+	for _, achievement := range Achievements {
+		if achievement.ID == id {
+			return achievement
+		}
+	}
+	return nil
+}
+
+// withSnapmoji matches commits whose message starts with a snapmoji, given by code
+func withSnapmoji(code string) func(Activity) bool {
+	emoji, _ := snapmojiKey(nil, code)
+	return func(commit Activity) bool {
+		return messageSnapmoji(nil, commit.Description) == emoji
+	}
+}
+
+// nthCommit returns the timestamp of the nth commit that matches
+func (h *achievementHistory) nthCommit(n int, matches func(Activity) bool) (string, bool) {
+	count := 0
+	for _, commit := range h.commits {
+		if matches(commit) {
+			count++
+			if count == n {
+				return commit.Timestamp, true
+			}
+		}
+	}
+	return "", false
+}
+
+// nthAction returns the timestamp of the nth logged action of a kind
+func (h *achievementHistory) nthAction(n int, action Action) (string, bool) {
+	count := 0
+	for _, record := range h.actions {
+		if record.Action == action {
+			count++
+			if count == n {
+				return record.Timestamp, true
+			}
+		}
+	}
+	return "", false
+}
+
+// streak returns the timestamp of the first activity on the day a user had
+// contributed the given number of days in a row. Days are taken in the time
+// zone each activity was recorded in.
+func (h *achievementHistory) streak(days int) (string, bool) {
+	var times []time.Time
+	for _, commit := range h.commits {
+		if at, err := time.Parse(time.RFC3339, commit.Timestamp); err == nil {
+			times = append(times, at)
+		}
+	}
+	for _, record := range h.actions {
+		if at, err := time.Parse(time.RFC3339, record.Timestamp); err == nil {
+			times = append(times, at)
+		}
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+
+	var last time.Time
+	run := 0
+	for _, at := range times {
+		day := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, time.UTC)
+		switch {
+		case run > 0 && day.Equal(last):
+			continue
+		case run > 0 && day.Equal(last.AddDate(0, 0, 1)):
+			run++
+		default:
+			run = 1
+		}
+		last = day
+		if run == days {
+			return at.Format(time.RFC3339), true
+		}
+	}
+	return "", false
+}
+
+// HasAchievement reports whether a user earned an achievement
+func (u *User) HasAchievement(id string) bool {
+	for _, earned := range u.Achievements {
+		if earned.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Badge is an achievement a user earned
+type Badge struct {
+	*Achievement
+	EarnedAt string
+}
+
+// Badges returns the achievements a user earned, in the order of Achievements
+func (u *User) Badges() []Badge {
+	var badges []Badge
+	for _, achievement := range Achievements {
+		for _, earned := range u.Achievements {
+			if earned.ID == achievement.ID {
+				badges = append(badges, Badge{Achievement: achievement, EarnedAt: earned.EarnedAt})
+			}
+		}
+	}
+	return badges
+}
+
+// evaluateAchievements adds the achievements a user's commits and action log
+// have earned since they were last evaluated, and reports whether any were added
+func (u *User) evaluateAchievements(commits []Activity) bool {
+	history := &achievementHistory{commits: commits}
+	for _, record := range u.ActionLog {
+		if record.Action != ActionCommit {
+			history.actions = append(history.actions, record)
+		}
+	}
+
+	added := false
+	for _, achievement := range Achievements {
+		if u.HasAchievement(achievement.ID) {
+			continue
+		}
+		if at, ok := achievement.earned(history); ok {
+			u.Achievements = append(u.Achievements, EarnedAchievement{ID: achievement.ID, EarnedAt: at})
+			added = true
+		}
+	}
+	return added
+}
+
+// userCommits returns a user's commits in history that count as contributions, oldest first
+func (um *UserManager) userCommits(name string) ([]Activity, error) {
+	contributions, err := um.commitContributions()
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []Activity
+	for _, c := range contributions {
+		if c.Name == name && !c.Activity.voided() {
+			commits = append(commits, c.Activity)
+		}
+	}
+	sort.SliceStable(commits, func(i, j int) bool {
+		return activityTime(commits[i]).Before(activityTime(commits[j]))
+	})
+	return commits, nil
+}
+
+// UpdateAchievements evaluates the achievements of a user, found by name and
+// email, saves the new ones and returns the ones to announce: those earned at
+// or after a time, such as a commit's. When the user files are a cache, they are rebuilt with their
+// achievements, so the time alone decides.
+func (um *UserManager) UpdateAchievements(name, email string, since time.Time) ([]*Achievement, error) {
+	cached, err := um.CacheEnabled()
+	if err != nil {
+		return nil, err
+	}
+	user, err := um.getUser(name, email)
+	if err != nil {
+		return nil, err
+	}
+	commits, err := um.userCommits(user.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	known := len(user.Achievements)
+	if cached {
+		known = 0
+	}
+	if user.evaluateAchievements(commits) && !cached {
+		if err := um.SaveUser(user); err != nil {
+			return nil, err
+		}
+	}
+
+	var announced []*Achievement
+	for _, earned := range user.Achievements[known:] {
+		at, err := time.Parse(time.RFC3339, earned.EarnedAt)
+		if err != nil || at.Before(since.Truncate(time.Second)) {
+			continue
+		}
+		if achievement := GetAchievement(earned.ID); achievement != nil {
+			announced = append(announced, achievement)
+		}
+	}
+	return announced, nil
+}
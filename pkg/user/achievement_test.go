@@ -0,0 +1,112 @@
+package user
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stanlocht/snap/pkg/repository"
+)
+
+func TestEvaluateAchievements(t *testing.T) {
+	start := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	var commits []Activity
+	commit := func(message string, at time.Time) {
+		commits = append(commits, Activity{Action: ActionCommit, Description: message, Timestamp: at.Format(time.RFC3339)})
+	}
+
+	// Six days in a row, with a gap before the next, and ten bug fixes on the first day
+	for day := 0; day < 6; day++ {
+		commit("✨ Add feature", start.AddDate(0, 0, day))
+	}
+	for i := 0; i < 10; i++ {
+		commit(":bug: Fix crash", start.Add(time.Duration(i)*time.Minute))
+	}
+	commit("📝 Document", start.AddDate(0, 0, 7))
+
+	u := &User{Name: "alice"}
+	for i := 0; i < 9; i++ {
+		u.ActionLog = append(u.ActionLog, ActionRecord{Action: ActionIssueClose, Timestamp: start.Format(time.RFC3339)})
+	}
+	if !u.evaluateAchievements(commits) {
+		t.Fatalf("Expected achievements to be added")
+	}
+	earned := func(id string) string {
+		for _, e := range u.Achievements {
+			if e.ID == id {
+				return e.EarnedAt
+			}
+		}
+		return ""
+	}
+	if earned("first_commit") != start.Format(time.RFC3339) {
+		t.Errorf("Expected the first commit achievement at the first commit, got %q", earned("first_commit"))
+	}
+	if earned("bug_squasher") != start.Add(9*time.Minute).Format(time.RFC3339) {
+		t.Errorf("Expected the bug squasher achievement at the tenth fix, got %q", earned("bug_squasher"))
+	}
+	for _, id := range []string{"streak_7", "security_fix", "issue_closer", "night_owl"} {
+		if u.HasAchievement(id) {
+			t.Errorf("Expected no %s achievement", id)
+		}
+	}
+
+	// A late security fix on the seventh day completes the streak, and a tenth close
+	night := time.Date(2025, 3, 7, 2, 30, 0, 0, time.FixedZone("CET", 3600))
+	commit("🔒 Escape input", night)
+	u.ActionLog = append(u.ActionLog, ActionRecord{Action: ActionIssueClose, Timestamp: night.Format(time.RFC3339)})
+	if !u.evaluateAchievements(commits) {
+		t.Fatalf("Expected achievements to be added")
+	}
+	for _, id := range []string{"streak_7", "security_fix", "issue_closer", "night_owl"} {
+		if earned(id) != night.Format(time.RFC3339) {
+			t.Errorf("Expected %s at %s, got %q", id, night.Format(time.RFC3339), earned(id))
+		}
+	}
+	if u.evaluateAchievements(commits) {
+		t.Errorf("Expected achievements to be earned once")
+	}
+
+	badges := u.Badges()
+	if len(badges) != len(Achievements) || badges[0].ID != "first_commit" {
+		t.Errorf("Expected every badge in order, got %v", badges)
+	}
+}
+
+func TestUpdateAchievements(t *testing.T) {
+	tempDir := t.TempDir()
+	repo, err := repository.Init(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	manager := NewUserManager(tempDir)
+
+	var announced [][]*Achievement
+	for i, message := range []string{"✨ Add a", "🔒 Check input"} {
+		c, err := repo.CreateCommit(message, "alice", "", &repository.Tree{Entries: map[string]string{"a.txt": fmt.Sprint(i)}})
+		if err != nil {
+			t.Fatalf("Failed to create commit: %v", err)
+		}
+		achievements, err := manager.UpdateAchievements("alice", "", c.Timestamp)
+		if err != nil {
+			t.Fatalf("Failed to update achievements: %v", err)
+		}
+		announced = append(announced, achievements)
+	}
+
+	if len(announced[0]) != 1 || announced[0][0].ID != "first_commit" {
+		t.Errorf("Expected the first commit to be announced, got %v", announced[0])
+	}
+	if len(announced[1]) != 1 || announced[1][0].ID != "security_fix" {
+		t.Errorf("Expected only the security fix to be announced, got %v", announced[1])
+	}
+
+	// Achievements are kept with the user
+	alice, err := manager.GetUser("alice")
+	if err != nil {
+		t.Fatalf("Failed to get user: %v", err)
+	}
+	if !alice.HasAchievement("first_commit") || !alice.HasAchievement("security_fix") {
+		t.Errorf("Expected achievements to be saved, got %v", alice.Achievements)
+	}
+}
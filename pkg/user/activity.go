@@ -0,0 +1,133 @@
+package user
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/stanlocht/snap/pkg/config"
+)
+
+// TimezoneConfigKey is the config key naming the time zone days are counted
+// in for streaks and heatmaps, e.g. Europe/Amsterdam. The local zone is used by default.
+const TimezoneConfigKey = "user.timezone"
+
+// dateLayout is how days are written
+const dateLayout = "2006-01-02"
+
+// DayActivity is what a user did on one day
+type DayActivity struct {
+	Date    string // YYYY-MM-DD
+	Actions int
+	Points  int
+}
+
+// HeatmapCell is a day in a heatmap
+type HeatmapCell struct {
+	Date    string
+	Actions int
+	Level   int  // 0 for no activity to 4 for the busiest days
+	Future  bool // After the heatmap's last day, to pad its last week
+}
+
+// Location returns the time zone the repository counts days in
+func (um *UserManager) Location() (*time.Location, error) {
+	name, err := config.GetValue(um.configPath(), TimezoneConfigKey)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	if name == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s in config: %w", TimezoneConfigKey, err)
+	}
+	return loc, nil
+}
+
+// DailyActivity aggregates a user's action log by day in a time zone
+func (u *User) DailyActivity(loc *time.Location) map[string]*DayActivity {
+	days := make(map[string]*DayActivity)
+	for _, record := range u.ActionLog {
+		at, err := time.Parse(time.RFC3339, record.Timestamp)
+		if err != nil {
+			continue
+		}
+		date := at.In(loc).Format(dateLayout)
+		day := days[date]
+		if day == nil {
+			day = &DayActivity{Date: date}
+			days[date] = day
+		}
+		day.Actions++
+		day.Points += record.Points
+	}
+	return days
+}
+
+// Streaks returns a user's current and longest runs of days with activity in
+// a time zone. The current streak is still alive if the user was active yesterday
+// but not yet today.
+func (u *User) Streaks(loc *time.Location, now time.Time) (current, longest int) {
+	days := u.DailyActivity(loc)
+	dates := make([]string, 0, len(days))
+	for date := range days {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+
+	run := 0
+	var last time.Time
+	for _, date := range dates {
+		day, _ := time.ParseInLocation(dateLayout, date, loc)
+		if run > 0 && day.Equal(last.AddDate(0, 0, 1)) {
+			run++
+		} else {
+			run = 1
+		}
+		last = day
+		if run > longest {
+			longest = run
+		}
+	}
+
+	today := now.In(loc)
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, loc)
+	if run > 0 && (last.Equal(today) || last.Equal(today.AddDate(0, 0, -1))) {
+		current = run
+	}
+	return current, longest
+}
+
+// Heatmap lays out a user's daily activity as weeks of days, Sunday first,
+// ending with the week of the given day
+func Heatmap(days map[string]*DayActivity, end time.Time, weeks int) [][]HeatmapCell {
+	end = time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, end.Location())
+	start := end.AddDate(0, 0, -int(end.Weekday())-7*(weeks-1))
+
+	busiest := 0
+	for _, day := range days {
+		if day.Actions > busiest {
+			busiest = day.Actions
+		}
+	}
+
+	grid := make([][]HeatmapCell, weeks)
+	for week := range grid {
+		grid[week] = make([]HeatmapCell, 7)
+		for weekday := range grid[week] {
+			date := start.AddDate(0, 0, 7*week+weekday)
+			cell := HeatmapCell{Date: date.Format(dateLayout), Future: date.After(end)}
+			if day := days[cell.Date]; day != nil && !cell.Future {
+				cell.Actions = day.Actions
+				cell.Level = int(math.Ceil(4 * float64(day.Actions) / float64(busiest)))
+			}
+			grid[week][weekday] = cell
+		}
+	}
+	return grid
+}
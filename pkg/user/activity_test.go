@@ -0,0 +1,86 @@
+package user
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStreaks(t *testing.T) {
+	u := &User{Name: "alice"}
+	for _, timestamp := range []string{
+		"2025-03-01T10:00:00Z", "2025-03-02T10:00:00Z", "2025-03-03T10:00:00Z", // 3 days
+		"2025-03-05T23:30:00Z", // Mar 5 in UTC, Mar 6 in Amsterdam
+		"2025-03-06T10:00:00Z", "2025-03-06T11:00:00Z",
+		"2025-03-07T09:00:00+01:00",
+	} {
+		u.ActionLog = append(u.ActionLog, ActionRecord{Action: ActionCommit, Points: 10, Timestamp: timestamp})
+	}
+
+	// Actions and points are counted per day
+	days := u.DailyActivity(time.UTC)
+	if len(days) != 6 || days["2025-03-06"].Actions != 2 || days["2025-03-06"].Points != 20 {
+		t.Errorf("Expected 6 days with 2 actions on Mar 6, got %d days and %+v", len(days), days["2025-03-06"])
+	}
+
+	// In UTC, Mar 5 to 7 make a streak as long as Mar 1 to 3
+	now := time.Date(2025, 3, 8, 12, 0, 0, 0, time.UTC)
+	if current, longest := u.Streaks(time.UTC, now); current != 3 || longest != 3 {
+		t.Errorf("Expected a current and longest streak of 3 in UTC, got %d and %d", current, longest)
+	}
+
+	// In Amsterdam, the late action moves to Mar 6, breaking the streak
+	amsterdam, err := time.LoadLocation("Europe/Amsterdam")
+	if err != nil {
+		t.Skipf("No time zone data: %v", err)
+	}
+	if current, longest := u.Streaks(amsterdam, now); current != 2 || longest != 3 {
+		t.Errorf("Expected a current streak of 2 and longest of 3 in Amsterdam, got %d and %d", current, longest)
+	}
+
+	// A day without activity ends the current streak
+	if current, _ := u.Streaks(time.UTC, now.AddDate(0, 0, 1)); current != 0 {
+		t.Errorf("Expected the streak to end, got %d", current)
+	}
+}
+
+func TestHeatmap(t *testing.T) {
+	days := map[string]*DayActivity{
+		"2025-03-03": {Date: "2025-03-03", Actions: 1},
+		"2025-03-05": {Date: "2025-03-05", Actions: 8},
+		"2025-03-07": {Date: "2025-03-07", Actions: 3},
+	}
+
+	// Wednesday Mar 5 ends the heatmap; its week starts on Sunday Mar 2
+	grid := Heatmap(days, time.Date(2025, 3, 5, 15, 0, 0, 0, time.UTC), 2)
+	if len(grid) != 2 || grid[0][0].Date != "2025-02-23" || grid[1][0].Date != "2025-03-02" {
+		t.Fatalf("Expected weeks starting Feb 23 and Mar 2, got %v", grid)
+	}
+	week := grid[1]
+	if week[1].Level != 1 || week[3].Level != 4 || week[2].Level != 0 {
+		t.Errorf("Expected levels 1, 0 and 4 on Mar 3 to 5, got %d, %d and %d", week[1].Level, week[2].Level, week[3].Level)
+	}
+	if !week[4].Future || week[5].Actions != 0 {
+		t.Errorf("Expected the days after Mar 5 to be empty, got %+v", week[4:])
+	}
+}
+
+func TestLocation(t *testing.T) {
+	tempDir := t.TempDir()
+	manager := NewUserManager(tempDir)
+
+	if loc, err := manager.Location(); err != nil || loc != time.Local {
+		t.Errorf("Expected the local time zone without a config, got %v (%v)", loc, err)
+	}
+
+	if err := os.MkdirAll(filepath.Join(tempDir, ".snap"), 0755); err != nil {
+		t.Fatalf("Failed to create .snap directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, ".snap", "config"), []byte("[user]\n\ttimezone = Mars/Olympus\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if _, err := manager.Location(); err == nil {
+		t.Errorf("Expected an unknown time zone to be rejected")
+	}
+}
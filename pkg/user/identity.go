@@ -0,0 +1,261 @@
+package user
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// MailmapFileName is the file in the repository root that maps the names and
+// emails people committed under to their canonical identity, like git's .mailmap
+const MailmapFileName = ".snapmailmap"
+
+// Identity is a person's canonical name and email
+type Identity struct {
+	Name  string
+	Email string
+}
+
+// mailmapEntry maps the identities matching a name and/or email to a canonical one
+type mailmapEntry struct {
+	name        string // Canonical name, empty to keep the name
+	email       string // Canonical email, empty to keep the email
+	matchName   string // Name to match, empty for any
+	matchEmail  string // Email to match, empty for any
+	specificity int
+}
+
+// Mailmap resolves aliases to canonical identities
+type Mailmap struct {
+	entries []mailmapEntry
+}
+
+// ParseMailmap parses a mailmap. Besides git's forms
+//
+//	Proper Name <commit@email>
+//	<proper@email> <commit@email>
+//	Proper Name <proper@email> <commit@email>
+//	Proper Name <proper@email> Commit Name <commit@email>
+//
+// a line may map a name used without an email, as --author names often are:
+//
+//	Proper Name <proper@email> Commit Name
+//
+// Names and emails match case-insensitively. Text after # is a comment.
+func ParseMailmap(data []byte) (*Mailmap, error) {
+	m := &Mailmap{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		var names, emails []string
+		rest := line
+		for rest != "" {
+			open := strings.Index(rest, "<")
+			if open < 0 {
+				names = append(names, strings.TrimSpace(rest))
+				emails = append(emails, "")
+				break
+			}
+			end := strings.Index(rest[open:], ">")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unclosed <", lineNumber)
+			}
+			names = append(names, strings.TrimSpace(rest[:open]))
+			emails = append(emails, strings.TrimSpace(rest[open+1:open+end]))
+			rest = strings.TrimSpace(rest[open+end+1:])
+		}
+
+		var entry mailmapEntry
+		switch {
+		case len(names) == 1 && emails[0] != "":
+			// Proper Name <commit@email>
+			entry.name, entry.matchEmail = names[0], emails[0]
+		case len(names) == 2 && names[0] == "" && names[1] == "":
+			// <proper@email> <commit@email>
+			entry.email, entry.matchEmail = emails[0], emails[1]
+		case len(names) == 2:
+			// Proper Name <proper@email> [Commit Name] [<commit@email>]
+			entry.name, entry.email = names[0], emails[0]
+			entry.matchName, entry.matchEmail = names[1], emails[1]
+		default:
+			return nil, fmt.Errorf("line %d: expected Name <email>, optionally followed by the name and/or <email> to map", lineNumber)
+		}
+		if entry.matchName == "" && entry.matchEmail == "" {
+			return nil, fmt.Errorf("line %d: nothing to map", lineNumber)
+		}
+		if entry.matchEmail != "" {
+			entry.specificity++
+		}
+		if entry.matchName != "" {
+			entry.specificity += 2
+		}
+		m.entries = append(m.entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read mailmap: %w", err)
+	}
+
+	// Entries matching both name and email win over those matching one of them
+	sort.SliceStable(m.entries, func(i, j int) bool { return m.entries[i].specificity > m.entries[j].specificity })
+	return m, nil
+}
+
+// Resolve returns the canonical identity for a name and email. Identities the
+// mailmap doesn't mention are their own.
+func (m *Mailmap) Resolve(name, email string) Identity {
+	identity := Identity{Name: name, Email: email}
+	if m == nil {
+		return identity
+	}
+	for _, entry := range m.entries {
+		if entry.matchEmail != "" && !strings.EqualFold(entry.matchEmail, email) {
+			continue
+		}
+		if entry.matchName != "" && !strings.EqualFold(entry.matchName, name) {
+			continue
+		}
+		if entry.name != "" {
+			identity.Name = entry.name
+		}
+		if entry.email != "" {
+			identity.Email = entry.email
+		}
+		return identity
+	}
+	return identity
+}
+
+// MailmapPath returns the path to the repository's mailmap
+func (um *UserManager) MailmapPath() string {
+	return filepath.Join(um.RepoPath, MailmapFileName)
+}
+
+// Mailmap reads the repository's mailmap. Without one, every identity is its own.
+func (um *UserManager) Mailmap() (*Mailmap, error) {
+	data, err := os.ReadFile(um.MailmapPath())
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Mailmap{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", MailmapFileName, err)
+	}
+	m, err := ParseMailmap(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", MailmapFileName, err)
+	}
+	return m, nil
+}
+
+// Resolve returns the canonical identity for a name and email, as the repository's mailmap gives it
+func (um *UserManager) Resolve(name, email string) (Identity, error) {
+	m, err := um.Mailmap()
+	if err != nil {
+		return Identity{}, err
+	}
+	return m.Resolve(name, email), nil
+}
+
+// userFileName returns the name of a user's file. Path separators, %, control
+// characters and a leading dot are escaped as %XX, so a name containing / or ..
+// stays inside the users directory while other names keep their file.
+func userFileName(name string) string {
+	var b strings.Builder
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if c == '/' || c == '\\' || c == '%' || c < 0x20 || c == 0x7f || (i == 0 && c == '.') {
+			fmt.Fprintf(&b, "%%%02X", c)
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String() + ".json"
+}
+
+// userNameFromFile returns the user name a file belongs to, or false if it isn't a user file
+func userNameFromFile(fileName string) (string, bool) {
+	escaped, ok := strings.CutSuffix(fileName, ".json")
+	if !ok || escaped == "" {
+		return "", false
+	}
+	name, err := url.PathUnescape(escaped)
+	if err != nil {
+		return "", false
+	}
+	return name, true
+}
+
+// MergeUsers merges the stats of one user into another, e.g. an alias into the
+// canonical user, and removes the first. Action logs are combined in time order
+// and an achievement keeps the earlier of the times it was earned.
+func (um *UserManager) MergeUsers(from, into string) (*User, error) {
+	if from == into {
+		return nil, fmt.Errorf("can't merge %s into itself", from)
+	}
+	if _, err := os.Stat(um.getUserFilePath(from)); err != nil {
+		return nil, fmt.Errorf("user %s not found", from)
+	}
+	source, err := um.loadUser(from)
+	if err != nil {
+		return nil, err
+	}
+	target, err := um.loadUser(into)
+	if err != nil {
+		return nil, err
+	}
+
+	target.merge(source)
+	if err := um.SaveUser(target); err != nil {
+		return nil, err
+	}
+	if err := os.Remove(um.getUserFilePath(from)); err != nil {
+		return nil, fmt.Errorf("failed to remove user file: %w", err)
+	}
+	return target, nil
+}
+
+// merge adds another user's stats to a user's
+func (u *User) merge(other *User) {
+	if u.Email == "" {
+		u.Email = other.Email
+	}
+	u.Points += other.Points
+	u.Commits += other.Commits
+	u.IssuesOpen += other.IssuesOpen
+	u.IssuesClosed += other.IssuesClosed
+	u.Comments += other.Comments
+
+	u.ActionLog = append(u.ActionLog, other.ActionLog...)
+	sort.SliceStable(u.ActionLog, func(i, j int) bool {
+		return parseTimestamp(u.ActionLog[i].Timestamp).Before(parseTimestamp(u.ActionLog[j].Timestamp))
+	})
+
+	for _, earned := range other.Achievements {
+		found := false
+		for i := range u.Achievements {
+			if u.Achievements[i].ID != earned.ID {
+				continue
+			}
+			found = true
+			if parseTimestamp(earned.EarnedAt).Before(parseTimestamp(u.Achievements[i].EarnedAt)) {
+				u.Achievements[i].EarnedAt = earned.EarnedAt
+			}
+		}
+		if !found {
+			u.Achievements = append(u.Achievements, earned)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package user
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMailmap(t *testing.T) {
+	m, err := ParseMailmap([]byte(`# Team identities
+Alice Smith <alice@example.com> <alice@old.example.com>
+Alice Smith <alice@example.com> alice
+<bob@example.com> <bob@laptop.local>
+Carol <carol@example.com> Carol Old <carol@example.com>
+Dave <dave@example.com>
+`))
+	if err != nil {
+		t.Fatalf("Failed to parse mailmap: %v", err)
+	}
+
+	tests := []struct {
+		name, email string
+		expected    Identity
+	}{
+		{"Alice", "alice@old.example.com", Identity{"Alice Smith", "alice@example.com"}},
+		{"ALICE", "", Identity{"Alice Smith", "alice@example.com"}},
+		{"Bob", "bob@laptop.local", Identity{"Bob", "bob@example.com"}},
+		{"Carol Old", "carol@example.com", Identity{"Carol", "carol@example.com"}},
+		{"Carol", "carol@example.com", Identity{"Carol", "carol@example.com"}},
+		{"dave", "dave@example.com", Identity{"Dave", "dave@example.com"}},
+		{"Eve", "eve@example.com", Identity{"Eve", "eve@example.com"}},
+	}
+	for _, test := range tests {
+		if identity := m.Resolve(test.name, test.email); identity != test.expected {
+			t.Errorf("Resolve(%q, %q) = %v; expected %v", test.name, test.email, identity, test.expected)
+		}
+	}
+
+	for _, invalid := range []string{"Alice <alice@example.com", "Alice", "<a@example.com> <b@example.com> <c@example.com>"} {
+		if _, err := ParseMailmap([]byte(invalid)); err == nil {
+			t.Errorf("Expected %q to be rejected", invalid)
+		}
+	}
+}
+
+func TestUserFileName(t *testing.T) {
+	for _, name := range []string{"alice", "Alice Smith", "../x", "a/b", ".hidden", "100%", `c:\d`} {
+		fileName := userFileName(name)
+		if strings.ContainsAny(fileName, `/\`) || strings.HasPrefix(fileName, ".") {
+			t.Errorf("Expected %q to stay inside the users directory, got %q", name, fileName)
+		}
+		if decoded, ok := userNameFromFile(fileName); !ok || decoded != name {
+			t.Errorf("Expected %q to round-trip, got %q", name, decoded)
+		}
+	}
+
+	// Names that were safe already keep their file
+	if fileName := userFileName("Alice Smith"); fileName != "Alice Smith.json" {
+		t.Errorf("Expected Alice Smith.json, got %q", fileName)
+	}
+}
+
+func TestMergeUsers(t *testing.T) {
+	tempDir := t.TempDir()
+	manager := NewUserManager(tempDir)
+
+	if err := manager.RecordAction("alice", ActionCommit, "✨ Add feature", "2025-01-02T10:00:00Z"); err != nil {
+		t.Fatalf("Failed to record action: %v", err)
+	}
+	if err := manager.RecordAction("Alice Smith", ActionCommit, "✨ Add more", "2025-01-01T10:00:00Z"); err != nil {
+		t.Fatalf("Failed to record action: %v", err)
+	}
+
+	merged, err := manager.MergeUsers("alice", "Alice Smith")
+	if err != nil {
+		t.Fatalf("Failed to merge users: %v", err)
+	}
+	if merged.Commits != 2 || merged.Points != 20 || len(merged.ActionLog) != 2 {
+		t.Errorf("Expected 2 commits and 20 points, got %d and %d", merged.Commits, merged.Points)
+	}
+	if merged.ActionLog[0].Description != "✨ Add more" {
+		t.Errorf("Expected the action log in time order, got %v", merged.ActionLog)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, ".snap", "users", "alice.json")); !os.IsNotExist(err) {
+		t.Errorf("Expected the alias file to be removed")
+	}
+
+	if _, err := manager.MergeUsers("alice", "Alice Smith"); err == nil {
+		t.Errorf("Expected merging a missing user to fail")
+	}
+	if _, err := manager.MergeUsers("Alice Smith", "Alice Smith"); err == nil {
+		t.Errorf("Expected merging a user into itself to fail")
+	}
+}
+
+func TestRecordAlias(t *testing.T) {
+	tempDir := t.TempDir()
+	manager := NewUserManager(tempDir)
+	if err := os.WriteFile(filepath.Join(tempDir, MailmapFileName), []byte("Alice Smith <alice@example.com> alice\n"), 0644); err != nil {
+		t.Fatalf("Failed to write mailmap: %v", err)
+	}
+
+	if err := manager.RecordAction("alice", ActionCommit, "✨ Add feature", "2025-01-01T10:00:00Z"); err != nil {
+		t.Fatalf("Failed to record action: %v", err)
+	}
+
+	u, err := manager.GetUser("alice")
+	if err != nil {
+		t.Fatalf("Failed to get user: %v", err)
+	}
+	if u.Name != "Alice Smith" || u.Email != "alice@example.com" || u.Commits != 1 {
+		t.Errorf("Expected the commit recorded for Alice Smith, got %+v", u)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, ".snap", "users", "alice.json")); !os.IsNotExist(err) {
+		t.Errorf("Expected no file for the alias")
+	}
+}
+
+func TestRecordEmailAlias(t *testing.T) {
+	tempDir := t.TempDir()
+	manager := NewUserManager(tempDir)
+	if err := os.WriteFile(filepath.Join(tempDir, MailmapFileName), []byte("Alice Smith <alice@example.com> <alice@laptop.local>\n"), 0644); err != nil {
+		t.Fatalf("Failed to write mailmap: %v", err)
+	}
+
+	activity := Activity{Action: ActionIssueComment, Description: "Commented on issue #1", Timestamp: "2025-01-01T10:00:00Z", Email: "alice@laptop.local"}
+	if _, err := manager.Record("ali", activity); err != nil {
+		t.Fatalf("Failed to record action: %v", err)
+	}
+	if _, err := manager.RecordIssueClose("ali", "alice@laptop.local", 0, "Closed issue #1", "2025-01-01T11:00:00Z"); err != nil {
+		t.Fatalf("Failed to record issue close: %v", err)
+	}
+
+	u, err := manager.GetUser("Alice Smith")
+	if err != nil {
+		t.Fatalf("Failed to get user: %v", err)
+	}
+	if u.Comments != 1 || u.IssuesClosed != 1 {
+		t.Errorf("Expected both actions recorded for Alice Smith, got %+v", u)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, ".snap", "users", "ali.json")); !os.IsNotExist(err) {
+		t.Errorf("Expected no file for the name the email is mapped from")
+	}
+}
@@ -0,0 +1,403 @@
+package user
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stanlocht/snap/pkg/config"
+)
+
+// Category is what a leaderboard ranks users by
+type Category string
+
+const (
+	// CategoryPoints ranks users by points
+	CategoryPoints Category = "points"
+	// CategoryCommits ranks users by commits
+	CategoryCommits Category = "commits"
+	// CategoryIssuesClosed ranks users by issues closed
+	CategoryIssuesClosed Category = "issues_closed"
+	// CategoryBugsFixed ranks users by 🐛 commits
+	CategoryBugsFixed Category = "bugs_fixed"
+)
+
+// Categories lists the leaderboard categories
+var Categories = []Category{CategoryPoints, CategoryCommits, CategoryIssuesClosed, CategoryBugsFixed}
+
+// Unit names what a category counts, e.g. "issues closed"
+func (c Category) Unit() string {
+	return strings.ReplaceAll(string(c), "_", " ")
+}
+
+// Standing is a user's score on a leaderboard
+type Standing struct {
+	Name  string `json:"name"`
+	Score int    `json:"score"`
+}
+
+// Window limits a leaderboard to the actions from Since up to, but not
+// including, Until. Zero times leave the window open on that side.
+type Window struct {
+	Since time.Time
+	Until time.Time
+}
+
+// Season is a named window whose final standings are archived once it ends
+type Season struct {
+	Name  string
+	Start time.Time
+	End   time.Time // Exclusive
+}
+
+// SeasonArchive holds the final standings of a season
+type SeasonArchive struct {
+	Season     string                  `json:"season"`
+	Start      string                  `json:"start"`
+	End        string                  `json:"end"`
+	ArchivedAt string                  `json:"archived_at"`
+	Boards     map[Category][]Standing `json:"boards"`
+	Teams      map[Category][]Standing `json:"teams,omitempty"` // Team boards, for seasons archived with teams configured
+}
+
+// quarterSeason matches the built-in seasons, e.g. 2026-Q4
+var quarterSeason = regexp.MustCompile(`^(\d{4})-Q([1-4])$`)
+
+// seasonName matches the names seasons may have, which name their archive files
+var seasonName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// relativeSince matches relative windows, e.g. 7d
+var relativeSince = regexp.MustCompile(`^(\d+)([hdw])$`)
+
+// ParseCategory parses a leaderboard category, allowing "issues-closed" for "issues_closed"
+func ParseCategory(value string) (Category, error) {
+	if value == "" {
+		return CategoryPoints, nil
+	}
+	for _, category := range Categories {
+		if string(category) == strings.ReplaceAll(value, "-", "_") {
+			return category, nil
+		}
+	}
+	return "", fmt.Errorf("unknown category %q: use points, commits, issues_closed or bugs_fixed", value)
+}
+
+// ParseSince parses the start of a window, either relative to now (24h, 7d,
+// 2w) or a date (2026-01-31) in a time zone
+func ParseSince(value string, now time.Time, loc *time.Location) (time.Time, error) {
+	if match := relativeSince.FindStringSubmatch(value); match != nil {
+		n, _ := strconv.Atoi(match[1])
+		switch match[2] {
+		case "h":
+			return now.Add(-time.Duration(n) * time.Hour), nil
+		case "d":
+			return now.AddDate(0, 0, -n), nil
+		default:
+			return now.AddDate(0, 0, -7*n), nil
+		}
+	}
+	since, err := time.ParseInLocation(dateLayout, value, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q: use e.g. 24h, 7d, 2w or 2026-01-31", value)
+	}
+	return since, nil
+}
+
+// score returns a user's score in a category for the actions in a window
+func (u *User) score(category Category, window Window) int {
+	// Lifetime totals come from the counters, which history may no longer fully log
+	if window.Since.IsZero() && window.Until.IsZero() {
+		switch category {
+		case CategoryPoints:
+			return u.Points
+		case CategoryCommits:
+			return u.Commits
+		case CategoryIssuesClosed:
+			return u.IssuesClosed
+		}
+	}
+
+	bug, _ := snapmojiKey(nil, ":bug:")
+	score := 0
+	for _, record := range u.ActionLog {
+		at, err := time.Parse(time.RFC3339, record.Timestamp)
+		if err != nil || (!window.Since.IsZero() && at.Before(window.Since)) || (!window.Until.IsZero() && !at.Before(window.Until)) {
+			continue
+		}
+		switch category {
+		case CategoryPoints:
+			score += record.Points
+		case CategoryCommits:
+			if record.Action == ActionCommit && !record.Flag.voids() {
+				score++
+			}
+		case CategoryIssuesClosed:
+			if record.Action == ActionIssueClose {
+				score++
+			}
+		case CategoryBugsFixed:
+			if record.Action == ActionCommit && !record.Flag.voids() && messageSnapmoji(nil, record.Description) == bug {
+				score++
+			}
+		}
+	}
+	return score
+}
+
+// Leaderboard ranks users in a category for the actions in a window, highest
+// score first. Users without a score are left out, except on the lifetime points board.
+func (um *UserManager) Leaderboard(category Category, window Window) ([]Standing, error) {
+	users, err := um.GetLeaderboard()
+	if err != nil {
+		return nil, err
+	}
+
+	lifetime := category == CategoryPoints && window.Since.IsZero() && window.Until.IsZero()
+	standings := []Standing{}
+	for _, u := range users {
+		score := u.score(category, window)
+		if score > 0 || lifetime {
+			standings = append(standings, Standing{Name: u.Name, Score: score})
+		}
+	}
+	sortStandings(standings)
+	return standings, nil
+}
+
+// Ranks returns the rank of each standing, sharing ranks between equal scores
+func Ranks(standings []Standing) []int {
+	ranks := make([]int, len(standings))
+	for i := range standings {
+		if i > 0 && standings[i].Score == standings[i-1].Score {
+			ranks[i] = ranks[i-1]
+		} else {
+			ranks[i] = i + 1
+		}
+	}
+	return ranks
+}
+
+// QuarterName returns the name of the quarter season a time falls in, e.g. 2026-Q4
+func QuarterName(t time.Time) string {
+	return fmt.Sprintf("%d-Q%d", t.Year(), (int(t.Month())+2)/3)
+}
+
+// Window returns the window a season covers
+func (s *Season) Window() Window {
+	return Window{Since: s.Start, Until: s.End}
+}
+
+// Season returns a season configured with [season "<name>"] start and end
+// dates in the config, or a quarter such as 2026-Q4
+func (um *UserManager) Season(name string) (*Season, error) {
+	if !seasonName.MatchString(name) {
+		return nil, fmt.Errorf("invalid season name %q", name)
+	}
+	loc, err := um.Location()
+	if err != nil {
+		return nil, err
+	}
+
+	// Configured seasons
+	path := um.configPath()
+	start, err := config.GetValue(path, fmt.Sprintf("season.%s.start", name))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	if start != "" {
+		end, err := config.GetValue(path, fmt.Sprintf("season.%s.end", name))
+		if err != nil {
+			return nil, err
+		}
+		season := &Season{Name: name}
+		if season.Start, err = time.ParseInLocation(dateLayout, start, loc); err != nil {
+			return nil, fmt.Errorf("invalid start of season %s: %q", name, start)
+		}
+		// The end date is the season's last day
+		last, err := time.ParseInLocation(dateLayout, end, loc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid end of season %s: %q", name, end)
+		}
+		season.End = last.AddDate(0, 0, 1)
+		if !season.End.After(season.Start) {
+			return nil, fmt.Errorf("season %s ends before it starts", name)
+		}
+		return season, nil
+	}
+
+	// Quarters
+	if match := quarterSeason.FindStringSubmatch(name); match != nil {
+		year, _ := strconv.Atoi(match[1])
+		quarter, _ := strconv.Atoi(match[2])
+		start := time.Date(year, time.Month(3*quarter-2), 1, 0, 0, 0, 0, loc)
+		return &Season{Name: name, Start: start, End: start.AddDate(0, 3, 0)}, nil
+	}
+
+	return nil, fmt.Errorf("unknown season %q: configure it with snap config set season.%s.start YYYY-MM-DD, or use a quarter such as 2026-Q4", name, name)
+}
+
+// Seasons returns the seasons configured in the config, ordered by start
+func (um *UserManager) Seasons() ([]*Season, error) {
+	names, err := config.GetSubsections(um.configPath(), "season")
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var seasons []*Season
+	for _, name := range names {
+		season, err := um.Season(name)
+		if err != nil {
+			return nil, err
+		}
+		seasons = append(seasons, season)
+	}
+	sort.SliceStable(seasons, func(i, j int) bool { return seasons[i].Start.Before(seasons[j].Start) })
+	return seasons, nil
+}
+
+// getSeasonsDir returns the path to the directory of season archives
+func (um *UserManager) getSeasonsDir() string {
+	return filepath.Join(um.RepoPath, ".snap", "seasons")
+}
+
+// SeasonArchive returns the archived standings of a season, or nil if it isn't archived
+func (um *UserManager) SeasonArchive(name string) (*SeasonArchive, error) {
+	if !seasonName.MatchString(name) {
+		return nil, fmt.Errorf("invalid season name %q", name)
+	}
+	data, err := os.ReadFile(filepath.Join(um.getSeasonsDir(), name+".json"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read season archive: %w", err)
+	}
+	var archive SeasonArchive
+	if err := json.Unmarshal(data, &archive); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal season archive: %w", err)
+	}
+	return &archive, nil
+}
+
+// finalStandings ranks users and teams in every category for a season, as its archive holds them
+func (um *UserManager) finalStandings(season *Season, now time.Time) (*SeasonArchive, error) {
+	archive := &SeasonArchive{
+		Season:     season.Name,
+		Start:      season.Start.Format(time.RFC3339),
+		End:        season.End.Format(time.RFC3339),
+		ArchivedAt: now.Format(time.RFC3339),
+		Boards:     make(map[Category][]Standing),
+	}
+	teams, err := um.Teams()
+	if err != nil {
+		return nil, err
+	}
+	if len(teams) > 0 {
+		archive.Teams = make(map[Category][]Standing)
+	}
+	for _, category := range Categories {
+		standings, err := um.Leaderboard(category, season.Window())
+		if err != nil {
+			return nil, err
+		}
+		archive.Boards[category] = standings
+		if archive.Teams != nil {
+			if archive.Teams[category], err = um.TeamLeaderboard(category, season.Window()); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return archive, nil
+}
+
+// ArchiveSeason saves the final standings of a season that has ended in
+// .snap/seasons, so later changes to history or the rules don't rewrite them.
+// A season that was archived before keeps its archive, and one that hasn't
+// ended yet gives nil.
+func (um *UserManager) ArchiveSeason(name string, now time.Time) (*SeasonArchive, error) {
+	archive, err := um.SeasonArchive(name)
+	if err != nil || archive != nil {
+		return archive, err
+	}
+	season, err := um.Season(name)
+	if err != nil {
+		return nil, err
+	}
+	if now.Before(season.End) {
+		return nil, nil
+	}
+
+	if archive, err = um.finalStandings(season, now); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(um.getSeasonsDir(), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create seasons directory: %w", err)
+	}
+	data, err := json.MarshalIndent(archive, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal season archive: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(um.getSeasonsDir(), season.Name+".json"), data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write season archive: %w", err)
+	}
+	return archive, nil
+}
+
+// SeasonLeaderboard ranks users in a category for a season, reading the
+// standings of an archived season from its archive. It never writes: see
+// ArchiveSeason. It reports whether the standings are final.
+func (um *UserManager) SeasonLeaderboard(name string, category Category, now time.Time) ([]Standing, bool, error) {
+	return um.seasonStandings(name, category, now, false)
+}
+
+// SeasonTeamLeaderboard ranks teams in a category for a season, reading the
+// standings of an archived season from its archive as SeasonLeaderboard does
+func (um *UserManager) SeasonTeamLeaderboard(name string, category Category, now time.Time) ([]Standing, bool, error) {
+	return um.seasonStandings(name, category, now, true)
+}
+
+// seasonStandings ranks users or teams in a category for a season
+func (um *UserManager) seasonStandings(name string, category Category, now time.Time, teams bool) ([]Standing, bool, error) {
+	archive, err := um.SeasonArchive(name)
+	if err != nil {
+		return nil, false, err
+	}
+	if archive == nil {
+		season, err := um.Season(name)
+		if err != nil {
+			return nil, false, err
+		}
+		if now.Before(season.End) {
+			var standings []Standing
+			if teams {
+				standings, err = um.TeamLeaderboard(category, season.Window())
+			} else {
+				standings, err = um.Leaderboard(category, season.Window())
+			}
+			return standings, false, err
+		}
+		if archive, err = um.finalStandings(season, now); err != nil {
+			return nil, false, err
+		}
+	}
+
+	standings := archive.Boards[category]
+	if teams {
+		standings = archive.Teams[category]
+	}
+	if standings == nil {
+		standings = []Standing{}
+	}
+	return standings, true, nil
+}
@@ -0,0 +1,135 @@
+package user
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLeaderboard(t *testing.T) {
+	tempDir := t.TempDir()
+	manager := NewUserManager(tempDir)
+	if err := os.MkdirAll(filepath.Join(tempDir, ".snap"), 0755); err != nil {
+		t.Fatalf("Failed to create .snap directory: %v", err)
+	}
+	config := "[user]\n\ttimezone = UTC\n[season \"spring\"]\n\tstart = 2026-03-01\n\tend = 2026-05-31\n"
+	if err := os.WriteFile(filepath.Join(tempDir, ".snap", "config"), []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	// Alice was busy long ago; bob fixed bugs this spring
+	for _, timestamp := range []string{"2025-01-10T10:00:00Z", "2025-01-11T10:00:00Z", "2025-01-12T10:00:00Z"} {
+		if err := manager.RecordAction("alice", ActionCommit, "✨ Add feature", timestamp); err != nil {
+			t.Fatalf("Failed to record action: %v", err)
+		}
+	}
+	for _, timestamp := range []string{"2026-04-01T10:00:00Z", "2026-05-31T23:00:00Z"} {
+		if err := manager.RecordAction("bob", ActionCommit, ":bug: Fix crash", timestamp); err != nil {
+			t.Fatalf("Failed to record action: %v", err)
+		}
+	}
+	if err := manager.RecordAction("carol", ActionIssueClose, "Closed issue #1", "2026-06-01T00:00:00Z"); err != nil {
+		t.Fatalf("Failed to record action: %v", err)
+	}
+
+	// Lifetime points favour alice
+	standings, err := manager.Leaderboard(CategoryPoints, Window{})
+	if err != nil {
+		t.Fatalf("Failed to get leaderboard: %v", err)
+	}
+	if len(standings) != 3 || standings[0].Name != "alice" || standings[0].Score != 30 {
+		t.Errorf("Expected alice to lead with 30 points, got %v", standings)
+	}
+
+	// Since spring, only bob and carol count
+	since, err := ParseSince("2026-03-01", time.Now(), time.UTC)
+	if err != nil {
+		t.Fatalf("Failed to parse since: %v", err)
+	}
+	standings, err = manager.Leaderboard(CategoryPoints, Window{Since: since})
+	if err != nil {
+		t.Fatalf("Failed to get leaderboard: %v", err)
+	}
+	if len(standings) != 2 || standings[0].Name != "bob" || standings[1].Name != "carol" {
+		t.Errorf("Expected bob then carol, got %v", standings)
+	}
+
+	// The spring season ends before carol's close, and ranks bugs fixed
+	now := time.Date(2026, 6, 15, 0, 0, 0, 0, time.UTC)
+	standings, final, err := manager.SeasonLeaderboard("spring", CategoryBugsFixed, now)
+	if err != nil {
+		t.Fatalf("Failed to get season leaderboard: %v", err)
+	}
+	if !final || len(standings) != 1 || standings[0] != (Standing{Name: "bob", Score: 2}) {
+		t.Errorf("Expected final standings with bob's 2 bug fixes, got %v (final %v)", standings, final)
+	}
+
+	// Asking for the standings doesn't write anything, archiving does
+	if archive, err := manager.SeasonArchive("spring"); err != nil || archive != nil {
+		t.Errorf("Expected no archive before archiving, got %v (%v)", archive, err)
+	}
+	if archive, err := manager.ArchiveSeason("spring", now); err != nil || archive == nil {
+		t.Fatalf("Failed to archive season: %v", err)
+	}
+
+	// Archived seasons don't change with later activity
+	if err := manager.RecordAction("alice", ActionCommit, ":bug: Fix typo", "2026-04-02T10:00:00Z"); err != nil {
+		t.Fatalf("Failed to record action: %v", err)
+	}
+	standings, _, err = manager.SeasonLeaderboard("spring", CategoryBugsFixed, now)
+	if err != nil {
+		t.Fatalf("Failed to get season leaderboard: %v", err)
+	}
+	if len(standings) != 1 {
+		t.Errorf("Expected the archived standings, got %v", standings)
+	}
+
+	// Quarters are seasons too, and still open ones aren't archived
+	standings, final, err = manager.SeasonLeaderboard("2026-Q2", CategoryIssuesClosed, time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Failed to get quarter leaderboard: %v", err)
+	}
+	if final || len(standings) != 1 || standings[0].Name != "carol" {
+		t.Errorf("Expected carol's close in an open quarter, got %v (final %v)", standings, final)
+	}
+	if archive, err := manager.ArchiveSeason("2026-Q2", time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)); err != nil || archive != nil {
+		t.Errorf("Expected an open season not to be archived, got %v (%v)", archive, err)
+	}
+	if archive, err := manager.SeasonArchive("2026-Q2"); err != nil || archive != nil {
+		t.Errorf("Expected no archive for an open season, got %v (%v)", archive, err)
+	}
+
+	if _, err := manager.Season("../users"); err == nil {
+		t.Errorf("Expected an invalid season name to be rejected")
+	}
+	if _, err := manager.Season("winter"); err == nil {
+		t.Errorf("Expected an unknown season to be rejected")
+	}
+}
+
+func TestRanks(t *testing.T) {
+	ranks := Ranks([]Standing{{"a", 9}, {"b", 5}, {"c", 5}, {"d", 1}})
+	if ranks[0] != 1 || ranks[1] != 2 || ranks[2] != 2 || ranks[3] != 4 {
+		t.Errorf("Expected ranks 1, 2, 2 and 4, got %v", ranks)
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	tests := map[string]time.Time{
+		"24h":        now.Add(-24 * time.Hour),
+		"7d":         now.AddDate(0, 0, -7),
+		"2w":         now.AddDate(0, 0, -14),
+		"2026-01-31": time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC),
+	}
+	for value, expected := range tests {
+		since, err := ParseSince(value, now, time.UTC)
+		if err != nil || !since.Equal(expected) {
+			t.Errorf("ParseSince(%q) = %v, %v; expected %v", value, since, err, expected)
+		}
+	}
+	if _, err := ParseSince("soon", now, time.UTC); err == nil {
+		t.Errorf("Expected an invalid time to be rejected")
+	}
+}
@@ -0,0 +1,491 @@
+package user
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stanlocht/snap/pkg/config"
+	"github.com/stanlocht/snap/pkg/issue"
+	"github.com/stanlocht/snap/pkg/repository"
+)
+
+// CacheConfigKey is the config key that, when true, makes the user files a
+// cache of the points history earns, rebuilt whenever history or the rules change
+const CacheConfigKey = "points.cache"
+
+// cacheKeyFileName is the file in the users directory recording what the cached user files were built from
+const cacheKeyFileName = ".cache-key"
+
+// Contribution is an activity found in the repository's history
+type Contribution struct {
+	Name     string
+	Email    string
+	Activity Activity
+}
+
+// Drift describes a user whose stored stats differ from the ones history gives
+type Drift struct {
+	Name     string
+	Stored   *User
+	Computed *User
+}
+
+// CacheEnabled reports whether the user files are only a cache of the points history earns
+func (um *UserManager) CacheEnabled() (bool, error) {
+	value, err := config.GetValue(um.configPath(), CacheConfigKey)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if value == "" {
+		return false, nil
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s in config: expected true or false", CacheConfigKey)
+	}
+	return enabled, nil
+}
+
+// historyHeads returns the commit IDs of the local branches and HEAD, whose history earns points
+func historyHeads(repo *repository.Repository) ([]string, error) {
+	refs, err := repo.ListRefs(repository.BranchRefPrefix)
+	if err != nil {
+		return nil, err
+	}
+	var heads []string
+	for _, name := range repository.SortedRefNames(refs) {
+		heads = append(heads, refs[name])
+	}
+
+	// HEAD may be detached
+	headID, err := repo.GetHEADCommitID()
+	if err != nil {
+		return nil, err
+	}
+	if headID != "" {
+		heads = append(heads, headID)
+	}
+	return heads, nil
+}
+
+// Contributions returns the activities that earn points in the repository's
+// history, oldest first: commits on local branches (merges excluded), comments
+// written here and issues closed by commits
+func (um *UserManager) Contributions() ([]Contribution, error) {
+	// Commits
+	contributions, err := um.commitContributions()
+	if err != nil {
+		return nil, err
+	}
+
+	// Issue events
+	issues, err := issue.NewIssueManager(um.RepoPath).ListIssues(true)
+	if err != nil {
+		return nil, err
+	}
+	mailmap, err := um.Mailmap()
+	if err != nil {
+		return nil, err
+	}
+	for _, i := range issues {
+		for _, c := range issueContributions(i) {
+			c.Name = mailmap.Resolve(c.Name, "").Name
+			contributions = append(contributions, c)
+		}
+	}
+
+	sort.SliceStable(contributions, func(a, b int) bool {
+		return activityTime(contributions[a].Activity).Before(activityTime(contributions[b].Activity))
+	})
+	return contributions, nil
+}
+
+// commitContributions returns the commits on local branches, merges excluded,
+// newest first, by their authors' canonical identities. Commits that changed no
+// files, reverts and the commits they revert are marked.
+func (um *UserManager) commitContributions() ([]Contribution, error) {
+	repo := &repository.Repository{Path: um.RepoPath}
+	heads, err := historyHeads(repo)
+	if err != nil {
+		return nil, err
+	}
+	mailmap, err := um.Mailmap()
+	if err != nil {
+		return nil, err
+	}
+
+	var contributions []Contribution
+	seen := make(map[string]bool)
+	for _, head := range heads {
+		var walkErr error
+		err := repo.WalkCommits(head, func(commit *repository.Commit) bool {
+			if seen[commit.ID] {
+				return true
+			}
+			seen[commit.ID] = true
+			if commit.MergeParentID != "" {
+				return true
+			}
+
+			tree, err := repo.GetTree(commit.TreeID)
+			if err != nil {
+				walkErr = err
+				return false
+			}
+			changed, err := repo.ChangedFiles(commit.ParentID, tree)
+			if err != nil {
+				walkErr = err
+				return false
+			}
+			identity := mailmap.Resolve(commit.Author, commit.Email)
+			contributions = append(contributions, Contribution{
+				Name:  identity.Name,
+				Email: identity.Email,
+				Activity: Activity{
+					Action:      ActionCommit,
+					Description: commit.Message,
+					Files:       len(changed),
+					Timestamp:   commit.Timestamp.Format(time.RFC3339),
+					Commit:      commit.ID,
+					Empty:       len(changed) == 0,
+					Reverts:     RevertedCommit(commit.Message),
+				},
+			})
+			return true
+		})
+		if err == nil {
+			err = walkErr
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to walk commit history: %w", err)
+		}
+	}
+
+	for _, revert := range contributions {
+		if revert.Activity.Reverts == "" {
+			continue
+		}
+		for i := range contributions {
+			if strings.HasPrefix(contributions[i].Activity.Commit, revert.Activity.Reverts) {
+				contributions[i].Activity.Reverted = true
+			}
+		}
+	}
+	return contributions, nil
+}
+
+// issueContributions returns the comments written on an issue and the closes by commits in its history
+func issueContributions(i *issue.Issue) []Contribution {
+	var contributions []Contribution
+	for _, comment := range i.Comments {
+		if comment.Imported {
+			continue
+		}
+		contributions = append(contributions, Contribution{
+			Name: comment.Author,
+			Activity: Activity{
+				Action:      ActionIssueComment,
+				Description: fmt.Sprintf("Commented on issue #%d", i.ID),
+				Timestamp:   comment.CreatedAt.Format(time.RFC3339),
+			},
+		})
+	}
+
+	// Closing points may depend on the estimate the issue had at the time,
+	// which the estimate events tell, starting from the first one's old value
+	estimate := i.Estimate
+	for _, event := range i.Events {
+		if event.Field == "estimate" {
+			estimate, _ = issue.ParseDuration(event.Old)
+			break
+		}
+	}
+	for _, event := range i.Events {
+		switch event.Field {
+		case "estimate":
+			estimate, _ = issue.ParseDuration(event.New)
+		case "closed_by":
+			commitID := event.New
+			if len(commitID) > 7 {
+				commitID = commitID[:7]
+			}
+			contributions = append(contributions, Contribution{
+				Name: event.Actor,
+				Activity: Activity{
+					Action:      ActionIssueClose,
+					Description: fmt.Sprintf("Closed issue #%d in commit %s", i.ID, commitID),
+					Estimate:    estimate,
+					Timestamp:   event.At.Format(time.RFC3339),
+				},
+			})
+		}
+	}
+	return contributions
+}
+
+// activityTime returns when an activity happened
+func activityTime(a Activity) time.Time {
+	return parseTimestamp(a.Timestamp)
+}
+
+// parseTimestamp parses an RFC 3339 timestamp, giving the zero time for an invalid one
+func parseTimestamp(timestamp string) time.Time {
+	at, _ := time.Parse(time.RFC3339, timestamp)
+	return at
+}
+
+// Recompute replays the repository's history under its current rules and
+// returns the stats every user should have. Users with a file but nothing in
+// history come back with no points.
+func (um *UserManager) Recompute() ([]*User, error) {
+	rules, err := um.Rules()
+	if err != nil {
+		return nil, err
+	}
+	contributions, err := um.Contributions()
+	if err != nil {
+		return nil, err
+	}
+
+	users := make(map[string]*User)
+	get := func(name string) *User {
+		if users[name] == nil {
+			users[name] = &User{Name: name, ActionLog: []ActionRecord{}}
+		}
+		return users[name]
+	}
+
+	for _, c := range contributions {
+		rule, ok := rules.Actions[c.Activity.Action]
+		if !ok || c.Name == "" {
+			// A team may drop an action from its rules
+			continue
+		}
+		user := get(c.Name)
+		if c.Email != "" {
+			user.Email = c.Email
+		}
+		user.award(rule, c.Activity)
+	}
+
+	// Achievements follow from the rebuilt action logs and the commits
+	commits := make(map[string][]Activity)
+	for _, c := range contributions {
+		if c.Activity.Action == ActionCommit && !c.Activity.voided() {
+			commits[c.Name] = append(commits[c.Name], c.Activity)
+		}
+	}
+	for name, user := range users {
+		user.evaluateAchievements(commits[name])
+	}
+
+	// Keep the users that have a file, and the emails history doesn't know.
+	// Files of aliases are folded into their canonical user.
+	stored, err := um.storedUsers()
+	if err != nil {
+		return nil, err
+	}
+	mailmap, err := um.Mailmap()
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range stored {
+		identity := mailmap.Resolve(s.Name, s.Email)
+		user := get(identity.Name)
+		if user.Email == "" {
+			user.Email = identity.Email
+		}
+	}
+
+	names := make([]string, 0, len(users))
+	for name := range users {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	result := make([]*User, 0, len(names))
+	for _, name := range names {
+		result = append(result, users[name])
+	}
+	return result, nil
+}
+
+// storedUsers reads every user file, ordered by name
+func (um *UserManager) storedUsers() ([]*User, error) {
+	files, err := os.ReadDir(um.getUsersDir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read users directory: %w", err)
+	}
+
+	var users []*User
+	for _, file := range files {
+		name, ok := userNameFromFile(file.Name())
+		if file.IsDir() || !ok {
+			continue
+		}
+		user, err := um.loadUser(name)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, nil
+}
+
+// SaveUsers replaces the stored users with recomputed ones, removing the files
+// of users not among them, such as aliases the mailmap folded away
+func (um *UserManager) SaveUsers(users []*User) error {
+	stored, err := um.storedUsers()
+	if err != nil {
+		return err
+	}
+
+	kept := make(map[string]bool, len(users))
+	for _, user := range users {
+		if err := um.SaveUser(user); err != nil {
+			return err
+		}
+		kept[user.Name] = true
+	}
+	for _, user := range stored {
+		if kept[user.Name] {
+			continue
+		}
+		if err := os.Remove(um.getUserFilePath(user.Name)); err != nil {
+			return fmt.Errorf("failed to remove user file: %w", err)
+		}
+	}
+	return nil
+}
+
+// CheckDrift compares the stored users with the ones history gives and returns
+// the users whose points or counters differ, including aliases that still have a file
+func (um *UserManager) CheckDrift() ([]Drift, error) {
+	computed, err := um.Recompute()
+	if err != nil {
+		return nil, err
+	}
+
+	var drifts []Drift
+	for _, c := range computed {
+		stored, err := um.loadUser(c.Name)
+		if err != nil {
+			return nil, err
+		}
+		if stored.Points != c.Points || stored.Commits != c.Commits || stored.IssuesOpen != c.IssuesOpen ||
+			stored.IssuesClosed != c.IssuesClosed || stored.Comments != c.Comments {
+			drifts = append(drifts, Drift{Name: c.Name, Stored: stored, Computed: c})
+		}
+	}
+
+	// Files of aliases shouldn't exist at all
+	names := make(map[string]bool, len(computed))
+	for _, c := range computed {
+		names[c.Name] = true
+	}
+	stored, err := um.storedUsers()
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range stored {
+		if !names[s.Name] {
+			drifts = append(drifts, Drift{Name: s.Name, Stored: s, Computed: &User{Name: s.Name, ActionLog: []ActionRecord{}}})
+		}
+	}
+	sort.SliceStable(drifts, func(i, j int) bool { return drifts[i].Name < drifts[j].Name })
+	return drifts, nil
+}
+
+// cacheKey identifies what the user files are built from: the branches, HEAD,
+// the issue tracker, the rules and the mailmap
+func (um *UserManager) cacheKey() (string, error) {
+	repo := &repository.Repository{Path: um.RepoPath}
+	heads, err := historyHeads(repo)
+	if err != nil {
+		return "", err
+	}
+	issuesID, err := repo.ResolveRef(issue.IssuesRef)
+	if err != nil {
+		return "", err
+	}
+	rules, err := um.Rules()
+	if err != nil {
+		return "", err
+	}
+
+	mailmap, err := os.ReadFile(um.MailmapPath())
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return "", fmt.Errorf("failed to read %s: %w", MailmapFileName, err)
+	}
+
+	data, err := json.Marshal(struct {
+		Heads   []string `json:"heads"`
+		Issues  string   `json:"issues"`
+		Rules   *Rules   `json:"rules"`
+		Mailmap string   `json:"mailmap"`
+	}{heads, issuesID, rules, string(mailmap)})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal cache key: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// refreshCache rebuilds the user files from history when they are a cache
+// and history or the rules changed since they were built
+func (um *UserManager) refreshCache() error {
+	cached, err := um.CacheEnabled()
+	if err != nil || !cached {
+		return err
+	}
+
+	key, err := um.cacheKey()
+	if err != nil {
+		return err
+	}
+	keyPath := filepath.Join(um.getUsersDir(), cacheKeyFileName)
+	if data, err := os.ReadFile(keyPath); err == nil && string(data) == key {
+		return nil
+	}
+
+	users, err := um.Recompute()
+	if err != nil {
+		return err
+	}
+	if err := um.SaveUsers(users); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(um.getUsersDir(), 0755); err != nil {
+		return fmt.Errorf("failed to create users directory: %w", err)
+	}
+	if err := os.WriteFile(keyPath, []byte(key), 0644); err != nil {
+		return fmt.Errorf("failed to write cache key: %w", err)
+	}
+	return nil
+}
+
+// logged returns the points the latest record of an action with a description earned
+func (u *User) logged(activity Activity) (int, bool) {
+	for i := len(u.ActionLog) - 1; i >= 0; i-- {
+		record := u.ActionLog[i]
+		if record.Action == activity.Action && record.Description == activity.Description {
+			return record.Points, true
+		}
+	}
+	return 0, false
+}
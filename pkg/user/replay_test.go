@@ -0,0 +1,155 @@
+package user
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stanlocht/snap/pkg/issue"
+	"github.com/stanlocht/snap/pkg/repository"
+)
+
+func TestRecompute(t *testing.T) {
+	tempDir := t.TempDir()
+	repo, err := repository.Init(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	manager := NewUserManager(tempDir)
+	issueManager := issue.NewIssueManager(tempDir)
+
+	// commit creates a commit and records it, as snap commit does
+	commit := func(author, message string, entries map[string]string) *repository.Commit {
+		c, err := repo.CreateCommit(message, author, author+"@example.com", &repository.Tree{Entries: entries})
+		if err != nil {
+			t.Fatalf("Failed to create commit: %v", err)
+		}
+		if _, err := manager.Record(author, Activity{Action: ActionCommit, Description: message, Files: len(entries), Timestamp: c.Timestamp.Format(time.RFC3339)}); err != nil {
+			t.Fatalf("Failed to record commit: %v", err)
+		}
+		return c
+	}
+
+	commit("alice", "✨ Add a", map[string]string{"a.txt": "1"})
+	if _, err := issueManager.CreateIssue("Broken b", "", "alice"); err != nil {
+		t.Fatalf("Failed to create issue: %v", err)
+	}
+	if _, err := issueManager.AddComment(1, "carol", "Seen it too", ""); err != nil {
+		t.Fatalf("Failed to add comment: %v", err)
+	}
+	if err := manager.RecordAction("carol", ActionIssueComment, "Commented on issue #1", time.Now().Format(time.RFC3339)); err != nil {
+		t.Fatalf("Failed to record comment: %v", err)
+	}
+
+	fix := commit("bob", "🐛 Fix b, fixes #1", map[string]string{"a.txt": "1", "b.txt": "2"})
+	issueManager.Author = "bob"
+	if _, err := issueManager.LinkCommit(fix.ID, fix.Message); err != nil {
+		t.Fatalf("Failed to link commit: %v", err)
+	}
+	if err := manager.RecordAction("bob", ActionIssueClose, "Closed issue #1 in commit "+fix.ID[:7], time.Now().Format(time.RFC3339)); err != nil {
+		t.Fatalf("Failed to record close: %v", err)
+	}
+
+	// Points recorded as things happen match history
+	drifts, err := manager.CheckDrift()
+	if err != nil {
+		t.Fatalf("Failed to check drift: %v", err)
+	}
+	if len(drifts) != 0 {
+		t.Errorf("Expected no drift, got %d drifted users", len(drifts))
+	}
+
+	// A hand-edited file and a commit that was never recorded drift
+	alice, err := manager.GetUser("alice")
+	if err != nil {
+		t.Fatalf("Failed to get user: %v", err)
+	}
+	alice.Points = 999
+	if err := manager.SaveUser(alice); err != nil {
+		t.Fatalf("Failed to save user: %v", err)
+	}
+	if _, err := repo.CreateCommit("📝 Document b", "dave", "", &repository.Tree{Entries: map[string]string{"a.txt": "1", "b.txt": "2", "README": "3"}}); err != nil {
+		t.Fatalf("Failed to create commit: %v", err)
+	}
+	drifts, err = manager.CheckDrift()
+	if err != nil {
+		t.Fatalf("Failed to check drift: %v", err)
+	}
+	if len(drifts) != 2 || drifts[0].Name != "alice" || drifts[1].Name != "dave" {
+		t.Fatalf("Expected alice and dave to drift, got %v", drifts)
+	}
+	if drifts[0].Stored.Points != 999 || drifts[0].Computed.Points != PointValues[ActionCommit] {
+		t.Errorf("Expected alice to have 999 points stored and %d in history, got %d and %d",
+			PointValues[ActionCommit], drifts[0].Stored.Points, drifts[0].Computed.Points)
+	}
+
+	// Recomputing fixes them
+	users, err := manager.Recompute()
+	if err != nil {
+		t.Fatalf("Failed to recompute: %v", err)
+	}
+	if err := manager.SaveUsers(users); err != nil {
+		t.Fatalf("Failed to save users: %v", err)
+	}
+	bob, err := manager.GetUser("bob")
+	if err != nil {
+		t.Fatalf("Failed to get user: %v", err)
+	}
+	if bob.Email != "bob@example.com" || bob.Commits != 1 || bob.IssuesClosed != 1 || bob.Points != PointValues[ActionCommit]+PointValues[ActionIssueClose] {
+		t.Errorf("Expected bob's commit and close from history, got %+v", bob)
+	}
+	if drifts, err := manager.CheckDrift(); err != nil || len(drifts) != 0 {
+		t.Errorf("Expected no drift after recomputing, got %v (%v)", drifts, err)
+	}
+}
+
+func TestPointsCache(t *testing.T) {
+	tempDir := t.TempDir()
+	repo, err := repository.Init(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, ".snap", "config"), []byte("[points]\n\tcache = true\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	manager := NewUserManager(tempDir)
+
+	// Recording reports the points without keeping its own count
+	c, err := repo.CreateCommit("✨ Add a", "alice", "", &repository.Tree{Entries: map[string]string{"a.txt": "1"}})
+	if err != nil {
+		t.Fatalf("Failed to create commit: %v", err)
+	}
+	points, err := manager.Record("alice", Activity{Action: ActionCommit, Description: c.Message, Files: 1, Timestamp: c.Timestamp.Format(time.RFC3339)})
+	if err != nil {
+		t.Fatalf("Failed to record commit: %v", err)
+	}
+	if points != PointValues[ActionCommit] {
+		t.Errorf("Expected %d points, got %d", PointValues[ActionCommit], points)
+	}
+
+	// Hand edits are overwritten once history changes
+	alice, err := manager.GetUser("alice")
+	if err != nil {
+		t.Fatalf("Failed to get user: %v", err)
+	}
+	if alice.Points != PointValues[ActionCommit] || alice.Commits != 1 {
+		t.Errorf("Expected 1 commit from history, got %+v", alice)
+	}
+	alice.Points = 999
+	if err := manager.SaveUser(alice); err != nil {
+		t.Fatalf("Failed to save user: %v", err)
+	}
+	if _, err := repo.CreateCommit("🐛 Fix a", "alice", "", &repository.Tree{Entries: map[string]string{"a.txt": "2"}}); err != nil {
+		t.Fatalf("Failed to create commit: %v", err)
+	}
+	users, err := manager.GetLeaderboard()
+	if err != nil {
+		t.Fatalf("Failed to get leaderboard: %v", err)
+	}
+	// The second commit follows the first within minutes, so it earns half
+	expected := PointValues[ActionCommit] + PointValues[ActionCommit]/2
+	if len(users) != 1 || users[0].Points != expected {
+		t.Errorf("Expected alice with %d points, got %+v", expected, users)
+	}
+}
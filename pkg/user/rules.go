@@ -0,0 +1,405 @@
+package user
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stanlocht/snap/pkg/config"
+	"github.com/stanlocht/snap/pkg/snapmoji"
+)
+
+// RulesFileName is the file in .snap where a team defines its point rules
+const RulesFileName = "rules.json"
+
+// Rule defines the points an action earns
+type Rule struct {
+	Points           int                `json:"points"`
+	PerEstimatedHour float64            `json:"per_estimated_hour,omitempty"` // Replaces Points for actions on issues with an estimate
+	PerFile          float64            `json:"per_file,omitempty"`           // Extra points for every changed file
+	MaxFiles         int                `json:"max_files,omitempty"`          // Changed files counted for PerFile, 0 for all
+	Multipliers      map[string]float64 `json:"multipliers,omitempty"`        // By the snapmoji a commit message starts with
+	Cap              int                `json:"cap,omitempty"`                // Most points one action earns, 0 for no limit
+	DailyCap         int                `json:"daily_cap,omitempty"`          // Most points the action earns a user per day, 0 for no limit
+	RapidMinutes     int                `json:"rapid_minutes,omitempty"`      // Window in which earlier actions make the next one worth less, 0 for none
+	RapidDecay       float64            `json:"rapid_decay,omitempty"`        // What each action in the window multiplies the points by, from 0 to 1
+
+	registry *snapmoji.Registry // Snapmojis the multipliers are for, the defaults if nil
+}
+
+// Rules defines the points every action earns. Actions without a rule can't be recorded.
+type Rules struct {
+	Actions map[Action]*Rule `json:"actions"`
+
+	registry *snapmoji.Registry // Snapmojis multipliers may be given for, the defaults if nil
+}
+
+// Activity describes an action a user performed, for the rules to score
+type Activity struct {
+	Action      Action
+	Description string        // For commits, the commit message
+	Files       int           // Files the action changed
+	Estimate    time.Duration // Estimate of the issue the action was on
+	Timestamp   string        // RFC 3339
+	Email       string        // Email of the user, which the mailmap may know them by
+
+	// For commits
+	Commit   string // ID of the commit
+	Empty    bool   // The commit changed no files
+	Reverts  string // ID of the commit it reverts, if any
+	Reverted bool   // A later commit in history reverts it
+}
+
+// actionName matches the names teams may give their actions
+var actionName = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// DefaultRules returns the rules used when a repository defines none: the flat
+// PointValues, with commits worth half as much for every other commit in the two
+// minutes before them, and at most 150 points of commits a day
+func DefaultRules() *Rules {
+	rules := &Rules{Actions: make(map[Action]*Rule, len(PointValues))}
+	for action, points := range PointValues {
+		rules.Actions[action] = &Rule{Points: points}
+	}
+	commit := rules.Actions[ActionCommit]
+	commit.RapidMinutes, commit.RapidDecay, commit.DailyCap = 2, 0.5, 150
+	return rules
+}
+
+// snapmojis returns a registry, or the default snapmojis if it is nil
+func snapmojis(registry *snapmoji.Registry) *snapmoji.Registry {
+	if registry == nil {
+		return snapmoji.Default()
+	}
+	return registry
+}
+
+// snapmojiKey returns the emoji of a known snapmoji given as an emoji (with or
+// without its variation selector) or a :code:, so multipliers can be written either way
+func snapmojiKey(registry *snapmoji.Registry, key string) (string, bool) {
+	s, ok := snapmojis(registry).Find(key)
+	return s.Emoji, ok
+}
+
+// messageSnapmoji returns the emoji of the snapmoji a commit message starts with, if any
+func messageSnapmoji(registry *snapmoji.Registry, message string) string {
+	s, _ := snapmojis(registry).Match(message)
+	return s.Emoji
+}
+
+// validate checks a rule and writes its multipliers by emoji
+func (r *Rule) validate() error {
+	switch {
+	case r.Points < 0:
+		return fmt.Errorf("points can't be negative")
+	case r.PerEstimatedHour < 0:
+		return fmt.Errorf("per_estimated_hour can't be negative")
+	case r.PerFile < 0:
+		return fmt.Errorf("per_file can't be negative")
+	case r.MaxFiles < 0:
+		return fmt.Errorf("max_files can't be negative")
+	case r.Cap < 0:
+		return fmt.Errorf("cap can't be negative")
+	case r.DailyCap < 0:
+		return fmt.Errorf("daily_cap can't be negative")
+	case r.RapidMinutes < 0:
+		return fmt.Errorf("rapid_minutes can't be negative")
+	case r.RapidDecay < 0 || r.RapidDecay > 1:
+		return fmt.Errorf("rapid_decay must be between 0 and 1")
+	}
+
+	multipliers := make(map[string]float64, len(r.Multipliers))
+	for key, multiplier := range r.Multipliers {
+		emoji, ok := snapmojiKey(r.registry, key)
+		if !ok {
+			return fmt.Errorf("multiplier for %q: not a snapmoji", key)
+		}
+		if multiplier < 0 {
+			return fmt.Errorf("multiplier for %s can't be negative", key)
+		}
+		if _, ok := multipliers[emoji]; ok {
+			return fmt.Errorf("multiplier for %s is set twice", emoji)
+		}
+		multipliers[emoji] = multiplier
+	}
+	if len(multipliers) > 0 {
+		r.Multipliers = multipliers
+	}
+	return nil
+}
+
+// Validate checks that every action has a valid name and rule
+func (rs *Rules) Validate() error {
+	for action, rule := range rs.Actions {
+		if !actionName.MatchString(string(action)) {
+			return fmt.Errorf("invalid action name %q: use lowercase letters, digits and underscores", action)
+		}
+		if rule == nil {
+			return fmt.Errorf("action %s has no rule", action)
+		}
+		rule.registry = rs.registry
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("action %s: %w", action, err)
+		}
+	}
+	return nil
+}
+
+// ParseRules parses a rule file whose multipliers are for the default
+// snapmojis. Unknown fields are rejected so typos don't go unnoticed.
+func ParseRules(data []byte) (*Rules, error) {
+	return parseRules(data, nil)
+}
+
+// ParseRules parses a rule file whose multipliers are for the repository's snapmojis
+func (um *UserManager) ParseRules(data []byte) (*Rules, error) {
+	registry, err := snapmoji.Load(um.RepoPath)
+	if err != nil {
+		return nil, err
+	}
+	return parseRules(data, registry)
+}
+
+// parseRules parses a rule file whose multipliers are for a registry's snapmojis
+func parseRules(data []byte, registry *snapmoji.Registry) (*Rules, error) {
+	rules := Rules{registry: registry}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&rules); err != nil {
+		return nil, fmt.Errorf("invalid rules: %w", err)
+	}
+	if len(rules.Actions) == 0 {
+		return nil, fmt.Errorf("invalid rules: no actions defined")
+	}
+	if err := rules.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid rules: %w", err)
+	}
+	return &rules, nil
+}
+
+// Score returns the points an activity earns under a rule, before the safeguards
+// against gaming, such as the daily cap
+func (r *Rule) Score(activity Activity) int {
+	points := float64(r.Points)
+	estimated := r.PerEstimatedHour > 0 && activity.Estimate > 0
+	if estimated {
+		points = activity.Estimate.Hours() * r.PerEstimatedHour
+	}
+
+	files := activity.Files
+	if r.MaxFiles > 0 && files > r.MaxFiles {
+		files = r.MaxFiles
+	}
+	points += float64(files) * r.PerFile
+
+	if multiplier, ok := r.Multipliers[messageSnapmoji(r.registry, activity.Description)]; ok {
+		points *= multiplier
+	}
+
+	score := int(math.Round(points))
+	if estimated && score < 1 {
+		// Small estimated issues are still worth closing
+		score = 1
+	}
+	if r.Cap > 0 && score > r.Cap {
+		score = r.Cap
+	}
+	return score
+}
+
+// configPath returns the path to the repository's config file
+func (um *UserManager) configPath() string {
+	return filepath.Join(um.RepoPath, ".snap", "config")
+}
+
+// RulesPath returns the path to the repository's rule file
+func (um *UserManager) RulesPath() string {
+	return filepath.Join(um.RepoPath, ".snap", RulesFileName)
+}
+
+// Rules returns the repository's point rules: the defaults, replaced action by
+// action by the rule file, then field by field by [rules "<action>"] sections
+// of the config. Commits by snapmojis without a multiplier get the one their
+// snapmoji defines, if any.
+func (um *UserManager) Rules() (*Rules, error) {
+	registry, err := snapmoji.Load(um.RepoPath)
+	if err != nil {
+		return nil, err
+	}
+	rules := DefaultRules()
+	rules.registry = registry
+
+	// Rule file
+	data, err := os.ReadFile(um.RulesPath())
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read %s: %w", RulesFileName, err)
+	}
+	if err == nil {
+		fileRules, err := parseRules(data, registry)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", RulesFileName, err)
+		}
+		for action, rule := range fileRules.Actions {
+			rules.Actions[action] = rule
+		}
+	}
+
+	// Config
+	if err := um.applyConfigRules(rules); err != nil {
+		return nil, err
+	}
+
+	if err := rules.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid rules in config: %w", err)
+	}
+
+	// Snapmoji multipliers
+	if commit := rules.Actions[ActionCommit]; commit != nil {
+		for _, s := range registry.Snapmojis {
+			if _, ok := commit.Multipliers[s.Emoji]; s.Multiplier > 0 && !ok {
+				if commit.Multipliers == nil {
+					commit.Multipliers = make(map[string]float64)
+				}
+				commit.Multipliers[s.Emoji] = s.Multiplier
+			}
+		}
+	}
+	return rules, nil
+}
+
+// applyConfigRules applies [rules "<action>"] sections and points.estimates from the config
+func (um *UserManager) applyConfigRules(rules *Rules) error {
+	path := um.configPath()
+	actions, err := config.GetSubsections(path, "rules")
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, name := range actions {
+		action := Action(name)
+		rule := rules.Actions[action]
+		if rule == nil {
+			rule = &Rule{}
+			rules.Actions[action] = rule
+		}
+
+		for _, field := range []string{"points", "per_estimated_hour", "per_file", "max_files", "multipliers", "cap", "daily_cap", "rapid_minutes", "rapid_decay"} {
+			key := fmt.Sprintf("rules.%s.%s", name, field)
+			value, err := config.GetValue(path, key)
+			if err != nil {
+				return err
+			}
+			if value == "" {
+				continue
+			}
+			if err := setRuleField(rule, field, value); err != nil {
+				return fmt.Errorf("invalid %s in config: %w", key, err)
+			}
+		}
+	}
+
+	// The shorthand for estimate-based points from before rules could be configured
+	value, err := config.GetValue(path, EstimatePointsConfigKey)
+	if err != nil {
+		return err
+	}
+	if value != "" {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid %s in config: expected true or false", EstimatePointsConfigKey)
+		}
+		if rule := rules.Actions[ActionIssueClose]; enabled && rule != nil && rule.PerEstimatedHour == 0 {
+			rule.PerEstimatedHour = PointsPerEstimatedHour
+		}
+	}
+
+	return nil
+}
+
+// setRuleField sets a rule field from a config value
+func setRuleField(rule *Rule, field, value string) error {
+	var err error
+	switch field {
+	case "points":
+		rule.Points, err = strconv.Atoi(value)
+	case "max_files":
+		rule.MaxFiles, err = strconv.Atoi(value)
+	case "cap":
+		rule.Cap, err = strconv.Atoi(value)
+	case "daily_cap":
+		rule.DailyCap, err = strconv.Atoi(value)
+	case "rapid_minutes":
+		rule.RapidMinutes, err = strconv.Atoi(value)
+	case "rapid_decay":
+		rule.RapidDecay, err = strconv.ParseFloat(value, 64)
+	case "per_estimated_hour":
+		rule.PerEstimatedHour, err = strconv.ParseFloat(value, 64)
+	case "per_file":
+		rule.PerFile, err = strconv.ParseFloat(value, 64)
+	case "multipliers":
+		// e.g. "🔒=2, :bug:=1.5"
+		rule.Multipliers = make(map[string]float64)
+		for _, item := range strings.Split(value, ",") {
+			key, number, ok := strings.Cut(item, "=")
+			if !ok {
+				return fmt.Errorf("expected snapmoji=multiplier, got %q", strings.TrimSpace(item))
+			}
+			multiplier, err := strconv.ParseFloat(strings.TrimSpace(number), 64)
+			if err != nil {
+				return fmt.Errorf("invalid multiplier %q", strings.TrimSpace(number))
+			}
+			rule.Multipliers[strings.TrimSpace(key)] = multiplier
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("invalid number %q", value)
+	}
+	return nil
+}
+
+// SortedActions returns the actions with a rule, ordered by name
+func (rs *Rules) SortedActions() []Action {
+	actions := make([]Action, 0, len(rs.Actions))
+	for action := range rs.Actions {
+		actions = append(actions, action)
+	}
+	sort.Slice(actions, func(i, j int) bool { return actions[i] < actions[j] })
+	return actions
+}
+
+// earnedOn returns the points a user earned for an action on the day of a timestamp
+func (u *User) earnedOn(action Action, timestamp string) int {
+	day, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		return 0
+	}
+	year, month, date := day.Date()
+
+	total := 0
+	for _, record := range u.ActionLog {
+		if record.Action != action {
+			continue
+		}
+		at, err := time.Parse(time.RFC3339, record.Timestamp)
+		if err != nil {
+			continue
+		}
+		if y, m, d := at.In(day.Location()).Date(); y == year && m == month && d == date {
+			total += record.Points
+		}
+	}
+	return total
+}
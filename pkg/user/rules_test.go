@@ -0,0 +1,215 @@
+package user
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stanlocht/snap/pkg/snapmoji"
+)
+
+const testRules = `{
+  "actions": {
+    "commit": {
+      "points": 10,
+      "per_file": 1,
+      "max_files": 5,
+      "multipliers": {"🔒": 2, ":bug:": 1.5, "⚡": 1.2},
+      "cap": 25,
+      "daily_cap": 40
+    },
+    "review": {"points": 8}
+  }
+}`
+
+func TestParseRules(t *testing.T) {
+	rules, err := ParseRules([]byte(testRules))
+	if err != nil {
+		t.Fatalf("Failed to parse rules: %v", err)
+	}
+	commit := rules.Actions[ActionCommit]
+	if commit == nil || commit.Multipliers["🐛"] != 1.5 || commit.Multipliers["⚡️"] != 1.2 {
+		t.Errorf("Expected multipliers by emoji, got %+v", commit)
+	}
+	if rules.Actions["review"].Points != 8 {
+		t.Errorf("Expected a custom review action")
+	}
+
+	invalid := map[string]string{
+		"not json":         `{`,
+		"no actions":       `{"actions": {}}`,
+		"unknown field":    `{"actions": {"commit": {"point": 10}}}`,
+		"negative points":  `{"actions": {"commit": {"points": -1}}}`,
+		"unknown snapmoji": `{"actions": {"commit": {"points": 1, "multipliers": {"🦄": 2}}}}`,
+		"same snapmoji":    `{"actions": {"commit": {"points": 1, "multipliers": {"🔒": 2, ":lock:": 3}}}}`,
+		"bad action name":  `{"actions": {"Code Review": {"points": 1}}}`,
+		"decay over 1":     `{"actions": {"commit": {"points": 1, "rapid_minutes": 2, "rapid_decay": 2}}}`,
+	}
+	for name, data := range invalid {
+		if _, err := ParseRules([]byte(data)); err == nil {
+			t.Errorf("Expected rules with %s to be invalid", name)
+		}
+	}
+}
+
+func TestRuleScore(t *testing.T) {
+	rules, err := ParseRules([]byte(testRules))
+	if err != nil {
+		t.Fatalf("Failed to parse rules: %v", err)
+	}
+	commit := rules.Actions[ActionCommit]
+
+	tests := []struct {
+		activity Activity
+		expected int
+	}{
+		{Activity{Description: "✨ Add search"}, 10},
+		{Activity{Description: "✨ Add search", Files: 3}, 13},
+		{Activity{Description: "✨ Add search", Files: 50}, 15},    // Files counted up to max_files
+		{Activity{Description: ":bug: Fix crash", Files: 2}, 18},  // 12 × 1.5
+		{Activity{Description: "🔒 Escape input", Files: 5}, 25},   // 30, capped
+		{Activity{Description: "⚡️ Cache results"}, 12},           // Variation selector or not
+		{Activity{Description: "Fix 🔒 later in the message"}, 10}, // Only a leading snapmoji counts
+	}
+	for _, test := range tests {
+		if score := commit.Score(test.activity); score != test.expected {
+			t.Errorf("Expected %q with %d files to score %d, got %d", test.activity.Description, test.activity.Files, test.expected, score)
+		}
+	}
+
+	estimated := &Rule{Points: 15, PerEstimatedHour: 5}
+	if score := estimated.Score(Activity{Estimate: 4 * time.Hour}); score != 20 {
+		t.Errorf("Expected 4 estimated hours to score 20, got %d", score)
+	}
+	if score := estimated.Score(Activity{}); score != 15 {
+		t.Errorf("Expected no estimate to score the flat points, got %d", score)
+	}
+}
+
+func TestRecordWithRules(t *testing.T) {
+	tempDir := t.TempDir()
+	manager := NewUserManager(tempDir)
+
+	// Without rules the defaults apply, and unknown actions are rejected
+	if err := manager.RecordAction("alice", "review", "Reviewed", "2025-01-01T10:00:00Z"); err == nil {
+		t.Errorf("Expected an unknown action to be rejected")
+	}
+
+	if err := os.MkdirAll(filepath.Join(tempDir, ".snap"), 0755); err != nil {
+		t.Fatalf("Failed to create .snap directory: %v", err)
+	}
+	if err := os.WriteFile(manager.RulesPath(), []byte(testRules), 0644); err != nil {
+		t.Fatalf("Failed to write rules: %v", err)
+	}
+
+	// The rule file replaces the commit rule, adds review and keeps the other defaults
+	rules, err := manager.Rules()
+	if err != nil {
+		t.Fatalf("Failed to get rules: %v", err)
+	}
+	if rules.Actions["review"] == nil || rules.Actions[ActionIssueComment].Points != PointValues[ActionIssueComment] {
+		t.Errorf("Expected review and the default comment rule, got %v", rules.SortedActions())
+	}
+	if err := manager.RecordAction("alice", "review", "Reviewed", "2025-01-01T10:00:00Z"); err != nil {
+		t.Errorf("Failed to record a custom action: %v", err)
+	}
+
+	// The daily cap limits what commits earn in a day
+	var earned []int
+	for _, timestamp := range []string{"2025-01-01T09:00:00Z", "2025-01-01T10:00:00Z", "2025-01-01T11:00:00Z", "2025-01-02T09:00:00Z"} {
+		points, err := manager.Record("alice", Activity{Action: ActionCommit, Description: "🔒 Fix", Files: 5, Timestamp: timestamp})
+		if err != nil {
+			t.Fatalf("Failed to record commit: %v", err)
+		}
+		earned = append(earned, points)
+	}
+	if earned[0] != 25 || earned[1] != 15 || earned[2] != 0 || earned[3] != 25 {
+		t.Errorf("Expected 25, 15, 0 and 25 points, got %v", earned)
+	}
+	user, err := manager.GetUser("alice")
+	if err != nil {
+		t.Fatalf("Failed to get user: %v", err)
+	}
+	if user.Commits != 4 || user.Points != 8+25+15+0+25 {
+		t.Errorf("Expected 4 commits and 73 points, got %d and %d", user.Commits, user.Points)
+	}
+
+	// Config overrides single fields
+	config := "[rules \"commit\"]\n\tpoints = 20\n\tmultipliers = 🐛=3\n[rules \"pair\"]\n\tpoints = 4\n"
+	if err := os.WriteFile(filepath.Join(tempDir, ".snap", "config"), []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	rules, err = manager.Rules()
+	if err != nil {
+		t.Fatalf("Failed to get rules: %v", err)
+	}
+	commit := rules.Actions[ActionCommit]
+	if commit.Points != 20 || commit.PerFile != 1 || len(commit.Multipliers) != 1 || commit.Multipliers["🐛"] != 3 || rules.Actions["pair"].Points != 4 {
+		t.Errorf("Expected the config to override the rule file, got %+v", commit)
+	}
+
+	for _, config := range []string{"[rules \"commit\"]\n\tpoints = lots\n", "[rules \"commit\"]\n\tmultipliers = 🦄=2\n"} {
+		if err := os.WriteFile(filepath.Join(tempDir, ".snap", "config"), []byte(config), 0644); err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+		if _, err := manager.Rules(); err == nil {
+			t.Errorf("Expected config %q to be invalid", strings.TrimSpace(config))
+		}
+	}
+
+	// A broken rule file is reported rather than ignored
+	if err := os.Remove(filepath.Join(tempDir, ".snap", "config")); err != nil {
+		t.Fatalf("Failed to remove config: %v", err)
+	}
+	if err := os.WriteFile(manager.RulesPath(), []byte(`{"actions": {"commit": {"points": "ten"}}}`), 0644); err != nil {
+		t.Fatalf("Failed to write rules: %v", err)
+	}
+	if err := manager.RecordAction("alice", ActionCommit, "✨ Add", "2025-01-03T09:00:00Z"); err == nil || !strings.Contains(err.Error(), RulesFileName) {
+		t.Errorf("Expected an error about %s, got %v", RulesFileName, err)
+	}
+}
+
+func TestSnapmojiMultipliers(t *testing.T) {
+	tempDir := t.TempDir()
+	manager := NewUserManager(tempDir)
+	if err := os.MkdirAll(filepath.Join(tempDir, ".snap"), 0755); err != nil {
+		t.Fatalf("Failed to create .snap directory: %v", err)
+	}
+	registry := `{"snapmojis": [{"emoji": "🦄", "code": ":unicorn:", "description": "Magic", "multiplier": 2}]}`
+	if err := os.WriteFile(snapmoji.RegistryPath(tempDir), []byte(registry), 0644); err != nil {
+		t.Fatalf("Failed to write snapmojis: %v", err)
+	}
+
+	// Commits by a custom snapmoji earn its multiplier, whether written as emoji or code
+	for i, message := range []string{"🦄 Add magic", ":unicorn: More magic"} {
+		timestamp := time.Date(2025, 1, 1, 10+i, 0, 0, 0, time.UTC).Format(time.RFC3339)
+		points, err := manager.Record("alice", Activity{Action: ActionCommit, Description: message, Timestamp: timestamp})
+		if err != nil {
+			t.Fatalf("Failed to record commit: %v", err)
+		}
+		if points != 20 {
+			t.Errorf("Expected 20 points for %q, got %d", message, points)
+		}
+	}
+
+	// The rules may give custom snapmojis multipliers too, which win over the snapmoji's own
+	config := "[rules \"commit\"]\n\tmultipliers = :unicorn:=3\n"
+	if err := os.WriteFile(filepath.Join(tempDir, ".snap", "config"), []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	rules, err := manager.Rules()
+	if err != nil {
+		t.Fatalf("Failed to get rules: %v", err)
+	}
+	if score := rules.Actions[ActionCommit].Score(Activity{Action: ActionCommit, Description: ":unicorn: Magic"}); score != 30 {
+		t.Errorf("Expected 30 points, got %d", score)
+	}
+	if _, err := manager.ParseRules([]byte(`{"actions": {"commit": {"points": 10, "multipliers": {"🦄": 2}}}}`)); err != nil {
+		t.Errorf("Expected a rule file with a custom snapmoji to be valid, got %v", err)
+	}
+	if _, err := ParseRules([]byte(`{"actions": {"commit": {"points": 10, "multipliers": {"🦄": 2}}}}`)); err == nil {
+		t.Errorf("Expected a custom snapmoji to be unknown without the repository")
+	}
+}
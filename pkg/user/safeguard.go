@@ -0,0 +1,217 @@
+package user
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Flag marks an action whose points the safeguards against gaming changed
+type Flag string
+
+const (
+	// FlagEmpty marks a commit that changed no files, which earns nothing
+	FlagEmpty Flag = "empty"
+	// FlagRevert marks a commit reverting another, which earns nothing
+	FlagRevert Flag = "revert"
+	// FlagReverted marks a commit whose points were taken back when it was reverted
+	FlagReverted Flag = "reverted"
+	// FlagPopped marks a commit whose points were taken back when it was undone with snap pop
+	FlagPopped Flag = "popped"
+	// FlagRapid marks an action that earned less for following others of its kind quickly
+	FlagRapid Flag = "rapid"
+	// FlagCapped marks an action that earned less because of its rule's daily cap
+	FlagCapped Flag = "capped"
+)
+
+// Flags lists the flags in the order reports show them
+var Flags = []Flag{FlagEmpty, FlagRapid, FlagCapped, FlagRevert, FlagReverted, FlagPopped}
+
+// Describe names the actions a flag marks, e.g. "empty commits"
+func (f Flag) Describe() string {
+	switch f {
+	case FlagEmpty:
+		return "empty commits"
+	case FlagRevert:
+		return "reverts"
+	case FlagReverted:
+		return "reverted commits"
+	case FlagPopped:
+		return "popped commits"
+	case FlagRapid:
+		return "rapid-fire actions"
+	case FlagCapped:
+		return "actions over a daily cap"
+	}
+	return string(f)
+}
+
+// voids reports whether a flag means the action doesn't count as a contribution at all
+func (f Flag) voids() bool {
+	return f == FlagEmpty || f == FlagRevert || f == FlagReverted || f == FlagPopped
+}
+
+// voided reports whether a commit doesn't count as a contribution at all
+func (a Activity) voided() bool {
+	return a.Empty || a.Reverts != "" || a.Reverted
+}
+
+// revertMarker matches the line git writes in the message of a revert
+var revertMarker = regexp.MustCompile(`This reverts commit ([0-9a-f]{7,64})`)
+
+// RevertedCommit returns the ID, possibly abbreviated, of the commit a commit
+// message says it reverts ("This reverts commit <id>."), or "" if it reverts none
+func RevertedCommit(message string) string {
+	if match := revertMarker.FindStringSubmatch(message); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+// safeguardedScore returns the points an activity earns under a rule once the
+// safeguards are applied, and the flag marking how they changed it, if they did
+func (u *User) safeguardedScore(rule *Rule, activity Activity) (int, Flag) {
+	switch {
+	case activity.Empty:
+		return 0, FlagEmpty
+	case activity.Reverts != "":
+		return 0, FlagRevert
+	case activity.Reverted:
+		return 0, FlagReverted
+	}
+
+	points := rule.Score(activity)
+	var flag Flag
+
+	// Every recent action of the same kind makes the next one worth less
+	if rule.RapidMinutes > 0 {
+		if n := u.recentActions(activity, time.Duration(rule.RapidMinutes)*time.Minute); n > 0 {
+			decayed := int(math.Round(float64(points) * math.Pow(rule.RapidDecay, float64(n))))
+			if decayed < points {
+				points, flag = decayed, FlagRapid
+			}
+		}
+	}
+
+	if rule.DailyCap > 0 {
+		left := rule.DailyCap - u.earnedOn(activity.Action, activity.Timestamp)
+		if left < 0 {
+			left = 0
+		}
+		if points > left {
+			points, flag = left, FlagCapped
+		}
+	}
+	return points, flag
+}
+
+// recentActions counts the logged actions of an activity's kind that count as
+// contributions and happened within a window before it
+func (u *User) recentActions(activity Activity, window time.Duration) int {
+	at, err := time.Parse(time.RFC3339, activity.Timestamp)
+	if err != nil {
+		return 0
+	}
+	count := 0
+	for _, record := range u.ActionLog {
+		if record.Action != activity.Action || record.Flag.voids() {
+			continue
+		}
+		recordAt, err := time.Parse(time.RFC3339, record.Timestamp)
+		if err == nil && !recordAt.After(at) && at.Sub(recordAt) < window {
+			count++
+		}
+	}
+	return count
+}
+
+// Clawback takes back the points a commit earned when it is undone or
+// reverted, flagging its record with FlagPopped or FlagReverted. The commit
+// may be given by an abbreviated ID. It returns the user who lost the points
+// and how many, or no user if no recorded commit matches. When the user files
+// are a cache, rebuilding them from history takes the points back instead.
+func (um *UserManager) Clawback(commitID string, flag Flag) (string, int, error) {
+	if len(commitID) < 7 {
+		return "", 0, fmt.Errorf("commit ID %q is too short", commitID)
+	}
+	cached, err := um.CacheEnabled()
+	if err != nil || cached {
+		return "", 0, err
+	}
+
+	users, err := um.storedUsers()
+	if err != nil {
+		return "", 0, err
+	}
+	for _, user := range users {
+		for i := range user.ActionLog {
+			record := &user.ActionLog[i]
+			if record.Action != ActionCommit || record.Flag.voids() || !strings.HasPrefix(record.Commit, commitID) {
+				continue
+			}
+			points := record.Points
+			user.Points -= points
+			user.Commits--
+			record.Points = 0
+			record.Flag = flag
+			if err := um.SaveUser(user); err != nil {
+				return "", 0, err
+			}
+			return user.Name, points, nil
+		}
+	}
+	return "", 0, nil
+}
+
+// Suspicion sums up the actions of a user that the safeguards flagged
+type Suspicion struct {
+	Name    string
+	Flags   map[Flag]int // Flagged actions by flag
+	Total   int
+	Last    time.Time // The latest flagged action
+	Commits int       // Commits that count, for comparison
+}
+
+// Audit reports the users whose actions in a window the safeguards flagged,
+// those with the most flagged actions first
+func (um *UserManager) Audit(window Window) ([]Suspicion, error) {
+	users, err := um.GetLeaderboard()
+	if err != nil {
+		return nil, err
+	}
+
+	var suspicions []Suspicion
+	for _, u := range users {
+		suspicion := Suspicion{Name: u.Name, Flags: make(map[Flag]int)}
+		for _, record := range u.ActionLog {
+			at := parseTimestamp(record.Timestamp)
+			if (!window.Since.IsZero() && at.Before(window.Since)) || (!window.Until.IsZero() && !at.Before(window.Until)) {
+				continue
+			}
+			if record.Action == ActionCommit && !record.Flag.voids() {
+				suspicion.Commits++
+			}
+			if record.Flag == "" {
+				continue
+			}
+			suspicion.Flags[record.Flag]++
+			suspicion.Total++
+			if at.After(suspicion.Last) {
+				suspicion.Last = at
+			}
+		}
+		if suspicion.Total > 0 {
+			suspicions = append(suspicions, suspicion)
+		}
+	}
+	sort.SliceStable(suspicions, func(i, j int) bool {
+		if suspicions[i].Total != suspicions[j].Total {
+			return suspicions[i].Total > suspicions[j].Total
+		}
+		return suspicions[i].Name < suspicions[j].Name
+	})
+	return suspicions, nil
+}
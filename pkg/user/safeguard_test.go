@@ -0,0 +1,122 @@
+package user
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stanlocht/snap/pkg/repository"
+)
+
+func TestSafeguards(t *testing.T) {
+	manager := NewUserManager(t.TempDir())
+
+	record := func(activity Activity) int {
+		activity.Action = ActionCommit
+		points, err := manager.Record("mallory", activity)
+		if err != nil {
+			t.Fatalf("Failed to record commit: %v", err)
+		}
+		return points
+	}
+
+	// Empty commits earn nothing and don't count
+	if points := record(Activity{Description: "✨ Nothing", Empty: true, Timestamp: "2025-01-01T09:00:00Z"}); points != 0 {
+		t.Errorf("Expected no points for an empty commit, got %d", points)
+	}
+
+	// Each commit in the two minutes before halves the points
+	var got []int
+	for _, timestamp := range []string{"2025-01-01T10:00:00Z", "2025-01-01T10:00:30Z", "2025-01-01T10:01:00Z", "2025-01-01T10:05:00Z"} {
+		got = append(got, record(Activity{Description: "✨ Add", Timestamp: timestamp}))
+	}
+	if got[0] != 10 || got[1] != 5 || got[2] != 3 || got[3] != 10 {
+		t.Errorf("Expected 10, 5, 3 and 10 points, got %v", got)
+	}
+
+	// The daily cap stops scripted commits spread over the day
+	for hour := 11; hour < 24; hour++ {
+		record(Activity{Description: "✨ Add", Timestamp: time.Date(2025, 1, 1, hour, 0, 0, 0, time.UTC).Format(time.RFC3339)})
+	}
+	mallory, err := manager.GetUser("mallory")
+	if err != nil {
+		t.Fatalf("Failed to get user: %v", err)
+	}
+	if mallory.Points != 150 || mallory.Commits != 17 {
+		t.Errorf("Expected 150 points for 17 counted commits, got %d and %d", mallory.Points, mallory.Commits)
+	}
+	if last := mallory.ActionLog[len(mallory.ActionLog)-1]; last.Flag != FlagCapped || last.Points != 2 {
+		t.Errorf("Expected the last commit to be capped at the 2 points left, got %+v", last)
+	}
+
+	suspicions, err := manager.Audit(Window{})
+	if err != nil {
+		t.Fatalf("Failed to audit: %v", err)
+	}
+	if len(suspicions) != 1 || suspicions[0].Flags[FlagEmpty] != 1 || suspicions[0].Flags[FlagRapid] != 2 || suspicions[0].Flags[FlagCapped] == 0 {
+		t.Errorf("Expected mallory's empty, rapid and capped commits to be reported, got %+v", suspicions)
+	}
+}
+
+func TestClawback(t *testing.T) {
+	tempDir := t.TempDir()
+	repo, err := repository.Init(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	manager := NewUserManager(tempDir)
+
+	// commit creates a commit and records it, as snap commit does
+	commit := func(author, message string, entries map[string]string) *repository.Commit {
+		head, err := repo.GetHEADCommitID()
+		if err != nil {
+			t.Fatalf("Failed to get HEAD: %v", err)
+		}
+		tree := &repository.Tree{Entries: entries}
+		changed, err := repo.ChangedFiles(head, tree)
+		if err != nil {
+			t.Fatalf("Failed to get changed files: %v", err)
+		}
+		c, err := repo.CreateCommit(message, author, "", tree)
+		if err != nil {
+			t.Fatalf("Failed to create commit: %v", err)
+		}
+		activity := Activity{
+			Action:      ActionCommit,
+			Description: message,
+			Files:       len(changed),
+			Timestamp:   c.Timestamp.Format(time.RFC3339),
+			Commit:      c.ID,
+			Empty:       len(changed) == 0,
+			Reverts:     RevertedCommit(message),
+		}
+		if _, err := manager.Record(author, activity); err != nil {
+			t.Fatalf("Failed to record commit: %v", err)
+		}
+		return c
+	}
+
+	commit("alice", "✨ Add a", map[string]string{"a.txt": "1"})
+	broken := commit("bob", "✨ Add b", map[string]string{"a.txt": "1", "b.txt": "2"})
+	commit("alice", "🔥 Revert b\n\nThis reverts commit "+broken.ID[:7]+".", map[string]string{"a.txt": "1"})
+
+	// The revert earns nothing and takes back bob's points
+	name, points, err := manager.Clawback(broken.ID[:7], FlagReverted)
+	if err != nil || name != "bob" || points != 10 {
+		t.Fatalf("Expected bob's 10 points to be taken back, got %q, %d (%v)", name, points, err)
+	}
+	if name, _, err := manager.Clawback(broken.ID, FlagReverted); err != nil || name != "" {
+		t.Errorf("Expected points to be taken back only once, got %q (%v)", name, err)
+	}
+	bob, err := manager.GetUser("bob")
+	if err != nil {
+		t.Fatalf("Failed to get user: %v", err)
+	}
+	if bob.Points != 0 || bob.Commits != 0 || bob.ActionLog[0].Flag != FlagReverted {
+		t.Errorf("Expected bob's commit to be reverted, got %+v", bob)
+	}
+
+	// History agrees: the revert is found from the commit message
+	if drifts, err := manager.CheckDrift(); err != nil || len(drifts) != 0 {
+		t.Errorf("Expected no drift, got %v (%v)", drifts, err)
+	}
+}
@@ -0,0 +1,198 @@
+package user
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/stanlocht/snap/pkg/config"
+)
+
+// Team is a group of users configured with [team "<name>"] members in the config
+type Team struct {
+	Name    string
+	Members []string // Canonical names
+}
+
+// teamName matches the names teams may have, which are used in issue assignees such as team:backend
+var teamName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Has reports whether a user is a member of a team
+func (t *Team) Has(name string) bool {
+	for _, member := range t.Members {
+		if member == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Teams returns the teams configured in the config, ordered by name. Members
+// are listed by name or email, e.g. "alice, bob@example.com", and resolved to
+// canonical identities through the mailmap and the emails users committed with.
+// Members known by an email nobody has used yet are left out.
+func (um *UserManager) Teams() ([]*Team, error) {
+	path := um.configPath()
+	names, err := config.GetSubsections(path, "team")
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	mailmap, err := um.Mailmap()
+	if err != nil {
+		return nil, err
+	}
+	stored, err := um.storedUsers()
+	if err != nil {
+		return nil, err
+	}
+
+	var teams []*Team
+	for _, name := range names {
+		if !teamName.MatchString(name) {
+			return nil, fmt.Errorf("invalid team name %q", name)
+		}
+		value, err := config.GetValue(path, fmt.Sprintf("team.%s.members", name))
+		if err != nil {
+			return nil, err
+		}
+
+		team := &Team{Name: name}
+		for _, member := range strings.Split(value, ",") {
+			member = strings.Trim(strings.TrimSpace(member), "<>")
+			if member == "" {
+				continue
+			}
+			var identity Identity
+			if strings.Contains(member, "@") {
+				identity = mailmap.Resolve("", member)
+				for _, u := range stored {
+					if identity.Name == "" && strings.EqualFold(u.Email, identity.Email) {
+						identity.Name = u.Name
+					}
+				}
+			} else {
+				identity = mailmap.Resolve(member, "")
+			}
+			if identity.Name != "" && !team.Has(identity.Name) {
+				team.Members = append(team.Members, identity.Name)
+			}
+		}
+		sort.Strings(team.Members)
+		teams = append(teams, team)
+	}
+	sort.Slice(teams, func(i, j int) bool { return teams[i].Name < teams[j].Name })
+	return teams, nil
+}
+
+// GetTeam returns a configured team by name
+func (um *UserManager) GetTeam(name string) (*Team, error) {
+	teams, err := um.Teams()
+	if err != nil {
+		return nil, err
+	}
+	for _, team := range teams {
+		if team.Name == name {
+			return team, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown team %q: configure it with snap config set team.%s.members \"alice, bob\"", name, name)
+}
+
+// TeamsOf returns the teams a user is a member of
+func (um *UserManager) TeamsOf(name string) ([]*Team, error) {
+	teams, err := um.Teams()
+	if err != nil {
+		return nil, err
+	}
+	var memberOf []*Team
+	for _, team := range teams {
+		if team.Has(name) {
+			memberOf = append(memberOf, team)
+		}
+	}
+	return memberOf, nil
+}
+
+// TeamLeaderboard ranks teams in a category for the actions in a window by
+// their members' combined scores, highest first. Teams without a score are
+// left out, except on the lifetime points board.
+func (um *UserManager) TeamLeaderboard(category Category, window Window) ([]Standing, error) {
+	teams, err := um.Teams()
+	if err != nil {
+		return nil, err
+	}
+	users, err := um.GetLeaderboard()
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]*User, len(users))
+	for _, u := range users {
+		byName[u.Name] = u
+	}
+
+	lifetime := category == CategoryPoints && window.Since.IsZero() && window.Until.IsZero()
+	standings := []Standing{}
+	for _, team := range teams {
+		score := 0
+		for _, member := range team.Members {
+			if u := byName[member]; u != nil {
+				score += u.score(category, window)
+			}
+		}
+		if score > 0 || lifetime {
+			standings = append(standings, Standing{Name: team.Name, Score: score})
+		}
+	}
+	sortStandings(standings)
+	return standings, nil
+}
+
+// sortStandings orders standings by score, highest first, then by name
+func sortStandings(standings []Standing) {
+	sort.SliceStable(standings, func(i, j int) bool {
+		if standings[i].Score != standings[j].Score {
+			return standings[i].Score > standings[j].Score
+		}
+		return standings[i].Name < standings[j].Name
+	})
+}
+
+// TeamStats returns a team as a user whose stats and action log are its
+// members' combined, with the achievements the team earned together, so a
+// team's streaks and heatmap follow from it as a user's do
+func (um *UserManager) TeamStats(team *Team) (*User, error) {
+	users, err := um.GetLeaderboard()
+	if err != nil {
+		return nil, err
+	}
+	stats := &User{Name: team.Name, ActionLog: []ActionRecord{}}
+	for _, u := range users {
+		if team.Has(u.Name) {
+			stats.merge(u)
+		}
+	}
+	stats.Email = ""
+	stats.Achievements = nil
+
+	contributions, err := um.commitContributions()
+	if err != nil {
+		return nil, err
+	}
+	var commits []Activity
+	for _, c := range contributions {
+		if team.Has(c.Name) && !c.Activity.voided() {
+			commits = append(commits, c.Activity)
+		}
+	}
+	sort.SliceStable(commits, func(i, j int) bool {
+		return activityTime(commits[i]).Before(activityTime(commits[j]))
+	})
+	stats.evaluateAchievements(commits)
+	return stats, nil
+}
@@ -0,0 +1,94 @@
+package user
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stanlocht/snap/pkg/repository"
+)
+
+func TestTeams(t *testing.T) {
+	tempDir := t.TempDir()
+	manager := NewUserManager(tempDir)
+	if _, err := repository.Init(tempDir); err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	config := "[user]\n\ttimezone = UTC\n" +
+		"[team \"backend\"]\n\tmembers = alice, <bob@example.com>\n" +
+		"[team \"frontend\"]\n\tmembers = carol, nobody@example.com\n" +
+		"[season \"spring\"]\n\tstart = 2026-03-01\n\tend = 2026-05-31\n"
+	if err := os.WriteFile(filepath.Join(tempDir, ".snap", "config"), []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, MailmapFileName), []byte("Alice Smith <alice@example.com> alice\n"), 0644); err != nil {
+		t.Fatalf("Failed to write mailmap: %v", err)
+	}
+
+	// Bob is known by the email he committed with
+	if err := manager.SaveUser(&User{Name: "Bob", Email: "bob@example.com", ActionLog: []ActionRecord{}}); err != nil {
+		t.Fatalf("Failed to save user: %v", err)
+	}
+	for _, timestamp := range []string{"2026-04-01T10:00:00Z", "2026-04-02T10:00:00Z"} {
+		if err := manager.RecordAction("alice", ActionCommit, ":bug: Fix crash", timestamp); err != nil {
+			t.Fatalf("Failed to record action: %v", err)
+		}
+	}
+	if err := manager.RecordAction("Bob", ActionIssueClose, "Closed issue #1", "2026-04-03T10:00:00Z"); err != nil {
+		t.Fatalf("Failed to record action: %v", err)
+	}
+	if err := manager.RecordAction("carol", ActionCommit, "✨ Add page", "2026-06-01T10:00:00Z"); err != nil {
+		t.Fatalf("Failed to record action: %v", err)
+	}
+
+	// Members resolve to canonical identities; unknown emails are left out
+	teams, err := manager.Teams()
+	if err != nil {
+		t.Fatalf("Failed to get teams: %v", err)
+	}
+	if len(teams) != 2 || strings.Join(teams[0].Members, ",") != "Alice Smith,Bob" || strings.Join(teams[1].Members, ",") != "carol" {
+		t.Fatalf("Expected backend with Alice Smith and Bob, and frontend with carol, got %+v and %+v", teams[0], teams[1])
+	}
+	if memberOf, err := manager.TeamsOf("Bob"); err != nil || len(memberOf) != 1 || memberOf[0].Name != "backend" {
+		t.Errorf("Expected Bob to be in backend, got %v (%v)", memberOf, err)
+	}
+	if _, err := manager.GetTeam("design"); err == nil {
+		t.Errorf("Expected an unknown team to be rejected")
+	}
+
+	// Teams are ranked by their members' combined scores
+	standings, err := manager.TeamLeaderboard(CategoryPoints, Window{})
+	if err != nil {
+		t.Fatalf("Failed to get team leaderboard: %v", err)
+	}
+	if len(standings) != 2 || standings[0] != (Standing{Name: "backend", Score: 35}) || standings[1] != (Standing{Name: "frontend", Score: 10}) {
+		t.Errorf("Expected backend with 35 points and frontend with 10, got %v", standings)
+	}
+
+	// Ended seasons rank and archive the team standings too
+	now := time.Date(2026, 6, 15, 0, 0, 0, 0, time.UTC)
+	standings, final, err := manager.SeasonTeamLeaderboard("spring", CategoryCommits, now)
+	if err != nil {
+		t.Fatalf("Failed to get season team leaderboard: %v", err)
+	}
+	if !final || len(standings) != 1 || standings[0] != (Standing{Name: "backend", Score: 2}) {
+		t.Errorf("Expected backend's 2 commits as final standings, got %v (final %v)", standings, final)
+	}
+	if archive, err := manager.ArchiveSeason("spring", now); err != nil || len(archive.Teams[CategoryCommits]) != 1 {
+		t.Errorf("Expected the team board in the archive, got %+v (%v)", archive, err)
+	}
+
+	// A team's stats combine its members', with streaks across them
+	stats, err := manager.TeamStats(teams[0])
+	if err != nil {
+		t.Fatalf("Failed to get team stats: %v", err)
+	}
+	if stats.Points != 35 || stats.Commits != 2 || stats.IssuesClosed != 1 {
+		t.Errorf("Expected 35 points, 2 commits and 1 issue closed, got %+v", stats)
+	}
+	if _, longest := stats.Streaks(time.UTC, now); longest != 3 {
+		t.Errorf("Expected a longest team streak of 3 days, got %d", longest)
+	}
+}
@@ -6,7 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
-	"strings"
+	"time"
 )
 
 // Action represents a user action that earns points
@@ -21,16 +21,28 @@ const (
 	ActionIssueClose Action = "issue_close"
 	// ActionIssueAssign represents an issue assignment action
 	ActionIssueAssign Action = "issue_assign"
+	// ActionIssueComment represents a comment on an issue
+	ActionIssueComment Action = "issue_comment"
 )
 
-// PointValues defines the point values for different actions
+// PointValues defines the default point values for different actions; see Rules
 var PointValues = map[Action]int{
-	ActionCommit:      10,
-	ActionIssueCreate: 5,
-	ActionIssueClose:  15,
-	ActionIssueAssign: 5,
+	ActionCommit:       10,
+	ActionIssueCreate:  5,
+	ActionIssueClose:   15,
+	ActionIssueAssign:  5,
+	ActionIssueComment: 2,
 }
 
+// EstimatePointsConfigKey is the config key that, when true, makes closing an issue
+// with an estimate earn PointsPerEstimatedHour for every estimated hour instead
+// of the flat ActionIssueClose value, unless the issue_close rule says otherwise
+const EstimatePointsConfigKey = "points.estimates"
+
+// PointsPerEstimatedHour is what each estimated hour of a closed issue is worth
+// when points follow estimates, so a 3h issue earns the same as a flat close
+const PointsPerEstimatedHour = 5
+
 // User represents a user in the repository
 type User struct {
 	Name         string         `json:"name"`
@@ -40,6 +52,9 @@ type User struct {
 	Commits      int            `json:"commits"`
 	IssuesOpen   int            `json:"issues_open"`
 	IssuesClosed int            `json:"issues_closed"`
+	Comments     int            `json:"comments"`
+
+	Achievements []EarnedAchievement `json:"achievements,omitempty"`
 }
 
 // ActionRecord represents a record of a user action
@@ -48,6 +63,8 @@ type ActionRecord struct {
 	Points      int    `json:"points"`
 	Description string `json:"description"`
 	Timestamp   string `json:"timestamp"`
+	Commit      string `json:"commit,omitempty"` // ID of the commit, for commits
+	Flag        Flag   `json:"flag,omitempty"`   // How the safeguards against gaming changed the points
 }
 
 // UserManager manages users in a repository
@@ -69,11 +86,28 @@ func (um *UserManager) getUsersDir() string {
 
 // getUserFilePath returns the path to a user file
 func (um *UserManager) getUserFilePath(name string) string {
-	return filepath.Join(um.getUsersDir(), fmt.Sprintf("%s.json", name))
+	return filepath.Join(um.getUsersDir(), userFileName(name))
 }
 
-// GetUser gets a user by name
+// GetUser gets a user by name, or by an alias the mailmap gives
 func (um *UserManager) GetUser(name string) (*User, error) {
+	return um.getUser(name, "")
+}
+
+// getUser gets a user by name and email, either of which the mailmap may map
+func (um *UserManager) getUser(name, email string) (*User, error) {
+	if err := um.refreshCache(); err != nil {
+		return nil, err
+	}
+	identity, err := um.Resolve(name, email)
+	if err != nil {
+		return nil, err
+	}
+	return um.loadUser(identity.Name)
+}
+
+// loadUser reads a user's file, returning a new user if there is none
+func (um *UserManager) loadUser(name string) (*User, error) {
 	// Check if user file exists
 	filePath := um.getUserFilePath(name)
 	if _, err := os.Stat(filePath); os.IsNotExist(err) {
@@ -125,47 +159,127 @@ func (um *UserManager) SaveUser(user *User) error {
 
 // RecordAction records a user action and awards points
 func (um *UserManager) RecordAction(name string, action Action, description string, timestamp string) error {
+	_, err := um.Record(name, Activity{Action: action, Description: description, Timestamp: timestamp})
+	return err
+}
+
+// Record records an activity and awards the points the repository's rules give
+// it, up to the rule's daily cap. Aliases in the mailmap, by name or by the
+// activity's email, are recorded for the canonical user. It returns the points earned.
+func (um *UserManager) Record(name string, activity Activity) (int, error) {
+	identity, err := um.Resolve(name, activity.Email)
+	if err != nil {
+		return 0, err
+	}
+	name = identity.Name
+
+	// Get rule for action
+	rules, err := um.Rules()
+	if err != nil {
+		return 0, err
+	}
+	rule, ok := rules.Actions[activity.Action]
+	if !ok {
+		return 0, fmt.Errorf("unknown action: %s", activity.Action)
+	}
+
+	// When the user files are a cache, the activity is already in history, so
+	// rebuilding the cache awards it
+	cached, err := um.CacheEnabled()
+	if err != nil {
+		return 0, err
+	}
+	if cached {
+		user, err := um.GetUser(name)
+		if err != nil {
+			return 0, err
+		}
+		if points, ok := user.logged(activity); ok {
+			return points, nil
+		}
+		// History doesn't earn points for this action, so it isn't kept
+		return user.award(rule, activity), nil
+	}
+
 	// Get user
-	user, err := um.GetUser(name)
+	user, err := um.loadUser(name)
 	if err != nil {
-		return err
+		return 0, err
+	}
+
+	// Award points and save user
+	if user.Email == "" {
+		user.Email = identity.Email
 	}
+	points := user.award(rule, activity)
+	if err := um.SaveUser(user); err != nil {
+		return 0, err
+	}
+
+	return points, nil
+}
 
-	// Get points for action
-	points, ok := PointValues[action]
+// IssueClosePoints returns the points for closing an issue with an estimate,
+// before any daily cap
+func (um *UserManager) IssueClosePoints(estimate time.Duration) (int, error) {
+	rules, err := um.Rules()
+	if err != nil {
+		return 0, err
+	}
+	rule, ok := rules.Actions[ActionIssueClose]
 	if !ok {
-		return fmt.Errorf("unknown action: %s", action)
+		return 0, fmt.Errorf("unknown action: %s", ActionIssueClose)
 	}
+	return rule.Score(Activity{Action: ActionIssueClose, Estimate: estimate}), nil
+}
+
+// RecordIssueClose records that a user closed an issue with an estimate and
+// awards points for it. It returns the points earned.
+func (um *UserManager) RecordIssueClose(name, email string, estimate time.Duration, description string, timestamp string) (int, error) {
+	return um.Record(name, Activity{Action: ActionIssueClose, Description: description, Estimate: estimate, Timestamp: timestamp, Email: email})
+}
+
+// award adds the points a rule gives an activity, after the safeguards against
+// gaming, to a user's stats and returns them. Empty commits and reverts are
+// logged but don't count as contributions.
+func (u *User) award(rule *Rule, activity Activity) int {
+	points, flag := u.safeguardedScore(rule, activity)
 
 	// Update user stats
-	user.Points += points
-	user.ActionLog = append(user.ActionLog, ActionRecord{
-		Action:      action,
+	u.Points += points
+	u.ActionLog = append(u.ActionLog, ActionRecord{
+		Action:      activity.Action,
 		Points:      points,
-		Description: description,
-		Timestamp:   timestamp,
+		Description: activity.Description,
+		Timestamp:   activity.Timestamp,
+		Commit:      activity.Commit,
+		Flag:        flag,
 	})
+	if flag.voids() {
+		return points
+	}
 
 	// Update specific counters
-	switch action {
+	switch activity.Action {
 	case ActionCommit:
-		user.Commits++
+		u.Commits++
 	case ActionIssueCreate:
-		user.IssuesOpen++
+		u.IssuesOpen++
 	case ActionIssueClose:
-		user.IssuesClosed++
-	}
-
-	// Save user
-	if err := um.SaveUser(user); err != nil {
-		return err
+		u.IssuesClosed++
+	case ActionIssueComment:
+		u.Comments++
 	}
 
-	return nil
+	return points
 }
 
 // GetLeaderboard gets the leaderboard of users
 func (um *UserManager) GetLeaderboard() ([]*User, error) {
+	if err := um.refreshCache(); err != nil {
+		return nil, err
+	}
+
 	// Read all user files
 	usersDir := um.getUsersDir()
 	files, err := os.ReadDir(usersDir)
@@ -184,14 +298,13 @@ func (um *UserManager) GetLeaderboard() ([]*User, error) {
 			continue
 		}
 
-		// Extract name from filename (remove .json extension)
-		name := strings.TrimSuffix(file.Name(), ".json")
-		if name == file.Name() {
-			// If the filename doesn't end with .json, skip it
+		// Extract name from filename
+		name, ok := userNameFromFile(file.Name())
+		if !ok {
 			continue
 		}
 
-		user, err := um.GetUser(name)
+		user, err := um.loadUser(name)
 		if err != nil {
 			continue
 		}
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestNewUserManager(t *testing.T) {
@@ -274,3 +275,55 @@ func TestGetLeaderboard(t *testing.T) {
 		t.Errorf("Expected user1 to be second, got %s", leaderboard[1].Name)
 	}
 }
+
+func TestRecordIssueClose(t *testing.T) {
+	tempDir := t.TempDir()
+	manager := NewUserManager(tempDir)
+
+	// Without config, closing an issue earns the flat value
+	points, err := manager.RecordIssueClose("testuser", "", 8*time.Hour, "Closed issue #1", "2025-01-01T00:00:00Z")
+	if err != nil {
+		t.Fatalf("Failed to record issue close: %v", err)
+	}
+	if points != PointValues[ActionIssueClose] {
+		t.Errorf("Expected %d points, got %d", PointValues[ActionIssueClose], points)
+	}
+
+	// With estimates enabled, points follow the estimate
+	if err := os.MkdirAll(filepath.Join(tempDir, ".snap"), 0755); err != nil {
+		t.Fatalf("Failed to create config directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, ".snap", "config"), []byte("[points]\n\testimates = true\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	expected := map[time.Duration]int{
+		8 * time.Hour:    8 * PointsPerEstimatedHour,
+		30 * time.Minute: 3,
+		time.Minute:      1,
+		0:                PointValues[ActionIssueClose],
+	}
+	for estimate, want := range expected {
+		if points, err := manager.IssueClosePoints(estimate); err != nil || points != want {
+			t.Errorf("Expected %d points for an estimate of %v, got %d, %v", want, estimate, points, err)
+		}
+	}
+
+	points, err = manager.RecordIssueClose("testuser", "", 2*time.Hour, "Closed issue #2", "2025-01-02T00:00:00Z")
+	if err != nil {
+		t.Fatalf("Failed to record issue close: %v", err)
+	}
+	user, err := manager.GetUser("testuser")
+	if err != nil {
+		t.Fatalf("Failed to get user: %v", err)
+	}
+	if user.Points != PointValues[ActionIssueClose]+points || user.IssuesClosed != 2 || user.ActionLog[1].Points != 10 {
+		t.Errorf("Expected both closes to be recorded, got %d points and %d issues closed", user.Points, user.IssuesClosed)
+	}
+
+	if err := os.WriteFile(filepath.Join(tempDir, ".snap", "config"), []byte("[points]\n\testimates = maybe\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if _, err := manager.IssueClosePoints(time.Hour); err == nil {
+		t.Errorf("Expected an invalid config value to fail")
+	}
+}
@@ -1,13 +1,24 @@
 package web
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"html/template"
+	"net"
 	"net/http"
+	"net/url"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/stanlocht/snap/pkg/changelog"
+	"github.com/stanlocht/snap/pkg/config"
 	"github.com/stanlocht/snap/pkg/issue"
+	"github.com/stanlocht/snap/pkg/repository"
+	"github.com/stanlocht/snap/pkg/snapmoji"
 	"github.com/stanlocht/snap/pkg/user"
 )
 
@@ -41,6 +52,19 @@ type CommitListItem struct {
 type CommitDetailData struct {
 	Commit *CommitListItem
 	Files  []string
+	Issues []*LinkedIssueItem
+}
+
+// LinkedIssueItem represents an issue a commit refers to
+type LinkedIssueItem struct {
+	Issue  *IssueListItem
+	Closed bool // The commit closed the issue
+}
+
+// LinkedCommitItem represents a commit that refers to an issue
+type LinkedCommitItem struct {
+	Commit *CommitListItem
+	Closed bool // The commit closed the issue
 }
 
 // IssueListItem represents an issue in the list
@@ -51,28 +75,221 @@ type IssueListItem struct {
 	CreatedBy  string
 	CreatedAt  string
 	AssignedTo string
+	Labels     []*LabelItem
+	Priority   string
+	Milestone  string
+}
+
+// LabelItem represents a label on an issue
+type LabelItem struct {
+	Name  string
+	Color string
+}
+
+// MilestoneItem represents a milestone and its progress
+type MilestoneItem struct {
+	Name        string
+	Description string
+	Status      string
+	DueDate     string // Empty if the milestone has no due date
+	Overdue     bool
+	Total       int
+	Closed      int
+	Percent     int
+}
+
+// MilestoneDetailData represents the data for the milestone detail page
+type MilestoneDetailData struct {
+	Milestone *MilestoneItem
+	Issues    []*IssueListItem
+}
+
+// ReleaseItem represents a tag in the releases list
+type ReleaseItem struct {
+	Tag      string
+	Previous string // Empty for the first release
+	Date     string
+}
+
+// ReleaseEntry represents a commit in release notes
+type ReleaseEntry struct {
+	changelog.Entry
+	ShortID string
+}
+
+// ReleaseSection represents a section of release notes
+type ReleaseSection struct {
+	Title   string
+	Entries []ReleaseEntry
+}
+
+// ReleaseDetailData represents the data for the release notes page
+type ReleaseDetailData struct {
+	Release      *ReleaseItem
+	Bump         string
+	Sections     []ReleaseSection
+	Contributors []changelog.Contributor
+	Issues       []changelog.IssueRef
+}
+
+// issuesPerPage is the number of issues shown on one page of the issues page
+const issuesPerPage = 25
+
+// IssuesPageData represents the data for the issues page
+type IssuesPageData struct {
+	Query   string
+	Error   string // Set when the query could not be parsed
+	Issues  []*IssueListItem
+	Total   int
+	Page    int
+	Pages   int
+	PrevURL string
+	NextURL string
 }
 
 // IssueDetailData represents the data for the issue detail page
 type IssueDetailData struct {
 	Issue       *IssueListItem
 	Description string
-	Comments    []string
+	Comments    []*CommentItem
+	Commits     []*LinkedCommitItem
+	Timeline    []*TimelineItem
+	Relations   []*RelationItem
+}
+
+// NewIssueData represents the data for the new issue page
+type NewIssueData struct {
+	Templates   []*TemplateItem // Templates to pick from, shown until one is picked
+	Template    *TemplateItem   // The picked template, nil for a blank issue
+	Picked      bool
+	Title       string
+	Description string
+	Author      string
+	Error       string // Set when the issue could not be created
+}
+
+// TemplateItem represents an issue template in the template picker
+type TemplateItem struct {
+	Name     string
+	Title    string
+	About    string
+	Labels   []string
+	Required []string
+}
+
+// RelationItem represents the issues related to an issue in one way
+type RelationItem struct {
+	Name   string
+	Issues []*IssueListItem
+}
+
+// BoardData represents the data for the issue board
+type BoardData struct {
+	States    []string
+	Done      string // The state that closes issues
+	Columns   []*BoardColumn
+	Label     string // Filters from the query string
+	Milestone string
+}
+
+// BoardColumn represents the issues in one workflow state
+type BoardColumn struct {
+	State  string
+	Issues []*IssueListItem
+}
+
+// Sizes used to lay out the dependency graph, in pixels
+const (
+	graphNodeWidth  = 200
+	graphNodeHeight = 44
+	graphColumnGap  = 80
+	graphRowGap     = 30
+	graphMargin     = 20
+)
+
+// GraphData represents the data for the dependency graph page
+type GraphData struct {
+	Width      int
+	Height     int
+	NodeWidth  int
+	NodeHeight int
+	Nodes      []*GraphNode
+	Edges      []*GraphEdge
+}
+
+// GraphNode represents an issue placed in the dependency graph
+type GraphNode struct {
+	ID     int
+	Title  string
+	Closed bool
+	X, Y   int
+}
+
+// GraphEdge represents a relationship drawn in the dependency graph
+type GraphEdge struct {
+	Type           string
+	X1, Y1, X2, Y2 int
+}
+
+// TimelineItem represents an event in the history of an issue
+type TimelineItem struct {
+	Actor string
+	At    string
+	Text  string
+}
+
+// CommentItem represents a comment in an issue discussion
+type CommentItem struct {
+	ID        string
+	Author    string
+	CreatedAt string
+	EditedAt  string // Empty if the comment was never edited
+	Body      template.HTML
+	Depth     int
 }
 
 // UserListItem represents a user in the list
 type UserListItem struct {
+	Rank    int
 	Name    string
+	Score   int
 	Points  int
 	Commits int
 	Issues  int
 }
 
+// UsersPageData represents the data for the users page: a leaderboard
+type UsersPageData struct {
+	Users      []*UserListItem
+	Category   string
+	Unit       string // What the score counts, if the board isn't lifetime points
+	Since      string
+	Season     string
+	Final      bool // The season has ended and its standings are archived
+	Team       string
+	ByTeams    bool // Teams are ranked instead of users
+	Categories []string
+	Seasons    []string
+	Teams      []string
+}
+
 // UserDetailData represents the data for the user detail page
 type UserDetailData struct {
 	User          *UserListItem
 	RecentCommits []*CommitListItem
 	RecentActions []string
+	Badges        []BadgeItem
+	Heatmap       [][]user.HeatmapCell // Weeks of the last year, Sunday first
+	CurrentStreak int
+	LongestStreak int
+}
+
+// BadgeItem represents an achievement on the user detail page
+type BadgeItem struct {
+	Emoji       string
+	Name        string
+	Description string
+	EarnedAt    string
 }
 
 // QuestData represents the data for the quest page
@@ -91,26 +308,35 @@ func (s *Server) handleHome(w http.ResponseWriter, r *http.Request) {
 	// Get repository name
 	repoName := filepath.Base(s.Repo.Path)
 
-	// Get commit history
-	history, err := s.Repo.GetCommitHistory("")
+	// Get commit count from the commit graph
+	commitCount, err := s.Repo.CountCommits("")
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Error getting commit history: %v", err), http.StatusInternalServerError)
+		http.Error(w, fmt.Sprintf("Error counting commits: %v", err), http.StatusInternalServerError)
 		return
 	}
 
 	// Get recent commits
-	recentCommits := make([]*CommitListItem, 0, 5)
-	for i, commit := range history {
-		if i >= 5 {
-			break
-		}
+	history, err := s.Repo.GetRecentCommits("", 5)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error getting commit history: %v", err), http.StatusInternalServerError)
+		return
+	}
 
-		emoji := extractEmoji(commit.Message)
+	// Load the repository's snapmojis
+	snapmojis, err := snapmoji.Load(s.Repo.Path)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error loading snapmojis: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	recentCommits := make([]*CommitListItem, 0, 5)
+	for _, commit := range history {
+		emoji, message := extractEmoji(snapmojis, commit.Message)
 
 		recentCommits = append(recentCommits, &CommitListItem{
 			ID:        commit.ID,
 			ShortID:   truncateID(commit.ID),
-			Message:   strings.TrimPrefix(strings.TrimPrefix(commit.Message, emoji), " "),
+			Message:   message,
 			Author:    commit.Author,
 			Timestamp: formatTime(commit.Timestamp),
 			Emoji:     emoji,
@@ -139,7 +365,7 @@ func (s *Server) handleHome(w http.ResponseWriter, r *http.Request) {
 		RepoName:    repoName,
 		CurrentPage: "home",
 		Data: &HomeData{
-			CommitCount:      len(history),
+			CommitCount:      commitCount,
 			IssueCount:       issueCount,
 			ContributorCount: contributorCount,
 			RecentCommits:    recentCommits,
@@ -162,15 +388,22 @@ func (s *Server) handleCommits(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Load the repository's snapmojis
+	snapmojis, err := snapmoji.Load(s.Repo.Path)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error loading snapmojis: %v", err), http.StatusInternalServerError)
+		return
+	}
+
 	// Prepare commit list
 	commits := make([]*CommitListItem, 0, len(history))
 	for _, commit := range history {
-		emoji := extractEmoji(commit.Message)
+		emoji, message := extractEmoji(snapmojis, commit.Message)
 
 		commits = append(commits, &CommitListItem{
 			ID:        commit.ID,
 			ShortID:   truncateID(commit.ID),
-			Message:   strings.TrimPrefix(strings.TrimPrefix(commit.Message, emoji), " "),
+			Message:   message,
 			Author:    commit.Author,
 			Timestamp: formatTime(commit.Timestamp),
 			Emoji:     emoji,
@@ -221,19 +454,46 @@ func (s *Server) handleCommitDetail(w http.ResponseWriter, r *http.Request) {
 		files = append(files, file)
 	}
 
+	// Load the repository's snapmojis
+	snapmojis, err := snapmoji.Load(s.Repo.Path)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error loading snapmojis: %v", err), http.StatusInternalServerError)
+		return
+	}
+
 	// Extract emoji
-	emoji := extractEmoji(commit.Message)
+	emoji, message := extractEmoji(snapmojis, commit.Message)
 
 	// Prepare commit data
 	commitData := &CommitListItem{
 		ID:        commit.ID,
 		ShortID:   truncateID(commit.ID),
-		Message:   strings.TrimPrefix(strings.TrimPrefix(commit.Message, emoji), " "),
+		Message:   message,
 		Author:    commit.Author,
 		Timestamp: formatTime(commit.Timestamp),
 		Emoji:     emoji,
 	}
 
+	// Get the issues the commit refers to
+	issueManager := issue.NewIssueManager(s.Repo.Path)
+	linkedIssues, err := issueManager.IssuesForCommit(commit.ID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error getting issues: %v", err), http.StatusInternalServerError)
+		return
+	}
+	labels, err := labelColors(issueManager)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error getting labels: %v", err), http.StatusInternalServerError)
+		return
+	}
+	issueList := make([]*LinkedIssueItem, 0, len(linkedIssues))
+	for _, linked := range linkedIssues {
+		issueList = append(issueList, &LinkedIssueItem{
+			Issue:  newIssueListItem(linked, labels),
+			Closed: linked.ClosedBy == commit.ID,
+		})
+	}
+
 	// Prepare data
 	data := &PageData{
 		Title:       "Commit Detail",
@@ -242,6 +502,7 @@ func (s *Server) handleCommitDetail(w http.ResponseWriter, r *http.Request) {
 		Data: &CommitDetailData{
 			Commit: commitData,
 			Files:  files,
+			Issues: issueList,
 		},
 	}
 
@@ -254,30 +515,63 @@ func (s *Server) handleIssues(w http.ResponseWriter, r *http.Request) {
 	// Get repository name
 	repoName := filepath.Base(s.Repo.Path)
 
+	// Parse the search query and filters
+	params := r.URL.Query()
+	pageData := &IssuesPageData{Query: params.Get("q")}
+	query, err := issue.ParseQuery(pageData.Query)
+	if err != nil {
+		pageData.Error = err.Error()
+		query, _ = issue.ParseQuery("")
+	}
+	priority, err := issue.ParsePriority(params.Get("priority"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	query.AddFilter(issue.Filter{
+		ShowClosed: true, // Show all issues, including closed ones
+		Label:      params.Get("label"),
+		Milestone:  params.Get("milestone"),
+		Priority:   priority,
+		Assignee:   params.Get("assignee"),
+	})
+	page, err := strconv.Atoi(params.Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
 	// Get issues
 	issueManager := issue.NewIssueManager(s.Repo.Path)
-	issues, err := issueManager.ListIssues(true) // Show all issues, including closed ones
+	result, err := issueManager.Search(query, page, issuesPerPage)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Error getting issues: %v", err), http.StatusInternalServerError)
 		return
 	}
+	labels, err := labelColors(issueManager)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error getting labels: %v", err), http.StatusInternalServerError)
+		return
+	}
 
 	// Prepare issue list
-	issueList := make([]*IssueListItem, 0, len(issues))
-	for _, issue := range issues {
-		status := "Open"
-		if string(issue.Status) == "closed" {
-			status = "Closed"
-		}
-
-		issueList = append(issueList, &IssueListItem{
-			ID:         issue.ID,
-			Title:      issue.Title,
-			Status:     status,
-			CreatedBy:  issue.CreatedBy,
-			CreatedAt:  formatTime(issue.CreatedAt),
-			AssignedTo: issue.AssignedTo,
-		})
+	pageData.Issues = make([]*IssueListItem, 0, len(result.Issues))
+	for _, issue := range result.Issues {
+		pageData.Issues = append(pageData.Issues, newIssueListItem(issue, labels))
+	}
+
+	// Prepare pagination
+	pageData.Total = result.Total
+	pageData.Page = result.Page
+	pageData.Pages = result.Pages()
+	pageURL := func(page int) string {
+		params.Set("page", strconv.Itoa(page))
+		return "/issues?" + params.Encode()
+	}
+	if pageData.Page > 1 {
+		pageData.PrevURL = pageURL(pageData.Page - 1)
+	}
+	if pageData.Page < pageData.Pages {
+		pageData.NextURL = pageURL(pageData.Page + 1)
 	}
 
 	// Prepare data
@@ -285,7 +579,7 @@ func (s *Server) handleIssues(w http.ResponseWriter, r *http.Request) {
 		Title:       "Issues",
 		RepoName:    repoName,
 		CurrentPage: "issues",
-		Data:        issueList,
+		Data:        pageData,
 	}
 
 	// Render template
@@ -320,20 +614,72 @@ func (s *Server) handleIssueDetail(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Prepare issue data
-	status := "Open"
-	if string(issue.Status) == "closed" {
-		status = "Closed"
+	labels, err := labelColors(issueManager)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error getting labels: %v", err), http.StatusInternalServerError)
+		return
+	}
+	issueData := newIssueListItem(issue, labels)
+
+	// Prepare comments in discussion order
+	var comments []*CommentItem
+	for _, comment := range issue.CommentThread() {
+		item := &CommentItem{
+			ID:        comment.ID,
+			Author:    comment.Author,
+			CreatedAt: formatTime(comment.CreatedAt),
+			Body:      renderMarkdown(comment.Body),
+			Depth:     comment.Depth,
+		}
+		if comment.Edited() {
+			item.EditedAt = formatTime(comment.LastModified())
+		}
+		comments = append(comments, item)
 	}
 
-	issueData := &IssueListItem{
-		ID:         issue.ID,
-		Title:      issue.Title,
-		Status:     status,
-		CreatedBy:  issue.CreatedBy,
-		CreatedAt:  formatTime(issue.CreatedAt),
-		AssignedTo: issue.AssignedTo,
+	// Prepare linked commits, oldest first. Commits that weren't fetched are left out.
+	var linkedCommits []*repository.Commit
+	for _, commitID := range issue.Commits {
+		commit, err := s.Repo.GetCommit(commitID)
+		if err != nil {
+			continue
+		}
+		linkedCommits = append(linkedCommits, commit)
+	}
+	sort.Slice(linkedCommits, func(i, j int) bool {
+		return linkedCommits[i].Timestamp.Before(linkedCommits[j].Timestamp)
+	})
+	// Load the repository's snapmojis
+	snapmojis, err := snapmoji.Load(s.Repo.Path)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error loading snapmojis: %v", err), http.StatusInternalServerError)
+		return
 	}
 
+	commits := make([]*LinkedCommitItem, 0, len(linkedCommits))
+	for _, commit := range linkedCommits {
+		commits = append(commits, &LinkedCommitItem{
+			Commit: newCommitListItem(snapmojis, commit),
+			Closed: commit.ID == issue.ClosedBy,
+		})
+	}
+
+	// Prepare timeline
+	timeline := []*TimelineItem{{Actor: issue.CreatedBy, At: formatTime(issue.CreatedAt), Text: "opened the issue"}}
+	for _, event := range issue.History() {
+		timeline = append(timeline, &TimelineItem{
+			Actor: event.Actor,
+			At:    formatTime(event.At),
+			Text:  event.Describe(),
+		})
+	}
+
+	// Prepare relations
+	relations, err := issueManager.Relations(issue.ID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error getting relations: %v", err), http.StatusInternalServerError)
+		return
+	}
 	// Prepare data
 	data := &PageData{
 		Title:       "Issue Detail",
@@ -342,7 +688,10 @@ func (s *Server) handleIssueDetail(w http.ResponseWriter, r *http.Request) {
 		Data: &IssueDetailData{
 			Issue:       issueData,
 			Description: issue.Description,
-			Comments:    []string{}, // No comments implementation yet
+			Comments:    comments,
+			Commits:     commits,
+			Timeline:    timeline,
+			Relations:   newRelationItems(relations, labels),
 		},
 	}
 
@@ -350,28 +699,622 @@ func (s *Server) handleIssueDetail(w http.ResponseWriter, r *http.Request) {
 	s.Templates.Execute(w, data)
 }
 
-// handleUsers handles the users page
+// handleNewIssue handles the new issue page: a template picker, then a form
+// that checks the template's required sections before creating the issue
+func (s *Server) handleNewIssue(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Get repository name
+	repoName := filepath.Base(s.Repo.Path)
+
+	// Get templates
+	issueManager := issue.NewIssueManager(s.Repo.Path)
+	templates, err := issueManager.ListTemplates()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error getting templates: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	// Parse form, which also holds the query string
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form", http.StatusBadRequest)
+		return
+	}
+	newIssue := &NewIssueData{
+		Picked:      r.Form.Has("template") || len(templates) == 0,
+		Title:       r.FormValue("title"),
+		Description: r.FormValue("description"),
+		Author:      r.FormValue("author"),
+	}
+	if newIssue.Author == "" {
+		newIssue.Author = s.webAuthor()
+	}
+
+	// Get the picked template, if any
+	var template *issue.Template
+	if name := r.FormValue("template"); name != "" {
+		template, err = issueManager.GetTemplate(name)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error getting template: %v", err), http.StatusNotFound)
+			return
+		}
+		newIssue.Template = newTemplateItem(template)
+		if r.Method == http.MethodGet {
+			newIssue.Title = template.Prefix
+			newIssue.Description = template.Body
+		}
+	}
+	if !newIssue.Picked {
+		for _, template := range templates {
+			newIssue.Templates = append(newIssue.Templates, newTemplateItem(template))
+		}
+	}
+
+	status := http.StatusOK
+	if r.Method == http.MethodPost {
+		if !s.allowEdit(w, r) {
+			return
+		}
+
+		// Create issue
+		var created *issue.Issue
+		if template != nil {
+			created, err = issueManager.CreateIssueFromTemplate(template, newIssue.Title, newIssue.Description, newIssue.Author)
+		} else if strings.TrimSpace(newIssue.Title) == "" {
+			err = fmt.Errorf("issue title is required")
+		} else {
+			created, err = issueManager.CreateIssue(strings.TrimSpace(newIssue.Title), strings.TrimSpace(newIssue.Description), newIssue.Author)
+		}
+		if err == nil {
+			http.Redirect(w, r, fmt.Sprintf("/issue/%d", created.ID), http.StatusSeeOther)
+			return
+		}
+
+		// Show the form again with what was written
+		newIssue.Error = err.Error()
+		status = http.StatusBadRequest
+	}
+
+	// Prepare data
+	data := &PageData{
+		Title:       "New Issue",
+		RepoName:    repoName,
+		CurrentPage: "issues",
+		Data:        newIssue,
+	}
+
+	// Render template
+	w.WriteHeader(status)
+	s.Templates.Execute(w, data)
+}
+
+// handleDependencies handles the dependency graph page
+func (s *Server) handleDependencies(w http.ResponseWriter, r *http.Request) {
+	// Get repository name
+	repoName := filepath.Base(s.Repo.Path)
+
+	// Get graph
+	issueManager := issue.NewIssueManager(s.Repo.Path)
+	graph, err := issueManager.DependencyGraph()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error getting dependency graph: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	// Prepare data
+	data := &PageData{
+		Title:       "Dependencies",
+		RepoName:    repoName,
+		CurrentPage: "dependencies",
+		Data:        layoutGraph(graph),
+	}
+
+	// Render template
+	s.Templates.Execute(w, data)
+}
+
+// layoutGraph places the issues of a dependency graph in columns: every issue
+// is in a column to the right of the issues that block it.
+func layoutGraph(graph *issue.Graph) *GraphData {
+	data := &GraphData{NodeWidth: graphNodeWidth, NodeHeight: graphNodeHeight}
+	if len(graph.Issues) == 0 {
+		return data
+	}
+
+	depths := graph.Depths()
+	rows := make(map[int]int)
+	nodes := make(map[string]*GraphNode)
+	for _, i := range graph.Issues {
+		depth := depths[i.UID]
+		title := i.Title
+		if runes := []rune(title); len(runes) > 24 {
+			title = string(runes[:23]) + "…"
+		}
+
+		node := &GraphNode{
+			ID:     i.ID,
+			Title:  title,
+			Closed: i.Status == issue.StatusClosed,
+			X:      graphMargin + depth*(graphNodeWidth+graphColumnGap),
+			Y:      graphMargin + rows[depth]*(graphNodeHeight+graphRowGap),
+		}
+		rows[depth]++
+		nodes[i.UID] = node
+		data.Nodes = append(data.Nodes, node)
+
+		if right := node.X + graphNodeWidth + graphMargin; right > data.Width {
+			data.Width = right
+		}
+		if bottom := node.Y + graphNodeHeight + graphMargin; bottom > data.Height {
+			data.Height = bottom
+		}
+	}
+
+	// Connect the sides of the nodes that face each other
+	for _, edge := range graph.Edges {
+		from, to := nodes[edge.From.UID], nodes[edge.To.UID]
+		item := &GraphEdge{Type: string(edge.Type)}
+		switch {
+		case from.X < to.X:
+			item.X1, item.Y1 = from.X+graphNodeWidth, from.Y+graphNodeHeight/2
+			item.X2, item.Y2 = to.X, to.Y+graphNodeHeight/2
+		case from.X > to.X:
+			item.X1, item.Y1 = from.X, from.Y+graphNodeHeight/2
+			item.X2, item.Y2 = to.X+graphNodeWidth, to.Y+graphNodeHeight/2
+		case from.Y < to.Y:
+			item.X1, item.Y1 = from.X+graphNodeWidth/2, from.Y+graphNodeHeight
+			item.X2, item.Y2 = to.X+graphNodeWidth/2, to.Y
+		default:
+			item.X1, item.Y1 = from.X+graphNodeWidth/2, from.Y
+			item.X2, item.Y2 = to.X+graphNodeWidth/2, to.Y+graphNodeHeight
+		}
+		data.Edges = append(data.Edges, item)
+	}
+
+	return data
+}
+
+// handleBoard handles the issue board, which groups issues by workflow state
+func (s *Server) handleBoard(w http.ResponseWriter, r *http.Request) {
+	// Get repository name
+	repoName := filepath.Base(s.Repo.Path)
+
+	// Get workflow
+	issueManager := issue.NewIssueManager(s.Repo.Path)
+	workflow, err := issueManager.Workflow()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error getting workflow: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	// Get issues
+	filter := issue.Filter{
+		ShowClosed: true,
+		Label:      r.URL.Query().Get("label"),
+		Milestone:  r.URL.Query().Get("milestone"),
+	}
+	issues, err := issueManager.FilterIssues(filter)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error getting issues: %v", err), http.StatusInternalServerError)
+		return
+	}
+	labels, err := labelColors(issueManager)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error getting labels: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	// Group issues by state
+	board := &BoardData{States: workflow.States, Done: workflow.Done(), Label: filter.Label, Milestone: filter.Milestone}
+	columns := make(map[string]*BoardColumn, len(workflow.States))
+	for _, state := range workflow.States {
+		columns[state] = &BoardColumn{State: state}
+		board.Columns = append(board.Columns, columns[state])
+	}
+	for _, i := range issues {
+		column := columns[workflow.StateOf(i)]
+		column.Issues = append(column.Issues, newIssueListItem(i, labels))
+	}
+
+	// Prepare data
+	data := &PageData{
+		Title:       "Board",
+		RepoName:    repoName,
+		CurrentPage: "board",
+		Data:        board,
+	}
+
+	// Render template
+	s.Templates.Execute(w, data)
+}
+
+// handleBoardMove moves an issue to another workflow state. Browsers posting the
+// board's form are sent back to the board; scripts asking for JSON get the new state.
+func (s *Server) handleBoardMove(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Only accept moves from the board itself
+	if !s.allowEdit(w, r) {
+		return
+	}
+
+	// Parse form
+	issueID, err := strconv.Atoi(r.FormValue("issue"))
+	if err != nil {
+		http.Error(w, "Invalid issue ID", http.StatusBadRequest)
+		return
+	}
+	state := r.FormValue("state")
+
+	// Move issue, recording the transition for the repository's user
+	issueManager := issue.NewIssueManager(s.Repo.Path)
+	issueManager.Author = s.webAuthor()
+	if err := issueManager.MoveIssue(issueID, state, false); err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, issue.ErrBlocked) {
+			status = http.StatusConflict
+		}
+		http.Error(w, fmt.Sprintf("Error moving issue: %v", err), status)
+		return
+	}
+
+	if !strings.Contains(r.Header.Get("Accept"), "application/json") {
+		// Go back to the board the form was on, keeping its filters
+		back := "/board"
+		if referer, err := url.Parse(r.Referer()); err == nil && referer.Path == "/board" {
+			back = referer.RequestURI()
+		}
+		http.Redirect(w, r, back, http.StatusSeeOther)
+		return
+	}
+
+	moved, err := issueManager.GetIssue(issueID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error getting issue: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"id":     moved.ID,
+		"state":  strings.ToLower(strings.TrimSpace(state)),
+		"status": moved.Status,
+	})
+}
+
+// handleMilestones handles the milestones page
+func (s *Server) handleMilestones(w http.ResponseWriter, r *http.Request) {
+	// Get repository name
+	repoName := filepath.Base(s.Repo.Path)
+
+	// Get milestones
+	issueManager := issue.NewIssueManager(s.Repo.Path)
+	milestones, err := issueManager.ListMilestones(true)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error getting milestones: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	// Prepare milestone list
+	milestoneList := make([]*MilestoneItem, 0, len(milestones))
+	for _, milestone := range milestones {
+		item, err := newMilestoneItem(issueManager, milestone)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error getting milestone progress: %v", err), http.StatusInternalServerError)
+			return
+		}
+		milestoneList = append(milestoneList, item)
+	}
+
+	// Prepare data
+	data := &PageData{
+		Title:       "Milestones",
+		RepoName:    repoName,
+		CurrentPage: "milestones",
+		Data:        milestoneList,
+	}
+
+	// Render template
+	s.Templates.Execute(w, data)
+}
+
+// handleMilestoneDetail handles the milestone detail page
+func (s *Server) handleMilestoneDetail(w http.ResponseWriter, r *http.Request) {
+	// Get milestone name from URL
+	name := strings.TrimPrefix(r.URL.Path, "/milestone/")
+	if name == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	// Get repository name
+	repoName := filepath.Base(s.Repo.Path)
+
+	// Get milestone
+	issueManager := issue.NewIssueManager(s.Repo.Path)
+	milestone, err := issueManager.GetMilestone(name)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	milestoneData, err := newMilestoneItem(issueManager, milestone)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error getting milestone progress: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	// Get the milestone's issues
+	issues, err := issueManager.MilestoneIssues(name)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error getting issues: %v", err), http.StatusInternalServerError)
+		return
+	}
+	labels, err := labelColors(issueManager)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error getting labels: %v", err), http.StatusInternalServerError)
+		return
+	}
+	issueList := make([]*IssueListItem, 0, len(issues))
+	for _, issue := range issues {
+		issueList = append(issueList, newIssueListItem(issue, labels))
+	}
+
+	// Prepare data
+	data := &PageData{
+		Title:       "Milestone Detail",
+		RepoName:    repoName,
+		CurrentPage: "milestones",
+		Data: &MilestoneDetailData{
+			Milestone: milestoneData,
+			Issues:    issueList,
+		},
+	}
+
+	// Render template
+	s.Templates.Execute(w, data)
+}
+
+// handleReleases handles the releases page, which lists the tags newest first
+func (s *Server) handleReleases(w http.ResponseWriter, r *http.Request) {
+	// Get repository name
+	repoName := filepath.Base(s.Repo.Path)
+
+	// Get releases
+	releases, err := changelog.Releases(s.Repo)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error getting releases: %v", err), http.StatusInternalServerError)
+		return
+	}
+	releaseList := make([]*ReleaseItem, 0, len(releases))
+	for _, release := range releases {
+		releaseList = append(releaseList, &ReleaseItem{Tag: release.Tag, Previous: release.Previous, Date: formatTime(release.Date)})
+	}
+
+	// Prepare data
+	data := &PageData{
+		Title:       "Releases",
+		RepoName:    repoName,
+		CurrentPage: "releases",
+		Data:        releaseList,
+	}
+
+	// Render template
+	s.Templates.Execute(w, data)
+}
+
+// handleReleaseDetail handles the release notes page of a tag, with the
+// changes since the tag before it
+func (s *Server) handleReleaseDetail(w http.ResponseWriter, r *http.Request) {
+	// Get tag name from URL
+	name := strings.TrimPrefix(r.URL.Path, "/release/")
+	if name == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	// Get repository name
+	repoName := filepath.Base(s.Repo.Path)
+
+	// Get tag
+	tags, err := s.Repo.ListTags()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error getting tags: %v", err), http.StatusInternalServerError)
+		return
+	}
+	commitID := ""
+	for _, tag := range tags {
+		if tag.Name == name {
+			commitID = tag.CommitID
+		}
+	}
+	if commitID == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	// Generate the release notes since the previous tag
+	previous, err := changelog.PreviousTag(s.Repo, commitID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error finding previous tag: %v", err), http.StatusInternalServerError)
+		return
+	}
+	notes, err := changelog.Generate(s.Repo, previous, name)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error generating release notes: %v", err), http.StatusInternalServerError)
+		return
+	}
+	releaseData := &ReleaseDetailData{
+		Release:      &ReleaseItem{Tag: name, Previous: previous, Date: formatTime(notes.Date)},
+		Bump:         string(notes.Bump),
+		Contributors: notes.Contributors,
+		Issues:       notes.Issues,
+	}
+	for _, section := range notes.Sections {
+		releaseSection := ReleaseSection{Title: section.Title}
+		for _, entry := range section.Entries {
+			releaseSection.Entries = append(releaseSection.Entries, ReleaseEntry{Entry: entry, ShortID: truncateID(entry.Commit)})
+		}
+		releaseData.Sections = append(releaseData.Sections, releaseSection)
+	}
+
+	// Prepare data
+	data := &PageData{
+		Title:       "Release Notes",
+		RepoName:    repoName,
+		CurrentPage: "releases",
+		Data:        releaseData,
+	}
+
+	// Render template
+	s.Templates.Execute(w, data)
+}
+
+// handleUsers handles the users page, a leaderboard that can be limited with
+// since or season and ranked by category
 func (s *Server) handleUsers(w http.ResponseWriter, r *http.Request) {
 	// Get repository name
 	repoName := filepath.Base(s.Repo.Path)
 
-	// Get users
+	// Parse the leaderboard options
+	params := r.URL.Query()
+	pageData := &UsersPageData{Since: params.Get("since"), Season: params.Get("season"), Team: params.Get("team"), ByTeams: params.Get("teams") != ""}
+	category, err := user.ParseCategory(params.Get("category"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if pageData.Since != "" && pageData.Season != "" {
+		http.Error(w, "since and season can't be combined", http.StatusBadRequest)
+		return
+	}
+	if pageData.ByTeams && pageData.Team != "" {
+		http.Error(w, "teams and team can't be combined", http.StatusBadRequest)
+		return
+	}
+	pageData.Category = string(category)
+	for _, c := range user.Categories {
+		pageData.Categories = append(pageData.Categories, string(c))
+	}
+
 	userManager := user.NewUserManager(s.Repo.Path)
-	users, err := userManager.GetLeaderboard()
+	loc, err := userManager.Location()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error getting time zone: %v", err), http.StatusInternalServerError)
+		return
+	}
+	now := time.Now().In(loc)
+
+	// Offer the configured seasons and the last two quarters
+	seasons, err := userManager.Seasons()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error getting seasons: %v", err), http.StatusInternalServerError)
+		return
+	}
+	for _, season := range seasons {
+		pageData.Seasons = append(pageData.Seasons, season.Name)
+	}
+	pageData.Seasons = append(pageData.Seasons, user.QuarterName(now), user.QuarterName(now.AddDate(0, -3, 0)))
+
+	// Offer the teams
+	teams, err := userManager.Teams()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error getting teams: %v", err), http.StatusInternalServerError)
+		return
+	}
+	var team *user.Team
+	for _, t := range teams {
+		pageData.Teams = append(pageData.Teams, t.Name)
+		if t.Name == pageData.Team {
+			team = t
+		}
+	}
+	if pageData.Team != "" && team == nil {
+		http.Error(w, fmt.Sprintf("unknown team %q", pageData.Team), http.StatusBadRequest)
+		return
+	}
+
+	// Get standings
+	var standings []user.Standing
+	leaderboard, seasonLeaderboard := userManager.Leaderboard, userManager.SeasonLeaderboard
+	if pageData.ByTeams {
+		leaderboard, seasonLeaderboard = userManager.TeamLeaderboard, userManager.SeasonTeamLeaderboard
+	}
+	switch {
+	case pageData.Season != "":
+		standings, pageData.Final, err = seasonLeaderboard(pageData.Season, category, now)
+		if err != nil {
+			// Most likely an unknown season
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	case pageData.Since != "":
+		var since time.Time
+		since, err = user.ParseSince(pageData.Since, now, loc)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		standings, err = leaderboard(category, user.Window{Since: since})
+	default:
+		standings, err = leaderboard(category, user.Window{})
+	}
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Error getting users: %v", err), http.StatusInternalServerError)
 		return
 	}
+	if category != user.CategoryPoints || pageData.Since != "" || pageData.Season != "" {
+		pageData.Unit = category.Unit()
+	}
+
+	// Keep a team's members
+	if team != nil {
+		members := standings[:0]
+		for _, standing := range standings {
+			if team.Has(standing.Name) {
+				members = append(members, standing)
+			}
+		}
+		standings = members
+	}
 
-	// Prepare user list
-	userList := make([]*UserListItem, 0, len(users))
+	// Prepare user list, with each user's lifetime stats
+	users, err := userManager.GetLeaderboard()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error getting users: %v", err), http.StatusInternalServerError)
+		return
+	}
+	stats := make(map[string]*user.User, len(users))
 	for _, u := range users {
-		userList = append(userList, &UserListItem{
-			Name:    u.Name,
-			Points:  u.Points,
-			Commits: u.Commits,
-			Issues:  u.IssuesOpen + u.IssuesClosed,
-		})
+		stats[u.Name] = u
+	}
+	ranks := user.Ranks(standings)
+	pageData.Users = make([]*UserListItem, 0, len(standings))
+	for i, standing := range standings {
+		item := &UserListItem{Rank: ranks[i], Name: standing.Name, Score: standing.Score}
+		members := []string{standing.Name}
+		if pageData.ByTeams {
+			// A team's stats are its members' combined
+			for _, t := range teams {
+				if t.Name == standing.Name {
+					members = t.Members
+				}
+			}
+		}
+		for _, member := range members {
+			if u := stats[member]; u != nil {
+				item.Points += u.Points
+				item.Commits += u.Commits
+				item.Issues += u.IssuesOpen + u.IssuesClosed
+			}
+		}
+		pageData.Users = append(pageData.Users, item)
 	}
 
 	// Prepare data
@@ -379,7 +1322,7 @@ func (s *Server) handleUsers(w http.ResponseWriter, r *http.Request) {
 		Title:       "Users",
 		RepoName:    repoName,
 		CurrentPage: "users",
-		Data:        userList,
+		Data:        pageData,
 	}
 
 	// Render template
@@ -412,34 +1355,58 @@ func (s *Server) handleUserDetail(w http.ResponseWriter, r *http.Request) {
 		formattedActions = append(formattedActions, fmt.Sprintf("%s: %s (+%d points)", action.Timestamp, action.Description, action.Points))
 	}
 
-	// Get commit history
-	history, err := s.Repo.GetCommitHistory("")
+	// Format badges for the user's achievements
+	badges := make([]BadgeItem, 0, len(u.Achievements))
+	for _, badge := range u.Badges() {
+		earnedAt := badge.EarnedAt
+		if at, err := time.Parse(time.RFC3339, badge.EarnedAt); err == nil {
+			earnedAt = formatTime(at)
+		}
+		badges = append(badges, BadgeItem{
+			Emoji:       badge.Emoji,
+			Name:        badge.Name,
+			Description: badge.Description,
+			EarnedAt:    earnedAt,
+		})
+	}
+
+	// Aggregate the user's activity by day
+	loc, err := userManager.Location()
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Error getting commit history: %v", err), http.StatusInternalServerError)
+		http.Error(w, fmt.Sprintf("Error getting time zone: %v", err), http.StatusInternalServerError)
+		return
+	}
+	now := time.Now().In(loc)
+	currentStreak, longestStreak := u.Streaks(loc, now)
+	heatmap := user.Heatmap(u.DailyActivity(loc), now, 53)
+
+	// Load the repository's snapmojis
+	snapmojis, err := snapmoji.Load(s.Repo.Path)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error loading snapmojis: %v", err), http.StatusInternalServerError)
 		return
 	}
 
-	// Get user's recent commits
+	// Get user's recent commits, stopping once we have enough
 	recentCommits := make([]*CommitListItem, 0, 5)
-	count := 0
-	for _, commit := range history {
+	err = s.Repo.WalkCommits("", func(commit *repository.Commit) bool {
 		if commit.Author == userName {
-			emoji := extractEmoji(commit.Message)
+			emoji, message := extractEmoji(snapmojis, commit.Message)
 
 			recentCommits = append(recentCommits, &CommitListItem{
 				ID:        commit.ID,
 				ShortID:   truncateID(commit.ID),
-				Message:   strings.TrimPrefix(strings.TrimPrefix(commit.Message, emoji), " "),
+				Message:   message,
 				Author:    commit.Author,
 				Timestamp: formatTime(commit.Timestamp),
 				Emoji:     emoji,
 			})
-
-			count++
-			if count >= 5 {
-				break
-			}
 		}
+		return len(recentCommits) < 5
+	})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error getting commit history: %v", err), http.StatusInternalServerError)
+		return
 	}
 
 	// Prepare user data
@@ -459,6 +1426,10 @@ func (s *Server) handleUserDetail(w http.ResponseWriter, r *http.Request) {
 			User:          userData,
 			RecentCommits: recentCommits,
 			RecentActions: formattedActions,
+			Badges:        badges,
+			Heatmap:       heatmap,
+			CurrentStreak: currentStreak,
+			LongestStreak: longestStreak,
 		},
 	}
 
@@ -514,10 +1485,19 @@ func (s *Server) handleQuest(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Filter issues assigned to the current user
+	// Filter issues assigned to the current user or their teams
+	memberOf, err := user.NewUserManager(s.Repo.Path).TeamsOf(currentUser)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error getting teams: %v", err), http.StatusInternalServerError)
+		return
+	}
+	assignedTeams := make(map[string]bool, len(memberOf))
+	for _, team := range memberOf {
+		assignedTeams[team.Name] = true
+	}
 	assignedIssues := make([]*IssueListItem, 0)
 	for _, issue := range issues {
-		if issue.AssignedTo == currentUser {
+		if issue.AssignedTo == currentUser || assignedTeams[issue.AssignedTeam()] {
 			status := "Open"
 			if string(issue.Status) == "closed" {
 				status = "Closed"
@@ -549,20 +1529,192 @@ func (s *Server) handleQuest(w http.ResponseWriter, r *http.Request) {
 	s.Templates.Execute(w, data)
 }
 
-// extractEmoji extracts the emoji from a commit message
-func extractEmoji(message string) string {
+// labelColors returns the color of every label defined in the repository
+func labelColors(issueManager *issue.IssueManager) (map[string]string, error) {
+	labels, err := issueManager.ListLabels()
+	if err != nil {
+		return nil, err
+	}
+
+	colors := make(map[string]string, len(labels))
+	for _, label := range labels {
+		colors[label.Name] = label.Color
+	}
+	return colors, nil
+}
+
+// newCommitListItem prepares a commit for display
+func newCommitListItem(snapmojis *snapmoji.Registry, commit *repository.Commit) *CommitListItem {
+	emoji, message := extractEmoji(snapmojis, commit.Message)
+
+	return &CommitListItem{
+		ID:        commit.ID,
+		ShortID:   truncateID(commit.ID),
+		Message:   message,
+		Author:    commit.Author,
+		Timestamp: formatTime(commit.Timestamp),
+		Emoji:     emoji,
+	}
+}
+
+// newIssueListItem prepares an issue for display. Labels without a known color are shown in gray.
+func newIssueListItem(i *issue.Issue, labelColors map[string]string) *IssueListItem {
+	status := "Open"
+	if string(i.Status) == "closed" {
+		status = "Closed"
+	}
+
+	item := &IssueListItem{
+		ID:         i.ID,
+		Title:      i.Title,
+		Status:     status,
+		CreatedBy:  i.CreatedBy,
+		CreatedAt:  formatTime(i.CreatedAt),
+		AssignedTo: i.AssignedTo,
+		Priority:   string(i.Priority),
+		Milestone:  i.Milestone,
+	}
+	for _, name := range i.Labels {
+		color, ok := labelColors[name]
+		if !ok {
+			color = "#888888"
+		}
+		item.Labels = append(item.Labels, &LabelItem{Name: name, Color: color})
+	}
+
+	return item
+}
+
+// newTemplateItem prepares an issue template for the template picker
+func newTemplateItem(template *issue.Template) *TemplateItem {
+	return &TemplateItem{
+		Name:     template.Name,
+		Title:    template.Title,
+		About:    template.About,
+		Labels:   template.Labels,
+		Required: template.Required,
+	}
+}
+
+// sameOrigin reports whether a request comes from a page of this server.
+// Requests without an Origin header, such as those from curl, are allowed.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && u.Host == r.Host
+}
+
+// allowEdit reports whether a request may change the repository, writing an
+// error if not. Edits must come from a page of this server, and from this
+// machine unless the server was started with --allow-edits.
+func (s *Server) allowEdit(w http.ResponseWriter, r *http.Request) bool {
+	if !sameOrigin(r) {
+		http.Error(w, "Cross-origin requests are not allowed", http.StatusForbidden)
+		return false
+	}
+	if !s.AllowEdits && !isLoopback(r) {
+		http.Error(w, "Editing from other machines is disabled on this server (start snap web with --allow-edits)", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// isLoopback reports whether a request comes from the machine the server runs on
+func isLoopback(r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// webAuthor returns the name changes made through the web UI are recorded under
+func (s *Server) webAuthor() string {
+	author, _ := config.GetValue(filepath.Join(s.Repo.Path, repository.SnapDirName, "config"), "user.name")
+	if author == "" {
+		return "web"
+	}
+	return author
+}
+
+// newRelationItems prepares the relations of an issue for display, leaving out empty ones
+func newRelationItems(relations *issue.Relations, labelColors map[string]string) []*RelationItem {
+	var items []*RelationItem
+	for _, relation := range []struct {
+		name   string
+		issues []*issue.Issue
+	}{
+		{"Blocks", relations.Blocks},
+		{"Blocked by", relations.BlockedBy},
+		{"Duplicate of", relations.DuplicateOf},
+		{"Duplicates", relations.Duplicates},
+		{"Related to", relations.Related},
+	} {
+		if len(relation.issues) == 0 {
+			continue
+		}
+		item := &RelationItem{Name: relation.name}
+		for _, related := range relation.issues {
+			item.Issues = append(item.Issues, newIssueListItem(related, labelColors))
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// newMilestoneItem prepares a milestone and its progress for display
+func newMilestoneItem(issueManager *issue.IssueManager, milestone *issue.Milestone) (*MilestoneItem, error) {
+	progress, err := issueManager.MilestoneProgress(milestone.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	status := "Open"
+	if milestone.Status == issue.StatusClosed {
+		status = "Closed"
+	}
+
+	item := &MilestoneItem{
+		Name:        milestone.Name,
+		Description: milestone.Description,
+		Status:      status,
+		Overdue:     milestone.Overdue(time.Now()),
+		Total:       progress.Total,
+		Closed:      progress.Closed,
+		Percent:     progress.Percent(),
+	}
+	if !milestone.DueDate.IsZero() {
+		item.DueDate = milestone.DueDate.Format("2006-01-02")
+	}
+
+	return item, nil
+}
+
+// extractEmoji splits a commit message into the emoji of the snapmoji it
+// starts with and the rest. Messages starting with an emoji or :code: that is
+// no longer a snapmoji keep it as it's written.
+func extractEmoji(snapmojis *snapmoji.Registry, message string) (string, string) {
+	if s, ok := snapmojis.Match(message); ok {
+		return s.Emoji, snapmojis.Strip(message)
+	}
+
+	emoji := ""
 	// Check if message starts with an emoji (Unicode character)
 	if len(message) > 0 {
 		firstRune := []rune(message)[0]
 		if firstRune > 127 { // Non-ASCII character
 			// Find the end of the emoji
+			emoji = string(firstRune)
 			for i, c := range message {
 				if i > 0 && c == ' ' {
-					return message[:i]
+					emoji = message[:i]
+					break
 				}
 			}
-			// If no space found, return the first character
-			return string([]rune(message)[0])
 		}
 	}
 
@@ -570,9 +1722,9 @@ func extractEmoji(message string) string {
 	if strings.HasPrefix(message, ":") {
 		endIndex := strings.Index(message[1:], ":")
 		if endIndex != -1 {
-			return message[:endIndex+2]
+			emoji = message[:endIndex+2]
 		}
 	}
 
-	return ""
+	return emoji, strings.TrimPrefix(strings.TrimPrefix(message, emoji), " ")
 }
@@ -1,11 +1,22 @@
 package web
 
 import (
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/stanlocht/snap/pkg/config"
+	"github.com/stanlocht/snap/pkg/issue"
+	"github.com/stanlocht/snap/pkg/repository"
+	"github.com/stanlocht/snap/pkg/snapmoji"
+	"github.com/stanlocht/snap/pkg/user"
 )
 
 // TestHandleNotFound tests the 404 handling
@@ -108,6 +119,54 @@ func TestHandleIssueDetail(t *testing.T) {
 	}
 }
 
+// TestHandleIssueDetailComments tests that comments are shown on the issue page
+func TestHandleIssueDetailComments(t *testing.T) {
+	// Setup test repository
+	repo, tempDir := setupTestRepo(t)
+	defer cleanupTestRepo(tempDir)
+
+	// Create an issue with a comment and a reply
+	issueManager := issue.NewIssueManager(repo.Path)
+	created, err := issueManager.CreateIssue("Test issue", "Description", "alice")
+	if err != nil {
+		t.Fatalf("Failed to create issue: %v", err)
+	}
+	comment, err := issueManager.AddComment(created.ID, "bob", "Looks **bad**", "")
+	if err != nil {
+		t.Fatalf("Failed to add comment: %v", err)
+	}
+	if _, err := issueManager.AddComment(created.ID, "alice", "Agreed", comment.ID); err != nil {
+		t.Fatalf("Failed to add reply: %v", err)
+	}
+
+	// Create server
+	server, err := NewServer(repo)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	// Request the issue page
+	req, err := http.NewRequest("GET", "/issue/1", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	rr := httptest.NewRecorder()
+	server.handleIssueDetail(rr, req)
+
+	// Check status code
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("Handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+
+	// Check the rendered comments
+	body := rr.Body.String()
+	for _, expected := range []string{"<strong>bad</strong>", "comment-item depth-1", "Agreed"} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("Expected issue page to contain %q", expected)
+		}
+	}
+}
+
 // TestHandleUserDetail tests the handleUserDetail function
 func TestHandleUserDetail(t *testing.T) {
 	// Setup test repository
@@ -140,25 +199,664 @@ func TestHandleUserDetail(t *testing.T) {
 	}
 }
 
+// TestHandleUsersLeaderboards tests the windowed and per-category leaderboards on the users page
+func TestHandleUsersLeaderboards(t *testing.T) {
+	repo, tempDir := setupTestRepo(t)
+	defer cleanupTestRepo(tempDir)
+
+	server, err := NewServer(repo)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	userManager := user.NewUserManager(repo.Path)
+	recent := time.Now().Add(-time.Hour).Format(time.RFC3339)
+	if err := userManager.RecordAction("veteran", user.ActionCommit, "✨ Old work", "2020-01-01T10:00:00Z"); err != nil {
+		t.Fatalf("Failed to record action: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := userManager.RecordAction("veteran", user.ActionCommit, "✨ Old work", "2020-01-02T10:00:00Z"); err != nil {
+			t.Fatalf("Failed to record action: %v", err)
+		}
+	}
+	if err := userManager.RecordAction("newcomer", user.ActionCommit, "🐛 Fix crash", recent); err != nil {
+		t.Fatalf("Failed to record action: %v", err)
+	}
+	configPath := filepath.Join(repo.Path, ".snap", "config")
+	for key, members := range map[string]string{"team.old.members": "veteran", "team.new.members": "newcomer"} {
+		if err := config.SetValue(configPath, key, members); err != nil {
+			t.Fatalf("Failed to configure team: %v", err)
+		}
+	}
+
+	tests := []struct {
+		query    string
+		expected []string
+		excluded []string
+	}{
+		{"", []string{"#1", "veteran", "newcomer"}, []string{"user-score"}},
+		{"?since=7d", []string{"newcomer", "10 points"}, []string{"veteran"}},
+		{"?category=bugs_fixed", []string{"1 bugs fixed", `<option value="bugs_fixed" selected>`}, []string{"veteran"}},
+		{"?teams=1", []string{"team old", "25 points", "team new"}, []string{"/user/veteran"}},
+		{"?team=new", []string{"newcomer", `<option value="new" selected>`}, []string{"/user/veteran"}},
+		{"?season=2020-Q1", []string{"veteran"}, []string{"newcomer"}},
+	}
+	for _, test := range tests {
+		rr := httptest.NewRecorder()
+		server.handleUsers(rr, httptest.NewRequest("GET", "/users"+test.query, nil))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("%s: handler returned wrong status code: got %v want %v", test.query, rr.Code, http.StatusOK)
+		}
+		for _, expected := range test.expected {
+			if !strings.Contains(rr.Body.String(), expected) {
+				t.Errorf("%s: expected page to contain %q", test.query, expected)
+			}
+		}
+		for _, excluded := range test.excluded {
+			if strings.Contains(rr.Body.String(), excluded) {
+				t.Errorf("%s: expected page not to contain %q", test.query, excluded)
+			}
+		}
+	}
+
+	// Viewing an ended season doesn't archive it: GET requests don't write
+	if _, err := os.Stat(filepath.Join(repo.Path, ".snap", "seasons")); !os.IsNotExist(err) {
+		t.Errorf("Expected the users page not to write season archives")
+	}
+
+	// Invalid options are rejected
+	for _, query := range []string{"?category=karma", "?since=soon", "?since=7d&season=2026-Q4", "?team=nope", "?teams=1&team=old"} {
+		rr := httptest.NewRecorder()
+		server.handleUsers(rr, httptest.NewRequest("GET", "/users"+query, nil))
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status %v, got %v", query, http.StatusBadRequest, rr.Code)
+		}
+	}
+}
+
+// TestHandleUserDetailBadges tests that earned achievements show as badges,
+// along with the user's streaks and activity heatmap
+func TestHandleUserDetailBadges(t *testing.T) {
+	repo, tempDir := setupTestRepo(t)
+	defer cleanupTestRepo(tempDir)
+
+	server, err := NewServer(repo)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	userManager := user.NewUserManager(repo.Path)
+	alice := &user.User{
+		Name:         "alice",
+		Points:       10,
+		Achievements: []user.EarnedAchievement{{ID: "night_owl", EarnedAt: "2025-03-07T02:30:00Z"}},
+		ActionLog:    []user.ActionRecord{{Action: user.ActionCommit, Points: 10, Timestamp: time.Now().Format(time.RFC3339)}},
+	}
+	if err := userManager.SaveUser(alice); err != nil {
+		t.Fatalf("Failed to save user: %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/user/alice", nil)
+	rr := httptest.NewRecorder()
+	server.handleUserDetail(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+	for _, expected := range []string{"Achievements", "🦉", "Night owl", "2025-03-07 02:30:00", "Current streak: 1 day", "heatmap-level-4"} {
+		if !strings.Contains(rr.Body.String(), expected) {
+			t.Errorf("Expected user page to contain %q", expected)
+		}
+	}
+}
+
 // TestExtractEmoji tests the extractEmoji function
 func TestExtractEmoji(t *testing.T) {
 	// Test cases
 	testCases := []struct {
 		message  string
 		expected string
+		rest     string
 	}{
-		{"✨ Initial commit", "✨"},
-		{":sparkles: Initial commit", ":sparkles:"},
-		{"No emoji", ""},
+		{"✨ Initial commit", "✨", "Initial commit"},
+		{":sparkles: Initial commit", "✨", "Initial commit"},
+		{"⚡Faster", "⚡️", "Faster"},
+		{"🦄 No longer a snapmoji", "🦄", "No longer a snapmoji"},
+		{":unicorn: No longer a snapmoji", ":unicorn:", "No longer a snapmoji"},
+		{"No emoji", "", "No emoji"},
 		// Note: The function only extracts emojis at the start of the message
-		{"Multiple 🔥 emojis ✨", ""}, // No emoji at start, so expect empty string
+		{"Multiple 🔥 emojis ✨", "", "Multiple 🔥 emojis ✨"}, // No emoji at start, so expect empty string
 	}
 
 	// Run tests
 	for _, tc := range testCases {
-		result := extractEmoji(tc.message)
-		if result != tc.expected {
-			t.Errorf("extractEmoji(%s) = %s, want %s", tc.message, result, tc.expected)
+		result, rest := extractEmoji(snapmoji.Default(), tc.message)
+		if result != tc.expected || rest != tc.rest {
+			t.Errorf("extractEmoji(%s) = %s, %s, want %s, %s", tc.message, result, rest, tc.expected, tc.rest)
+		}
+	}
+}
+
+func TestHandleMilestones(t *testing.T) {
+	// Setup test repository
+	repo, tempDir := setupTestRepo(t)
+	defer cleanupTestRepo(tempDir)
+
+	// Create a labeled issue in a milestone, plus a closed one
+	issueManager := issue.NewIssueManager(repo.Path)
+	if _, err := issueManager.CreateMilestone("v1.0", "First release", time.Time{}); err != nil {
+		t.Fatalf("Failed to create milestone: %v", err)
+	}
+	if _, err := issueManager.CreateLabel("bug", "#d73a4a", ""); err != nil {
+		t.Fatalf("Failed to create label: %v", err)
+	}
+	for _, title := range []string{"Crash", "Typo"} {
+		created, err := issueManager.CreateIssue(title, "", "alice")
+		if err != nil {
+			t.Fatalf("Failed to create issue: %v", err)
+		}
+		if err := issueManager.SetMilestone(created.ID, "v1.0"); err != nil {
+			t.Fatalf("Failed to set milestone: %v", err)
+		}
+	}
+	if err := issueManager.AddLabels(1, "bug"); err != nil {
+		t.Fatalf("Failed to add label: %v", err)
+	}
+	if err := issueManager.CloseIssue(2, ""); err != nil {
+		t.Fatalf("Failed to close issue: %v", err)
+	}
+
+	// Create server
+	server, err := NewServer(repo)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	handler, err := server.Handler()
+	if err != nil {
+		t.Fatalf("Failed to create handler: %v", err)
+	}
+
+	pages := map[string][]string{
+		"/milestones":       {`href="/milestone/v1.0"`, "1 of 2 issues closed", "width: 50%"},
+		"/milestone/v1.0":   {"First release", "#1: Crash", "#2: Typo", "50% complete"},
+		"/issues?label=bug": {"#1: Crash", `class="issue-label"`, "#d73a4a"},
+	}
+	for path, expected := range pages {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest("GET", path, nil))
+		if rr.Code != http.StatusOK {
+			t.Errorf("%s returned wrong status code: got %v want %v", path, rr.Code, http.StatusOK)
+			continue
+		}
+		body := rr.Body.String()
+		for _, text := range expected {
+			if !strings.Contains(body, text) {
+				t.Errorf("Expected %s to contain %q", path, text)
+			}
 		}
+		if path == "/issues?label=bug" && strings.Contains(body, "#2: Typo") {
+			t.Errorf("Expected the label filter to hide issue #2")
+		}
+	}
+
+	// Unknown milestones are not found
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/milestone/v9", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown milestone, got %v", rr.Code)
+	}
+}
+
+func TestHandleLinkedCommitsAndIssues(t *testing.T) {
+	// Setup test repository
+	repo, tempDir := setupTestRepo(t)
+	defer cleanupTestRepo(tempDir)
+
+	// Create an issue and a commit that fixes it
+	issueManager := issue.NewIssueManager(repo.Path)
+	if _, err := issueManager.CreateIssue("Crash on start", "", "alice"); err != nil {
+		t.Fatalf("Failed to create issue: %v", err)
+	}
+	commit := commitFile(t, repo, "main.go", "package main\n", "🐛 Fix startup crash, fixes #1")
+	if _, err := issueManager.LinkCommit(commit.ID, commit.Message); err != nil {
+		t.Fatalf("Failed to link commit: %v", err)
+	}
+
+	// Create server
+	server, err := NewServer(repo)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	handler, err := server.Handler()
+	if err != nil {
+		t.Fatalf("Failed to create handler: %v", err)
+	}
+
+	pages := map[string][]string{
+		"/issue/1":             {"Linked Commits", "/commit/" + commit.ID, "closed this issue", "Timeline", "closed the issue in commit " + commit.ID[:7]},
+		"/commit/" + commit.ID: {"Linked Issues", `href="/issue/1"`, "closed by this commit"},
+	}
+	for path, expected := range pages {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest("GET", path, nil))
+		if rr.Code != http.StatusOK {
+			t.Errorf("%s returned wrong status code: got %v want %v", path, rr.Code, http.StatusOK)
+			continue
+		}
+		body := rr.Body.String()
+		for _, text := range expected {
+			if !strings.Contains(body, text) {
+				t.Errorf("Expected %s to contain %q", path, text)
+			}
+		}
+	}
+}
+
+func TestHandleIssuesSearch(t *testing.T) {
+	// Setup test repository
+	repo, tempDir := setupTestRepo(t)
+	defer cleanupTestRepo(tempDir)
+
+	// Create more issues than fit on one page
+	issueManager := issue.NewIssueManager(repo.Path)
+	for i := 1; i <= issuesPerPage+2; i++ {
+		title := fmt.Sprintf("Task %d", i)
+		if i == 3 {
+			title = "Crash on start"
+		}
+		if _, err := issueManager.CreateIssue(title, "", "alice"); err != nil {
+			t.Fatalf("Failed to create issue: %v", err)
+		}
+	}
+
+	// Create server
+	server, err := NewServer(repo)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	get := func(path string) string {
+		rr := httptest.NewRecorder()
+		server.handleIssues(rr, httptest.NewRequest("GET", path, nil))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("%s returned wrong status code: got %v want %v", path, rr.Code, http.StatusOK)
+		}
+		return rr.Body.String()
+	}
+
+	// Search
+	body := get("/issues?q=" + url.QueryEscape(`is:open "crash on"`))
+	if !strings.Contains(body, "#3: Crash on start") || strings.Contains(body, "#1: Task 1") {
+		t.Errorf("Expected the search to find only issue #3")
+	}
+	if !strings.Contains(body, `value="is:open &#34;crash on&#34;"`) {
+		t.Errorf("Expected the search box to keep the query")
+	}
+
+	// Pagination
+	body = get("/issues")
+	if !strings.Contains(body, "Page 1 of 2") || !strings.Contains(body, "page=2") || strings.Contains(body, "#26: Task 26") {
+		t.Errorf("Expected the first page to hold %d issues and link to page 2", issuesPerPage)
+	}
+	body = get("/issues?page=2")
+	if !strings.Contains(body, "#26: Task 26") || !strings.Contains(body, "#27: Task 27") || strings.Contains(body, "#1: Task 1<") {
+		t.Errorf("Expected the second page to hold the last two issues")
+	}
+
+	// Invalid queries are reported on the page
+	body = get("/issues?q=is:pending")
+	if !strings.Contains(body, "search-error") || !strings.Contains(body, "unknown issue state") {
+		t.Errorf("Expected the page to report the invalid query")
+	}
+}
+
+func TestHandleDependencies(t *testing.T) {
+	// Setup test repository
+	repo, tempDir := setupTestRepo(t)
+	defer cleanupTestRepo(tempDir)
+
+	// Create server
+	server, err := NewServer(repo)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	handler, err := server.Handler()
+	if err != nil {
+		t.Fatalf("Failed to create handler: %v", err)
+	}
+
+	// Without links the page explains how to add them
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/dependencies", nil))
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "No Dependencies Yet") {
+		t.Errorf("Expected the empty dependencies page, got status %v", rr.Code)
+	}
+
+	// Design blocks Build, and Build duplicates Make
+	issueManager := issue.NewIssueManager(repo.Path)
+	for _, title := range []string{"Design", "Build", "Make"} {
+		if _, err := issueManager.CreateIssue(title, "", "alice"); err != nil {
+			t.Fatalf("Failed to create issue: %v", err)
+		}
+	}
+	if err := issueManager.AddLink(1, 2, issue.LinkBlocks); err != nil {
+		t.Fatalf("Failed to link issues: %v", err)
+	}
+	if err := issueManager.AddLink(2, 3, issue.LinkDuplicateOf); err != nil {
+		t.Fatalf("Failed to link issues: %v", err)
+	}
+
+	pages := map[string][]string{
+		"/dependencies": {"<svg", `href="/issue/1"`, "#2 Build", `class="graph-edge blocks"`, `class="graph-edge duplicate_of"`},
+		"/issue/2":      {"Relations", "Blocked by", "#1: Design", "Duplicate of", "#3: Make"},
+	}
+	for path, expected := range pages {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest("GET", path, nil))
+		if rr.Code != http.StatusOK {
+			t.Errorf("%s returned wrong status code: got %v want %v", path, rr.Code, http.StatusOK)
+			continue
+		}
+		body := rr.Body.String()
+		for _, text := range expected {
+			if !strings.Contains(body, text) {
+				t.Errorf("Expected %s to contain %q", path, text)
+			}
+		}
+	}
+
+	// Blocked issues are placed to the right of their blockers
+	graph, err := issueManager.DependencyGraph()
+	if err != nil {
+		t.Fatalf("Failed to build graph: %v", err)
+	}
+	layout := layoutGraph(graph)
+	if len(layout.Nodes) != 3 || len(layout.Edges) != 2 {
+		t.Fatalf("Expected 3 nodes and 2 edges, got %d and %d", len(layout.Nodes), len(layout.Edges))
+	}
+	if layout.Nodes[1].X <= layout.Nodes[0].X || layout.Edges[0].X1 >= layout.Edges[0].X2 {
+		t.Errorf("Expected #2 to be placed right of #1")
+	}
+}
+
+func TestHandleBoard(t *testing.T) {
+	// Setup test repository
+	repo, tempDir := setupTestRepo(t)
+	defer cleanupTestRepo(tempDir)
+
+	issueManager := issue.NewIssueManager(repo.Path)
+	for _, title := range []string{"Crash", "Typo"} {
+		if _, err := issueManager.CreateIssue(title, "", "alice"); err != nil {
+			t.Fatalf("Failed to create issue: %v", err)
+		}
+	}
+
+	// Create server
+	server, err := NewServer(repo)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	handler, err := server.Handler()
+	if err != nil {
+		t.Fatalf("Failed to create handler: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/board", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("/board returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+	for _, state := range issue.DefaultStates {
+		if !strings.Contains(rr.Body.String(), fmt.Sprintf(`data-state="%s"`, state)) {
+			t.Errorf("Expected the board to have a %s column", state)
+		}
+	}
+
+	move := func(form url.Values, header map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/board/move", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.RemoteAddr = "127.0.0.1:50000"
+		for name, value := range header {
+			req.Header.Set(name, value)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// Forms are sent back to the board
+	rr = move(url.Values{"issue": {"1"}, "state": {"review"}}, nil)
+	if rr.Code != http.StatusSeeOther || rr.Header().Get("Location") != "/board" {
+		t.Errorf("Expected a redirect to the board, got %v %q", rr.Code, rr.Header().Get("Location"))
+	}
+
+	// Scripts get the new state
+	rr = move(url.Values{"issue": {"2"}, "state": {"done"}}, map[string]string{"Accept": "application/json"})
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"status":"closed"`) {
+		t.Errorf("Expected the issue to be closed, got %v %s", rr.Code, rr.Body.String())
+	}
+
+	invalid := []struct {
+		form   url.Values
+		header map[string]string
+		code   int
+	}{
+		{url.Values{"issue": {"1"}, "state": {"nowhere"}}, nil, http.StatusBadRequest},
+		{url.Values{"issue": {"x"}, "state": {"done"}}, nil, http.StatusBadRequest},
+		{url.Values{"issue": {"1"}, "state": {"done"}}, map[string]string{"Origin": "http://evil.example"}, http.StatusForbidden},
+	}
+	for _, test := range invalid {
+		if rr := move(test.form, test.header); rr.Code != test.code {
+			t.Errorf("Expected moving %v to return %v, got %v", test.form, test.code, rr.Code)
+		}
+	}
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/board/move", nil))
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected GET /board/move to be rejected, got %v", rr.Code)
+	}
+
+	// The moves are recorded on the issues
+	moved, err := issueManager.GetIssue(1)
+	if err != nil {
+		t.Fatalf("Failed to get issue: %v", err)
+	}
+	history := moved.History()
+	if len(history) != 1 || history[0].Describe() != "moved the issue from todo to review" {
+		t.Errorf("Expected the move to review to be recorded, got %d events", len(history))
+	}
+	closed, err := issueManager.GetIssue(2)
+	if err != nil {
+		t.Fatalf("Failed to get issue: %v", err)
+	}
+	if closed.Status != issue.StatusClosed {
+		t.Errorf("Expected moving #2 to done to close it")
+	}
+
+	// Other machines can only move issues when the server allows edits
+	remoteMove := func() int {
+		req := httptest.NewRequest("POST", "/board/move", strings.NewReader(url.Values{"issue": {"1"}, "state": {"in-progress"}}.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.RemoteAddr = "192.0.2.1:50000"
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr.Code
+	}
+	if code := remoteMove(); code != http.StatusForbidden {
+		t.Errorf("Expected a move from another machine to be forbidden, got %v", code)
+	}
+	server.AllowEdits = true
+	if code := remoteMove(); code != http.StatusSeeOther {
+		t.Errorf("Expected a move from another machine to be allowed with --allow-edits, got %v", code)
+	}
+
+	// Issues still blocked by open issues can't be moved to done
+	blocked, err := issueManager.CreateIssue("Release", "", "alice")
+	if err != nil {
+		t.Fatalf("Failed to create issue: %v", err)
+	}
+	if err := issueManager.AddLink(1, blocked.ID, issue.LinkBlocks); err != nil {
+		t.Fatalf("Failed to link issues: %v", err)
+	}
+	rr = move(url.Values{"issue": {strconv.Itoa(blocked.ID)}, "state": {"done"}}, map[string]string{"Accept": "application/json"})
+	if rr.Code != http.StatusConflict || !strings.Contains(rr.Body.String(), "blocked") {
+		t.Errorf("Expected moving a blocked issue to done to be refused, got %v %s", rr.Code, rr.Body.String())
+	}
+}
+
+// TestHandleNewIssue tests the template picker and new issue form
+func TestHandleNewIssue(t *testing.T) {
+	// Setup test repository
+	repo, tempDir := setupTestRepo(t)
+	defer cleanupTestRepo(tempDir)
+
+	templateDir := filepath.Join(repo.Path, ".snap", issue.TemplateDirName)
+	if err := os.MkdirAll(templateDir, 0755); err != nil {
+		t.Fatalf("Failed to create template directory: %v", err)
+	}
+	template := "---\nname: Bug report\ntitle: \"[Bug] \"\nlabels: bug\nrequired: Steps to reproduce\n---\n## Steps to reproduce\n"
+	if err := os.WriteFile(filepath.Join(templateDir, "bug.md"), []byte(template), 0644); err != nil {
+		t.Fatalf("Failed to write template: %v", err)
+	}
+
+	// Create server
+	server, err := NewServer(repo)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	handler, err := server.Handler()
+	if err != nil {
+		t.Fatalf("Failed to create handler: %v", err)
+	}
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest("GET", path, nil))
+		return rr
+	}
+	post := func(form url.Values, header map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/issues/new", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.RemoteAddr = "127.0.0.1:50000"
+		for name, value := range header {
+			req.Header.Set(name, value)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// The picker lists the templates and a blank issue
+	rr := get("/issues/new")
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `href="/issues/new?template=bug"`) || !strings.Contains(rr.Body.String(), "Blank issue") {
+		t.Errorf("Expected the template picker, got %v", rr.Code)
+	}
+
+	// Picking a template fills in the form
+	rr = get("/issues/new?template=bug")
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `value="[Bug] "`) || !strings.Contains(rr.Body.String(), `rows="16">## Steps to reproduce`) {
+		t.Errorf("Expected the form to start from the template, got %v", rr.Code)
+	}
+	if rr := get("/issues/new?template=missing"); rr.Code != http.StatusNotFound {
+		t.Errorf("Expected a missing template to return %v, got %v", http.StatusNotFound, rr.Code)
+	}
+
+	// Required sections are checked, keeping what was written
+	rr = post(url.Values{"template": {"bug"}, "title": {"[Bug] Crash"}, "description": {"## Steps to reproduce\n"}}, nil)
+	if rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), "Steps to reproduce") || !strings.Contains(rr.Body.String(), `value="[Bug] Crash"`) {
+		t.Errorf("Expected the form again with an error, got %v", rr.Code)
+	}
+
+	rr = post(url.Values{"template": {"bug"}, "title": {"[Bug] Crash"}, "description": {"## Steps to reproduce\nRun it"}, "author": {"alice"}}, nil)
+	if rr.Code != http.StatusSeeOther || rr.Header().Get("Location") != "/issue/1" {
+		t.Fatalf("Expected a redirect to the new issue, got %v %q", rr.Code, rr.Header().Get("Location"))
+	}
+	created, err := issue.NewIssueManager(repo.Path).GetIssue(1)
+	if err != nil {
+		t.Fatalf("Failed to get issue: %v", err)
+	}
+	if created.CreatedBy != "alice" || !created.HasLabel("bug") {
+		t.Errorf("Expected an issue by alice labeled bug, got %+v", created)
+	}
+
+	// Blank issues only need a title
+	if rr := post(url.Values{"template": {""}, "title": {"Typo"}}, nil); rr.Code != http.StatusSeeOther {
+		t.Errorf("Expected a blank issue to be created, got %v", rr.Code)
+	}
+	if rr := post(url.Values{"template": {""}, "title": {" "}}, nil); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected an issue without a title to fail, got %v", rr.Code)
+	}
+	if rr := post(url.Values{"template": {""}, "title": {"Spam"}}, map[string]string{"Origin": "http://evil.example"}); rr.Code != http.StatusForbidden {
+		t.Errorf("Expected a cross-origin post to be rejected, got %v", rr.Code)
+	}
+}
+
+func TestHandleReleases(t *testing.T) {
+	// Setup test repository
+	repo, tempDir := setupTestRepo(t)
+	defer cleanupTestRepo(tempDir)
+
+	// Create server
+	server, err := NewServer(repo)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	handler, err := server.Handler()
+	if err != nil {
+		t.Fatalf("Failed to create handler: %v", err)
+	}
+
+	// Without tags, the page explains how to tag a release
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/releases", nil))
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "snap tag v1.0.0") {
+		t.Errorf("Expected instructions for tagging a release, got %v", rr.Code)
+	}
+
+	// Tag two releases
+	if _, err := issue.NewIssueManager(repo.Path).CreateIssue("Crash", "", "alice"); err != nil {
+		t.Fatalf("Failed to create issue: %v", err)
+	}
+	for i, message := range []string{"✨ Add dark mode", "🐛 Fix crash\n\nFixes #1"} {
+		commit, err := repo.CreateCommit(message, "alice", "", &repository.Tree{Entries: map[string]string{"a.txt": message}})
+		if err != nil {
+			t.Fatalf("Failed to create commit: %v", err)
+		}
+		if err := repo.CreateTag(fmt.Sprintf("v1.%d.0", i), commit.ID); err != nil {
+			t.Fatalf("Failed to create tag: %v", err)
+		}
+	}
+
+	pages := map[string][]string{
+		"/releases":       {`href="/release/v1.0.0"`, `href="/release/v1.1.0"`, "Changes since v1.0.0", "First release"},
+		"/release/v1.1.0": {"Fixes", "Fix crash", `href="/issue/1"`, "#1: Crash", "(closed)", "Semver impact: patch", "alice"},
+		"/release/v1.0.0": {"Features", "Add dark mode", "Semver impact: minor"},
+	}
+	for path, expected := range pages {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest("GET", path, nil))
+		if rr.Code != http.StatusOK {
+			t.Errorf("%s returned wrong status code: got %v want %v", path, rr.Code, http.StatusOK)
+			continue
+		}
+		body := rr.Body.String()
+		for _, text := range expected {
+			if !strings.Contains(body, text) {
+				t.Errorf("Expected %s to contain %q", path, text)
+			}
+		}
+	}
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/release/v1.1.0", nil))
+	if strings.Contains(rr.Body.String(), "Add dark mode") {
+		t.Errorf("Expected the release notes to leave out the previous release's changes")
+	}
+
+	// Unknown tags are not found
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/release/v9", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown release, got %v", rr.Code)
 	}
 }
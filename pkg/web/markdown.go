@@ -0,0 +1,103 @@
+package web
+
+import (
+	"html"
+	"html/template"
+	"regexp"
+	"strings"
+)
+
+var (
+	markdownCode   = regexp.MustCompile("`([^`]+)`")
+	markdownBold   = regexp.MustCompile(`\*\*([^*]+)\*\*`)
+	markdownItalic = regexp.MustCompile(`\*([^*]+)\*`)
+	markdownLink   = regexp.MustCompile(`\[([^\]]+)\]\((https?://[^\s)]+)\)`)
+)
+
+// renderInline renders inline markdown in a line that has already been escaped
+func renderInline(line string) string {
+	// Keep code spans out of the other rules
+	var spans []string
+	line = markdownCode.ReplaceAllStringFunc(line, func(match string) string {
+		spans = append(spans, "<code>"+match[1:len(match)-1]+"</code>")
+		return "\x00"
+	})
+
+	line = markdownLink.ReplaceAllString(line, `<a href="$2" rel="nofollow">$1</a>`)
+	line = markdownBold.ReplaceAllString(line, "<strong>$1</strong>")
+	line = markdownItalic.ReplaceAllString(line, "<em>$1</em>")
+
+	for _, span := range spans {
+		line = strings.Replace(line, "\x00", span, 1)
+	}
+	return line
+}
+
+// renderMarkdown renders a small, safe subset of markdown: paragraphs, code
+// blocks, bullet lists, inline code, bold, italics and http(s) links.
+// All input is escaped first, so raw HTML is never passed through.
+func renderMarkdown(text string) template.HTML {
+	var out strings.Builder
+	var paragraph []string
+	inList, inCode := false, false
+
+	flushParagraph := func() {
+		if len(paragraph) > 0 {
+			out.WriteString("<p>" + strings.Join(paragraph, "<br>") + "</p>")
+			paragraph = nil
+		}
+	}
+	closeList := func() {
+		if inList {
+			out.WriteString("</ul>")
+			inList = false
+		}
+	}
+
+	text = strings.ReplaceAll(strings.ReplaceAll(text, "\x00", ""), "\r\n", "\n")
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		// Code blocks
+		if strings.HasPrefix(trimmed, "```") {
+			if inCode {
+				out.WriteString("</code></pre>")
+			} else {
+				flushParagraph()
+				closeList()
+				out.WriteString("<pre><code>")
+			}
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			out.WriteString(html.EscapeString(line) + "\n")
+			continue
+		}
+
+		escaped := renderInline(html.EscapeString(trimmed))
+		switch {
+		case trimmed == "":
+			flushParagraph()
+			closeList()
+		case strings.HasPrefix(trimmed, "- "), strings.HasPrefix(trimmed, "* "):
+			flushParagraph()
+			if !inList {
+				out.WriteString("<ul>")
+				inList = true
+			}
+			out.WriteString("<li>" + renderInline(html.EscapeString(trimmed[2:])) + "</li>")
+		default:
+			closeList()
+			paragraph = append(paragraph, escaped)
+		}
+	}
+
+	if inCode {
+		out.WriteString("</code></pre>")
+	}
+	flushParagraph()
+	closeList()
+
+	return template.HTML(out.String())
+}
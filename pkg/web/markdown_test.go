@@ -0,0 +1,35 @@
+package web
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	tests := []struct {
+		input    string
+		contains []string
+	}{
+		{"Hello **world**", []string{"<p>Hello <strong>world</strong></p>"}},
+		{"Use `snap *push*`", []string{"<code>snap *push*</code>"}},
+		{"- one\n- two", []string{"<ul><li>one</li><li>two</li></ul>"}},
+		{"```\nif a < b {}\n```", []string{"<pre><code>if a &lt; b {}\n</code></pre>"}},
+		{"[docs](https://example.com/a?b=1&c=2)", []string{`<a href="https://example.com/a?b=1&amp;c=2" rel="nofollow">docs</a>`}},
+		{"first\n\nsecond", []string{"<p>first</p><p>second</p>"}},
+	}
+
+	for _, test := range tests {
+		output := string(renderMarkdown(test.input))
+		for _, expected := range test.contains {
+			if !strings.Contains(output, expected) {
+				t.Errorf("Expected rendering of %q to contain %q, got %q", test.input, expected, output)
+			}
+		}
+	}
+
+	// Raw HTML and script links are escaped
+	output := string(renderMarkdown(`<script>alert(1)</script> [x](javascript:alert(1))`))
+	if strings.Contains(output, "<script>") || strings.Contains(output, `href="javascript`) {
+		t.Errorf("Expected HTML to be escaped, got %q", output)
+	}
+}
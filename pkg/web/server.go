@@ -6,8 +6,10 @@ import (
 	"html/template"
 	"io/fs"
 	"net/http"
+	"sync"
 	"time"
 
+	"github.com/stanlocht/snap/pkg/remote"
 	"github.com/stanlocht/snap/pkg/repository"
 )
 
@@ -19,8 +21,12 @@ var staticFS embed.FS
 
 // Server represents the web server
 type Server struct {
-	Repo      *repository.Repository
-	Templates *template.Template
+	Repo       *repository.Repository
+	Templates  *template.Template
+	AllowPush  bool // Accept pushes on the transport endpoint
+	AllowEdits bool // Accept changes to issues from other machines, not just this one
+
+	pushMu sync.Mutex // Serializes pushes so reference updates don't race
 }
 
 // NewServer creates a new web server
@@ -38,29 +44,52 @@ func NewServer(repo *repository.Repository) (*Server, error) {
 }
 
 // StartServer starts the web server
-func StartServer(repo *repository.Repository, port int) error {
+func StartServer(repo *repository.Repository, port int, allowPush, allowEdits bool) error {
 	// Create server
 	server, err := NewServer(repo)
 	if err != nil {
 		return err
 	}
+	server.AllowPush = allowPush
+	server.AllowEdits = allowEdits
+
+	handler, err := server.Handler()
+	if err != nil {
+		return err
+	}
+
+	// Start server
+	addr := fmt.Sprintf(":%d", port)
+	return http.ListenAndServe(addr, handler)
+}
 
+// Handler returns an HTTP handler serving the web interface and the transport endpoints
+func (s *Server) Handler() (http.Handler, error) {
 	// Set up static file server
 	staticContent, err := fs.Sub(staticFS, "static")
 	if err != nil {
-		return fmt.Errorf("error setting up static file server: %w", err)
+		return nil, fmt.Errorf("error setting up static file server: %w", err)
 	}
 
 	// Register handlers
-	http.HandleFunc("/", server.handleHome)
-	http.HandleFunc("/commits", server.handleCommits)
-	http.HandleFunc("/commit/", server.handleCommitDetail)
-	http.HandleFunc("/issues", server.handleIssues)
-	http.HandleFunc("/issue/", server.handleIssueDetail)
-	http.HandleFunc("/users", server.handleUsers)
-	http.HandleFunc("/user/", server.handleUserDetail)
-	http.HandleFunc("/quest", server.handleQuest)
-	http.HandleFunc("/test", func(w http.ResponseWriter, r *http.Request) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.handleHome)
+	mux.HandleFunc("/commits", s.handleCommits)
+	mux.HandleFunc("/commit/", s.handleCommitDetail)
+	mux.HandleFunc("/issues", s.handleIssues)
+	mux.HandleFunc("/issues/new", s.handleNewIssue)
+	mux.HandleFunc("/issue/", s.handleIssueDetail)
+	mux.HandleFunc("/milestones", s.handleMilestones)
+	mux.HandleFunc("/milestone/", s.handleMilestoneDetail)
+	mux.HandleFunc("/releases", s.handleReleases)
+	mux.HandleFunc("/release/", s.handleReleaseDetail)
+	mux.HandleFunc("/board", s.handleBoard)
+	mux.HandleFunc("/board/move", s.handleBoardMove)
+	mux.HandleFunc("/dependencies", s.handleDependencies)
+	mux.HandleFunc("/users", s.handleUsers)
+	mux.HandleFunc("/user/", s.handleUserDetail)
+	mux.HandleFunc("/quest", s.handleQuest)
+	mux.HandleFunc("/test", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "text/html")
 		data, err := templateFS.ReadFile("templates/test.html")
 		if err != nil {
@@ -69,11 +98,14 @@ func StartServer(repo *repository.Repository, port int) error {
 		}
 		w.Write(data)
 	})
-	http.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.FS(staticContent))))
+	mux.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.FS(staticContent))))
 
-	// Start server
-	addr := fmt.Sprintf(":%d", port)
-	return http.ListenAndServe(addr, nil)
+	// Register transport endpoints
+	mux.HandleFunc(remote.InfoRefsPath, s.handleInfoRefs)
+	mux.HandleFunc(remote.UploadPackPath, s.handleUploadPack)
+	mux.HandleFunc(remote.ReceivePackPath, s.handleReceivePack)
+
+	return mux, nil
 }
 
 // formatTime formats a time.Time for display
@@ -0,0 +1,57 @@
+// Drag and drop for the issue board. Cards can also be moved with their
+// forms, so the board works without JavaScript too.
+(function () {
+    function updateCounts() {
+        document.querySelectorAll('.board-column').forEach(function (column) {
+            column.querySelector('.board-count').textContent = column.querySelectorAll('.board-card').length;
+        });
+    }
+
+    document.querySelectorAll('.board-card').forEach(function (card) {
+        card.addEventListener('dragstart', function (event) {
+            event.dataTransfer.setData('text/plain', card.dataset.issue);
+            event.dataTransfer.effectAllowed = 'move';
+            card.classList.add('dragging');
+        });
+        card.addEventListener('dragend', function () {
+            card.classList.remove('dragging');
+        });
+    });
+
+    document.querySelectorAll('.board-column').forEach(function (column) {
+        column.addEventListener('dragover', function (event) {
+            event.preventDefault();
+            column.classList.add('drop-target');
+        });
+        column.addEventListener('dragleave', function () {
+            column.classList.remove('drop-target');
+        });
+        column.addEventListener('drop', function (event) {
+            event.preventDefault();
+            column.classList.remove('drop-target');
+
+            var card = document.querySelector('.board-card[data-issue="' + event.dataTransfer.getData('text/plain') + '"]');
+            if (!card || card.closest('.board-column') === column) {
+                return;
+            }
+
+            var body = new URLSearchParams({ issue: card.dataset.issue, state: column.dataset.state });
+            fetch('/board/move', { method: 'POST', headers: { 'Accept': 'application/json' }, body: body })
+                .then(function (response) {
+                    if (!response.ok) {
+                        return response.text().then(function (text) { throw new Error(text); });
+                    }
+                    return response.json();
+                })
+                .then(function (moved) {
+                    column.querySelector('.board-cards').appendChild(card);
+                    card.querySelector('select[name="state"]').value = moved.state;
+                    card.classList.toggle('closed', moved.status === 'closed');
+                    updateCounts();
+                })
+                .catch(function (error) {
+                    alert(error.message);
+                });
+        });
+    });
+})();
@@ -0,0 +1,116 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/stanlocht/snap/pkg/remote"
+	"github.com/stanlocht/snap/pkg/repository"
+)
+
+// maxFetchRequestSize limits the size of a want/have negotiation request
+const maxFetchRequestSize = 16 << 20
+
+// maxPushSize limits the total size of the objects in a push, which is held in
+// memory until its reference updates are checked
+const maxPushSize = 256 << 20
+
+// maxPushRequestSize limits the size of a push request: its objects plus the
+// reference updates and pack headers around them
+const maxPushRequestSize = maxPushSize + maxFetchRequestSize
+
+// writeJSON writes a value as a JSON response
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
+
+// handleInfoRefs advertises the references the repository offers
+func (s *Server) handleInfoRefs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	adv, err := remote.AdvertiseRefs(s.Repo)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error listing references: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, adv)
+}
+
+// handleUploadPack streams the objects a client needs to get from its haves to its wants
+func (s *Server) handleUploadPack(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request remote.FetchRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxFetchRequestSize)).Decode(&request); err != nil {
+		http.Error(w, fmt.Sprintf("Error parsing request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	// Unknown wants are an error, unknown haves are simply ignored
+	for _, want := range request.Wants {
+		if !s.Repo.HasObject(repository.ObjectCommit, want) {
+			http.Error(w, fmt.Sprintf("Unknown commit: %s", want), http.StatusBadRequest)
+			return
+		}
+	}
+
+	objects, err := s.Repo.ReachableObjects(request.Wants, request.Haves)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error collecting objects: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", remote.PackContentType)
+	pack := remote.NewPackWriter(w)
+	for _, object := range objects {
+		if err := pack.WriteObject(object); err != nil {
+			return
+		}
+	}
+	pack.Close()
+}
+
+// handleReceivePack stores pushed objects and applies reference updates
+func (s *Server) handleReceivePack(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !s.AllowPush {
+		http.Error(w, "Pushing is disabled on this server (start snap web with --allow-push)", http.StatusForbidden)
+		return
+	}
+
+	objects, updates, err := remote.ReadReceiveRequest(http.MaxBytesReader(w, r.Body, maxPushRequestSize), maxPushSize)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.Is(err, remote.ErrPackTooLarge) || errors.As(err, &tooLarge) {
+			http.Error(w, fmt.Sprintf("Push is larger than %d MiB", maxPushSize>>20), http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, fmt.Sprintf("Error reading push: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	s.pushMu.Lock()
+	err = remote.ReceivePack(s.Repo, objects, updates)
+	s.pushMu.Unlock()
+
+	status := http.StatusOK
+	if err != nil {
+		status = http.StatusConflict
+	}
+	writeJSON(w, status, remote.NewPushResponse(err))
+}
@@ -0,0 +1,190 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stanlocht/snap/pkg/remote"
+	"github.com/stanlocht/snap/pkg/repository"
+	"github.com/stanlocht/snap/pkg/storage"
+)
+
+// commitFile writes a file into the working tree, stages it and commits it
+func commitFile(t *testing.T, repo *repository.Repository, name, content, message string) *repository.Commit {
+	t.Helper()
+
+	filePath := filepath.Join(repo.Path, name)
+	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	index, err := storage.LoadIndex(repo.Path)
+	if err != nil {
+		t.Fatalf("Failed to load index: %v", err)
+	}
+	if _, err := index.AddFile(repo.Path, filePath); err != nil {
+		t.Fatalf("Failed to add file: %v", err)
+	}
+	if err := index.SaveIndex(repo.Path); err != nil {
+		t.Fatalf("Failed to save index: %v", err)
+	}
+
+	commit, err := repo.CreateCommit(message, "testuser", "test@example.com", &repository.Tree{Entries: index.Entries})
+	if err != nil {
+		t.Fatalf("Failed to create commit: %v", err)
+	}
+	return commit
+}
+
+// startTransportServer serves a repository over HTTP
+func startTransportServer(t *testing.T, repo *repository.Repository, allowPush bool) *httptest.Server {
+	t.Helper()
+
+	server, err := NewServer(repo)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	server.AllowPush = allowPush
+
+	handler, err := server.Handler()
+	if err != nil {
+		t.Fatalf("Failed to create handler: %v", err)
+	}
+
+	ts := httptest.NewServer(handler)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestHTTPCloneAndPush(t *testing.T) {
+	// Setup upstream repository with a commit
+	upstream, tempDir := setupTestRepo(t)
+	defer cleanupTestRepo(tempDir)
+	first := commitFile(t, upstream, "README.md", "hello\n", "📝 Add readme")
+
+	ts := startTransportServer(t, upstream, true)
+
+	// Clone over HTTP
+	clone, result, err := remote.Clone(ts.URL+"/", filepath.Join(t.TempDir(), "clone"))
+	if err != nil {
+		t.Fatalf("Failed to clone: %v", err)
+	}
+	if result.Objects == 0 {
+		t.Errorf("Expected objects to be received")
+	}
+	headID, err := clone.GetHEADCommitID()
+	if err != nil || headID != first.ID {
+		t.Errorf("Expected HEAD to be %s, got %s (%v)", first.ID, headID, err)
+	}
+	data, err := os.ReadFile(filepath.Join(clone.Path, "README.md"))
+	if err != nil || string(data) != "hello\n" {
+		t.Errorf("Expected README.md to be checked out, got %q (%v)", data, err)
+	}
+
+	// Push a new commit back
+	second := commitFile(t, clone, "main.go", "package main\n", "✨ Add main")
+	pushResult, err := remote.Push(clone, remote.DefaultRemoteName, "master", false)
+	if err != nil {
+		t.Fatalf("Failed to push: %v", err)
+	}
+	if pushResult.Old != first.ID || pushResult.New != second.ID {
+		t.Errorf("Expected push from %s to %s, got %s to %s", first.ID, second.ID, pushResult.Old, pushResult.New)
+	}
+	upstreamID, err := upstream.ResolveRef(repository.BranchRefPrefix + "master")
+	if err != nil || upstreamID != second.ID {
+		t.Errorf("Expected upstream master to be %s, got %s (%v)", second.ID, upstreamID, err)
+	}
+	if !upstream.HasObject(repository.ObjectCommit, second.ID) {
+		t.Errorf("Expected pushed commit to be stored upstream")
+	}
+	if data, err := os.ReadFile(filepath.Join(upstream.Path, "main.go")); err != nil || string(data) != "package main\n" {
+		t.Errorf("Expected the pushed main.go to be checked out upstream, got %q (%v)", data, err)
+	}
+
+	// A diverged upstream rejects a non-fast-forward push
+	commitFile(t, upstream, "upstream.txt", "theirs\n", "✨ Upstream change")
+	commitFile(t, clone, "local.txt", "ours\n", "✨ Local change")
+	if _, err := remote.Fetch(clone, remote.DefaultRemoteName); err != nil {
+		t.Fatalf("Failed to fetch: %v", err)
+	}
+	if _, err := remote.Push(clone, remote.DefaultRemoteName, "master", false); !errors.Is(err, remote.ErrNonFastForward) {
+		t.Errorf("Expected ErrNonFastForward, got %v", err)
+	}
+}
+
+func TestHTTPPushDisabled(t *testing.T) {
+	// Setup upstream repository with a commit
+	upstream, tempDir := setupTestRepo(t)
+	defer cleanupTestRepo(tempDir)
+	first := commitFile(t, upstream, "README.md", "hello\n", "📝 Add readme")
+
+	ts := startTransportServer(t, upstream, false)
+
+	// Cloning still works
+	clone, _, err := remote.Clone(ts.URL, filepath.Join(t.TempDir(), "clone"))
+	if err != nil {
+		t.Fatalf("Failed to clone: %v", err)
+	}
+
+	// Pushing is refused and the upstream branch doesn't move
+	commitFile(t, clone, "main.go", "package main\n", "✨ Add main")
+	if _, err := remote.Push(clone, remote.DefaultRemoteName, "master", false); err == nil {
+		t.Errorf("Expected push to be refused")
+	}
+	upstreamID, _ := upstream.ResolveRef(repository.BranchRefPrefix + "master")
+	if upstreamID != first.ID {
+		t.Errorf("Expected upstream master to stay at %s, got %s", first.ID, upstreamID)
+	}
+}
+
+func TestHTTPPushLimits(t *testing.T) {
+	// Setup upstream repository with a commit
+	upstream, tempDir := setupTestRepo(t)
+	defer cleanupTestRepo(tempDir)
+	first := commitFile(t, upstream, "README.md", "hello\n", "📝 Add readme")
+
+	ts := startTransportServer(t, upstream, true)
+	clone, _, err := remote.Clone(ts.URL, filepath.Join(t.TempDir(), "clone"))
+	if err != nil {
+		t.Fatalf("Failed to clone: %v", err)
+	}
+
+	// Staged changes upstream refuse pushes to its checked-out branch
+	if err := os.WriteFile(filepath.Join(upstream.Path, "draft.txt"), []byte("draft\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	index, err := storage.LoadIndex(upstream.Path)
+	if err != nil {
+		t.Fatalf("Failed to load index: %v", err)
+	}
+	if _, err := index.AddFile(upstream.Path, filepath.Join(upstream.Path, "draft.txt")); err != nil {
+		t.Fatalf("Failed to add file: %v", err)
+	}
+	if err := index.SaveIndex(upstream.Path); err != nil {
+		t.Fatalf("Failed to save index: %v", err)
+	}
+	commitFile(t, clone, "main.go", "package main\n", "✨ Add main")
+	if _, err := remote.Push(clone, remote.DefaultRemoteName, "master", false); err == nil {
+		t.Errorf("Expected a push to a branch with staged changes to be refused")
+	}
+	if upstreamID, _ := upstream.ResolveRef(repository.BranchRefPrefix + "master"); upstreamID != first.ID {
+		t.Errorf("Expected upstream master to stay at %s, got %s", first.ID, upstreamID)
+	}
+
+	// Pushes over the size limit are refused from the object headers
+	body := fmt.Sprintf("[]\nSNAPPACK 1\nblob b1 %d\n", maxPushSize+1)
+	resp, err := http.Post(ts.URL+remote.ReceivePackPath, remote.PackContentType, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Failed to post push: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected 413 for an oversized push, got %d", resp.StatusCode)
+	}
+}